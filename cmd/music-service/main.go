@@ -16,8 +16,18 @@ package main
 // @host      localhost:8080
 // @BasePath  /api/v1
 
+// @securityDefinitions.apikey BearerAuth
+// @in header
+// @name Authorization
+// @description "Bearer <token>" from /auth/login, or "Bearer <api key>"
+
+// @securityDefinitions.apikey ApiKeyAuth
+// @in header
+// @name X-API-Key
+
 import (
 	"context"
+	"fmt"
 	"go.uber.org/fx"
 	"log"
 	"log/slog"
@@ -25,10 +35,12 @@ import (
 	"music-service/internal/api/routes"
 	"music-service/internal/api/services"
 	"music-service/internal/config"
+	"music-service/internal/storage/blob"
 	"music-service/internal/storage/database/repository"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	_ "music-service/docs"
 )
@@ -46,8 +58,15 @@ func provideDBManager(cfg *config.Config) *repository.Manager {
 	return repository.MustConnectDB(cfg, ctx)
 }
 
-func provideRepositories(dbManager *repository.Manager) (repository.GroupRepositoryInterface, repository.SongRepositoryInterface) {
-	return dbManager.Groups, dbManager.Songs
+func provideRepositories(dbManager *repository.Manager) (
+	repository.GroupRepositoryInterface,
+	repository.SongRepositoryInterface,
+	repository.ArtworkRepositoryInterface,
+	repository.SmartPlaylistRepositoryInterface,
+	repository.UserRepositoryInterface,
+	repository.LibraryRepositoryInterface,
+) {
+	return dbManager.Groups, dbManager.Songs, dbManager.Artworks, dbManager.SmartPlaylists, dbManager.Users, dbManager.Library
 }
 
 // Add this function to provide a *slog.Logger
@@ -69,13 +88,35 @@ func main() {
 			provideDBManager,
 			provideRepositories,
 
+			// Blob storage
+			blob.NewLocalStorage,
+
 			// Services
+			services.NewSimilarityService,
 			services.NewSongService,
 			services.NewGroupService,
+			services.NewArtworkService,
+			services.NewPlaylistService,
+			services.NewSmartPlaylistService,
+			services.NewAuthService,
+			services.NewUserService,
+			services.NewLibraryService,
+			services.NewRatingService,
+			services.NewDuplicateService,
+			services.NewTrashService,
+			services.NewIdempotencyService,
 
 			// Handlers setup
 			handlers.NewGroupHandler,
 			handlers.NewSongHandler,
+			handlers.NewArtworkHandler,
+			handlers.NewPlaylistHandler,
+			handlers.NewSmartPlaylistHandler,
+			handlers.NewAuthHandler,
+			handlers.NewUserHandler,
+			handlers.NewLibraryHandler,
+			handlers.NewRatingHandler,
+			handlers.NewTrashHandler,
 
 			// Router
 			routes.NewRouter,
@@ -86,6 +127,10 @@ func main() {
 
 		// Lifecycle hooks
 		fx.Invoke(registerHooks),
+		fx.Invoke(createAdmin),
+		fx.Invoke(loadSimilarityIndex),
+		fx.Invoke(startTrashPurger),
+		fx.Invoke(startIdempotencyKeyPurger),
 		fx.Invoke(startHTTPServer),
 	)
 
@@ -130,6 +175,109 @@ func startHTTPServer(lc fx.Lifecycle, router *routes.Router, log *slog.Logger) {
 	})
 }
 
+// createAdmin creates the configured admin account at startup, self-registered users are never made admins
+func createAdmin(lc fx.Lifecycle, authService *services.AuthService, cfg *config.Config, log *slog.Logger) {
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			if cfg.Internal.Auth.AdminUsername == "" {
+				log.Warn("No admin account is configured, set auth.admin_username and auth.admin_password to create one")
+				return nil
+			}
+
+			created, err := authService.EnsureAdmin(ctx, cfg.Internal.Auth.AdminUsername, cfg.Internal.Auth.AdminPassword)
+			if err != nil {
+				return fmt.Errorf("failed to create admin account: %w", err)
+			}
+			if created {
+				log.Info("Created admin account", "username", cfg.Internal.Auth.AdminUsername)
+			}
+			return nil
+		},
+	})
+}
+
+// loadSimilarityIndex indexes the lyrics of all songs in the background so startup is not held up by large catalogs
+func loadSimilarityIndex(lc fx.Lifecycle, similarityService *services.SimilarityService, log *slog.Logger) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				if err := similarityService.LoadIndex(ctx); err != nil {
+					log.Error("Failed to load lyrics similarity index", "error", err)
+					return
+				}
+				log.Info("Lyrics similarity index loaded")
+			}()
+			return nil
+		},
+		OnStop: func(context.Context) error {
+			cancel()
+			return nil
+		},
+	})
+}
+
+// startTrashPurger permanently deletes expired trash at startup and then every purge interval
+func startTrashPurger(lc fx.Lifecycle, trashService *services.TrashService, cfg *config.Config, log *slog.Logger) {
+	runPeriodically(lc, cfg.Internal.Trash.PurgeInterval, func(ctx context.Context) {
+		report, err := trashService.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("Failed to purge expired trash", "error", err)
+		}
+		if report.Groups > 0 || report.Songs > 0 {
+			log.Info("Purged expired trash", "groups", report.Groups, "songs", report.Songs)
+		}
+	})
+}
+
+// startIdempotencyKeyPurger deletes expired idempotency keys at startup and then every purge interval
+func startIdempotencyKeyPurger(lc fx.Lifecycle, idempotencyService *services.IdempotencyService, cfg *config.Config, log *slog.Logger) {
+	runPeriodically(lc, cfg.Internal.Idempotency.PurgeInterval, func(ctx context.Context) {
+		deleted, err := idempotencyService.PurgeExpired(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Error("Failed to purge expired idempotency keys", "error", err)
+		}
+		if deleted > 0 {
+			log.Info("Purged expired idempotency keys", "keys", deleted)
+		}
+	})
+}
+
+// runPeriodically runs job in the background when the app starts and then every interval until it stops
+func runPeriodically(lc fx.Lifecycle, interval time.Duration, job func(ctx context.Context)) {
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	lc.Append(fx.Hook{
+		OnStart: func(context.Context) error {
+			go func() {
+				defer close(done)
+				ticker := time.NewTicker(interval)
+				defer ticker.Stop()
+
+				for {
+					job(ctx)
+
+					select {
+					case <-ctx.Done():
+						return
+					case <-ticker.C:
+					}
+				}
+			}()
+			return nil
+		},
+		OnStop: func(stopCtx context.Context) error {
+			// Wait for a running job so the database is not closed under it
+			cancel()
+			select {
+			case <-done:
+			case <-stopCtx.Done():
+			}
+			return nil
+		},
+	})
+}
+
 func registerHooks(lc fx.Lifecycle, dbManager *repository.Manager, cfg *config.Config, log *slog.Logger) {
 	lc.Append(fx.Hook{
 		OnStart: func(ctx context.Context) error {
@@ -20,29 +20,45 @@ WHERE deleted_at IS NULL;
 
 -- name: UpdateGroup :one
 UPDATE groups
-SET name = $2
-WHERE id = $1
-RETURNING *;;
+SET name = @name,
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING *;
 
--- name: DeleteGroup :exec
+-- name: DeleteGroup :execrows
 UPDATE groups
 SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL;
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'));
 
 /* Songs Table */
 
 -- name: CreateSong :one
 INSERT INTO songs (group_id, title, runtime, lyrics, release_date, link)
 VALUES ($1, $2, $3, $4, $5, $6)
-RETURNING *;;
+RETURNING *;
 
 -- name: GetSong :one
 SELECT id, group_id, title, runtime,  lyrics, release_date, link, created_at, updated_at, deleted_at
 FROM songs
 WHERE id = $1 LIMIT 1;
 
+-- name: GetSongWithGroup :one
+SELECT id, group_id, title, runtime, CASE WHEN @with_lyrics::BOOLEAN THEN lyrics END AS lyrics,
+       release_date, link, created_at, updated_at, group_name, group_created_at, group_updated_at,
+       play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
+WHERE id = @id AND deleted_at IS NULL;
+
 -- name: GetSongsWithPagination :many
-SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at FROM songs
+SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at,
+       group_name, group_created_at, group_updated_at, play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
 WHERE deleted_at IS NULL
 ORDER BY created_at DESC LIMIT $1 OFFSET $2;
 
@@ -53,19 +69,22 @@ WHERE deleted_at IS NULL;
 -- name: UpdateSong :one
 UPDATE songs
 SET
-    group_id = $2,
-    title = $3,
-    runtime = $4,
-    lyrics = $5,
-    release_date = $6,
-    link = $7
-WHERE id = $1
-RETURNING *;;
+    group_id = @group_id,
+    title = @title,
+    runtime = @runtime,
+    lyrics = @lyrics,
+    release_date = @release_date,
+    link = @link,
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING *;
 
 -- name: DeleteSong :execresult
 UPDATE songs
 SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL;
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'));
 
 -- name: GetSongsByGroup :many
 SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
@@ -73,20 +92,787 @@ FROM songs
 WHERE group_id = $1 AND deleted_at IS NULL
 ORDER BY release_date DESC LIMIT $2 OFFSET $3;
 
--- name: GetSongsWithFilters :many
-SELECT s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at,  s.updated_at
+/* Artworks Table */
+
+-- name: UpsertArtwork :one
+INSERT INTO artworks (entity_type, entity_id, width, height, format)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (entity_type, entity_id) DO UPDATE
+SET width = EXCLUDED.width,
+    height = EXCLUDED.height,
+    format = EXCLUDED.format,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: GetArtwork :one
+SELECT id, entity_type, entity_id, width, height, format, created_at, updated_at
+FROM artworks
+WHERE entity_type = $1 AND entity_id = $2 LIMIT 1;
+
+-- name: GetArtworksByEntities :many
+SELECT id, entity_type, entity_id, width, height, format, created_at, updated_at
+FROM artworks
+WHERE entity_type = @entity_type AND entity_id = ANY(@entity_ids::UUID[]);
+
+-- name: DeleteArtwork :execresult
+DELETE FROM artworks
+WHERE entity_type = $1 AND entity_id = $2;
+
+
+/* Playlists Table */
+
+-- name: CreatePlaylist :one
+INSERT INTO playlists (name, description, owner_id, visibility)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetPlaylist :one
+SELECT id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+FROM playlists
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetPlaylistsWithPagination :many
+SELECT id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+FROM playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = @viewer_id::UUID OR @viewer_is_admin::BOOLEAN)
+  AND (sqlc.narg('owner_id')::UUID IS NULL OR owner_id = sqlc.narg('owner_id')::UUID)
+  AND (@visibility::VARCHAR = '' OR visibility = @visibility::VARCHAR)
+ORDER BY created_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetPlaylistsCount :one
+SELECT count(*) FROM playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = @viewer_id::UUID OR @viewer_is_admin::BOOLEAN)
+  AND (sqlc.narg('owner_id')::UUID IS NULL OR owner_id = sqlc.narg('owner_id')::UUID)
+  AND (@visibility::VARCHAR = '' OR visibility = @visibility::VARCHAR);
+
+-- name: UpdatePlaylist :one
+UPDATE playlists
+SET name = $2,
+    description = $3,
+    visibility = $4
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: TouchPlaylist :execrows
+UPDATE playlists
+SET updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+-- name: DeletePlaylist :execresult
+UPDATE playlists
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+
+/* Playlist Entries Table */
+
+-- name: CreatePlaylistEntry :one
+INSERT INTO playlist_entries (playlist_id, song_id, position)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetPlaylistEntry :one
+SELECT id, playlist_id, song_id, position, added_at
+FROM playlist_entries
+WHERE id = $1 AND playlist_id = $2 LIMIT 1;
+
+-- name: GetPlaylistEntries :many
+SELECT e.id, e.playlist_id, e.song_id, e.position, e.added_at,
+       s.title, s.runtime, s.link, s.group_id, g.name AS group_name, s.deleted_at AS song_deleted_at
+FROM playlist_entries e
+         JOIN songs s ON s.id = e.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE e.playlist_id = $1
+ORDER BY e.position;
+
+-- name: GetPlaylistEntryCount :one
+SELECT count(*) FROM playlist_entries
+WHERE playlist_id = $1;
+
+-- name: GetPlaylistsStats :many
+SELECT e.playlist_id,
+       count(*) AS entry_count,
+       COALESCE(SUM(s.runtime) FILTER (WHERE s.deleted_at IS NULL), 0)::BIGINT AS total_runtime,
+       count(*) FILTER (WHERE s.deleted_at IS NOT NULL) AS unavailable_count
+FROM playlist_entries e
+         JOIN songs s ON s.id = e.song_id
+WHERE e.playlist_id = ANY(@playlist_ids::UUID[])
+GROUP BY e.playlist_id;
+
+-- name: ShiftPlaylistEntries :exec
+UPDATE playlist_entries
+SET position = position + @delta::INT
+WHERE playlist_id = @playlist_id
+  AND position BETWEEN @from_position::INT AND @to_position::INT;
+
+-- name: SetPlaylistEntryPosition :exec
+UPDATE playlist_entries
+SET position = $2
+WHERE id = $1;
+
+-- name: DeletePlaylistEntry :exec
+DELETE FROM playlist_entries
+WHERE id = $1;
+
+
+/* Smart Playlists Table */
+
+-- name: CreateSmartPlaylist :one
+INSERT INTO smart_playlists (name, description, owner_id, visibility, rules)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetSmartPlaylist :one
+SELECT id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+FROM smart_playlists
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1;
+
+-- name: GetSmartPlaylistsWithPagination :many
+SELECT id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+FROM smart_playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = @viewer_id::UUID OR @viewer_is_admin::BOOLEAN)
+  AND (sqlc.narg('owner_id')::UUID IS NULL OR owner_id = sqlc.narg('owner_id')::UUID)
+  AND (@visibility::VARCHAR = '' OR visibility = @visibility::VARCHAR)
+ORDER BY created_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetSmartPlaylistsCount :one
+SELECT count(*) FROM smart_playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = @viewer_id::UUID OR @viewer_is_admin::BOOLEAN)
+  AND (sqlc.narg('owner_id')::UUID IS NULL OR owner_id = sqlc.narg('owner_id')::UUID)
+  AND (@visibility::VARCHAR = '' OR visibility = @visibility::VARCHAR);
+
+-- name: UpdateSmartPlaylist :one
+UPDATE smart_playlists
+SET name = $2,
+    description = $3,
+    visibility = $4,
+    rules = $5
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING *;
+
+-- name: DeleteSmartPlaylist :execresult
+UPDATE smart_playlists
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL;
+
+
+/* Song Tags Table */
+
+-- name: GetSongTags :many
+SELECT tag FROM song_tags
+WHERE song_id = $1
+ORDER BY tag;
+
+-- name: ReplaceSongTags :exec
+WITH removed AS (
+    DELETE FROM song_tags
+    WHERE song_tags.song_id = @song_id AND tag <> ALL(@tags::VARCHAR[])
+)
+INSERT INTO song_tags (song_id, tag)
+SELECT @song_id, unnest(@tags::VARCHAR[])
+ON CONFLICT DO NOTHING;
+
+-- name: FindSongByGroupAndTitle :one
+SELECT s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at, s.updated_at, s.deleted_at
 FROM songs s
          JOIN groups g ON s.group_id = g.id
 WHERE s.deleted_at IS NULL
-  AND (LOWER(g.name) LIKE LOWER('%' || NULLIF($3, '')::VARCHAR || '%') OR $3 = '')
-  AND (LOWER(s.title) LIKE LOWER('%' || NULLIF($4, '')::VARCHAR || '%') OR $4 = '')
-ORDER BY s.created_at DESC
-    LIMIT $1 OFFSET $2;
+  AND g.deleted_at IS NULL
+  AND (LOWER(g.name) = LOWER(@group_name::VARCHAR)
+    OR EXISTS (SELECT 1
+               FROM group_aliases a
+               WHERE a.group_id = g.id
+                 AND LOWER(a.name) = LOWER(@group_name::VARCHAR)))
+  AND LOWER(s.title) = LOWER(@title::VARCHAR)
+ORDER BY s.created_at
+LIMIT 1;
+
+
+/* Users Table */
+
+-- name: CreateUser :one
+INSERT INTO users (username, password_hash, role)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetUser :one
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE id = $1 LIMIT 1;
+
+-- name: GetUserByUsername :one
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE username = $1 LIMIT 1;
+
+-- name: GetUsersWithPagination :many
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE (@role::VARCHAR = '' OR role = @role::VARCHAR)
+ORDER BY username
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetUsersCount :one
+SELECT count(*) FROM users
+WHERE (@role::VARCHAR = '' OR role = @role::VARCHAR);
+
+-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING *;
+
+-- name: LockAdmins :many
+SELECT id FROM users
+WHERE role = 'admin'
+ORDER BY id
+FOR UPDATE;
+
+
+/* API Keys Table */
+
+-- name: CreateApiKey :one
+INSERT INTO api_keys (user_id, name, prefix, key_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetApiKeysByUser :many
+SELECT id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at, revoked_at
+FROM api_keys
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC;
+
+-- name: GetActiveApiKeyByHash :one
+SELECT id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at, revoked_at
+FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+LIMIT 1;
+
+-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1;
+
+-- name: RevokeApiKey :execresult
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL;
+
+
+/* Favorites Table */
+
+-- name: AddFavorite :exec
+INSERT INTO favorites (user_id, entity_type, entity_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING;
+
+-- name: RemoveFavorite :execresult
+DELETE FROM favorites
+WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3;
+
+-- name: GetFavoritesWithPagination :many
+SELECT f.entity_type,
+       f.entity_id,
+       COALESCE(s.title, g.name)::VARCHAR AS name,
+       COALESCE(sg.name, '')::VARCHAR AS group_name,
+       f.created_at
+FROM favorites f
+         LEFT JOIN songs s ON f.entity_type = 'song' AND s.id = f.entity_id AND s.deleted_at IS NULL
+         LEFT JOIN groups sg ON sg.id = s.group_id
+         LEFT JOIN groups g ON f.entity_type = 'group' AND g.id = f.entity_id AND g.deleted_at IS NULL
+WHERE f.user_id = @user_id
+  AND (@entity_type::VARCHAR = '' OR f.entity_type = @entity_type::VARCHAR)
+  AND (s.id IS NOT NULL OR g.id IS NOT NULL)
+ORDER BY f.created_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetFavoritesCount :one
+SELECT count(*)
+FROM favorites f
+         LEFT JOIN songs s ON f.entity_type = 'song' AND s.id = f.entity_id AND s.deleted_at IS NULL
+         LEFT JOIN groups g ON f.entity_type = 'group' AND g.id = f.entity_id AND g.deleted_at IS NULL
+WHERE f.user_id = @user_id
+  AND (@entity_type::VARCHAR = '' OR f.entity_type = @entity_type::VARCHAR)
+  AND (s.id IS NOT NULL OR g.id IS NOT NULL);
+
+
+/* Play Events Table */
+
+-- name: CreatePlayEvent :one
+WITH created AS (
+    INSERT INTO play_events (user_id, song_id, played_at, duration_played, client)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING *
+), counted AS (
+    INSERT INTO song_play_stats (song_id, play_count)
+    SELECT song_id, 1 FROM created
+    ON CONFLICT (song_id) DO UPDATE
+    SET play_count = song_play_stats.play_count + 1,
+        updated_at = NOW()
+)
+SELECT id, user_id, song_id, played_at, duration_played, client, created_at
+FROM created;
+
+-- name: GetPlayHistoryWithPagination :many
+SELECT e.id,
+       e.song_id,
+       s.title,
+       g.name AS group_name,
+       e.played_at,
+       e.duration_played,
+       e.client,
+       (s.deleted_at IS NULL)::BOOLEAN AS available
+FROM play_events e
+         JOIN songs s ON s.id = e.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE e.user_id = @user_id
+ORDER BY e.played_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetPlayHistoryCount :one
+SELECT count(*) FROM play_events
+WHERE user_id = $1;
+
+
+/* Song Ratings Table */
+
+-- name: LockSongRatingStats :one
+INSERT INTO song_rating_stats (song_id)
+VALUES ($1)
+ON CONFLICT (song_id) DO UPDATE
+SET song_id = EXCLUDED.song_id
+RETURNING *;
+
+-- name: GetSongRating :one
+SELECT user_id, song_id, rating, created_at, updated_at
+FROM song_ratings
+WHERE user_id = $1 AND song_id = $2 LIMIT 1;
+
+-- name: UpsertSongRating :one
+INSERT INTO song_ratings (user_id, song_id, rating)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, song_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    updated_at = NOW()
+RETURNING *;
+
+-- name: DeleteSongRating :one
+DELETE FROM song_ratings
+WHERE user_id = $1 AND song_id = $2
+RETURNING rating;
 
--- name: GetSongsCountWithFilters :one
+-- name: AdjustSongRatingStats :one
+UPDATE song_rating_stats
+SET rating_count = rating_count + @count_delta::INT,
+    rating_sum = rating_sum + @sum_delta::INT,
+    rating_average = COALESCE((rating_sum + @sum_delta::INT)::FLOAT8 / NULLIF(rating_count + @count_delta::INT, 0), 0),
+    updated_at = NOW()
+WHERE song_id = @song_id
+RETURNING *;
+
+-- name: GetUserRatingsWithPagination :many
+SELECT r.song_id,
+       s.title,
+       g.name AS group_name,
+       r.rating,
+       r.updated_at
+FROM song_ratings r
+         JOIN songs s ON s.id = r.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE r.user_id = @user_id AND s.deleted_at IS NULL
+ORDER BY r.updated_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetUserRatingsCount :one
 SELECT count(*)
+FROM song_ratings r
+         JOIN songs s ON s.id = r.song_id
+WHERE r.user_id = $1 AND s.deleted_at IS NULL;
+
+
+/* Song Similarity */
+
+-- name: GetSongsLyricsAfter :many
+SELECT id, lyrics
+FROM songs
+WHERE deleted_at IS NULL AND id > @after_id
+ORDER BY id
+    LIMIT @limit_count;
+
+-- name: GetSongsByIDs :many
+SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at,
+       group_name, group_created_at, group_updated_at, play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
+WHERE id = ANY(@song_ids::UUID[]) AND deleted_at IS NULL;
+
+
+/* Song Merges */
+
+-- name: GetSongsForDuplicateScan :many
+SELECT s.id, s.group_id, g.name AS group_name, s.title, s.runtime
 FROM songs s
-JOIN groups g ON s.group_id = g.id
+         JOIN groups g ON g.id = s.group_id
 WHERE s.deleted_at IS NULL
-  AND (LOWER(g.name) LIKE LOWER('%' || NULLIF(@group_name, '')::VARCHAR || '%') OR @group_name = '')
-  AND (LOWER(s.title) LIKE LOWER('%' || NULLIF(@song_title, '')::VARCHAR || '%') OR @song_title = '');
\ No newline at end of file
+ORDER BY s.created_at;
+
+-- name: TouchPlaylistsWithSong :exec
+UPDATE playlists
+SET updated_at = NOW()
+WHERE deleted_at IS NULL
+  AND id IN (SELECT playlist_id FROM playlist_entries WHERE song_id = $1);
+
+-- name: MoveSongPlaylistEntries :execrows
+UPDATE playlist_entries
+SET song_id = @to_song_id
+WHERE song_id = @from_song_id;
+
+-- name: CopySongFavorites :execrows
+INSERT INTO favorites (user_id, entity_type, entity_id, created_at)
+SELECT user_id, entity_type, @to_song_id::UUID, created_at
+FROM favorites
+WHERE entity_type = 'song' AND entity_id = @from_song_id
+ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING;
+
+-- name: DeleteSongFavorites :exec
+DELETE FROM favorites
+WHERE entity_type = 'song' AND entity_id = $1;
+
+-- name: MoveSongPlayEvents :execrows
+UPDATE play_events
+SET song_id = @to_song_id
+WHERE song_id = @from_song_id;
+
+-- name: CopySongRatings :execrows
+INSERT INTO song_ratings (user_id, song_id, rating, created_at, updated_at)
+SELECT user_id, @to_song_id::UUID, rating, created_at, updated_at
+FROM song_ratings
+WHERE song_id = @from_song_id
+ON CONFLICT (user_id, song_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    updated_at = EXCLUDED.updated_at
+WHERE song_ratings.updated_at < EXCLUDED.updated_at;
+
+-- name: DeleteSongRatings :exec
+DELETE FROM song_ratings
+WHERE song_id = $1;
+
+-- name: LockSongPlayStats :one
+INSERT INTO song_play_stats (song_id)
+VALUES ($1)
+ON CONFLICT (song_id) DO UPDATE
+SET song_id = EXCLUDED.song_id
+RETURNING *;
+
+-- name: RecountSongPlayStats :one
+UPDATE song_play_stats st
+SET play_count = (SELECT count(*) FROM play_events WHERE song_id = @song_id),
+    updated_at = NOW()
+WHERE st.song_id = @song_id
+RETURNING st.song_id, st.play_count, st.updated_at;
+
+-- name: RecountSongRatingStats :one
+UPDATE song_rating_stats st
+SET rating_count = r.rating_count,
+    rating_sum = r.rating_sum,
+    rating_average = r.rating_average,
+    updated_at = NOW()
+FROM (SELECT count(*)::INT                   AS rating_count,
+             COALESCE(sum(rating), 0)::INT   AS rating_sum,
+             COALESCE(avg(rating), 0)::FLOAT8 AS rating_average
+      FROM song_ratings
+      WHERE song_id = @song_id) r
+WHERE st.song_id = @song_id
+RETURNING st.song_id, st.rating_count, st.rating_sum, st.rating_average, st.updated_at;
+
+-- name: CopySongTags :execrows
+INSERT INTO song_tags (song_id, tag)
+SELECT @to_song_id::UUID, tag
+FROM song_tags
+WHERE song_id = @from_song_id
+ON CONFLICT (song_id, tag) DO NOTHING;
+
+
+/* Group Merges */
+
+-- name: GetGroupsSongCounts :many
+SELECT group_id, count(*) AS song_count
+FROM songs
+WHERE group_id = ANY(@group_ids::UUID[]) AND deleted_at IS NULL
+GROUP BY group_id;
+
+-- name: MoveGroupSongs :execrows
+UPDATE songs
+SET group_id = @to_group_id
+WHERE group_id = ANY(@from_group_ids::UUID[]) AND deleted_at IS NULL;
+
+-- name: CopyGroupFavorites :execrows
+INSERT INTO favorites (user_id, entity_type, entity_id, created_at)
+SELECT DISTINCT ON (user_id) user_id, entity_type, @to_group_id::UUID, created_at
+FROM favorites
+WHERE entity_type = 'group' AND entity_id = ANY(@from_group_ids::UUID[])
+ORDER BY user_id, created_at
+ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING;
+
+-- name: DeleteGroupsFavorites :exec
+DELETE FROM favorites
+WHERE entity_type = 'group' AND entity_id = ANY(@group_ids::UUID[]);
+
+-- name: AddGroupAliases :execrows
+INSERT INTO group_aliases (group_id, name, type)
+SELECT @group_id::UUID, unnest(@names::VARCHAR[]), @type::VARCHAR
+ON CONFLICT DO NOTHING;
+
+-- name: CopyGroupAliases :execrows
+INSERT INTO group_aliases (group_id, name, locale, type, created_at)
+SELECT @to_group_id::UUID, name, locale, type, created_at
+FROM group_aliases
+WHERE group_id = ANY(@from_group_ids::UUID[])
+ON CONFLICT DO NOTHING;
+
+-- name: DeleteGroups :execrows
+UPDATE groups
+SET deleted_at = NOW()
+WHERE id = ANY(@ids::UUID[]) AND deleted_at IS NULL;
+
+
+/* Group Aliases */
+
+-- name: CreateGroupAlias :one
+INSERT INTO group_aliases (group_id, name, locale, type)
+VALUES ($1, $2, $3, $4)
+RETURNING *;
+
+-- name: GetGroupAliases :many
+SELECT id, group_id, name, created_at, locale, type
+FROM group_aliases
+WHERE group_id = $1
+ORDER BY type, locale, name;
+
+-- name: DeleteGroupAlias :execrows
+DELETE FROM group_aliases
+WHERE id = $1 AND group_id = $2;
+
+-- name: SearchGroupsWithPagination :many
+SELECT g.id, g.name, g.created_at, g.updated_at
+FROM groups g
+WHERE g.deleted_at IS NULL
+  AND (@name::VARCHAR = ''
+       OR LOWER(g.name) LIKE '%' || LOWER(@name::VARCHAR) || '%'
+       OR EXISTS (SELECT 1
+                  FROM group_aliases a
+                  WHERE a.group_id = g.id
+                    AND LOWER(a.name) LIKE '%' || LOWER(@name::VARCHAR) || '%'))
+ORDER BY g.created_at DESC
+    LIMIT @limit_count OFFSET @offset_count;
+
+-- name: SearchGroupsCount :one
+SELECT count(*)
+FROM groups g
+WHERE g.deleted_at IS NULL
+  AND (@name::VARCHAR = ''
+       OR LOWER(g.name) LIKE '%' || LOWER(@name::VARCHAR) || '%'
+       OR EXISTS (SELECT 1
+                  FROM group_aliases a
+                  WHERE a.group_id = g.id
+                    AND LOWER(a.name) LIKE '%' || LOWER(@name::VARCHAR) || '%'));
+
+
+/* Group Names */
+
+-- name: GetGroupByNormalizedName :one
+SELECT id, name, created_at, updated_at, deleted_at
+FROM groups
+WHERE LOWER(BTRIM(name)) = LOWER(BTRIM(@name::VARCHAR)) AND deleted_at IS NULL
+LIMIT 1;
+
+
+/* Group Deletion */
+
+-- name: CascadeDeleteGroupSongs :many
+WITH deleted AS (
+    UPDATE songs
+    SET deleted_at = NOW()
+    WHERE group_id = @group_id AND deleted_at IS NULL
+    RETURNING id, group_id, deleted_at
+)
+INSERT INTO group_deleted_songs (song_id, group_id, deleted_at)
+SELECT id, group_id, deleted_at FROM deleted
+ON CONFLICT (song_id) DO UPDATE
+SET group_id = EXCLUDED.group_id,
+    deleted_at = EXCLUDED.deleted_at
+RETURNING song_id;
+
+-- name: RestoreGroup :one
+UPDATE groups
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, name, created_at, updated_at, deleted_at;
+
+-- name: RestoreGroupSongs :many
+WITH restored AS (
+    DELETE FROM group_deleted_songs
+    WHERE group_id = @group_id
+    RETURNING song_id
+)
+UPDATE songs s
+SET deleted_at = NULL
+FROM restored r
+WHERE s.id = r.song_id AND s.group_id = @group_id AND s.deleted_at IS NOT NULL
+RETURNING s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at, s.updated_at, s.deleted_at;
+
+-- name: RestoreSong :one
+WITH unlinked AS (
+    DELETE FROM group_deleted_songs
+    WHERE song_id = @id
+)
+UPDATE songs
+SET deleted_at = NULL
+WHERE id = @id AND deleted_at IS NOT NULL
+RETURNING *;
+
+
+/* Trash */
+
+-- name: GetTrashWithPagination :many
+SELECT t.entity_type, t.id, t.name, t.group_id, t.deleted_with_group, t.deleted_at
+FROM (SELECT 'group'::VARCHAR AS entity_type, g.id, g.name, NULL::UUID AS group_id, FALSE AS deleted_with_group, g.deleted_at
+      FROM groups g
+      WHERE g.deleted_at IS NOT NULL
+      UNION ALL
+      SELECT 'song'::VARCHAR, s.id, s.title, s.group_id,
+             EXISTS (SELECT 1 FROM group_deleted_songs d WHERE d.song_id = s.id), s.deleted_at
+      FROM songs s
+      WHERE s.deleted_at IS NOT NULL) t
+WHERE @entity_type::VARCHAR = '' OR t.entity_type = @entity_type::VARCHAR
+ORDER BY t.deleted_at DESC, t.id
+LIMIT @limit_count OFFSET @offset_count;
+
+-- name: GetTrashCount :one
+SELECT (CASE WHEN @entity_type::VARCHAR IN ('', 'group') THEN (SELECT count(*) FROM groups WHERE deleted_at IS NOT NULL) ELSE 0 END
+      + CASE WHEN @entity_type::VARCHAR IN ('', 'song') THEN (SELECT count(*) FROM songs WHERE deleted_at IS NOT NULL) ELSE 0 END)::BIGINT AS count;
+
+-- name: GetPurgeableGroupIDs :many
+SELECT g.id
+FROM groups g
+WHERE g.deleted_at < @cutoff::TIMESTAMPTZ
+  AND NOT EXISTS (SELECT 1 FROM songs s WHERE s.group_id = g.id AND s.deleted_at IS NULL)
+ORDER BY g.deleted_at
+LIMIT @limit_count
+FOR UPDATE;
+
+-- name: GetPurgeableSongIDs :many
+SELECT id
+FROM songs
+WHERE deleted_at < @cutoff::TIMESTAMPTZ
+ORDER BY deleted_at
+LIMIT @limit_count
+FOR UPDATE;
+
+-- name: GetGroupsSongIDsForUpdate :many
+SELECT id
+FROM songs
+WHERE group_id = ANY(@group_ids::UUID[])
+FOR UPDATE;
+
+-- name: PurgeSongs :execrows
+DELETE FROM songs
+WHERE id = ANY(@ids::UUID[]) AND deleted_at IS NOT NULL;
+
+-- name: PurgeGroups :execrows
+DELETE FROM groups
+WHERE id = ANY(@ids::UUID[]) AND deleted_at IS NOT NULL;
+
+-- name: TouchSongsPlaylists :many
+UPDATE playlists
+SET updated_at = NOW()
+WHERE id IN (SELECT playlist_id FROM playlist_entries WHERE song_id = ANY(@song_ids::UUID[]))
+RETURNING id;
+
+-- name: RenumberPlaylistEntries :exec
+UPDATE playlist_entries e
+SET position = r.position
+FROM (SELECT id, row_number() OVER (PARTITION BY playlist_id ORDER BY position)::INT AS position
+      FROM playlist_entries
+      WHERE playlist_id = ANY(@playlist_ids::UUID[])) r
+WHERE e.id = r.id AND e.position <> r.position;
+
+-- name: DeleteEntitiesFavorites :execrows
+DELETE FROM favorites
+WHERE entity_type = @entity_type AND entity_id = ANY(@entity_ids::UUID[]);
+
+-- name: DeleteArtworksByEntities :many
+DELETE FROM artworks
+WHERE entity_type = @entity_type AND entity_id = ANY(@entity_ids::UUID[])
+RETURNING id, entity_type, entity_id, width, height, format, created_at, updated_at;
+
+
+/* Partial Updates */
+
+-- name: PatchSong :one
+UPDATE songs
+SET group_id = COALESCE(sqlc.narg('group_id'), group_id),
+    title = COALESCE(sqlc.narg('title'), title),
+    runtime = COALESCE(sqlc.narg('runtime'), runtime),
+    lyrics = COALESCE(sqlc.narg('lyrics'), lyrics),
+    release_date = COALESCE(sqlc.narg('release_date'), release_date),
+    link = COALESCE(sqlc.narg('link'), link),
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING *;
+
+-- name: PatchGroup :one
+UPDATE groups
+SET name = COALESCE(sqlc.narg('name'), name),
+    updated_at = NOW()
+WHERE id = @id AND deleted_at IS NULL
+  AND (sqlc.narg('if_updated_at')::TIMESTAMPTZ IS NULL OR updated_at = sqlc.narg('if_updated_at'))
+RETURNING id, name, created_at, updated_at, deleted_at;
+
+
+/* Idempotency Keys */
+
+-- name: StartIdempotentRequest :one
+INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+VALUES (@user_id, @key, @request_hash, @expires_at)
+ON CONFLICT (user_id, key) DO UPDATE
+    SET request_hash = EXCLUDED.request_hash,
+        status_code = NULL,
+        content_type = '',
+        response_body = NULL,
+        created_at = NOW(),
+        expires_at = EXCLUDED.expires_at
+    WHERE idempotency_keys.expires_at <= NOW()
+       OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= @stale_before)
+RETURNING *;
+
+-- name: GetIdempotencyKey :one
+SELECT user_id, key, request_hash, status_code, content_type, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE user_id = @user_id AND key = @key;
+
+-- name: CompleteIdempotentRequest :exec
+UPDATE idempotency_keys
+SET status_code = @status_code,
+    content_type = @content_type,
+    response_body = @response_body
+WHERE user_id = @user_id AND key = @key;
+
+-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = @user_id AND key = @key;
+
+-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= NOW();
@@ -10,8 +10,11 @@ CREATE TABLE IF NOT EXISTS groups
     CONSTRAINT groups_pkey PRIMARY KEY (id)
 );
 
-CREATE INDEX IF NOT EXISTS idx_groups_name ON groups(name);
+-- Live group names are unique ignoring case and surrounding whitespace
+CREATE UNIQUE INDEX IF NOT EXISTS uq_groups_name_normalized ON groups(LOWER(BTRIM(name))) WHERE deleted_at IS NULL;
 CREATE INDEX IF NOT EXISTS idx_groups_deleted_at ON groups(deleted_at) WHERE deleted_at IS NOT NULL;
+-- Listings page through live groups by (created_at, id), newest first
+CREATE INDEX IF NOT EXISTS idx_groups_created_at_id ON groups(created_at DESC, id DESC) WHERE deleted_at IS NULL;
 
 -- Creating the songs table
 CREATE TABLE IF NOT EXISTS songs
@@ -35,6 +38,8 @@ CREATE INDEX IF NOT EXISTS idx_songs_group_id ON songs(group_id);
 CREATE INDEX IF NOT EXISTS idx_songs_title ON songs(title);
 CREATE INDEX IF NOT EXISTS idx_songs_release_date ON songs(release_date);
 CREATE INDEX IF NOT EXISTS idx_songs_deleted_at ON songs(deleted_at) WHERE deleted_at IS NOT NULL;
+-- Listings page through live songs by (created_at, id), newest first
+CREATE INDEX IF NOT EXISTS idx_songs_created_at_id ON songs(created_at DESC, id DESC) WHERE deleted_at IS NULL;
 
 CREATE INDEX IF NOT EXISTS idx_songs_lyrics ON songs USING GIN (lyrics);
 
@@ -57,4 +62,270 @@ CREATE TRIGGER update_groups_modtime
 CREATE TRIGGER update_songs_modtime
     BEFORE UPDATE ON songs
     FOR EACH ROW
-    EXECUTE FUNCTION update_modified_column();
\ No newline at end of file
+    EXECUTE FUNCTION update_modified_column();
+
+-- Creating the artworks table
+CREATE TABLE IF NOT EXISTS artworks
+(
+    id           UUID           NOT NULL DEFAULT gen_random_uuid(),
+    entity_type  VARCHAR(16)    NOT NULL,
+    entity_id    UUID           NOT NULL,
+    width        INT            NOT NULL,
+    height       INT            NOT NULL,
+    format       VARCHAR(16)    NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT artworks_pkey PRIMARY KEY (id),
+    CONSTRAINT uq_artworks_entity UNIQUE (entity_type, entity_id),
+    CONSTRAINT check_artworks_entity_type CHECK (entity_type IN ('song', 'group'))
+);
+
+-- Creating the users table, usernames are stored lower-cased and the first admin is created from the config
+CREATE TABLE IF NOT EXISTS users
+(
+    id             UUID           NOT NULL DEFAULT gen_random_uuid(),
+    username       VARCHAR(64)    NOT NULL,
+    password_hash  VARCHAR(255)   NOT NULL,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    role           VARCHAR(16)    NOT NULL DEFAULT 'viewer',
+
+    CONSTRAINT users_pkey PRIMARY KEY (id),
+    CONSTRAINT uq_users_username UNIQUE (username),
+    CONSTRAINT check_users_role CHECK (role IN ('viewer', 'editor', 'admin'))
+);
+
+-- Creating the api keys table, only a SHA-256 hash of the key is stored
+CREATE TABLE IF NOT EXISTS api_keys
+(
+    id             UUID           NOT NULL DEFAULT gen_random_uuid(),
+    user_id        UUID           NOT NULL,
+    name           VARCHAR(255)   NOT NULL,
+    prefix         VARCHAR(16)    NOT NULL,
+    key_hash       CHAR(64)       NOT NULL,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    last_used_at   TIMESTAMPTZ,
+    expires_at     TIMESTAMPTZ,
+    revoked_at     TIMESTAMPTZ,
+
+    CONSTRAINT api_keys_pkey PRIMARY KEY (id),
+    CONSTRAINT uq_api_keys_key_hash UNIQUE (key_hash),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys(user_id);
+
+-- Creating the playlists table, private playlists are only shown to their owner
+CREATE TABLE IF NOT EXISTS playlists
+(
+    id           UUID           NOT NULL DEFAULT gen_random_uuid(),
+    name         VARCHAR(255)   NOT NULL,
+    description  TEXT           NOT NULL DEFAULT '',
+    owner_id     UUID           NOT NULL,
+    visibility   VARCHAR(16)    NOT NULL DEFAULT 'private',
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    deleted_at   TIMESTAMPTZ,
+
+    CONSTRAINT playlists_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_playlists_owner FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT check_playlists_visibility CHECK (visibility IN ('public', 'unlisted', 'private'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_playlists_owner_id ON playlists(owner_id);
+CREATE INDEX IF NOT EXISTS idx_playlists_deleted_at ON playlists(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Creating the playlist entries table, positions are 1-based and kept contiguous
+CREATE TABLE IF NOT EXISTS playlist_entries
+(
+    id           UUID           NOT NULL DEFAULT gen_random_uuid(),
+    playlist_id  UUID           NOT NULL,
+    song_id      UUID           NOT NULL,
+    position     INT            NOT NULL,
+    added_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT playlist_entries_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_playlist_entries_playlist FOREIGN KEY (playlist_id) REFERENCES playlists (id) ON DELETE CASCADE,
+    CONSTRAINT fk_playlist_entries_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE,
+    CONSTRAINT uq_playlist_entries_position UNIQUE (playlist_id, position) DEFERRABLE INITIALLY DEFERRED,
+    CONSTRAINT check_playlist_entries_position_positive CHECK (position > 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_playlist_entries_song_id ON playlist_entries(song_id);
+
+-- Creating the song tags table, tags are stored lower-cased
+CREATE TABLE IF NOT EXISTS song_tags
+(
+    song_id      UUID           NOT NULL,
+    tag          VARCHAR(64)    NOT NULL,
+
+    CONSTRAINT song_tags_pkey PRIMARY KEY (song_id, tag),
+    CONSTRAINT fk_song_tags_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_tags_tag ON song_tags(tag);
+
+-- Creating the smart playlists table, rules are evaluated into SQL on every read
+CREATE TABLE IF NOT EXISTS smart_playlists
+(
+    id           UUID           NOT NULL DEFAULT gen_random_uuid(),
+    name         VARCHAR(255)   NOT NULL,
+    description  TEXT           NOT NULL DEFAULT '',
+    owner_id     UUID           NOT NULL,
+    visibility   VARCHAR(16)    NOT NULL DEFAULT 'private',
+    rules        JSONB          NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    deleted_at   TIMESTAMPTZ,
+
+    CONSTRAINT smart_playlists_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_smart_playlists_owner FOREIGN KEY (owner_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT check_smart_playlists_visibility CHECK (visibility IN ('public', 'unlisted', 'private'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_smart_playlists_owner_id ON smart_playlists(owner_id);
+CREATE INDEX IF NOT EXISTS idx_smart_playlists_deleted_at ON smart_playlists(deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- Creating the favorites table, a user can favorite songs and groups
+CREATE TABLE IF NOT EXISTS favorites
+(
+    user_id      UUID           NOT NULL,
+    entity_type  VARCHAR(16)    NOT NULL,
+    entity_id    UUID           NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT favorites_pkey PRIMARY KEY (user_id, entity_type, entity_id),
+    CONSTRAINT fk_favorites_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT check_favorites_entity_type CHECK (entity_type IN ('song', 'group'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_favorites_user_created_at ON favorites(user_id, created_at DESC);
+
+-- Creating the play events table, one row per listen reported by a client
+CREATE TABLE IF NOT EXISTS play_events
+(
+    id               UUID           NOT NULL DEFAULT gen_random_uuid(),
+    user_id          UUID           NOT NULL,
+    song_id          UUID           NOT NULL,
+    played_at        TIMESTAMPTZ    NOT NULL,
+    duration_played  INT            NOT NULL,
+    client           VARCHAR(64)    NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT play_events_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_play_events_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_play_events_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE,
+    CONSTRAINT check_play_events_duration_played CHECK (duration_played >= 0)
+);
+
+CREATE INDEX IF NOT EXISTS idx_play_events_user_played_at ON play_events(user_id, played_at DESC);
+CREATE INDEX IF NOT EXISTS idx_play_events_song_id ON play_events(song_id);
+
+-- Creating the song play stats table, maintained incrementally whenever a play event is recorded
+CREATE TABLE IF NOT EXISTS song_play_stats
+(
+    song_id      UUID           NOT NULL,
+    play_count   BIGINT         NOT NULL DEFAULT 0,
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT song_play_stats_pkey PRIMARY KEY (song_id),
+    CONSTRAINT fk_song_play_stats_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE
+);
+
+-- Creating the song ratings table, one 1-5 star rating per user and song
+CREATE TABLE IF NOT EXISTS song_ratings
+(
+    user_id      UUID           NOT NULL,
+    song_id      UUID           NOT NULL,
+    rating       INT            NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    updated_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT song_ratings_pkey PRIMARY KEY (user_id, song_id),
+    CONSTRAINT fk_song_ratings_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+    CONSTRAINT fk_song_ratings_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE,
+    CONSTRAINT check_song_ratings_rating CHECK (rating BETWEEN 1 AND 5)
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_ratings_song_id ON song_ratings(song_id);
+
+-- Creating the song rating stats table, maintained incrementally whenever a rating changes
+CREATE TABLE IF NOT EXISTS song_rating_stats
+(
+    song_id         UUID               NOT NULL,
+    rating_count    INT                NOT NULL DEFAULT 0,
+    rating_sum      INT                NOT NULL DEFAULT 0,
+    rating_average  DOUBLE PRECISION   NOT NULL DEFAULT 0,
+    updated_at      TIMESTAMPTZ        NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT song_rating_stats_pkey PRIMARY KEY (song_id),
+    CONSTRAINT fk_song_rating_stats_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_song_rating_stats_rating_average ON song_rating_stats(rating_average DESC);
+
+-- Creating the group aliases table, localised, legal, stage and former names a group is known under
+CREATE TABLE IF NOT EXISTS group_aliases
+(
+    id           UUID           NOT NULL DEFAULT gen_random_uuid(),
+    group_id     UUID           NOT NULL,
+    name         VARCHAR(255)   NOT NULL,
+    created_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    locale       VARCHAR(35)    NOT NULL DEFAULT '',
+    type         VARCHAR(16)    NOT NULL DEFAULT 'search',
+
+    CONSTRAINT group_aliases_pkey PRIMARY KEY (id),
+    CONSTRAINT fk_group_aliases_group FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE,
+    CONSTRAINT check_group_aliases_type CHECK (type IN ('legal', 'stage', 'former', 'search'))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS uq_group_aliases_group_name ON group_aliases(group_id, LOWER(name));
+CREATE INDEX IF NOT EXISTS idx_group_aliases_name ON group_aliases(LOWER(name));
+
+-- Creating the group deleted songs table, songs soft-deleted along with their group and restored with it
+CREATE TABLE IF NOT EXISTS group_deleted_songs
+(
+    song_id      UUID           NOT NULL,
+    group_id     UUID           NOT NULL,
+    deleted_at   TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+
+    CONSTRAINT group_deleted_songs_pkey PRIMARY KEY (song_id),
+    CONSTRAINT fk_group_deleted_songs_group FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE,
+    CONSTRAINT fk_group_deleted_songs_song FOREIGN KEY (song_id) REFERENCES songs (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_group_deleted_songs_group_id ON group_deleted_songs(group_id);
+
+-- Creating the idempotency keys table, the first response to a create request replayed when it is retried
+CREATE TABLE IF NOT EXISTS idempotency_keys
+(
+    user_id        UUID           NOT NULL,
+    key            VARCHAR(255)   NOT NULL,
+    request_hash   BYTEA          NOT NULL,
+    status_code    INT,
+    content_type   VARCHAR(255)   NOT NULL DEFAULT '',
+    response_body  BYTEA,
+    created_at     TIMESTAMPTZ    NOT NULL DEFAULT NOW(),
+    expires_at     TIMESTAMPTZ    NOT NULL,
+
+    CONSTRAINT idempotency_keys_pkey PRIMARY KEY (user_id, key),
+    CONSTRAINT fk_idempotency_keys_user FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_idempotency_keys_expires_at ON idempotency_keys(expires_at);
+
+-- Creating the song listings view, a song with its group, play count, rating and artworks as every listing returns it
+CREATE OR REPLACE VIEW song_listings AS
+SELECT s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at, s.updated_at, s.deleted_at,
+       g.name AS group_name, g.created_at AS group_created_at, g.updated_at AS group_updated_at, g.deleted_at AS group_deleted_at,
+       COALESCE(p.play_count, 0)::BIGINT AS play_count,
+       COALESCE(r.rating_average, 0)::FLOAT8 AS rating_average, COALESCE(r.rating_count, 0)::INT AS rating_count,
+       sa.width AS artwork_width, sa.height AS artwork_height, sa.format AS artwork_format, sa.updated_at AS artwork_updated_at,
+       ga.width AS group_artwork_width, ga.height AS group_artwork_height, ga.format AS group_artwork_format, ga.updated_at AS group_artwork_updated_at
+FROM songs s
+         JOIN groups g ON g.id = s.group_id
+         LEFT JOIN song_play_stats p ON p.song_id = s.id
+         LEFT JOIN song_rating_stats r ON r.song_id = s.id
+         LEFT JOIN artworks sa ON sa.entity_type = 'song' AND sa.entity_id = s.id
+         LEFT JOIN artworks ga ON ga.entity_type = 'group' AND ga.entity_id = g.id;
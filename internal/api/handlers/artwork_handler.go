@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/services"
+	"music-service/internal/pkg/utils/imaging"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"time"
+)
+
+// maxArtworkSize is the largest accepted upload in bytes
+const maxArtworkSize = 10 << 20
+
+// Errors of artwork uploads and deletions
+var (
+	errArtworkNotFound = services.NewError(services.KindNotFound, "artwork_not_found", "Artwork not found")
+	errImageTooLarge   = services.NewError(services.KindTooLarge, "image_too_large", "Image is too large")
+	errImageRequired   = services.NewValidationError("image_required", "Image file is required in the 'file' field",
+		services.FieldError{Field: "file", Message: "is required"})
+	errInvalidImage = services.NewValidationError("invalid_image", "Invalid image")
+)
+
+type ArtworkHandler struct {
+	artworkService *services.ArtworkService
+	songService    *services.SongService
+	groupService   *services.GroupService
+}
+
+// NewArtworkHandler creates a new artwork handler
+func NewArtworkHandler(artworkService *services.ArtworkService, songService *services.SongService, groupService *services.GroupService) *ArtworkHandler {
+	return &ArtworkHandler{
+		artworkService: artworkService,
+		songService:    songService,
+		groupService:   groupService,
+	}
+}
+
+// ArtworkData represents cover art information included in song and group responses
+type ArtworkData struct {
+	Width     int32             `json:"width"`
+	Height    int32             `json:"height"`
+	URLs      map[string]string `json:"urls"`
+	UpdatedAt time.Time         `json:"updated_at"`
+}
+
+// UploadSongArtwork godoc
+// @Summary Upload song artwork
+// @Description Upload a JPEG or PNG cover image for a song, thumbnails are generated on the server
+// @Tags artwork
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Param file formData file true "JPEG or PNG image"
+// @Success 201 {object} object{data=object{width=integer,height=integer,urls=object,updated_at=string}} "Uploaded artwork"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid ID or image"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 413 {object} middleware.Problem "Image too large"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/artwork [post]
+func (h *ArtworkHandler) UploadSongArtwork(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	if _, err = h.songService.GetSong(c, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.upload(c, repository.ArtworkEntitySong, id)
+}
+
+// DeleteSongArtwork godoc
+// @Summary Delete song artwork
+// @Description Delete the cover image and thumbnails of a song
+// @Tags artwork
+// @Param id path string true "Song ID" format(uuid)
+// @Success 204 "Artwork deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Artwork not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/artwork [delete]
+func (h *ArtworkHandler) DeleteSongArtwork(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	h.delete(c, repository.ArtworkEntitySong, id)
+}
+
+// UploadGroupArtwork godoc
+// @Summary Upload group artwork
+// @Description Upload a JPEG or PNG image for a music group, thumbnails are generated on the server
+// @Tags artwork
+// @Accept multipart/form-data
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param file formData file true "JPEG or PNG image"
+// @Success 201 {object} object{data=object{width=integer,height=integer,urls=object,updated_at=string}} "Uploaded artwork"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid ID or image"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 413 {object} middleware.Problem "Image too large"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/artwork [post]
+func (h *ArtworkHandler) UploadGroupArtwork(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	if _, err = h.groupService.GetGroup(c, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.upload(c, repository.ArtworkEntityGroup, id)
+}
+
+// DeleteGroupArtwork godoc
+// @Summary Delete group artwork
+// @Description Delete the image and thumbnails of a music group
+// @Tags artwork
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204 "Artwork deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Artwork not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/artwork [delete]
+func (h *ArtworkHandler) DeleteGroupArtwork(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	h.delete(c, repository.ArtworkEntityGroup, id)
+}
+
+func (h *ArtworkHandler) upload(c *gin.Context, entityType string, entityID uuid.UUID) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxArtworkSize+1<<20)
+
+	fileHeader, err := c.FormFile("file")
+	if err != nil {
+		var maxBytesErr *http.MaxBytesError
+		if errors.As(err, &maxBytesErr) {
+			respondError(c, errImageTooLarge)
+			return
+		}
+		respondError(c, errImageRequired.Wrap(err))
+		return
+	}
+
+	if fileHeader.Size > maxArtworkSize {
+		respondError(c, errImageTooLarge)
+		return
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		respondError(c, errInvalidImage.WithMessage("Failed to read image").Wrap(err))
+		return
+	}
+	defer file.Close()
+
+	artwork, err := h.artworkService.UploadArtwork(c, entityType, entityID, file)
+	if err != nil {
+		if errors.Is(err, imaging.ErrUnsupportedFormat) || errors.Is(err, imaging.ErrImageTooLarge) {
+			respondError(c, errInvalidImage.WithMessage(err.Error()).Wrap(err))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, newArtworkData(h.artworkService, artwork))
+}
+
+func (h *ArtworkHandler) delete(c *gin.Context, entityType string, entityID uuid.UUID) {
+	deleted, err := h.artworkService.DeleteArtwork(c, entityType, entityID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	if !deleted {
+		respondError(c, errArtworkNotFound)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// newArtworkData converts a stored artwork into its response representation
+func newArtworkData(artworkService *services.ArtworkService, artwork database.Artwork) *ArtworkData {
+	return &ArtworkData{
+		Width:     artwork.Width,
+		Height:    artwork.Height,
+		URLs:      artworkService.ArtworkURLs(artwork),
+		UpdatedAt: artwork.UpdatedAt.Time,
+	}
+}
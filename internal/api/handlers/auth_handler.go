@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"net/http"
+	"time"
+)
+
+type AuthHandler struct {
+	authService *services.AuthService
+}
+
+// NewAuthHandler creates a new auth handler
+func NewAuthHandler(authService *services.AuthService) *AuthHandler {
+	return &AuthHandler{
+		authService: authService,
+	}
+}
+
+// UserResponse is the formatted user response for the API, it never includes the password hash
+type UserResponse struct {
+	ID        string    `json:"id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// LoginResponse is the access token issued for a login
+type LoginResponse struct {
+	Token     string       `json:"token"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	User      UserResponse `json:"user"`
+}
+
+// APIKeyResponse is the formatted API key response for the API
+type APIKeyResponse struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Key        string     `json:"key,omitempty"` // only set when the key is created
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+type credentialsBody struct {
+	Username string `json:"username" binding:"required,min=3,max=64"`
+	Password string `json:"password" binding:"required"`
+}
+
+// Register godoc
+// @Summary Register a new user
+// @Description Create a user account with the viewer role, usernames are case-insensitive.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body object{username=string,password=string} true "User credentials"
+// @Success 201 {object} object{data=UserResponse} "Created user"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input"
+// @Failure 409 {object} middleware.Problem "Username is already taken"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *gin.Context) {
+	var body credentialsBody
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	if len(body.Password) < services.MinPasswordLength {
+		respondError(c, services.ErrInvalidBody.WithMessage("Password must be at least 8 characters long").
+			WithFields(services.FieldError{Field: "password", Message: "must be at least 8 characters"}))
+		return
+	}
+
+	user, err := h.authService.Register(c, body.Username, body.Password)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, formatUser(user))
+}
+
+// Login godoc
+// @Summary Log in
+// @Description Exchange a username and password for a signed access token to send as "Authorization: Bearer <token>"
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param credentials body object{username=string,password=string} true "User credentials"
+// @Success 200 {object} object{token=string,expires_at=string,user=UserResponse} "Access token"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input"
+// @Failure 401 {object} middleware.Problem "Invalid username or password"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *gin.Context) {
+	var body credentialsBody
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	user, token, expiresAt, err := h.authService.Login(c, body.Username, body.Password)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := LoginResponse{Token: token, ExpiresAt: expiresAt, User: formatUser(user)}
+	respond(c, http.StatusOK, Envelope{Data: response}, response)
+}
+
+// GetCurrentUser godoc
+// @Summary Get the current user
+// @Description Retrieve the user the request is authenticated as
+// @Tags auth
+// @Produce json
+// @Success 200 {object} UserResponse
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/me [get]
+func (h *AuthHandler) GetCurrentUser(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	user, err := h.authService.GetUser(c, principal.UserID)
+	if err != nil {
+		if errors.Is(err, services.ErrUserNotFound) {
+			respondError(c, services.ErrInvalidToken.WithMessage("User no longer exists"))
+			return
+		}
+		respondError(c, err)
+		return
+	}
+
+	response := formatUser(user)
+	respond(c, http.StatusOK, Envelope{Data: response}, response)
+}
+
+// CreateAPIKey godoc
+// @Summary Create an API key
+// @Description Create a long-lived API key for service-to-service calls, sent as "X-API-Key: <key>" or "Authorization: Bearer <key>".
+// @Description The key is only returned in this response.
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param key body object{name=string,expires_in_days=int} true "API key name and optional lifetime in days"
+// @Success 201 {object} object{data=APIKeyResponse} "Created API key"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/api-keys [post]
+func (h *AuthHandler) CreateAPIKey(c *gin.Context) {
+	var body struct {
+		Name          string `json:"name" binding:"required,max=255"`
+		ExpiresInDays int    `json:"expires_in_days" binding:"omitempty,min=1"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	var expiresAt *time.Time
+	if body.ExpiresInDays > 0 {
+		t := time.Now().AddDate(0, 0, body.ExpiresInDays)
+		expiresAt = &t
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	apiKey, key, err := h.authService.CreateAPIKey(c, principal.UserID, body.Name, expiresAt)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := formatAPIKey(apiKey)
+	response.Key = key
+	respondData(c, http.StatusCreated, response)
+}
+
+// GetAPIKeys godoc
+// @Summary List API keys
+// @Description List the active API keys of the current user
+// @Tags auth
+// @Produce json
+// @Success 200 {object} object{data=[]APIKeyResponse} "API keys"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/api-keys [get]
+func (h *AuthHandler) GetAPIKeys(c *gin.Context) {
+	principal, _ := middleware.GetPrincipal(c)
+
+	apiKeys, err := h.authService.GetAPIKeys(c, principal.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := make([]APIKeyResponse, len(apiKeys))
+	for i, apiKey := range apiKeys {
+		response[i] = formatAPIKey(apiKey)
+	}
+
+	respondData(c, http.StatusOK, response)
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke one of the current user's API keys, it stops working immediately
+// @Tags auth
+// @Param id path string true "API key ID" format(uuid)
+// @Success 204 "API key revoked"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "API key not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /auth/api-keys/{id} [delete]
+func (h *AuthHandler) RevokeAPIKey(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "API key"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err = h.authService.RevokeAPIKey(c, principal.UserID, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func formatUser(user database.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID.String(),
+		Username:  user.Username,
+		Role:      user.Role,
+		CreatedAt: user.CreatedAt.Time,
+	}
+}
+
+func formatAPIKey(apiKey database.ApiKey) APIKeyResponse {
+	response := APIKeyResponse{
+		ID:        apiKey.ID.String(),
+		Name:      apiKey.Name,
+		Prefix:    apiKey.Prefix,
+		CreatedAt: apiKey.CreatedAt.Time,
+	}
+	if apiKey.LastUsedAt.Valid {
+		response.LastUsedAt = &apiKey.LastUsedAt.Time
+	}
+	if apiKey.ExpiresAt.Valid {
+		response.ExpiresAt = &apiKey.ExpiresAt.Time
+	}
+	return response
+}
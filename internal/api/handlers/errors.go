@@ -0,0 +1,106 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/go-playground/validator/v10"
+	"music-service/internal/api/services"
+	"reflect"
+	"strings"
+)
+
+// respondError hands err to the problem middleware, which answers the request with its problem details
+func respondError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// invalidID is the error for a parameter that should hold the UUID of an entity
+func invalidID(field, entity string) *services.Error {
+	return services.NewValidationError("invalid_id", "Invalid "+entity+" ID format",
+		services.FieldError{Field: field, Message: "must be a UUID"})
+}
+
+// invalidParam is the error for a parameter with an invalid value, message explains what is expected
+func invalidParam(field, message string) *services.Error {
+	return services.NewValidationError("invalid_parameter", field+" "+message,
+		services.FieldError{Field: field, Message: message})
+}
+
+// bindJSON decodes the JSON body of the request into obj and answers bodies that are malformed
+// or fail the binding rules of obj
+func bindJSON(c *gin.Context, obj any) bool {
+	if err := c.ShouldBindJSON(obj); err != nil {
+		respondError(c, bindingError(obj, err))
+		return false
+	}
+	return true
+}
+
+// bindingError turns a decoding or validation error of the body into an invalid_body error listing the fields
+func bindingError(obj any, err error) error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]services.FieldError, 0, len(validationErrs))
+		for _, fieldErr := range validationErrs {
+			fields = append(fields, services.FieldError{
+				Field:   jsonFieldName(obj, fieldErr.StructField()),
+				Message: validationMessage(fieldErr),
+			})
+		}
+		return services.ErrInvalidBody.WithFields(fields...).Wrap(err)
+	case errors.As(err, &typeErr) && typeErr.Field != "":
+		return services.ErrInvalidBody.WithFields(services.FieldError{
+			Field:   typeErr.Field,
+			Message: "must be of type " + typeErr.Type.String(),
+		}).Wrap(err)
+	default:
+		return services.ErrInvalidBody.WithMessage("The request body is not valid JSON").Wrap(err)
+	}
+}
+
+// jsonFieldName returns the JSON name of a field of the struct obj points to
+func jsonFieldName(obj any, structField string) string {
+	t := reflect.TypeOf(obj)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return structField
+	}
+	field, ok := t.FieldByName(structField)
+	if !ok {
+		return structField
+	}
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return structField
+	}
+	return name
+}
+
+// validationMessage explains a failed binding rule
+func validationMessage(fieldErr validator.FieldError) string {
+	unit := ""
+	if fieldErr.Kind() == reflect.String {
+		unit = " characters"
+	} else if fieldErr.Kind() == reflect.Slice {
+		unit = " items"
+	}
+
+	switch fieldErr.Tag() {
+	case "required":
+		return "is required"
+	case "min", "gte":
+		return "must be at least " + fieldErr.Param() + unit
+	case "max", "lte":
+		return "must be at most " + fieldErr.Param() + unit
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(fieldErr.Param()), ", ")
+	default:
+		return "is invalid"
+	}
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"hash/fnv"
+	"music-service/internal/api/services"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// errPreconditionRequired is returned for writes to songs and groups without an If-Match header
+var errPreconditionRequired = services.NewError(services.KindPreconditionRequired, "precondition_required",
+	"If-Match header with the ETag of the version being changed is required")
+
+// entityTag is the strong ETag of a song or group response. It joins the version of the entity, derived from
+// when it was last updated, with a hash of the body, which also covers what changes without updating the entity
+// such as play counts, ratings, artwork and the group of a song.
+func entityTag(updatedAt pgtype.Timestamptz, body []byte) string {
+	hash := fnv.New64a()
+	hash.Write(body)
+	return `"` + entityVersion(updatedAt) + "-" + strconv.FormatUint(hash.Sum64(), 36) + `"`
+}
+
+// entityVersion is the part of an ETag that If-Match compares
+func entityVersion(updatedAt pgtype.Timestamptz) string {
+	return strconv.FormatInt(updatedAt.Time.UnixMicro(), 36)
+}
+
+// respondWithETag writes the response like respond, tagged with the ETag of its body. Reads answer
+// 304 Not Modified instead when If-None-Match lists the tag.
+func respondWithETag(c *gin.Context, status int, updatedAt pgtype.Timestamptz, envelope Envelope, legacy any) {
+	var value any = envelope
+	if apiVersion(c) == APIVersion1 {
+		value = legacy
+	}
+	body, err := json.Marshal(value)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	etag := entityTag(updatedAt, body)
+	c.Header("ETag", etag)
+
+	isRead := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+	if header := c.GetHeader("If-None-Match"); isRead && header != "" && matchesETag(header, etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+	c.Data(status, "application/json; charset=utf-8", body)
+}
+
+// checkIfMatch compares the If-Match header with the ETag of the version the client read and answers
+// 412 Precondition Failed when the entity has changed since. v2 requires the header and answers 428
+// Precondition Required without one, v1 predates it and only checks the header when it is sent.
+// Only the version part of the tags is compared, so plays, ratings or artwork changed in between do not
+// fail a write. It returns the version the write must still find, or the zero time for no If-Match or *.
+func checkIfMatch(c *gin.Context, updatedAt pgtype.Timestamptz) (time.Time, bool) {
+	header := c.GetHeader("If-Match")
+	if header == "" {
+		if apiVersion(c) == APIVersion2 {
+			respondError(c, errPreconditionRequired)
+			return time.Time{}, false
+		}
+		return time.Time{}, true
+	}
+	if strings.TrimSpace(header) == "*" {
+		return time.Time{}, true
+	}
+
+	if !matchesVersion(header, entityVersion(updatedAt)) {
+		respondError(c, services.ErrPreconditionFailed)
+		return time.Time{}, false
+	}
+	return updatedAt.Time, true
+}
+
+// matchesVersion reports whether a list of strong entity tags contains one of the given entity version
+func matchesVersion(header, version string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if strings.HasPrefix(tag, "W/") || len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+			continue
+		}
+		tagVersion, _, _ := strings.Cut(tag[1:len(tag)-1], "-")
+		if tagVersion == version {
+			return true
+		}
+	}
+	return false
+}
+
+// matchesETag reports whether a list of entity tags, as sent in If-None-Match, contains etag or is *.
+// The comparison is weak, it ignores the W/ prefix.
+func matchesETag(header, etag string) bool {
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || strings.TrimPrefix(tag, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
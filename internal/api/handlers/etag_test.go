@@ -0,0 +1,95 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/jackc/pgx/v5/pgtype"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// serveETag answers a request to an API version with body tagged by version, sending the If-None-Match and
+// If-Match headers when set. Writes check If-Match first.
+func serveETag(api int, method string, version pgtype.Timestamptz, body any, ifNoneMatch, ifMatch string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, "/songs/id", nil)
+	c.Set(apiVersionKey, api)
+	if ifNoneMatch != "" {
+		c.Request.Header.Set("If-None-Match", ifNoneMatch)
+	}
+	if ifMatch != "" {
+		c.Request.Header.Set("If-Match", ifMatch)
+	}
+	if method != http.MethodGet {
+		if _, ok := checkIfMatch(c, version); !ok {
+			return w
+		}
+	}
+
+	respondWithETag(c, http.StatusOK, version, Envelope{Data: body}, body)
+	// The router writes the status of responses without a body after the handlers ran
+	c.Writer.WriteHeaderNow()
+	return w
+}
+
+func TestRespondWithETag(t *testing.T) {
+	version := pgtype.Timestamptz{Time: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC), Valid: true}
+	song := gin.H{"title": "Song", "play_count": 1}
+	played := gin.H{"title": "Song", "play_count": 2}
+
+	etag := serveETag(APIVersion2, http.MethodGet, version, song, "", "").Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("no ETag")
+	}
+	if again := serveETag(APIVersion2, http.MethodGet, version, song, "", "").Header().Get("ETag"); again != etag {
+		t.Errorf("ETag of the same body = %s, want %s", again, etag)
+	}
+	if changed := serveETag(APIVersion2, http.MethodGet, version, played, "", "").Header().Get("ETag"); changed == etag {
+		t.Error("ETag did not change with the body while the version stayed the same")
+	}
+
+	tests := []struct {
+		name        string
+		api         int
+		method      string
+		version     pgtype.Timestamptz
+		body        any
+		ifNoneMatch string
+		ifMatch     string
+		wantStatus  int
+	}{
+		{"cached copy is current", APIVersion2, http.MethodGet, version, song, etag, "", http.StatusNotModified},
+		{"weak comparison", APIVersion2, http.MethodGet, version, song, "W/" + etag, "", http.StatusNotModified},
+		{"listed with other tags", APIVersion2, http.MethodGet, version, song, `"other", ` + etag, "", http.StatusNotModified},
+		{"any tag", APIVersion2, http.MethodGet, version, song, "*", "", http.StatusNotModified},
+		{"play count changed", APIVersion2, http.MethodGet, version, played, etag, "", http.StatusOK},
+		{"entity updated", APIVersion2, http.MethodGet, pgtype.Timestamptz{Time: version.Time.Add(time.Second), Valid: true}, song, etag, "", http.StatusOK},
+		{"If-None-Match ignored on writes", APIVersion2, http.MethodPatch, version, song, etag, "*", http.StatusOK},
+		{"If-Match with the version read", APIVersion2, http.MethodPatch, version, played, "", etag, http.StatusOK},
+		{"If-Match after an update", APIVersion2, http.MethodPatch, pgtype.Timestamptz{Time: version.Time.Add(time.Second), Valid: true}, song, "", etag, 0},
+		{"If-Match with a weak tag", APIVersion2, http.MethodPatch, version, song, "", "W/" + etag, 0},
+		{"If-Match with any version", APIVersion2, http.MethodPatch, version, song, "", "*", http.StatusOK},
+		{"If-Match required on v2", APIVersion2, http.MethodPatch, version, song, "", "", 0},
+		{"If-Match optional on v1", APIVersion1, http.MethodPatch, version, song, "", "", http.StatusOK},
+		{"If-Match checked on v1 when sent", APIVersion1, http.MethodPatch, pgtype.Timestamptz{Time: version.Time.Add(time.Second), Valid: true}, song, "", etag, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveETag(tt.api, tt.method, tt.version, tt.body, tt.ifNoneMatch, tt.ifMatch)
+			if tt.wantStatus == 0 {
+				// The precondition failed, the error is written by the problem middleware
+				if w.Body.Len() != 0 {
+					t.Errorf("body = %s, want none", w.Body)
+				}
+				return
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// fieldSet is the set of response fields a client asked for with ?fields=
+type fieldSet map[string]bool
+
+// parseFields reads the comma-separated ?fields= of a response of the given type, defaults are used when the
+// parameter is missing. Unknown fields are rejected and empty names skipped, the id is always included.
+func parseFields(c *gin.Context, response any, defaults []string) (fieldSet, error) {
+	known := jsonFields(response)
+
+	names := defaults
+	if value, ok := c.GetQuery("fields"); ok {
+		names = strings.Split(value, ",")
+	}
+
+	fields := fieldSet{"id": true}
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+		if !slices.Contains(known, name) {
+			return nil, invalidParam("fields", fmt.Sprintf("has unknown field %q, expected some of %s", name, strings.Join(known, ", ")))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// parseInclude reads the comma-separated ?include= of embeddable relations, defaults are used when the
+// parameter is missing and an empty parameter embeds nothing
+func parseInclude(c *gin.Context, allowed []string, defaults []string) (map[string]bool, error) {
+	names := defaults
+	if value, ok := c.GetQuery("include"); ok {
+		names = nil
+		if value != "" {
+			names = strings.Split(value, ",")
+		}
+	}
+
+	include := make(map[string]bool, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if !slices.Contains(allowed, name) {
+			return nil, invalidParam("include", fmt.Sprintf("has unknown relation %q, expected some of %s", name, strings.Join(allowed, ", ")))
+		}
+		include[name] = true
+	}
+	return include, nil
+}
+
+// project keeps only the fields of the set in a response, in the order of the struct. The response is
+// returned unchanged when the set has all of its fields.
+func (f fieldSet) project(response any) (any, error) {
+	names := jsonFields(response)
+	if len(f) == len(names) {
+		return response, nil
+	}
+
+	data, err := json.Marshal(response)
+	if err != nil {
+		return nil, err
+	}
+	var members map[string]json.RawMessage
+	if err = json.Unmarshal(data, &members); err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for _, name := range names {
+		value, ok := members[name]
+		if !ok || !f[name] {
+			continue
+		}
+		if buf.Len() > 1 {
+			buf.WriteByte(',')
+		}
+		key, _ := json.Marshal(name)
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return json.RawMessage(buf.Bytes()), nil
+}
+
+// projectAll projects every response of a list
+func projectAll[T any](f fieldSet, responses []T) ([]any, error) {
+	projected := make([]any, 0, len(responses))
+	for _, response := range responses {
+		value, err := f.project(response)
+		if err != nil {
+			return nil, err
+		}
+		projected = append(projected, value)
+	}
+	return projected, nil
+}
+
+// jsonFields lists the JSON names of the fields of a response struct in declaration order
+func jsonFields(response any) []string {
+	t := reflect.TypeOf(response)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	names := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
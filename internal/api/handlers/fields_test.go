@@ -0,0 +1,53 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestParseFields(t *testing.T) {
+	type response struct {
+		ID     string `json:"id"`
+		Title  string `json:"title"`
+		Lyrics string `json:"lyrics,omitempty"`
+	}
+	defaults := []string{"title"}
+
+	tests := []struct {
+		name    string
+		query   string
+		want    fieldSet
+		wantErr bool
+	}{
+		{"defaults", "", fieldSet{"id": true, "title": true}, false},
+		{"listed fields", "?fields=title,lyrics", fieldSet{"id": true, "title": true, "lyrics": true}, false},
+		{"spaces around names", "?fields=%20lyrics%20", fieldSet{"id": true, "lyrics": true}, false},
+		{"empty parameter", "?fields=", fieldSet{"id": true}, false},
+		{"empty names", "?fields=,lyrics,,", fieldSet{"id": true, "lyrics": true}, false},
+		{"unknown field", "?fields=title,rating", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/songs"+tt.query, nil)
+
+			got, err := parseFields(c, response{}, defaults)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseFields() = %v, want an error", got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseFields() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseFields() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
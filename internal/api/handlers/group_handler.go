@@ -4,21 +4,67 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
 	"net/http"
 	"strconv"
+	"time"
 )
 
 type GroupHandler struct {
-	groupService *services.GroupService
+	groupService   *services.GroupService
+	artworkService *services.ArtworkService
 }
 
 // NewGroupHandler creates a new group handler
-func NewGroupHandler(groupService *services.GroupService) *GroupHandler {
+func NewGroupHandler(groupService *services.GroupService, artworkService *services.ArtworkService) *GroupHandler {
 	return &GroupHandler{
-		groupService: groupService,
+		groupService:   groupService,
+		artworkService: artworkService,
 	}
 }
 
+// GroupResponse is the formatted group response for the API, songs include their group in this form
+type GroupResponse struct {
+	ID        string       `json:"id"`
+	Name      string       `json:"name"`
+	Artwork   *ArtworkData `json:"artwork,omitempty"`
+	CreatedAt time.Time    `json:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at"`
+}
+
+// GroupMergeReportResponse is what a group merge moved, or would move in a dry run
+type GroupMergeReportResponse struct {
+	DryRun         bool                        `json:"dry_run"`
+	Target         GroupResponse               `json:"target"`
+	Sources        []services.GroupMergeSource `json:"sources"`
+	SongsMoved     int64                       `json:"songs_moved"`
+	FavoritesMoved int64                       `json:"favorites_moved"`
+	AliasesAdded   int64                       `json:"aliases_added"`
+}
+
+// legacyGroupResponse is the stored group with its artwork, as v1 routes return groups
+type legacyGroupResponse struct {
+	database.Group
+	Artwork *ArtworkData `json:"artwork,omitempty"`
+}
+
+// legacyGroupListItem is a paginated group row with its artwork, as v1 routes list groups
+type legacyGroupListItem struct {
+	database.GetGroupsWithPaginationRow
+	Artwork *ArtworkData `json:"artwork,omitempty"`
+}
+
+// GroupAliasResponse is another name a group is known under
+type GroupAliasResponse struct {
+	ID        string    `json:"id"`
+	GroupID   string    `json:"group_id"`
+	Name      string    `json:"name"`
+	Locale    string    `json:"locale"`
+	Type      string    `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
 // CreateGroup godoc
 // @Summary Create a new music group
 // @Description Create a new music group with the provided name
@@ -26,27 +72,66 @@ func NewGroupHandler(groupService *services.GroupService) *GroupHandler {
 // @Accept json
 // @Produce json
 // @Param group body object{name=string} true "Group Name"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
 // @Success 201 {object} object{id=string,name=string,created_at=string,updated_at=string} "Created group data"
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 409 {object} middleware.Problem "A live group already has this name, ignoring case, or a request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /groups [post]
 func (h *GroupHandler) CreateGroup(c *gin.Context) {
 	var body struct {
 		Name string `json:"name" binding:"required"`
 	}
 
-	if err := c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
 	createdGroup, err := h.groupService.CreateGroup(c, body.Name)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create group: " + err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	respond(c, http.StatusCreated, Envelope{Data: newGroupResponse(createdGroup, nil)}, createdGroup)
+}
+
+// GetOrCreateGroup godoc
+// @Summary Get or create a music group by name
+// @Description Return the live group whose name matches ignoring case and surrounding whitespace, creating it when there is none.
+// @Description Meant for importers that only know group names.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body object{name=string} true "Group Name"
+// @Success 200 {object} object{data=object,created=boolean} "Existing group"
+// @Success 201 {object} object{data=object,created=boolean} "Created group"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/get-or-create [post]
+func (h *GroupHandler) GetOrCreateGroup(c *gin.Context) {
+	var body struct {
+		Name string `json:"name" binding:"required,max=255"`
+	}
+
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	group, created, err := h.groupService.GetOrCreateGroup(c, body.Name)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, createdGroup)
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	respond(c, status, Envelope{Data: newGroupResponse(group, nil), Meta: gin.H{"created": created}},
+		gin.H{"data": group, "created": created})
 }
 
 // GetGroup godoc
@@ -55,36 +140,49 @@ func (h *GroupHandler) CreateGroup(c *gin.Context) {
 // @Tags groups
 // @Produce json
 // @Param id path string true "Group ID" format(uuid)
+// @Param If-None-Match header string false "ETag of a cached copy"
 // @Success 200 {object} object{id=string,name=string,created_at=string,updated_at=string}
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 404 {object} object{error=string} "Group not found"
+// @Header 200 {string} ETag "Version of the group, send it back in If-Match to change it"
+// @Success 304 "The cached copy is current"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Group not found"
 // @Router /groups/{id} [get]
 func (h *GroupHandler) GetGroup(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		respondError(c, invalidID("id", "group"))
 		return
 	}
 
 	group, err := h.groupService.GetGroup(c, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Group not found"})
+		respondError(c, err)
+		return
+	}
+	response, err := h.formatGroup(c, group)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, group)
+	respondWithETag(c, http.StatusOK, group.UpdatedAt, Envelope{Data: response}, legacyGroup(group, response))
 }
 
 // GetAllGroups godoc
 // @Summary Get all music groups
-// @Description Get a paginated list of music groups
+// @Description Get a paginated list of music groups, optionally only those whose name or one of whose aliases contains name.
+// @Description Pass cursor instead of page to page with cursors: an empty cursor starts at the newest group and every page
+// @Description returns next_cursor and prev_cursor, which are null at either end. Cursor pages have no total.
 // @Tags groups
 // @Produce json
 // @Param page query int false "Page number" default(1)
+// @Param cursor query string false "Cursor of the page to read, empty for the first page"
 // @Param limit query int false "Items per page" default(10)
-// @Success 200 {object} object{data=array,page=int,limit=int,pages=int,total=int}
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Param name query string false "Filter by group name or alias"
+// @Success 200 {object} object{data=array,page=int,limit=int,pages=int,total=int,next_cursor=string,prev_cursor=string}
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid cursor"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /groups [get]
 func (h *GroupHandler) GetAllGroups(c *gin.Context) {
 	pageStr := c.Query("page")
@@ -101,30 +199,71 @@ func (h *GroupHandler) GetAllGroups(c *gin.Context) {
 	}
 
 	offset := (page - 1) * limit
+	name := c.Query("name")
 
-	groups, err := h.groupService.GetGroupsWithPagination(c, int32(limit), int32(offset))
-	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups: " + err.Error()})
-		return
+	var groups []database.GetGroupsWithPaginationRow
+	var meta gin.H
+
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		var cursorPage repository.CursorPage
+		groups, cursorPage, err = h.groupService.GetGroupsWithCursor(c, name, int32(limit), cursor)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		meta = cursorMeta(limit, cursorPage)
+	} else {
+		if name != "" {
+			groups, err = h.groupService.SearchGroupsWithPagination(c, name, int32(limit), int32(offset))
+		} else {
+			groups, err = h.groupService.GetGroupsWithPagination(c, int32(limit), int32(offset))
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+
+		var total int64
+		if name != "" {
+			total, err = h.groupService.SearchGroupsCount(c, name)
+		} else {
+			total, err = h.groupService.GetGroupsCount(c)
+		}
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		meta = paginationMeta(page, limit, total)
 	}
 
-	total, err := h.groupService.GetGroupsCount(c)
+	groupIDs := make([]uuid.UUID, 0, len(groups))
+	for _, group := range groups {
+		groupIDs = append(groupIDs, group.ID.Bytes)
+	}
+
+	artworks, err := h.artworkService.GetArtworks(c, repository.ArtworkEntityGroup, groupIDs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve groups count: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	totalPages := (int(total) + limit - 1) / limit
-
-	response := gin.H{
-		"data":  groups,
-		"page":  page,
-		"limit": limit,
-		"pages": totalPages,
-		"total": total,
+	items := make([]GroupResponse, 0, len(groups))
+	legacyItems := make([]legacyGroupListItem, 0, len(groups))
+	for _, group := range groups {
+		var artworkData *ArtworkData
+		if artwork, ok := artworks[group.ID.Bytes]; ok {
+			artworkData = newArtworkData(h.artworkService, artwork)
+		}
+		items = append(items, newGroupResponse(database.Group{
+			ID:        group.ID,
+			Name:      group.Name,
+			CreatedAt: group.CreatedAt,
+			UpdatedAt: group.UpdatedAt,
+		}, artworkData))
+		legacyItems = append(legacyItems, legacyGroupListItem{GetGroupsWithPaginationRow: group, Artwork: artworkData})
 	}
 
-	c.JSON(http.StatusOK, response)
+	respond(c, http.StatusOK, Envelope{Data: items, Meta: meta}, flatten(legacyItems, meta))
 }
 
 // UpdateGroup godoc
@@ -134,16 +273,32 @@ func (h *GroupHandler) GetAllGroups(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Group ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param group body object{name=string} true "Group Info"
 // @Success 200 {object} object{id=string,name=string,created_at=string,updated_at=string} "Group updated successfully"
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Header 200 {string} ETag "ETag of the updated group"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 409 {object} middleware.Problem "Another live group already has this name, ignoring case"
+// @Failure 412 {object} middleware.Problem "The group has been modified since it was read"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /groups/{id} [put]
 func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	current, err := h.groupService.GetGroup(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, current.UpdatedAt)
+	if !ok {
 		return
 	}
 
@@ -151,42 +306,381 @@ func (h *GroupHandler) UpdateGroup(c *gin.Context) {
 		Name string `json:"name" binding:"required"`
 	}
 
-	if err = c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	group, err := h.groupService.UpdateGroup(c, id, body.Name, ifUpdatedAt)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatGroup(c, group)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, group.UpdatedAt, Envelope{Data: response}, gin.H{"message": legacyGroup(group, response)})
+}
+
+// PatchGroup godoc
+// @Summary Partially update a music group
+// @Description Update only some fields of a group with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902) applied to {name}.
+// @Tags groups
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} object{data=object} "Updated group"
+// @Header 200 {string} ETag "ETag of the updated group"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid patch or field"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 409 {object} middleware.Problem "A JSON Patch test operation failed, or another live group already has the name"
+// @Failure 412 {object} middleware.Problem "The group has been modified since it was read"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 415 {object} middleware.Problem "Unsupported patch media type"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id} [patch]
+func (h *GroupHandler) PatchGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	group, err := h.groupService.GetGroup(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, group.UpdatedAt)
+	if !ok {
+		return
+	}
+
+	changed, err := patchFields(c, struct {
+		Name string `json:"name"`
+	}{Name: group.Name})
+	if err != nil {
+		respondPatchError(c, err)
+		return
+	}
+
+	params := repository.GroupPatchParams{ID: id, IfUpdatedAt: ifUpdatedAt}
+	for field, value := range changed {
+		if field != "name" {
+			respondPatchError(c, fieldError(field, "is not a group field"))
+			return
+		}
+		if value == nil {
+			respondPatchError(c, fieldError(field, "cannot be removed"))
+			return
+		}
+		name, err := patchString(field, value)
+		if err != nil {
+			respondPatchError(c, err)
+			return
+		}
+		params.Name = &name
+	}
+
+	group, err = h.groupService.PatchGroup(c, params)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	group, err := h.groupService.UpdateGroup(c, id, body.Name)
+	response, err := h.formatGroup(c, group)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update group: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": group})
+	respondWithETag(c, http.StatusOK, group.UpdatedAt, Envelope{Data: response}, gin.H{"data": legacyGroup(group, response)})
 }
 
 // DeleteGroup godoc
 // @Summary Delete a music group
-// @Description Delete a music group by ID
+// @Description Delete a music group by ID together with its songs, restoring the group brings the songs back
 // @Tags groups
 // @Produce json
 // @Param id path string true "Group ID" format(uuid)
-// @Success 204 {object} object{message=string} "Group deleted successfully"
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Success 204 "Group deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 412 {object} middleware.Problem "The group has been modified since it was read"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /groups/{id} [delete]
 func (h *GroupHandler) DeleteGroup(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		respondError(c, invalidID("id", "group"))
 		return
 	}
 
-	if err = h.groupService.DeleteGroup(c, id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete group: " + err.Error()})
+	group, err := h.groupService.GetGroup(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, group.UpdatedAt)
+	if !ok {
 		return
 	}
 
-	c.JSON(http.StatusNoContent, gin.H{"message": "Group deleted successfully"})
+	if err = h.groupService.DeleteGroup(c, id, ifUpdatedAt); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreGroup godoc
+// @Summary Restore a deleted music group
+// @Description Undelete a group and the songs that were deleted along with it. Songs deleted on their own stay deleted.
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} object{data=object,songs_restored=int} "Restored group and the number of songs restored with it"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 409 {object} middleware.Problem "A live group has taken the name in the meantime"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/restore [post]
+func (h *GroupHandler) RestoreGroup(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	group, restored, err := h.groupService.RestoreGroup(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatGroup(c, group)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, Envelope{Data: response, Meta: gin.H{"songs_restored": restored}},
+		gin.H{"data": legacyGroup(group, response), "songs_restored": restored})
+}
+
+// MergeGroups godoc
+// @Summary Merge groups into a group
+// @Description Move the songs and favorites of the source groups to this group, record the source names and aliases as aliases of it and delete the sources, all atomically.
+// @Description With dry_run the merge is rolled back and only reported.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the group to keep" format(uuid)
+// @Param merge body object{source_ids=[]string,dry_run=boolean} true "Groups to merge into this one"
+// @Success 200 {object} object{data=services.GroupMergeReport} "What was moved, or would be moved in a dry run"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid ID or merging a group into itself"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/merge [post]
+func (h *GroupHandler) MergeGroups(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	var body struct {
+		SourceIDs []string `json:"source_ids" binding:"required,min=1"`
+		DryRun    bool     `json:"dry_run"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	sourceIDs := make([]uuid.UUID, 0, len(body.SourceIDs))
+	for _, value := range body.SourceIDs {
+		sourceID, err := uuid.Parse(value)
+		if err != nil {
+			respondError(c, invalidID("source_ids", "source group"))
+			return
+		}
+		sourceIDs = append(sourceIDs, sourceID)
+	}
+
+	report, err := h.groupService.MergeGroups(c, id, sourceIDs, body.DryRun)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, Envelope{Data: GroupMergeReportResponse{
+		DryRun:         report.DryRun,
+		Target:         newGroupResponse(report.Target, nil),
+		Sources:        report.Sources,
+		SongsMoved:     report.SongsMoved,
+		FavoritesMoved: report.FavoritesMoved,
+		AliasesAdded:   report.AliasesAdded,
+	}}, gin.H{"data": report})
+}
+
+// GetGroupAliases godoc
+// @Summary List group aliases
+// @Description List the localised, legal, stage, former and search names of a group
+// @Tags groups
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Success 200 {object} object{data=[]GroupAliasResponse} "Group aliases"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/aliases [get]
+func (h *GroupHandler) GetGroupAliases(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	aliases, err := h.groupService.GetGroupAliases(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]GroupAliasResponse, 0, len(aliases))
+	for _, alias := range aliases {
+		data = append(data, formatGroupAlias(alias))
+	}
+
+	respondData(c, http.StatusOK, data)
+}
+
+// CreateGroupAlias godoc
+// @Summary Add a group alias
+// @Description Add another name the group is known under. Aliases are matched by group search and the group filter of songs.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID" format(uuid)
+// @Param alias body object{name=string,locale=string,type=string} true "Alias, type is one of legal, stage, former or search (default) and locale is a language tag such as ja or pt-BR"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=GroupAliasResponse} "Created alias"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid alias"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 409 {object} middleware.Problem "The group already has this alias, or a request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/aliases [post]
+func (h *GroupHandler) CreateGroupAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	var body struct {
+		Name   string `json:"name" binding:"required,max=255"`
+		Locale string `json:"locale" binding:"max=35"`
+		Type   string `json:"type"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	alias, err := h.groupService.CreateGroupAlias(c, repository.GroupAliasCreateParams{
+		GroupID: id,
+		Name:    body.Name,
+		Locale:  body.Locale,
+		Type:    body.Type,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, formatGroupAlias(alias))
+}
+
+// DeleteGroupAlias godoc
+// @Summary Delete a group alias
+// @Description Remove an alias from a group
+// @Tags groups
+// @Param id path string true "Group ID" format(uuid)
+// @Param alias_id path string true "Alias ID" format(uuid)
+// @Success 204 "Alias deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Alias not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /groups/{id}/aliases/{alias_id} [delete]
+func (h *GroupHandler) DeleteGroupAlias(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "group"))
+		return
+	}
+
+	aliasID, err := uuid.Parse(c.Param("alias_id"))
+	if err != nil {
+		respondError(c, invalidID("alias_id", "alias"))
+		return
+	}
+
+	if err = h.groupService.DeleteGroupAlias(c, id, aliasID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func formatGroupAlias(alias database.GroupAlias) GroupAliasResponse {
+	return GroupAliasResponse{
+		ID:        alias.ID.String(),
+		GroupID:   alias.GroupID.String(),
+		Name:      alias.Name,
+		Locale:    alias.Locale,
+		Type:      alias.Type,
+		CreatedAt: alias.CreatedAt.Time,
+	}
+}
+
+// Format a single group with its artwork
+func (h *GroupHandler) formatGroup(c *gin.Context, group database.Group) (GroupResponse, error) {
+	artwork, ok, err := h.artworkService.GetArtwork(c, repository.ArtworkEntityGroup, group.ID.Bytes)
+	if err != nil {
+		return GroupResponse{}, err
+	}
+	if !ok {
+		return newGroupResponse(group, nil), nil
+	}
+	return newGroupResponse(group, newArtworkData(h.artworkService, artwork)), nil
+}
+
+func newGroupResponse(group database.Group, artwork *ArtworkData) GroupResponse {
+	return GroupResponse{
+		ID:        group.ID.String(),
+		Name:      group.Name,
+		Artwork:   artwork,
+		CreatedAt: group.CreatedAt.Time,
+		UpdatedAt: group.UpdatedAt.Time,
+	}
+}
+
+// legacyGroup is the body v1 routes return for a group formatted as response
+func legacyGroup(group database.Group, response GroupResponse) legacyGroupResponse {
+	return legacyGroupResponse{Group: group, Artwork: response.Artwork}
 }
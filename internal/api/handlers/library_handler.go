@@ -0,0 +1,289 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"time"
+)
+
+type LibraryHandler struct {
+	libraryService *services.LibraryService
+}
+
+// NewLibraryHandler creates a new library handler
+func NewLibraryHandler(libraryService *services.LibraryService) *LibraryHandler {
+	return &LibraryHandler{
+		libraryService: libraryService,
+	}
+}
+
+// FavoriteResponse is a favorited song or group
+type FavoriteResponse struct {
+	Type        string    `json:"type"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`                 // song title or group name
+	GroupName   string    `json:"group_name,omitempty"` // only set for songs
+	FavoritedAt time.Time `json:"favorited_at"`
+}
+
+// PlayEventResponse is one entry of the listening history
+type PlayEventResponse struct {
+	ID             string    `json:"id"`
+	SongID         string    `json:"song_id"`
+	Title          string    `json:"title"`
+	GroupName      string    `json:"group_name"`
+	PlayedAt       time.Time `json:"played_at"`
+	DurationPlayed int32     `json:"duration_played"`
+	Client         string    `json:"client"`
+	Available      bool      `json:"available"`
+}
+
+// AddFavoriteSong godoc
+// @Summary Favorite a song
+// @Description Add a song to the current user's favorites, favoriting twice has no effect
+// @Tags me
+// @Param id path string true "Song ID" format(uuid)
+// @Success 204 "Song favorited"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/favorites/songs/{id} [put]
+func (h *LibraryHandler) AddFavoriteSong(c *gin.Context) {
+	h.addFavorite(c, repository.FavoriteEntitySong)
+}
+
+// RemoveFavoriteSong godoc
+// @Summary Unfavorite a song
+// @Description Remove a song from the current user's favorites
+// @Tags me
+// @Param id path string true "Song ID" format(uuid)
+// @Success 204 "Song unfavorited"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Favorite not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/favorites/songs/{id} [delete]
+func (h *LibraryHandler) RemoveFavoriteSong(c *gin.Context) {
+	h.removeFavorite(c, repository.FavoriteEntitySong)
+}
+
+// AddFavoriteGroup godoc
+// @Summary Favorite a group
+// @Description Add a music group to the current user's favorites, favoriting twice has no effect
+// @Tags me
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204 "Group favorited"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Group not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/favorites/groups/{id} [put]
+func (h *LibraryHandler) AddFavoriteGroup(c *gin.Context) {
+	h.addFavorite(c, repository.FavoriteEntityGroup)
+}
+
+// RemoveFavoriteGroup godoc
+// @Summary Unfavorite a group
+// @Description Remove a music group from the current user's favorites
+// @Tags me
+// @Param id path string true "Group ID" format(uuid)
+// @Success 204 "Group unfavorited"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Favorite not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/favorites/groups/{id} [delete]
+func (h *LibraryHandler) RemoveFavoriteGroup(c *gin.Context) {
+	h.removeFavorite(c, repository.FavoriteEntityGroup)
+}
+
+func (h *LibraryHandler) addFavorite(c *gin.Context, entityType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", entityType))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err = h.libraryService.AddFavorite(c, principal.UserID, entityType, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+func (h *LibraryHandler) removeFavorite(c *gin.Context, entityType string) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", entityType))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err = h.libraryService.RemoveFavorite(c, principal.UserID, entityType, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// GetFavorites godoc
+// @Summary List favorites
+// @Description Retrieve the current user's favorite songs and groups, most recently favorited first.
+// @Description Deleted songs and groups are left out.
+// @Tags me
+// @Produce json
+// @Param type query string false "Only list songs or groups" Enums(song, group)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} object{data=[]FavoriteResponse,page=int,limit=int,pages=int,total=int} "Paginated list of favorites"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/favorites [get]
+func (h *LibraryHandler) GetFavorites(c *gin.Context) {
+	entityType := c.Query("type")
+	if entityType != "" && entityType != repository.FavoriteEntitySong && entityType != repository.FavoriteEntityGroup {
+		respondError(c, invalidParam("type", "must be song or group"))
+		return
+	}
+
+	page, limit, offset := parsePagination(c)
+	principal, _ := middleware.GetPrincipal(c)
+
+	favorites, err := h.libraryService.GetFavoritesWithPagination(c, repository.FavoriteFilterParams{
+		UserID:     principal.UserID,
+		EntityType: entityType,
+		Limit:      int32(limit),
+		Offset:     int32(offset),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.libraryService.GetFavoritesCount(c, principal.UserID, entityType)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]FavoriteResponse, 0, len(favorites))
+	for _, favorite := range favorites {
+		data = append(data, FavoriteResponse{
+			Type:        favorite.EntityType,
+			ID:          favorite.EntityID.String(),
+			Name:        favorite.Name,
+			GroupName:   favorite.GroupName,
+			FavoritedAt: favorite.CreatedAt.Time,
+		})
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+// RecordPlay godoc
+// @Summary Record a play
+// @Description Add a play event to the current user's listening history, played_at defaults to now
+// @Tags me
+// @Accept json
+// @Produce json
+// @Param play body object{song_id=string,played_at=string,duration_played=integer,client=string} true "Play event, duration_played is in seconds"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=object{id=string,song_id=string,played_at=string,duration_played=integer,client=string}} "Recorded play event"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/history [post]
+func (h *LibraryHandler) RecordPlay(c *gin.Context) {
+	var body struct {
+		SongID         string    `json:"song_id" binding:"required"`
+		PlayedAt       time.Time `json:"played_at"`
+		DurationPlayed int32     `json:"duration_played" binding:"min=0"`
+		Client         string    `json:"client" binding:"max=64"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	songID, err := uuid.Parse(body.SongID)
+	if err != nil {
+		respondError(c, invalidID("song_id", "song"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	event, err := h.libraryService.RecordPlay(c, repository.PlayEventCreateParams{
+		UserID:         principal.UserID,
+		SongID:         songID,
+		PlayedAt:       body.PlayedAt,
+		DurationPlayed: body.DurationPlayed,
+		Client:         body.Client,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, gin.H{
+		"id":              event.ID.String(),
+		"song_id":         event.SongID.String(),
+		"played_at":       event.PlayedAt.Time,
+		"duration_played": event.DurationPlayed,
+		"client":          event.Client,
+	})
+}
+
+// GetHistory godoc
+// @Summary List listening history
+// @Description Retrieve the current user's play events, most recent first
+// @Tags me
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} object{data=[]PlayEventResponse,page=int,limit=int,pages=int,total=int} "Paginated listening history"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/history [get]
+func (h *LibraryHandler) GetHistory(c *gin.Context) {
+	page, limit, offset := parsePagination(c)
+	principal, _ := middleware.GetPrincipal(c)
+
+	events, err := h.libraryService.GetPlayHistoryWithPagination(c, principal.UserID, int32(limit), int32(offset))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.libraryService.GetPlayHistoryCount(c, principal.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]PlayEventResponse, 0, len(events))
+	for _, event := range events {
+		data = append(data, PlayEventResponse{
+			ID:             event.ID.String(),
+			SongID:         event.SongID.String(),
+			Title:          event.Title,
+			GroupName:      event.GroupName,
+			PlayedAt:       event.PlayedAt.Time,
+			DurationPlayed: event.DurationPlayed,
+			Client:         event.Client,
+			Available:      event.Available,
+		})
+	}
+
+	respondPage(c, data, page, limit, total)
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"strconv"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// parsePagination reads the page and limit query parameters, defaulting to the first page of 10.
+// The limit is capped at 100 so a page never asks the database for more rows than that.
+func parsePagination(c *gin.Context) (page, limit, offset int) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err = strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = defaultPageLimit
+	}
+	limit = min(limit, maxPageLimit)
+
+	return page, limit, (page - 1) * limit
+}
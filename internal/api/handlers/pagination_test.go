@@ -0,0 +1,37 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestParsePagination(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		wantPage   int
+		wantLimit  int
+		wantOffset int
+	}{
+		{"defaults", "", 1, 10, 0},
+		{"page and limit", "?page=3&limit=20", 3, 20, 40},
+		{"invalid values", "?page=abc&limit=-5", 1, 10, 0},
+		{"zero page", "?page=0&limit=5", 1, 5, 0},
+		{"limit above maximum", "?page=2&limit=1000", 2, 100, 100},
+		{"limit overflowing int32", "?limit=4294967297", 1, 100, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest(http.MethodGet, "/library"+tt.query, nil)
+
+			page, limit, offset := parsePagination(c)
+			if page != tt.wantPage || limit != tt.wantLimit || offset != tt.wantOffset {
+				t.Errorf("parsePagination() = %d, %d, %d, want %d, %d, %d", page, limit, offset, tt.wantPage, tt.wantLimit, tt.wantOffset)
+			}
+		})
+	}
+}
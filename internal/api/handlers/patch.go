@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/services"
+	"music-service/internal/pkg/utils/jsonpatch"
+	"reflect"
+)
+
+// Errors of PATCH requests whose body cannot be applied
+var (
+	errUnsupportedPatch = services.NewError(services.KindUnsupportedMedia, "unsupported_patch_type",
+		"PATCH accepts "+jsonpatch.MergePatchContentType+" or "+jsonpatch.JSONPatchContentType)
+	errInvalidPatch    = services.NewValidationError("invalid_patch", "Invalid patch")
+	errPatchTestFailed = services.NewError(services.KindConflict, "patch_test_failed", "Patch test failed")
+	errInvalidField    = services.NewValidationError("invalid_field", "A patched field has an invalid value")
+)
+
+// fieldError reports a patched field with an invalid value
+func fieldError(field, reason string) error {
+	return errInvalidField.WithMessage(field + " " + reason).WithFields(services.FieldError{Field: field, Message: reason})
+}
+
+// patchFields applies the PATCH body to the JSON form of current and returns the top-level members that
+// differ afterwards, members the patch removed are returned as nil. Plain application/json is treated as a merge patch.
+func patchFields(c *gin.Context, current any) (map[string]any, error) {
+	body, err := c.GetRawData()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(current)
+	if err != nil {
+		return nil, err
+	}
+	original, err := jsonpatch.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+	doc, err := jsonpatch.Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched any
+	switch c.ContentType() {
+	case jsonpatch.MergePatchContentType, "application/json":
+		patch, err := jsonpatch.Decode(body)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", jsonpatch.ErrInvalidPatch, err)
+		}
+		patched = jsonpatch.MergePatch(doc, patch)
+	case jsonpatch.JSONPatchContentType:
+		ops, err := jsonpatch.ParsePatch(body)
+		if err != nil {
+			return nil, err
+		}
+		if patched, err = jsonpatch.Apply(doc, ops); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errUnsupportedPatch
+	}
+
+	object, ok := patched.(map[string]any)
+	if !ok {
+		return nil, fmt.Errorf("%w: the patched document must be an object", jsonpatch.ErrInvalidPatch)
+	}
+
+	originalObject := original.(map[string]any)
+	changed := make(map[string]any)
+	for key, value := range object {
+		if before, ok := originalObject[key]; !ok || !reflect.DeepEqual(before, value) {
+			changed[key] = value
+		}
+	}
+	for key := range originalObject {
+		if _, ok := object[key]; !ok {
+			changed[key] = nil
+		}
+	}
+	return changed, nil
+}
+
+// respondPatchError answers errors of patchFields and of validating the fields it returned
+func respondPatchError(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		err = errPatchTestFailed.WithMessage("Patch test failed: " + err.Error())
+	case errors.Is(err, jsonpatch.ErrInvalidPatch):
+		err = errInvalidPatch.WithMessage("Invalid patch: " + err.Error())
+	}
+	respondError(c, err)
+}
+
+// patchString returns a patched string member, which must be present and not blank
+func patchString(field string, value any) (string, error) {
+	s, ok := value.(string)
+	if !ok {
+		return "", fieldError(field, "must be a string")
+	}
+	if s == "" {
+		return "", fieldError(field, "cannot be empty")
+	}
+	return s, nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"log/slog"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// servePatch applies a PATCH body to a song document and answers with the changed fields once they passed validation
+func servePatch(contentType, body string) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Problems(slog.New(slog.DiscardHandler)))
+	router.PATCH("/songs/:id", func(c *gin.Context) {
+		changed, err := patchFields(c, songPatchDocument{
+			GroupID:     "5f0c6a52-0b7a-4a3f-8d0c-6f35b1c1a001",
+			Title:       "Title",
+			Runtime:     200,
+			Lyrics:      "Verse",
+			ReleaseDate: "2020-01-02",
+			Link:        "https://example.com",
+		})
+		if err == nil {
+			_, err = songPatchParams(uuid.New(), changed)
+		}
+		if err != nil {
+			respondPatchError(c, err)
+			return
+		}
+		c.JSON(http.StatusOK, changed)
+	})
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPatch, "/songs/"+uuid.NewString(), strings.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPatchFields(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		want        map[string]any
+	}{
+		{"merge patch", "application/merge-patch+json", `{"title":"New","runtime":180}`, map[string]any{"title": "New", "runtime": 180.0}},
+		{"plain JSON is a merge patch", "application/json", `{"title":"New"}`, map[string]any{"title": "New"}},
+		{"merge patch null removes", "application/merge-patch+json", `{"lyrics":null}`, map[string]any{"lyrics": nil}},
+		{"unchanged fields are left out", "application/merge-patch+json", `{"title":"Title","link":"https://example.org"}`, map[string]any{"link": "https://example.org"}},
+		{"JSON patch", "application/json-patch+json", `[{"op":"test","path":"/title","value":"Title"},{"op":"replace","path":"/title","value":"New"}]`, map[string]any{"title": "New"}},
+		{"JSON patch remove", "application/json-patch+json", `[{"op":"remove","path":"/lyrics"}]`, map[string]any{"lyrics": nil}},
+		{"JSON patch copy", "application/json-patch+json", `[{"op":"copy","from":"/title","path":"/lyrics"}]`, map[string]any{"lyrics": "Title"}},
+		{"empty JSON patch", "application/json-patch+json", `[]`, map[string]any{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := servePatch(tt.contentType, tt.body)
+			if w.Code != http.StatusOK {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusOK, w.Body)
+			}
+
+			var got map[string]any
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("decoding changed fields: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("changed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPatchFieldsErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantCode    string
+	}{
+		{"failing test operation", "application/json-patch+json", `[{"op":"test","path":"/title","value":"Other"},{"op":"replace","path":"/title","value":"New"}]`, http.StatusConflict, "patch_test_failed"},
+		{"unknown operation", "application/json-patch+json", `[{"op":"rename","path":"/title"}]`, http.StatusBadRequest, "invalid_patch"},
+		{"JSON patch that is not an array", "application/json-patch+json", `{"title":"New"}`, http.StatusBadRequest, "invalid_patch"},
+		{"malformed merge patch", "application/merge-patch+json", `{"title":`, http.StatusBadRequest, "invalid_patch"},
+		{"document replaced by scalar", "application/merge-patch+json", `"title"`, http.StatusBadRequest, "invalid_patch"},
+		{"document removed", "application/json-patch+json", `[{"op":"replace","path":"","value":null}]`, http.StatusBadRequest, "invalid_patch"},
+		{"unsupported media type", "text/plain", `{"title":"New"}`, http.StatusUnsupportedMediaType, "unsupported_patch_type"},
+		{"unknown field", "application/merge-patch+json", `{"play_count":10}`, http.StatusBadRequest, "invalid_field"},
+		{"read-only field added by JSON patch", "application/json-patch+json", `[{"op":"add","path":"/id","value":"x"}]`, http.StatusBadRequest, "invalid_field"},
+		{"required field removed", "application/merge-patch+json", `{"title":null}`, http.StatusBadRequest, "invalid_field"},
+		{"required field removed by JSON patch", "application/json-patch+json", `[{"op":"remove","path":"/runtime"}]`, http.StatusBadRequest, "invalid_field"},
+		{"wrong field type", "application/merge-patch+json", `{"runtime":"long"}`, http.StatusBadRequest, "invalid_field"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertProblem(t, servePatch(tt.contentType, tt.body), tt.wantStatus, tt.wantCode)
+		})
+	}
+}
+
+func TestSongPatchParams(t *testing.T) {
+	tests := []struct {
+		name    string
+		changed map[string]any
+		field   string // field of the expected error, empty when the fields are valid
+	}{
+		{"title", map[string]any{"title": "New"}, ""},
+		{"lyrics removed", map[string]any{"lyrics": nil}, ""},
+		{"runtime", map[string]any{"runtime": 180.0}, ""},
+		{"fractional runtime", map[string]any{"runtime": 1.5}, "runtime"},
+		{"zero runtime", map[string]any{"runtime": 0.0}, "runtime"},
+		{"empty title", map[string]any{"title": ""}, "title"},
+		{"invalid group ID", map[string]any{"group_id": "group"}, "group_id"},
+		{"invalid release date", map[string]any{"release_date": "02.01.2020"}, "release_date"},
+		{"link removed", map[string]any{"link": nil}, "link"},
+		{"ID", map[string]any{"id": uuid.NewString()}, "id"},
+		{"timestamps", map[string]any{"updated_at": "2020-01-02T00:00:00Z"}, "updated_at"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := songPatchParams(uuid.New(), tt.changed)
+			if tt.field == "" {
+				if err != nil {
+					t.Fatalf("songPatchParams() error = %v", err)
+				}
+				return
+			}
+
+			var serviceErr *services.Error
+			if !errors.As(err, &serviceErr) {
+				t.Fatalf("songPatchParams() error = %v, want a field error", err)
+			}
+			if len(serviceErr.Fields) != 1 || serviceErr.Fields[0].Field != tt.field {
+				t.Errorf("fields = %+v, want %s", serviceErr.Fields, tt.field)
+			}
+		})
+	}
+}
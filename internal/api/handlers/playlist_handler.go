@@ -0,0 +1,585 @@
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/pkg/utils/playlistfile"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// maxPlaylistImportSize is the largest accepted playlist file in bytes
+const maxPlaylistImportSize = 5 << 20
+
+// errInvalidPlaylistFile is returned for imported playlist files that cannot be decoded
+var errInvalidPlaylistFile = services.NewValidationError("invalid_playlist_file", "Failed to read playlist file")
+
+type PlaylistHandler struct {
+	playlistService *services.PlaylistService
+}
+
+// NewPlaylistHandler creates a new playlist handler
+func NewPlaylistHandler(playlistService *services.PlaylistService) *PlaylistHandler {
+	return &PlaylistHandler{
+		playlistService: playlistService,
+	}
+}
+
+// PlaylistEntryResponse is a single ordered song of a playlist.
+// Entries whose song was deleted stay in place and are marked unavailable.
+type PlaylistEntryResponse struct {
+	ID        string    `json:"id"`
+	Position  int32     `json:"position"`
+	SongID    string    `json:"song_id"`
+	Title     string    `json:"title"`
+	GroupID   string    `json:"group_id"`
+	GroupName string    `json:"group_name"`
+	Runtime   int32     `json:"runtime"`
+	Available bool      `json:"available"`
+	AddedAt   time.Time `json:"added_at"`
+}
+
+// PlaylistResponse is the formatted playlist response for the API
+type PlaylistResponse struct {
+	ID               string                  `json:"id"`
+	Name             string                  `json:"name"`
+	Description      string                  `json:"description"`
+	OwnerID          string                  `json:"owner_id"`
+	Visibility       string                  `json:"visibility"`
+	EntryCount       int64                   `json:"entry_count"`
+	UnavailableCount int64                   `json:"unavailable_count"`
+	TotalRuntime     int64                   `json:"total_runtime"`
+	Entries          []PlaylistEntryResponse `json:"entries,omitempty"`
+	CreatedAt        time.Time               `json:"created_at"`
+	UpdatedAt        time.Time               `json:"updated_at"`
+}
+
+// CreatePlaylist godoc
+// @Summary Create a new playlist
+// @Description Create a new playlist owned by the caller, visibility is one of public, unlisted or private (default)
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param playlist body object{name=string,description=string,visibility=string} true "Playlist Information"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=PlaylistResponse} "Created playlist"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists [post]
+func (h *PlaylistHandler) CreatePlaylist(c *gin.Context) {
+	var body struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	}
+
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	if body.Visibility == "" {
+		body.Visibility = services.PlaylistVisibilityPrivate
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.playlistService.CreatePlaylist(c, repository.PlaylistCreateParams{
+		Name:        body.Name,
+		Description: body.Description,
+		OwnerID:     principal.UserID,
+		Visibility:  body.Visibility,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, formatPlaylist(playlist, services.PlaylistStats{}))
+}
+
+// GetPlaylist godoc
+// @Summary Get a playlist by ID
+// @Description Retrieve a playlist with its ordered entries and total runtime, private playlists are only found by their owner.
+// @Description Appending .m3u8, .xspf or .json to the ID exports the playlist in that format instead.
+// @Tags playlists
+// @Produce json
+// @Produce audio/x-mpegurl
+// @Produce application/xspf+xml
+// @Param id path string true "Playlist ID, optionally followed by .m3u8, .xspf or .json"
+// @Success 200 {object} PlaylistResponse
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id} [get]
+func (h *PlaylistHandler) GetPlaylist(c *gin.Context) {
+	idStr, format := playlistfile.SplitFormat(c.Param("id"))
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(c, invalidID("id", "playlist"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if format != "" {
+		h.exportPlaylist(c, principal, id, format)
+		return
+	}
+
+	playlist, err := h.playlistService.GetPlaylist(c, principal, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, err := h.playlistService.GetPlaylistEntries(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := formatPlaylistWithEntries(playlist, entries)
+	respond(c, http.StatusOK, Envelope{Data: response}, response)
+}
+
+// exportPlaylist writes the playlist as an M3U8, XSPF or JSON file download
+func (h *PlaylistHandler) exportPlaylist(c *gin.Context, principal services.Principal, id uuid.UUID, format string) {
+	export, err := h.playlistService.ExportPlaylist(c, principal, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	var buf bytes.Buffer
+	if err = playlistfile.Encode(&buf, format, export); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.%s\"", id, format))
+	c.Data(http.StatusOK, playlistfile.ContentType(format), buf.Bytes())
+}
+
+// ImportPlaylist godoc
+// @Summary Import a playlist
+// @Description Create a playlist from an M3U8, XSPF or JSON file sent as the request body.
+// @Description The caller owns the imported playlist, the creator named in the file is ignored.
+// @Description Tracks are matched to existing songs by group name and title, the report lists the lines that could not be matched.
+// @Tags playlists
+// @Accept plain
+// @Produce json
+// @Param format query string true "File format" Enums(m3u8, xspf, json)
+// @Param name query string false "Playlist name, defaults to the name in the file"
+// @Param visibility query string false "Playlist visibility" Enums(public, unlisted, private)
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=PlaylistResponse,report=services.ImportReport} "Imported playlist and match report"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid file or parameters"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/import [post]
+func (h *PlaylistHandler) ImportPlaylist(c *gin.Context) {
+	format := c.Query("format")
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxPlaylistImportSize)
+
+	file, err := playlistfile.Decode(c.Request.Body, format)
+	if err != nil {
+		respondError(c, errInvalidPlaylistFile.WithMessage("Failed to read playlist file: "+err.Error()).Wrap(err))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	params := repository.PlaylistCreateParams{
+		Name:        c.DefaultQuery("name", file.Name),
+		Description: file.Description,
+		OwnerID:     principal.UserID,
+		Visibility:  c.DefaultQuery("visibility", services.PlaylistVisibilityPrivate),
+	}
+
+	switch {
+	case params.Name == "":
+		respondError(c, invalidParam("name", "is required"))
+		return
+	case params.Visibility != services.PlaylistVisibilityPublic &&
+		params.Visibility != services.PlaylistVisibilityUnlisted &&
+		params.Visibility != services.PlaylistVisibilityPrivate:
+		respondError(c, invalidParam("visibility", "must be one of public, unlisted, private"))
+		return
+	}
+
+	playlist, report, err := h.playlistService.ImportPlaylist(c, params, file)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, err := h.playlistService.GetPlaylistEntries(c, playlist.ID.Bytes)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithMeta(c, http.StatusCreated, formatPlaylistWithEntries(playlist, entries), gin.H{"report": report})
+}
+
+// GetAllPlaylists godoc
+// @Summary Get all playlists
+// @Description Get a paginated list of public playlists and the caller's own, admins see every playlist.
+// @Description Unlisted playlists of others are left out, they are only found by their ID.
+// @Tags playlists
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param owner query string false "Filter by owner ID" format(uuid)
+// @Param visibility query string false "Filter by visibility" Enums(public, unlisted, private)
+// @Success 200 {object} object{data=[]PlaylistResponse,page=int,limit=int,pages=int,total=int}
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid owner ID"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists [get]
+func (h *PlaylistHandler) GetAllPlaylists(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	params, ok := parsePlaylistFilter(c)
+	if !ok {
+		return
+	}
+	params.Limit = int32(limit)
+	params.Offset = int32((page - 1) * limit)
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlists, err := h.playlistService.GetPlaylistsWithPagination(c, principal, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.playlistService.GetPlaylistsCount(c, principal, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	ids := make([]uuid.UUID, 0, len(playlists))
+	for _, playlist := range playlists {
+		ids = append(ids, playlist.ID.Bytes)
+	}
+
+	stats, err := h.playlistService.GetPlaylistsStats(c, ids)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]PlaylistResponse, 0, len(playlists))
+	for _, playlist := range playlists {
+		data = append(data, formatPlaylist(playlist, stats[playlist.ID.Bytes]))
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+// UpdatePlaylist godoc
+// @Summary Update a playlist
+// @Description Update a playlist's name, description and visibility
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path string true "Playlist ID" format(uuid)
+// @Param playlist body object{name=string,description=string,visibility=string} true "Playlist Information"
+// @Success 200 {object} object{data=PlaylistResponse} "Updated playlist"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id} [put]
+func (h *PlaylistHandler) UpdatePlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "playlist"))
+		return
+	}
+
+	var body struct {
+		Name        string `json:"name" binding:"required"`
+		Description string `json:"description"`
+		Visibility  string `json:"visibility" binding:"required,oneof=public unlisted private"`
+	}
+
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.playlistService.UpdatePlaylist(c, principal, repository.PlaylistUpdateParams{
+		ID:          id,
+		Name:        body.Name,
+		Description: body.Description,
+		Visibility:  body.Visibility,
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	stats, err := h.playlistService.GetPlaylistsStats(c, []uuid.UUID{id})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, formatPlaylist(playlist, stats[id]))
+}
+
+// DeletePlaylist godoc
+// @Summary Delete a playlist
+// @Description Delete a playlist by ID
+// @Tags playlists
+// @Param id path string true "Playlist ID" format(uuid)
+// @Success 204 "Playlist deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id} [delete]
+func (h *PlaylistHandler) DeletePlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "playlist"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err = h.playlistService.DeletePlaylist(c, principal, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// AddPlaylistEntry godoc
+// @Summary Add a song to a playlist
+// @Description Insert a song at a 1-based position, or append it when no position is given
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path string true "Playlist ID" format(uuid)
+// @Param entry body object{song_id=string,position=integer} true "Entry Information"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=PlaylistResponse} "Playlist with the new entry"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input or position"
+// @Failure 403 {object} middleware.Problem "Playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Playlist or song not found"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id}/entries [post]
+func (h *PlaylistHandler) AddPlaylistEntry(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "playlist"))
+		return
+	}
+
+	var body struct {
+		SongID   string `json:"song_id" binding:"required"`
+		Position *int32 `json:"position"`
+	}
+
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	songID, err := uuid.Parse(body.SongID)
+	if err != nil {
+		respondError(c, invalidID("song_id", "song"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if _, err = h.playlistService.AddEntry(c, principal, id, songID, body.Position); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.respondWithPlaylist(c, id, http.StatusCreated)
+}
+
+// RemovePlaylistEntry godoc
+// @Summary Remove an entry from a playlist
+// @Description Remove an entry, the following entries move up by one position
+// @Tags playlists
+// @Produce json
+// @Param id path string true "Playlist ID" format(uuid)
+// @Param entry_id path string true "Entry ID" format(uuid)
+// @Success 200 {object} object{data=PlaylistResponse} "Playlist without the entry"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Playlist or entry not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id}/entries/{entry_id} [delete]
+func (h *PlaylistHandler) RemovePlaylistEntry(c *gin.Context) {
+	id, entryID, ok := parsePlaylistEntryIDs(c)
+	if !ok {
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err := h.playlistService.RemoveEntry(c, principal, id, entryID); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.respondWithPlaylist(c, id, http.StatusOK)
+}
+
+// MovePlaylistEntry godoc
+// @Summary Move an entry within a playlist
+// @Description Move an entry to a new 1-based position, the entries in between are shifted
+// @Tags playlists
+// @Accept json
+// @Produce json
+// @Param id path string true "Playlist ID" format(uuid)
+// @Param entry_id path string true "Entry ID" format(uuid)
+// @Param move body object{position=integer} true "Target position"
+// @Success 200 {object} object{data=PlaylistResponse} "Reordered playlist"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input or position"
+// @Failure 403 {object} middleware.Problem "Playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Playlist or entry not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /playlists/{id}/entries/{entry_id}/move [post]
+func (h *PlaylistHandler) MovePlaylistEntry(c *gin.Context) {
+	id, entryID, ok := parsePlaylistEntryIDs(c)
+	if !ok {
+		return
+	}
+
+	var body struct {
+		Position int32 `json:"position" binding:"required"`
+	}
+
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if _, err := h.playlistService.MoveEntry(c, principal, id, entryID, body.Position); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	h.respondWithPlaylist(c, id, http.StatusOK)
+}
+
+// respondWithPlaylist writes the current state of the playlist including its entries
+func (h *PlaylistHandler) respondWithPlaylist(c *gin.Context, id uuid.UUID, status int) {
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.playlistService.GetPlaylist(c, principal, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	entries, err := h.playlistService.GetPlaylistEntries(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, status, formatPlaylistWithEntries(playlist, entries))
+}
+
+func parsePlaylistEntryIDs(c *gin.Context) (uuid.UUID, uuid.UUID, bool) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "playlist"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	entryID, err := uuid.Parse(c.Param("entry_id"))
+	if err != nil {
+		respondError(c, invalidID("entry_id", "entry"))
+		return uuid.Nil, uuid.Nil, false
+	}
+
+	return id, entryID, true
+}
+
+// parsePlaylistFilter reads the owner and visibility filters of a playlist listing
+func parsePlaylistFilter(c *gin.Context) (repository.PlaylistFilterParams, bool) {
+	params := repository.PlaylistFilterParams{Visibility: c.Query("visibility")}
+
+	if owner := c.Query("owner"); owner != "" {
+		ownerID, err := uuid.Parse(owner)
+		if err != nil {
+			respondError(c, invalidID("owner", "user"))
+			return repository.PlaylistFilterParams{}, false
+		}
+		params.OwnerID = ownerID
+	}
+
+	return params, true
+}
+
+func formatPlaylist(playlist database.Playlist, stats services.PlaylistStats) PlaylistResponse {
+	return PlaylistResponse{
+		ID:               playlist.ID.String(),
+		Name:             playlist.Name,
+		Description:      playlist.Description,
+		OwnerID:          playlist.OwnerID.String(),
+		Visibility:       playlist.Visibility,
+		EntryCount:       stats.EntryCount,
+		UnavailableCount: stats.UnavailableCount,
+		TotalRuntime:     stats.TotalRuntime,
+		CreatedAt:        playlist.CreatedAt.Time,
+		UpdatedAt:        playlist.UpdatedAt.Time,
+	}
+}
+
+// formatPlaylistWithEntries builds the playlist response and computes its stats from the entries
+func formatPlaylistWithEntries(playlist database.Playlist, entries []database.GetPlaylistEntriesRow) PlaylistResponse {
+	var stats services.PlaylistStats
+	formattedEntries := make([]PlaylistEntryResponse, 0, len(entries))
+
+	for _, entry := range entries {
+		available := !entry.SongDeletedAt.Valid
+
+		stats.EntryCount++
+		if available {
+			stats.TotalRuntime += int64(entry.Runtime)
+		} else {
+			stats.UnavailableCount++
+		}
+
+		formattedEntries = append(formattedEntries, PlaylistEntryResponse{
+			ID:        entry.ID.String(),
+			Position:  entry.Position,
+			SongID:    entry.SongID.String(),
+			Title:     entry.Title,
+			GroupID:   entry.GroupID.String(),
+			GroupName: entry.GroupName,
+			Runtime:   entry.Runtime,
+			Available: available,
+			AddedAt:   entry.AddedAt.Time,
+		})
+	}
+
+	response := formatPlaylist(playlist, stats)
+	response.Entries = formattedEntries
+	return response
+}
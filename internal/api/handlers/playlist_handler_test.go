@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type fakePlaylistRepo struct {
+	repository.PlaylistRepositoryInterface
+	playlists map[uuid.UUID]database.Playlist
+}
+
+func (r *fakePlaylistRepo) GetPlaylist(_ context.Context, id uuid.UUID) (database.Playlist, error) {
+	playlist, ok := r.playlists[id]
+	if !ok {
+		return database.Playlist{}, pgx.ErrNoRows
+	}
+	return playlist, nil
+}
+
+func (r *fakePlaylistRepo) UpdatePlaylist(_ context.Context, params repository.PlaylistUpdateParams) (database.Playlist, error) {
+	playlist := r.playlists[params.ID]
+	playlist.Name = params.Name
+	playlist.Visibility = params.Visibility
+	r.playlists[params.ID] = playlist
+	return playlist, nil
+}
+
+func (r *fakePlaylistRepo) DeletePlaylist(_ context.Context, id uuid.UUID) (bool, error) {
+	_, ok := r.playlists[id]
+	delete(r.playlists, id)
+	return ok, nil
+}
+
+func (r *fakePlaylistRepo) GetPlaylistsStats(context.Context, []uuid.UUID) ([]database.GetPlaylistsStatsRow, error) {
+	return nil, nil
+}
+
+type fakeSmartPlaylistRepo struct {
+	repository.SmartPlaylistRepositoryInterface
+	playlists map[uuid.UUID]database.SmartPlaylist
+}
+
+func (r *fakeSmartPlaylistRepo) GetSmartPlaylist(_ context.Context, id uuid.UUID) (database.SmartPlaylist, error) {
+	playlist, ok := r.playlists[id]
+	if !ok {
+		return database.SmartPlaylist{}, pgx.ErrNoRows
+	}
+	return playlist, nil
+}
+
+func (r *fakeSmartPlaylistRepo) DeleteSmartPlaylist(_ context.Context, id uuid.UUID) (bool, error) {
+	_, ok := r.playlists[id]
+	delete(r.playlists, id)
+	return ok, nil
+}
+
+// servePlaylistRequest serves a request as principal through a router with the problem middleware
+func servePlaylistRequest(principal *services.Principal, method, route, target, body string, handle gin.HandlerFunc) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware.Problems(slog.New(slog.DiscardHandler)), func(c *gin.Context) {
+		if principal != nil {
+			c.Set("principal", *principal)
+		}
+	})
+	router.Handle(method, route, handle)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestPlaylistWritesRequireOwner(t *testing.T) {
+	owner := services.Principal{UserID: uuid.New(), Username: "owner", Role: services.RoleViewer}
+	other := services.Principal{UserID: uuid.New(), Username: "other", Role: services.RoleEditor}
+	admin := services.Principal{UserID: uuid.New(), Username: "admin", Role: services.RoleAdmin}
+
+	tests := []struct {
+		name       string
+		principal  services.Principal
+		visibility string
+		wantStatus int
+		wantCode   string
+	}{
+		{"owner", owner, services.PlaylistVisibilityPrivate, http.StatusOK, ""},
+		{"admin", admin, services.PlaylistVisibilityPrivate, http.StatusOK, ""},
+		{"other user on public playlist", other, services.PlaylistVisibilityPublic, http.StatusForbidden, "not_owner"},
+		{"other user on unlisted playlist", other, services.PlaylistVisibilityUnlisted, http.StatusForbidden, "not_owner"},
+		{"other user on private playlist", other, services.PlaylistVisibilityPrivate, http.StatusNotFound, "playlist_not_found"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := uuid.New()
+			repo := &fakePlaylistRepo{playlists: map[uuid.UUID]database.Playlist{
+				id: {
+					ID:         pgtype.UUID{Bytes: id, Valid: true},
+					Name:       "Mine",
+					OwnerID:    pgtype.UUID{Bytes: owner.UserID, Valid: true},
+					Visibility: tt.visibility,
+				},
+			}}
+			handler := NewPlaylistHandler(services.NewPlaylistService(&repository.Manager{Playlists: repo}))
+
+			w := servePlaylistRequest(&tt.principal, http.MethodPut, "/playlists/:id", "/playlists/"+id.String(),
+				`{"name":"Renamed","visibility":"`+tt.visibility+`"}`, handler.UpdatePlaylist)
+			assertProblem(t, w, tt.wantStatus, tt.wantCode)
+
+			renamed := repo.playlists[id].Name == "Renamed"
+			if renamed != (tt.wantStatus == http.StatusOK) {
+				t.Errorf("playlist renamed = %v, want %v", renamed, !renamed)
+			}
+
+			w = servePlaylistRequest(&tt.principal, http.MethodDelete, "/playlists/:id", "/playlists/"+id.String(), "", handler.DeletePlaylist)
+			wantStatus := tt.wantStatus
+			if wantStatus == http.StatusOK {
+				wantStatus = http.StatusNoContent
+			}
+			assertProblem(t, w, wantStatus, tt.wantCode)
+
+			if _, kept := repo.playlists[id]; kept != (wantStatus != http.StatusNoContent) {
+				t.Errorf("playlist kept = %v, want %v", kept, !kept)
+			}
+		})
+	}
+}
+
+func TestSmartPlaylistWritesRequireOwner(t *testing.T) {
+	owner := services.Principal{UserID: uuid.New(), Role: services.RoleViewer}
+	other := services.Principal{UserID: uuid.New(), Role: services.RoleViewer}
+
+	id := uuid.New()
+	repo := &fakeSmartPlaylistRepo{playlists: map[uuid.UUID]database.SmartPlaylist{
+		id: {
+			ID:         pgtype.UUID{Bytes: id, Valid: true},
+			OwnerID:    pgtype.UUID{Bytes: owner.UserID, Valid: true},
+			Visibility: services.PlaylistVisibilityPublic,
+		},
+	}}
+	handler := NewSmartPlaylistHandler(services.NewSmartPlaylistService(repo, nil))
+
+	w := servePlaylistRequest(&other, http.MethodDelete, "/smart-playlists/:id", "/smart-playlists/"+id.String(), "", handler.DeleteSmartPlaylist)
+	assertProblem(t, w, http.StatusForbidden, "not_owner")
+
+	w = servePlaylistRequest(nil, http.MethodDelete, "/smart-playlists/:id", "/smart-playlists/"+id.String(), "", handler.DeleteSmartPlaylist)
+	assertProblem(t, w, http.StatusForbidden, "not_owner")
+
+	w = servePlaylistRequest(&owner, http.MethodDelete, "/smart-playlists/:id", "/smart-playlists/"+id.String(), "", handler.DeleteSmartPlaylist)
+	assertProblem(t, w, http.StatusNoContent, "")
+}
+
+// assertProblem checks the status of a response and, for errors, the code of its problem details
+func assertProblem(t *testing.T, w *httptest.ResponseRecorder, status int, code string) {
+	t.Helper()
+
+	if w.Code != status {
+		t.Fatalf("status = %d, want %d: %s", w.Code, status, w.Body)
+	}
+	if code == "" {
+		return
+	}
+
+	var problem struct {
+		Code string `json:"code"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+		t.Fatalf("decoding problem: %v", err)
+	}
+	if problem.Code != code {
+		t.Errorf("code = %q, want %q", problem.Code, code)
+	}
+}
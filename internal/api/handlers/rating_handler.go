@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"net/http"
+	"time"
+)
+
+type RatingHandler struct {
+	ratingService *services.RatingService
+}
+
+// NewRatingHandler creates a new rating handler
+func NewRatingHandler(ratingService *services.RatingService) *RatingHandler {
+	return &RatingHandler{
+		ratingService: ratingService,
+	}
+}
+
+// RatingStatsResponse is the aggregate rating of a song
+type RatingStatsResponse struct {
+	SongID  string  `json:"song_id"`
+	Average float64 `json:"average"`
+	Count   int32   `json:"count"`
+}
+
+// UserRatingResponse is a rating given by the current user
+type UserRatingResponse struct {
+	SongID    string    `json:"song_id"`
+	Title     string    `json:"title"`
+	GroupName string    `json:"group_name"`
+	Rating    int32     `json:"rating"`
+	RatedAt   time.Time `json:"rated_at"`
+}
+
+// RateSong godoc
+// @Summary Rate a song
+// @Description Give a song 1 to 5 stars, rating again replaces the previous rating
+// @Tags ratings
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Param rating body object{rating=integer} true "Rating from 1 to 5"
+// @Success 200 {object} object{data=RatingStatsResponse} "Updated song rating"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid rating"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/rating [put]
+func (h *RatingHandler) RateSong(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	var body struct {
+		Rating int32 `json:"rating" binding:"required"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	stats, err := h.ratingService.RateSong(c, principal.UserID, id, body.Rating)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, formatRatingStats(stats))
+}
+
+// RemoveRating godoc
+// @Summary Remove a song rating
+// @Description Remove the current user's rating of a song
+// @Tags ratings
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Success 200 {object} object{data=RatingStatsResponse} "Updated song rating"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 404 {object} middleware.Problem "Song or rating not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/rating [delete]
+func (h *RatingHandler) RemoveRating(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	stats, err := h.ratingService.RemoveRating(c, principal.UserID, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, formatRatingStats(stats))
+}
+
+// GetMyRatings godoc
+// @Summary List my ratings
+// @Description Retrieve the current user's song ratings, most recently rated first. Ratings of deleted songs are left out.
+// @Tags ratings
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} object{data=[]UserRatingResponse,page=int,limit=int,pages=int,total=int} "Paginated list of ratings"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /me/ratings [get]
+func (h *RatingHandler) GetMyRatings(c *gin.Context) {
+	page, limit, offset := parsePagination(c)
+	principal, _ := middleware.GetPrincipal(c)
+
+	ratings, err := h.ratingService.GetUserRatingsWithPagination(c, principal.UserID, int32(limit), int32(offset))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.ratingService.GetUserRatingsCount(c, principal.UserID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]UserRatingResponse, 0, len(ratings))
+	for _, rating := range ratings {
+		data = append(data, UserRatingResponse{
+			SongID:    rating.SongID.String(),
+			Title:     rating.Title,
+			GroupName: rating.GroupName,
+			Rating:    rating.Rating,
+			RatedAt:   rating.UpdatedAt.Time,
+		})
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+func formatRatingStats(stats database.SongRatingStat) RatingStatsResponse {
+	return RatingStatsResponse{
+		SongID:  stats.SongID.String(),
+		Average: stats.RatingAverage,
+		Count:   stats.RatingCount,
+	}
+}
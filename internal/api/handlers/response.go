@@ -0,0 +1,100 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+)
+
+// API versions. Routes under /api/v2 answer in an Envelope, routes under /api/v1 keep the bodies
+// they have always returned so existing clients are not broken.
+const (
+	APIVersion1 = 1
+	APIVersion2 = 2
+)
+
+// apiVersionKey is the gin context key the API version of the route is stored under
+const apiVersionKey = "api_version"
+
+// Envelope is the body of every successful v2 response. Data holds the requested resource or list,
+// Meta holds pagination and whatever else an operation reports besides its result.
+type Envelope struct {
+	Data any   `json:"data"`
+	Meta gin.H `json:"meta,omitempty" swaggertype:"object"`
+}
+
+// UseAPIVersion marks the routes of a group as belonging to an API version
+func UseAPIVersion(version int) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(apiVersionKey, version)
+		c.Next()
+	}
+}
+
+// apiVersion returns the API version of the route, routes outside a versioned group are v1
+func apiVersion(c *gin.Context) int {
+	if version, ok := c.Get(apiVersionKey); ok {
+		return version.(int)
+	}
+	return APIVersion1
+}
+
+// respond writes envelope on v2 routes and legacy, the body the route has always returned, on v1 routes
+func respond(c *gin.Context, status int, envelope Envelope, legacy any) {
+	if apiVersion(c) == APIVersion1 {
+		c.JSON(status, legacy)
+		return
+	}
+	c.JSON(status, envelope)
+}
+
+// respondData writes data for routes whose v1 body already was {"data": ...}
+func respondData(c *gin.Context, status int, data any) {
+	respondWithMeta(c, status, data, nil)
+}
+
+// respondWithMeta writes data with further information. v1 routes have always returned it next to data.
+func respondWithMeta(c *gin.Context, status int, data any, meta gin.H) {
+	respond(c, status, Envelope{Data: data, Meta: meta}, flatten(data, meta))
+}
+
+// flatten is the v1 body of data with further information, which v1 routes return next to data
+func flatten(data any, meta gin.H) gin.H {
+	body := gin.H{"data": data}
+	for key, value := range meta {
+		body[key] = value
+	}
+	return body
+}
+
+// respondPage writes a page of a list with its pagination
+func respondPage(c *gin.Context, data any, page, limit int, total int64) {
+	respondWithMeta(c, http.StatusOK, data, paginationMeta(page, limit, total))
+}
+
+// respondCursorPage writes a page of a list read with a cursor and the cursors of the pages around it
+func respondCursorPage(c *gin.Context, data any, limit int, page repository.CursorPage) {
+	respondWithMeta(c, http.StatusOK, data, cursorMeta(limit, page))
+}
+
+// cursorMeta describes a page read with a cursor, a cursor is null at the end of the list in its direction
+func cursorMeta(limit int, page repository.CursorPage) gin.H {
+	meta := gin.H{"limit": limit, "next_cursor": nil, "prev_cursor": nil}
+	if page.Next != "" {
+		meta["next_cursor"] = page.Next
+	}
+	if page.Prev != "" {
+		meta["prev_cursor"] = page.Prev
+	}
+	return meta
+}
+
+// paginationMeta describes a page of a list of total items
+func paginationMeta(page, limit int, total int64) gin.H {
+	return gin.H{
+		"page":  page,
+		"limit": limit,
+		"pages": (int(total) + limit - 1) / limit,
+		"total": total,
+	}
+}
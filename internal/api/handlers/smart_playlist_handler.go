@@ -0,0 +1,364 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type SmartPlaylistHandler struct {
+	smartPlaylistService *services.SmartPlaylistService
+}
+
+// NewSmartPlaylistHandler creates a new smart playlist handler
+func NewSmartPlaylistHandler(smartPlaylistService *services.SmartPlaylistService) *SmartPlaylistHandler {
+	return &SmartPlaylistHandler{
+		smartPlaylistService: smartPlaylistService,
+	}
+}
+
+// SmartPlaylistSongResponse is a song currently selected by the rules of a smart playlist
+type SmartPlaylistSongResponse struct {
+	Position    int       `json:"position"`
+	SongID      string    `json:"song_id"`
+	Title       string    `json:"title"`
+	GroupID     string    `json:"group_id"`
+	GroupName   string    `json:"group_name"`
+	Runtime     int32     `json:"runtime"`
+	ReleaseDate time.Time `json:"release_date"`
+	Link        string    `json:"link"`
+}
+
+// SmartPlaylistResponse is the formatted smart playlist response for the API
+type SmartPlaylistResponse struct {
+	ID           string                      `json:"id"`
+	Name         string                      `json:"name"`
+	Description  string                      `json:"description"`
+	OwnerID      string                      `json:"owner_id"`
+	Visibility   string                      `json:"visibility"`
+	Rules        repository.SongRules        `json:"rules"`
+	SongCount    *int                        `json:"song_count,omitempty"`
+	TotalRuntime *int64                      `json:"total_runtime,omitempty"`
+	Songs        []SmartPlaylistSongResponse `json:"songs,omitempty"`
+	CreatedAt    time.Time                   `json:"created_at"`
+	UpdatedAt    time.Time                   `json:"updated_at"`
+}
+
+type smartPlaylistBody struct {
+	Name        string               `json:"name" binding:"required"`
+	Description string               `json:"description"`
+	Visibility  string               `json:"visibility" binding:"omitempty,oneof=public unlisted private"`
+	Rules       repository.SongRules `json:"rules"`
+}
+
+// CreateSmartPlaylist godoc
+// @Summary Create a new smart playlist
+// @Description Create a playlist owned by the caller whose songs are selected by rules (group_name, title, release_date_from/to, runtime_min/max, tags, tags_match, sort, limit) on every read
+// @Tags smart-playlists
+// @Accept json
+// @Produce json
+// @Param playlist body object{name=string,description=string,visibility=string,rules=repository.SongRules} true "Smart Playlist Information"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
+// @Success 201 {object} object{data=SmartPlaylistResponse} "Created smart playlist with its current songs"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input or rules"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists [post]
+func (h *SmartPlaylistHandler) CreateSmartPlaylist(c *gin.Context) {
+	var body smartPlaylistBody
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	if body.Visibility == "" {
+		body.Visibility = services.PlaylistVisibilityPrivate
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.smartPlaylistService.CreateSmartPlaylist(c, repository.SmartPlaylistCreateParams{
+		Name:        body.Name,
+		Description: body.Description,
+		OwnerID:     principal.UserID,
+		Visibility:  body.Visibility,
+	}, body.Rules)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatEvaluatedSmartPlaylist(c, playlist)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusCreated, response)
+}
+
+// GetSmartPlaylist godoc
+// @Summary Get a smart playlist by ID
+// @Description Retrieve a smart playlist and evaluate its rules into the current list of songs, private ones are only found by their owner
+// @Tags smart-playlists
+// @Produce json
+// @Param id path string true "Smart Playlist ID" format(uuid)
+// @Success 200 {object} SmartPlaylistResponse
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Smart playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists/{id} [get]
+func (h *SmartPlaylistHandler) GetSmartPlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "smart playlist"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.smartPlaylistService.GetSmartPlaylist(c, principal, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatEvaluatedSmartPlaylist(c, playlist)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respond(c, http.StatusOK, Envelope{Data: response}, response)
+}
+
+// GetAllSmartPlaylists godoc
+// @Summary Get all smart playlists
+// @Description Get a paginated list of public smart playlists and the caller's own with their rules, songs are not evaluated.
+// @Description Admins see every smart playlist, unlisted ones of others are left out.
+// @Tags smart-playlists
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param owner query string false "Filter by owner ID" format(uuid)
+// @Param visibility query string false "Filter by visibility" Enums(public, unlisted, private)
+// @Success 200 {object} object{data=[]SmartPlaylistResponse,page=int,limit=int,pages=int,total=int}
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid owner ID"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists [get]
+func (h *SmartPlaylistHandler) GetAllSmartPlaylists(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	params, ok := parsePlaylistFilter(c)
+	if !ok {
+		return
+	}
+	params.Limit = int32(limit)
+	params.Offset = int32((page - 1) * limit)
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlists, err := h.smartPlaylistService.GetSmartPlaylistsWithPagination(c, principal, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.smartPlaylistService.GetSmartPlaylistsCount(c, principal, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]SmartPlaylistResponse, 0, len(playlists))
+	for _, playlist := range playlists {
+		response, err := h.formatSmartPlaylist(playlist)
+		if err != nil {
+			respondError(c, err)
+			return
+		}
+		data = append(data, response)
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+// UpdateSmartPlaylist godoc
+// @Summary Update a smart playlist
+// @Description Update a smart playlist's name, description, visibility and rules
+// @Tags smart-playlists
+// @Accept json
+// @Produce json
+// @Param id path string true "Smart Playlist ID" format(uuid)
+// @Param playlist body object{name=string,description=string,visibility=string,rules=repository.SongRules} true "Smart Playlist Information"
+// @Success 200 {object} object{data=SmartPlaylistResponse} "Updated smart playlist with its current songs"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input or rules"
+// @Failure 403 {object} middleware.Problem "Smart playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Smart playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists/{id} [put]
+func (h *SmartPlaylistHandler) UpdateSmartPlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "smart playlist"))
+		return
+	}
+
+	var body smartPlaylistBody
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	if body.Visibility == "" {
+		body.Visibility = services.PlaylistVisibilityPrivate
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	playlist, err := h.smartPlaylistService.UpdateSmartPlaylist(c, principal, repository.SmartPlaylistUpdateParams{
+		ID:          id,
+		Name:        body.Name,
+		Description: body.Description,
+		Visibility:  body.Visibility,
+	}, body.Rules)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatEvaluatedSmartPlaylist(c, playlist)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, response)
+}
+
+// DeleteSmartPlaylist godoc
+// @Summary Delete a smart playlist
+// @Description Delete a smart playlist by ID
+// @Tags smart-playlists
+// @Param id path string true "Smart Playlist ID" format(uuid)
+// @Success 204 "Smart playlist deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Smart playlist is owned by another user"
+// @Failure 404 {object} middleware.Problem "Smart playlist not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists/{id} [delete]
+func (h *SmartPlaylistHandler) DeleteSmartPlaylist(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "smart playlist"))
+		return
+	}
+
+	principal, _ := middleware.GetPrincipal(c)
+	if err = h.smartPlaylistService.DeleteSmartPlaylist(c, principal, id); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// PreviewSmartPlaylist godoc
+// @Summary Preview smart playlist rules
+// @Description Evaluate rules without saving them and return the songs they select
+// @Tags smart-playlists
+// @Accept json
+// @Produce json
+// @Param rules body repository.SongRules true "Rules"
+// @Success 200 {object} object{data=[]SmartPlaylistSongResponse,song_count=int,total_runtime=int}
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid rules"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /smart-playlists/preview [post]
+func (h *SmartPlaylistHandler) PreviewSmartPlaylist(c *gin.Context) {
+	var rules repository.SongRules
+	if !bindJSON(c, &rules) {
+		return
+	}
+
+	rows, err := h.smartPlaylistService.EvaluateRules(c, rules)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	songs, totalRuntime := formatRuledSongs(rows)
+
+	respondWithMeta(c, http.StatusOK, songs, gin.H{
+		"song_count":    len(songs),
+		"total_runtime": totalRuntime,
+	})
+}
+
+func (h *SmartPlaylistHandler) formatSmartPlaylist(playlist database.SmartPlaylist) (SmartPlaylistResponse, error) {
+	rules, err := h.smartPlaylistService.DecodeRules(playlist)
+	if err != nil {
+		return SmartPlaylistResponse{}, err
+	}
+
+	return SmartPlaylistResponse{
+		ID:          playlist.ID.String(),
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		OwnerID:     playlist.OwnerID.String(),
+		Visibility:  playlist.Visibility,
+		Rules:       rules,
+		CreatedAt:   playlist.CreatedAt.Time,
+		UpdatedAt:   playlist.UpdatedAt.Time,
+	}, nil
+}
+
+// formatEvaluatedSmartPlaylist formats the playlist together with the songs its rules select right now
+func (h *SmartPlaylistHandler) formatEvaluatedSmartPlaylist(c *gin.Context, playlist database.SmartPlaylist) (SmartPlaylistResponse, error) {
+	response, err := h.formatSmartPlaylist(playlist)
+	if err != nil {
+		return SmartPlaylistResponse{}, err
+	}
+
+	rows, err := h.smartPlaylistService.EvaluateRules(c, response.Rules)
+	if err != nil {
+		return SmartPlaylistResponse{}, err
+	}
+
+	songs, totalRuntime := formatRuledSongs(rows)
+	songCount := len(songs)
+
+	response.Songs = songs
+	response.SongCount = &songCount
+	response.TotalRuntime = &totalRuntime
+	return response, nil
+}
+
+func formatRuledSongs(rows []repository.RuledSongRow) ([]SmartPlaylistSongResponse, int64) {
+	var totalRuntime int64
+	songs := make([]SmartPlaylistSongResponse, 0, len(rows))
+
+	for i, row := range rows {
+		totalRuntime += int64(row.Runtime)
+		songs = append(songs, SmartPlaylistSongResponse{
+			Position:    i + 1,
+			SongID:      row.ID.String(),
+			Title:       row.Title,
+			GroupID:     row.GroupID.String(),
+			GroupName:   row.GroupName,
+			Runtime:     row.Runtime,
+			ReleaseDate: row.ReleaseDate.Time,
+			Link:        row.Link,
+		})
+	}
+
+	return songs, totalRuntime
+}
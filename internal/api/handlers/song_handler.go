@@ -4,48 +4,83 @@ import (
 	"encoding/json"
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"math"
 	"music-service/internal/api/services"
 	"music-service/internal/pkg/utils/constants"
 	"music-service/internal/pkg/utils/parser"
 	"music-service/internal/storage/database"
 	"music-service/internal/storage/database/repository"
 	"net/http"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 )
 
 type SongHandler struct {
-	songService  *services.SongService
-	groupService *services.GroupService
+	songService       *services.SongService
+	artworkService    *services.ArtworkService
+	similarityService *services.SimilarityService
+	duplicateService  *services.DuplicateService
 }
 
-func NewSongHandler(songService *services.SongService, groupService *services.GroupService) *SongHandler {
+func NewSongHandler(songService *services.SongService, artworkService *services.ArtworkService, similarityService *services.SimilarityService, duplicateService *services.DuplicateService) *SongHandler {
 	return &SongHandler{
-		songService:  songService,
-		groupService: groupService,
+		songService:       songService,
+		artworkService:    artworkService,
+		similarityService: similarityService,
+		duplicateService:  duplicateService,
 	}
 }
 
-// GroupData represents group information to be included in song responses
-type GroupData struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+// SongResponse is the formatted song response for the API. Lyrics are empty when they were not loaded
+// and Group is empty when it is not embedded, see parseSongFields.
+type SongResponse struct {
+	ID            string        `json:"id"`
+	GroupID       string        `json:"group_id"`
+	Group         GroupResponse `json:"group"`
+	Title         string        `json:"title"`
+	Runtime       int32         `json:"runtime"`
+	Lyrics        string        `json:"lyrics"`
+	ReleaseDate   time.Time     `json:"release_date"`
+	Link          string        `json:"link"`
+	Artwork       *ArtworkData  `json:"artwork,omitempty"`
+	PlayCount     int64         `json:"play_count"`
+	RatingAverage float64       `json:"rating_average"`
+	RatingCount   int32         `json:"rating_count"`
+	CreatedAt     time.Time     `json:"created_at"`
+	UpdatedAt     time.Time     `json:"updated_at"`
 }
 
-// SongResponse is the formatted song response for the API
-type SongResponse struct {
-	ID          string    `json:"id"`
-	Group       GroupData `json:"group"` // Changed from GroupID to Group
-	Title       string    `json:"title"`
-	Runtime     int32     `json:"runtime"`
-	Lyrics      string    `json:"lyrics"`
-	ReleaseDate time.Time `json:"release_date"`
-	Link        string    `json:"link"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+// songFields are the fields of SongResponse that ?fields= can select
+var songFields = jsonFields(SongResponse{})
+
+// songListFields are the fields of a song listing without ?fields=, lyrics are left out of v2 lists
+// because they make up most of the response
+func songListFields(c *gin.Context) []string {
+	if apiVersion(c) == APIVersion1 {
+		return songFields
+	}
+	return slices.DeleteFunc(slices.Clone(songFields), func(field string) bool { return field == "lyrics" })
+}
+
+// parseSongFields reads ?fields= and ?include= of a song response. The group is embedded unless
+// ?include= leaves it out or ?fields= does not select it, group_id is always there to follow it.
+func parseSongFields(c *gin.Context, defaults []string) (fieldSet, bool, error) {
+	fields, err := parseFields(c, SongResponse{}, defaults)
+	if err != nil {
+		return nil, false, err
+	}
+	include, err := parseInclude(c, []string{"group"}, []string{"group"})
+	if err != nil {
+		return nil, false, err
+	}
+
+	includeGroup := include["group"] && fields["group"]
+	if !includeGroup {
+		delete(fields, "group")
+	}
+	return fields, includeGroup, nil
 }
 
 // CreateSong godoc
@@ -55,9 +90,12 @@ type SongResponse struct {
 // @Accept json
 // @Produce json
 // @Param song body object{group_id=string,title=string,runtime=integer,lyrics=string,release_date=string,link=string} true "Song Information"
+// @Param Idempotency-Key header string false "Key that makes retrying the request safe, a retry with the same key and body replays the first response"
 // @Success 201 {object} object{data=object{id=string,group=object{id=string,name=string,created_at=string,updated_at=string},title=string,runtime=integer,lyrics=string,release_date=string,link=string,created_at=string,updated_at=string}} "Created song data"
-// @Failure 400 {object} object{error=string} "Bad request - Invalid input data"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input data"
+// @Failure 409 {object} middleware.Problem "A request with the same Idempotency-Key is still being processed"
+// @Failure 422 {object} middleware.Problem "Group not found, or Idempotency-Key was already used for a different request"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /songs [post]
 func (h *SongHandler) CreateSong(c *gin.Context) {
 	var body struct {
@@ -69,26 +107,25 @@ func (h *SongHandler) CreateSong(c *gin.Context) {
 		Link        string `json:"link" binding:"required"`
 	}
 
-	if err := c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
 	groupID, err := uuid.Parse(body.GroupID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		respondError(c, invalidID("group_id", "group"))
 		return
 	}
 
-	releaseDate, err := time.Parse(constants.DateFormat, body.ReleaseDate)
+	releaseDate, err := parseReleaseDate(body.ReleaseDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid release date format"})
+		respondError(c, errInvalidReleaseDate)
 		return
 	}
 
 	lyricsJSON, err := parser.ParseLyrics(body.Lyrics)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process lyrics"})
+		respondError(c, err)
 		return
 	}
 
@@ -103,17 +140,17 @@ func (h *SongHandler) CreateSong(c *gin.Context) {
 
 	song, err := h.songService.CreateSong(c, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create song: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	response, err := h.formatSong(c, song)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve song: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"data": response})
+	respondData(c, http.StatusCreated, response)
 }
 
 // GetSong godoc
@@ -122,117 +159,215 @@ func (h *SongHandler) CreateSong(c *gin.Context) {
 // @Tags songs
 // @Produce json
 // @Param id path string true "Song ID" format(uuid)
-// @Success 200 {object} object{id=string,group=object{id=string,name=string,created_at=string,updated_at=string},title=string,runtime=integer,lyrics=string,release_date=string,link=string,created_at=string,updated_at=string}
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 404 {object} object{error=string} "Song not found"
+// @Param If-None-Match header string false "ETag of a cached copy"
+// @Param fields query string false "Comma-separated fields to return, all by default, the id is always returned"
+// @Param include query string false "Relations to embed, group by default, empty for none"
+// @Success 200 {object} object{id=string,group_id=string,group=object{id=string,name=string,created_at=string,updated_at=string},title=string,runtime=integer,lyrics=string,release_date=string,link=string,created_at=string,updated_at=string}
+// @Header 200 {string} ETag "Version of the song, send it back in If-Match to change it"
+// @Success 304 "The cached copy is current"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
 // @Router /songs/{id} [get]
 func (h *SongHandler) GetSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID format"})
+		respondError(c, invalidID("id", "song"))
 		return
 	}
 
-	song, err := h.songService.GetSong(c, id)
+	fields, includeGroup, err := parseSongFields(c, songFields)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		respondError(c, err)
 		return
 	}
 
-	response, err := h.formatSong(c, song)
+	song, err := h.songService.GetSongWithGroup(c, id, fields["lyrics"])
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	formatted, err := h.formatBulkSongs(c, []database.GetSongsWithPaginationRow{song}, includeGroup)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	response, err := fields.project(formatted[0])
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve song: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	respondWithETag(c, http.StatusOK, song.UpdatedAt, Envelope{Data: response}, response)
 }
 
 // GetAllSongs godoc
 // @Summary Get all songs with pagination and filtering
-// @Description Get a paginated list of songs with optional filtering by group name and song title
+// @Description Get a paginated list of songs with optional filtering by group, song title, minimum average rating,
+// @Description release date, runtime and creation or update time, sorted by one or more fields.
+// @Description query takes a search in the query language described in the README, it is combined with the other filters.
+// @Description Pass cursor instead of page to page with cursors: an empty cursor starts at the first song and every page
+// @Description returns next_cursor and prev_cursor, which are null at either end. Cursor pages have no total.
+// @Description fields selects the fields of every song, v2 leaves lyrics out unless they are asked for.
 // @Tags songs
 // @Produce json
 // @Param page query int false "Page number" default(1)
+// @Param cursor query string false "Cursor of the page to read, empty for the first page"
 // @Param limit query int false "Items per page" default(10)
-// @Param group query string false "Filter by group name"
+// @Param query query string false "Search query, for example group:queen year:1975..1980 runtime:<300 bohemian"
+// @Param group query string false "Filter by group name or alias"
+// @Param group_id query string false "Only songs of this group" format(uuid)
 // @Param song query string false "Filter by song title"
-// @Success 200 {object} object{data=array,page=int,limit=int,pages=int,total=int}
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Param min_rating query number false "Only songs with an average rating of at least this value (1-5)"
+// @Param release_date_from query string false "Only songs released on or after this date (YYYY-MM-DD)"
+// @Param release_date_to query string false "Only songs released on or before this date (YYYY-MM-DD)"
+// @Param runtime_min query int false "Only songs at least this many seconds long"
+// @Param runtime_max query int false "Only songs at most this many seconds long"
+// @Param created_since query string false "Only songs created at or after this time (RFC 3339)"
+// @Param updated_since query string false "Only songs updated at or after this time (RFC 3339)"
+// @Param sort query string false "Comma-separated fields to sort by, prefixed with - for descending: title, release_date, runtime, created_at, updated_at, group, rating. Newest first by default, rating alone sorts the highest rated first"
+// @Param fields query string false "Comma-separated fields to return, the id is always returned. All but lyrics by default, all of them in v1"
+// @Param include query string false "Relations to embed, group by default, empty for none"
+// @Success 200 {object} object{data=array,page=int,limit=int,pages=int,total=int,next_cursor=string,prev_cursor=string}
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid filter, search query, sort or cursor"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /songs [get]
 func (h *SongHandler) GetAllSongs(c *gin.Context) {
-	pageStr := c.Query("page")
-	limitStr := c.Query("limit")
+	page, limit, offset := parsePagination(c)
 
-	groupName := c.Query("group")
-	songTitle := c.Query("song")
-
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page < 1 {
-		page = 1
+	params, err := parseSongFilter(c)
+	if err != nil {
+		respondError(c, err)
+		return
 	}
+	params.Limit = int32(limit)
 
-	limit, err := strconv.Atoi(limitStr)
-	if err != nil || limit < 1 {
-		limit = 10
+	fields, includeGroup, err := parseSongFields(c, songListFields(c))
+	if err != nil {
+		respondError(c, err)
+		return
 	}
+	params.WithLyrics = fields["lyrics"]
 
-	offset := (page - 1) * limit
-
-	var songs []database.GetSongsWithPaginationRow
-	var total int64
-
-	if groupName != "" || songTitle != "" {
-		params := repository.SongFilterParams{
-			Limit:     int32(limit),
-			Offset:    int32(offset),
-			GroupName: groupName,
-			SongTitle: songTitle,
-		}
-
-		songs, err = h.songService.GetSongsWithFilters(c, params)
+	if cursor, ok := c.GetQuery("cursor"); ok {
+		songs, cursorPage, err := h.songService.GetSongsWithCursor(c, params, cursor)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve songs: " + err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		total, err = h.songService.GetSongsCountWithFilters(c, groupName, songTitle)
+		bulkSongs, err := h.formatBulkSongs(c, songs, includeGroup)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve songs count: " + err.Error()})
+			respondError(c, err)
 			return
 		}
-	} else {
-		songs, err = h.songService.GetSongsWithPagination(c, int32(limit), int32(offset))
+		data, err := projectAll(fields, bulkSongs)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve songs: " + err.Error()})
+			respondError(c, err)
 			return
 		}
 
-		total, err = h.songService.GetSongsCount(c)
-		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve songs count: " + err.Error()})
-			return
-		}
+		respondCursorPage(c, data, limit, cursorPage)
+		return
 	}
 
-	totalPages := (int(total) + limit - 1) / limit
+	params.Offset = int32(offset)
+	songs, total, err := h.songService.GetSongsWithFilters(c, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
 
-	bulkSongs, err := h.formatBulkSongs(c, songs)
+	bulkSongs, err := h.formatBulkSongs(c, songs, includeGroup)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	data, err := projectAll(fields, bulkSongs)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to formatting songs: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
-	response := gin.H{
-		"data":  bulkSongs,
-		"page":  page,
-		"limit": limit,
-		"pages": totalPages,
-		"total": total,
+	respondPage(c, data, page, limit, total)
+}
+
+// parseSongFilter reads the filters and sort of a song listing from the query, values that cannot be
+// parsed are reported here and the service checks the rest
+func parseSongFilter(c *gin.Context) (repository.SongFilterParams, error) {
+	params := repository.SongFilterParams{
+		GroupName:       c.Query("group"),
+		SongTitle:       c.Query("song"),
+		ReleaseDateFrom: c.Query("release_date_from"),
+		ReleaseDateTo:   c.Query("release_date_to"),
+	}
+
+	var err error
+	if params.Query, err = services.ParseSongQuery(c.Query("query")); err != nil {
+		return params, err
+	}
+	if value := c.Query("group_id"); value != "" {
+		if params.GroupID, err = uuid.Parse(value); err != nil {
+			return params, invalidID("group_id", "group")
+		}
+	}
+	if value := c.Query("min_rating"); value != "" {
+		params.MinRating, err = strconv.ParseFloat(value, 64)
+		if err != nil || params.MinRating < services.MinRating || params.MinRating > services.MaxRating {
+			return params, invalidParam("min_rating", "must be a number between 1 and 5")
+		}
+	}
+	if params.RuntimeMin, err = parseSeconds(c, "runtime_min"); err != nil {
+		return params, err
+	}
+	if params.RuntimeMax, err = parseSeconds(c, "runtime_max"); err != nil {
+		return params, err
+	}
+	if params.CreatedSince, err = parseTime(c, "created_since"); err != nil {
+		return params, err
+	}
+	if params.UpdatedSince, err = parseTime(c, "updated_since"); err != nil {
+		return params, err
+	}
+
+	switch sort := c.Query("sort"); sort {
+	case "":
+	case "rating":
+		// Kept from before sorts were configurable, the highest rated songs come first
+		params.Sort = []string{"-rating", "-created_at"}
+	default:
+		params.Sort = strings.Split(sort, ",")
 	}
 
-	c.JSON(http.StatusOK, response)
+	return params, nil
+}
+
+// parseSeconds reads a number of seconds from the query, it is nil when the parameter is missing
+func parseSeconds(c *gin.Context, field string) (*int32, error) {
+	value := c.Query(field)
+	if value == "" {
+		return nil, nil
+	}
+	seconds, err := strconv.ParseInt(value, 10, 32)
+	if err != nil {
+		return nil, invalidParam(field, "must be a whole number of seconds")
+	}
+	result := int32(seconds)
+	return &result, nil
+}
+
+// parseTime reads an RFC 3339 time from the query, it is zero when the parameter is missing
+func parseTime(c *gin.Context, field string) (time.Time, error) {
+	value := c.Query(field)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, invalidParam(field, "must be a time in RFC 3339 format")
+	}
+	return t, nil
 }
 
 // GetSongVerses godoc
@@ -244,14 +379,14 @@ func (h *SongHandler) GetAllSongs(c *gin.Context) {
 // @Param page query int false "Page number" default(1)
 // @Param limit query int false "Items per page" default(10)
 // @Success 200 {object} object{song_id=string,page=int,limit=int,pages=int,total=int,verses=array} "Paginated verses"
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 404 {object} object{error=string} "Song not found"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
 // @Router /songs/{id}/verses [get]
 func (h *SongHandler) GetSongVerses(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID format"})
+		respondError(c, invalidID("id", "song"))
 		return
 	}
 
@@ -270,7 +405,7 @@ func (h *SongHandler) GetSongVerses(c *gin.Context) {
 
 	song, err := h.songService.GetSong(c, id)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
+		respondError(c, err)
 		return
 	}
 
@@ -280,41 +415,208 @@ func (h *SongHandler) GetSongVerses(c *gin.Context) {
 	}
 
 	if err = json.Unmarshal(song.Lyrics, &lyricsData); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to parse lyrics"})
+		respondError(c, err)
 		return
 	}
 
 	total := len(lyricsData.Verses)
-	pages := (total + limit - 1) / limit
-
 	startIndex := (page - 1) * limit
-	endIndex := startIndex + limit
+	endIndex := min(startIndex+limit, total)
+
+	verses := []string{}
+	if startIndex < total {
+		verses = lyricsData.Verses[startIndex:endIndex]
+	}
+
+	meta := paginationMeta(page, limit, int64(total))
+	meta["song_id"] = song.ID.String()
+	legacy := gin.H{"verses": verses}
+	for key, value := range meta {
+		legacy[key] = value
+	}
+	respond(c, http.StatusOK, Envelope{Data: verses, Meta: meta}, legacy)
+}
+
+// SimilarSongResponse is a song with its lyrics similarity score, from 0 to 1
+type SimilarSongResponse struct {
+	SongResponse
+	Score float64 `json:"score"`
+}
+
+// GetSimilarSongs godoc
+// @Summary Get songs with similar lyrics
+// @Description Recommend songs whose lyrics share the most distinctive words and phrases with the song's lyrics, most similar first
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Param limit query int false "Maximum number of songs, up to 50" default(10)
+// @Success 200 {object} object{song_id=string,data=[]SimilarSongResponse} "Similar songs"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/similar [get]
+func (h *SongHandler) GetSimilarSongs(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
 
-	if startIndex >= total {
-		c.JSON(http.StatusOK, gin.H{
-			"song_id": song.ID.String(),
-			"page":    page,
-			"limit":   limit,
-			"pages":   pages,
-			"total":   total,
-			"verses":  []string{},
-		})
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", strconv.Itoa(services.DefaultSimilarLimit)))
+	if err != nil || limit < 1 {
+		limit = services.DefaultSimilarLimit
+	}
+	if limit > services.MaxSimilarLimit {
+		limit = services.MaxSimilarLimit
+	}
+
+	similar, err := h.similarityService.GetSimilarSongs(c, id, limit)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if endIndex > total {
-		endIndex = total
+	songs := make([]database.GetSongsWithPaginationRow, 0, len(similar))
+	for _, match := range similar {
+		songs = append(songs, match.Song)
 	}
 
-	verses := lyricsData.Verses[startIndex:endIndex]
+	formattedSongs, err := h.formatBulkSongs(c, songs, true)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]SimilarSongResponse, 0, len(formattedSongs))
+	for i, song := range formattedSongs {
+		data = append(data, SimilarSongResponse{SongResponse: song, Score: similar[i].Score})
+	}
+
+	respondWithMeta(c, http.StatusOK, data, gin.H{"song_id": id.String()})
+}
+
+// DuplicateSongData is one song of a duplicate candidate pair
+type DuplicateSongData struct {
+	ID        string `json:"id"`
+	Title     string `json:"title"`
+	GroupID   string `json:"group_id"`
+	GroupName string `json:"group_name"`
+	Runtime   int32  `json:"runtime"`
+}
+
+// DuplicateCandidateResponse is a pair of songs that are likely the same song
+type DuplicateCandidateResponse struct {
+	Score   float64                   `json:"score"`
+	Signals services.DuplicateSignals `json:"signals"`
+	Songs   [2]DuplicateSongData      `json:"songs"`
+}
+
+// GetDuplicateSongs godoc
+// @Summary List likely duplicate songs
+// @Description List pairs of songs that are likely the same song, scored from 0 to 1 by normalised title, group, runtime closeness and lyrics similarity, best first
+// @Tags songs
+// @Produce json
+// @Param min_score query number false "Minimum score of the pairs, between 0 and 1" default(0.75)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} object{data=[]DuplicateCandidateResponse,page=int,limit=int,pages=int,total=int} "Paginated duplicate candidates"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid min_score"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/duplicates [get]
+func (h *SongHandler) GetDuplicateSongs(c *gin.Context) {
+	page, limit, offset := parsePagination(c)
+
+	minScore := services.DefaultDuplicateMinScore
+	if value := c.Query("min_score"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil || parsed < 0 || parsed > 1 {
+			respondError(c, invalidParam("min_score", "must be a number between 0 and 1"))
+			return
+		}
+		minScore = parsed
+	}
+
+	candidates, err := h.duplicateService.FindDuplicates(c, minScore)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total := len(candidates)
+	start := min(offset, total)
+	end := min(offset+limit, total)
+
+	data := make([]DuplicateCandidateResponse, 0, end-start)
+	for _, candidate := range candidates[start:end] {
+		response := DuplicateCandidateResponse{
+			Score:   candidate.Score,
+			Signals: candidate.Signals,
+		}
+		for i, song := range candidate.Songs {
+			response.Songs[i] = DuplicateSongData{
+				ID:        song.ID.String(),
+				Title:     song.Title,
+				GroupID:   song.GroupID.String(),
+				GroupName: song.GroupName,
+				Runtime:   song.Runtime,
+			}
+		}
+		data = append(data, response)
+	}
+
+	respondPage(c, data, page, limit, int64(total))
+}
 
-	c.JSON(http.StatusOK, gin.H{
-		"song_id": song.ID.String(),
-		"page":    page,
-		"limit":   limit,
-		"pages":   pages,
-		"total":   total,
-		"verses":  verses,
+// MergeSong godoc
+// @Summary Merge a duplicate into a song
+// @Description Keep this song and move the playlist entries, favorites, ratings, plays and tags of the duplicate to it, then delete the duplicate. Everything happens in one transaction.
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path string true "ID of the song to keep" format(uuid)
+// @Param merge body object{duplicate_id=string} true "ID of the song to merge and delete"
+// @Success 200 {object} object{data=SongResponse,merged_song_id=string,moved=services.MergeReport} "Kept song and what was moved to it"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid ID or merging a song into itself"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/merge [post]
+func (h *SongHandler) MergeSong(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	var body struct {
+		DuplicateID string `json:"duplicate_id" binding:"required"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	duplicateID, err := uuid.Parse(body.DuplicateID)
+	if err != nil {
+		respondError(c, invalidID("duplicate_id", "duplicate song"))
+		return
+	}
+
+	song, report, err := h.duplicateService.MergeSongs(c, id, duplicateID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatSong(c, song)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithMeta(c, http.StatusOK, response, gin.H{
+		"merged_song_id": duplicateID.String(),
+		"moved":          report,
 	})
 }
 
@@ -325,17 +627,32 @@ func (h *SongHandler) GetSongVerses(c *gin.Context) {
 // @Accept json
 // @Produce json
 // @Param id path string true "Song ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being changed, or *"
 // @Param song body object{group_id=string,title=string,runtime=integer,lyrics=string,release_date=string,link=string} true "Song Information"
 // @Success 200 {object} object{message=object{id=string,group=object{id=string,name=string,created_at=string,updated_at=string},title=string,runtime=integer,lyrics=string,release_date=string,link=string,created_at=string,updated_at=string}} "Updated song data"
-// @Failure 400 {object} object{error=string} "Bad request - Invalid input or ID"
-// @Failure 404 {object} object{error=string} "Song not found"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Header 200 {string} ETag "ETag of the updated song"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid input or ID"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 412 {object} middleware.Problem "The song has been modified since it was read"
+// @Failure 422 {object} middleware.Problem "Group not found"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /songs/{id} [put]
 func (h *SongHandler) UpdateSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID format"})
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	current, err := h.songService.GetSong(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, current.UpdatedAt)
+	if !ok {
 		return
 	}
 
@@ -348,20 +665,19 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 		Link        string `json:"link" binding:"required"`
 	}
 
-	if err = c.BindJSON(&body); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	if !bindJSON(c, &body) {
 		return
 	}
 
 	groupID, err := uuid.Parse(body.GroupID)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid group ID format"})
+		respondError(c, invalidID("group_id", "group"))
 		return
 	}
 
-	releaseDate, err := time.Parse(time.RFC3339, body.ReleaseDate)
+	releaseDate, err := parseReleaseDate(body.ReleaseDate)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid release date format"})
+		respondError(c, errInvalidReleaseDate)
 		return
 	}
 
@@ -370,17 +686,12 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 	if body.Lyrics != "" {
 		lyricsJSON, err = parser.ParseLyrics(body.Lyrics)
 		if err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process lyrics"})
+			respondError(c, err)
 			return
 		}
 	} else {
-		// If no lyrics were provided, get the existing lyrics
-		song, err := h.songService.GetSong(c, id)
-		if err != nil {
-			c.JSON(http.StatusNotFound, gin.H{"error": "Song not found"})
-			return
-		}
-		lyricsJSON = song.Lyrics
+		// If no lyrics were provided, keep the existing lyrics
+		lyricsJSON = current.Lyrics
 	}
 
 	params := repository.SongUpdateParams{
@@ -391,21 +702,212 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 		Lyrics:      lyricsJSON,
 		ReleaseDate: releaseDate,
 		Link:        body.Link,
+		IfUpdatedAt: ifUpdatedAt,
 	}
 
 	song, err := h.songService.UpdateSong(c, params)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update song: " + err.Error()})
+		respondError(c, err)
 		return
 	}
 
 	response, err := h.formatSong(c, song)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve song: " + err.Error()})
+		respondError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, song.UpdatedAt, Envelope{Data: response}, gin.H{"message": response})
+}
+
+// songPatchDocument is the JSON form of a song that PATCH documents are applied to
+type songPatchDocument struct {
+	GroupID     string `json:"group_id"`
+	Title       string `json:"title"`
+	Runtime     int32  `json:"runtime"`
+	Lyrics      string `json:"lyrics"`
+	ReleaseDate string `json:"release_date"`
+	Link        string `json:"link"`
+}
+
+// PatchSong godoc
+// @Summary Partially update a song
+// @Description Update only some fields of a song with a JSON Merge Patch (RFC 7396) or a JSON Patch (RFC 6902).
+// @Description The patch applies to {group_id, title, runtime, lyrics, release_date, link} with release_date as YYYY-MM-DD, only changed fields are validated and written.
+// @Description Removing lyrics clears them, the other fields cannot be removed.
+// @Tags songs
+// @Accept application/merge-patch+json,application/json-patch+json
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Param patch body object true "Merge patch object or array of JSON Patch operations"
+// @Success 200 {object} object{data=SongResponse} "Updated song"
+// @Header 200 {string} ETag "ETag of the updated song"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid patch or field"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 409 {object} middleware.Problem "A JSON Patch test operation failed"
+// @Failure 412 {object} middleware.Problem "The song has been modified since it was read"
+// @Failure 415 {object} middleware.Problem "Unsupported patch media type"
+// @Failure 422 {object} middleware.Problem "Group not found"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id} [patch]
+func (h *SongHandler) PatchSong(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{"message": response})
+	song, err := h.songService.GetSong(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, song.UpdatedAt)
+	if !ok {
+		return
+	}
+
+	lyrics, err := lyricsText(song.Lyrics)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	changed, err := patchFields(c, songPatchDocument{
+		GroupID:     song.GroupID.String(),
+		Title:       song.Title,
+		Runtime:     song.Runtime,
+		Lyrics:      lyrics,
+		ReleaseDate: song.ReleaseDate.Time.Format(constants.DateFormat),
+		Link:        song.Link,
+	})
+	if err != nil {
+		respondPatchError(c, err)
+		return
+	}
+
+	params, err := songPatchParams(id, changed)
+	if err != nil {
+		respondPatchError(c, err)
+		return
+	}
+	params.IfUpdatedAt = ifUpdatedAt
+
+	patched, err := h.songService.PatchSong(c, params)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatSong(c, patched)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithETag(c, http.StatusOK, patched.UpdatedAt, Envelope{Data: response}, gin.H{"data": response})
+}
+
+// songPatchParams validates the changed members of a patched song document
+func songPatchParams(id uuid.UUID, changed map[string]any) (repository.SongPatchParams, error) {
+	params := repository.SongPatchParams{ID: id}
+	for field, value := range changed {
+		if value == nil && field != "lyrics" {
+			return repository.SongPatchParams{}, fieldError(field, "cannot be removed")
+		}
+
+		switch field {
+		case "group_id":
+			s, err := patchString(field, value)
+			if err != nil {
+				return repository.SongPatchParams{}, err
+			}
+			groupID, err := uuid.Parse(s)
+			if err != nil {
+				return repository.SongPatchParams{}, fieldError(field, "must be a UUID")
+			}
+			params.GroupID = &groupID
+		case "title":
+			title, err := patchString(field, value)
+			if err != nil {
+				return repository.SongPatchParams{}, err
+			}
+			params.Title = &title
+		case "runtime":
+			n, ok := value.(float64)
+			if !ok || n != math.Trunc(n) || n < 1 || n > math.MaxInt32 {
+				return repository.SongPatchParams{}, fieldError(field, "must be a positive whole number of seconds")
+			}
+			runtime := int32(n)
+			params.Runtime = &runtime
+		case "lyrics":
+			text, ok := value.(string)
+			if value != nil && !ok {
+				return repository.SongPatchParams{}, fieldError(field, "must be a string")
+			}
+			lyricsJSON, err := parser.ParseLyrics(text)
+			if err != nil {
+				return repository.SongPatchParams{}, err
+			}
+			params.Lyrics = lyricsJSON
+		case "release_date":
+			s, err := patchString(field, value)
+			if err != nil {
+				return repository.SongPatchParams{}, err
+			}
+			releaseDate, err := parseReleaseDate(s)
+			if err != nil {
+				return repository.SongPatchParams{}, fieldError(field, "must be a date as YYYY-MM-DD")
+			}
+			params.ReleaseDate = &releaseDate
+		case "link":
+			link, err := patchString(field, value)
+			if err != nil {
+				return repository.SongPatchParams{}, err
+			}
+			params.Link = &link
+		default:
+			return repository.SongPatchParams{}, fieldError(field, "is not a song field")
+		}
+	}
+	return params, nil
+}
+
+// errInvalidReleaseDate is returned for release dates parseReleaseDate does not accept
+var errInvalidReleaseDate = services.ErrInvalidBody.WithMessage("Invalid release date format").
+	WithFields(services.FieldError{Field: "release_date", Message: "must be a date as YYYY-MM-DD"})
+
+// parseReleaseDate accepts a YYYY-MM-DD date as well as an RFC 3339 timestamp
+func parseReleaseDate(value string) (time.Time, error) {
+	if releaseDate, err := time.Parse(constants.DateFormat, value); err == nil {
+		return releaseDate, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// lyricsText returns the raw text of lyrics stored by parser.ParseLyrics
+func lyricsText(lyricsJSON []byte) (string, error) {
+	if lyricsJSON == nil {
+		// The lyrics were not loaded
+		return "", nil
+	}
+
+	var lyricsData struct {
+		Text   string   `json:"text"`
+		Verses []string `json:"verses"`
+	}
+
+	if err := json.Unmarshal(lyricsJSON, &lyricsData); err != nil {
+		return "", err
+	}
+
+	if lyricsData.Text == "" && len(lyricsData.Verses) > 0 {
+		return strings.Join(lyricsData.Verses, "\n"), nil
+	}
+	return lyricsData.Text, nil
 }
 
 // DeleteSong godoc
@@ -414,122 +916,228 @@ func (h *SongHandler) UpdateSong(c *gin.Context) {
 // @Tags songs
 // @Produce json
 // @Param id path string true "Song ID" format(uuid)
-// @Success 204 {object} object{message=string} "Song deleted successfully"
-// @Failure 400 {object} object{error=string} "Bad request"
-// @Failure 500 {object} object{error=string} "Internal server error"
+// @Param If-Match header string true "ETag of the version being changed, or *"
+// @Success 204 "Song deleted"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 412 {object} middleware.Problem "The song has been modified since it was read"
+// @Failure 428 {object} middleware.Problem "If-Match header missing"
+// @Failure 500 {object} middleware.Problem "Internal server error"
 // @Router /songs/{id} [delete]
 func (h *SongHandler) DeleteSong(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := uuid.Parse(idStr)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid song ID format"})
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	song, err := h.songService.GetSong(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	ifUpdatedAt, ok := checkIfMatch(c, song.UpdatedAt)
+	if !ok {
+		return
+	}
+
+	if err := h.songService.DeleteSong(c, id, ifUpdatedAt); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Status(http.StatusNoContent)
+}
+
+// RestoreSong godoc
+// @Summary Restore a deleted song
+// @Description Undelete a song. A song deleted along with its group can only come back by restoring the group.
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Success 200 {object} object{data=SongResponse} "Restored song"
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 409 {object} middleware.Problem "The group of the song is deleted"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/restore [post]
+func (h *SongHandler) RestoreSong(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	song, err := h.songService.RestoreSong(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response, err := h.formatSong(c, song)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, response)
+}
+
+// SongTagsResponse is the tag set of a song
+type SongTagsResponse struct {
+	SongID string   `json:"song_id"`
+	Tags   []string `json:"tags"`
+}
+
+// GetSongTags godoc
+// @Summary Get song tags
+// @Description Get the tags of a song, tags are used by smart playlist rules
+// @Tags songs
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Success 200 {object} object{song_id=string,tags=[]string}
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/tags [get]
+func (h *SongHandler) GetSongTags(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
+	}
+
+	song, err := h.songService.GetSong(c, id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	if err := h.songService.DeleteSong(c, id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete song: " + err.Error()})
+	tags, err := h.songService.GetSongTags(c, id)
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, gin.H{"message": "Song deleted successfully"})
+	if tags == nil {
+		tags = []string{}
+	}
+
+	respond(c, http.StatusOK, Envelope{Data: SongTagsResponse{SongID: song.ID.String(), Tags: tags}},
+		gin.H{"song_id": song.ID.String(), "tags": tags})
 }
 
-// Format a single song with group data
-func (h *SongHandler) formatSong(c *gin.Context, song database.Song) (SongResponse, error) {
-	var lyricsData struct {
-		Text   string   `json:"text"`
-		Verses []string `json:"verses"`
+// ReplaceSongTags godoc
+// @Summary Replace song tags
+// @Description Replace the complete tag set of a song, tags are lower-cased and de-duplicated
+// @Tags songs
+// @Accept json
+// @Produce json
+// @Param id path string true "Song ID" format(uuid)
+// @Param tags body object{tags=[]string} true "Tags"
+// @Success 200 {object} object{song_id=string,tags=[]string}
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 404 {object} middleware.Problem "Song not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /songs/{id}/tags [put]
+func (h *SongHandler) ReplaceSongTags(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		respondError(c, invalidID("id", "song"))
+		return
 	}
 
-	if err := json.Unmarshal(song.Lyrics, &lyricsData); err != nil {
-		return SongResponse{}, err
+	var body struct {
+		Tags []string `json:"tags" binding:"required,dive,max=64"`
 	}
 
-	lyrics := lyricsData.Text
-	if lyrics == "" && len(lyricsData.Verses) > 0 {
-		lyrics = strings.Join(lyricsData.Verses, "\n")
+	if !bindJSON(c, &body) {
+		return
 	}
 
-	groupId, err := uuid.Parse(song.GroupID.String())
+	song, err := h.songService.GetSong(c, id)
 	if err != nil {
-		return SongResponse{}, err
+		respondError(c, err)
+		return
 	}
 
-	group, err := h.groupService.GetGroup(c, groupId)
+	tags, err := h.songService.ReplaceSongTags(c, id, body.Tags)
 	if err != nil {
-		return SongResponse{}, err
+		respondError(c, err)
+		return
 	}
 
-	return SongResponse{
-		ID: song.ID.String(),
-		Group: GroupData{
-			ID:        group.ID.String(),
-			Name:      group.Name,
-			CreatedAt: group.CreatedAt.Time,
-			UpdatedAt: group.UpdatedAt.Time,
-		},
-		Title:       song.Title,
-		Runtime:     song.Runtime,
-		Lyrics:      lyrics,
-		ReleaseDate: song.ReleaseDate.Time,
-		Link:        song.Link,
-		CreatedAt:   song.CreatedAt.Time,
-		UpdatedAt:   song.UpdatedAt.Time,
-	}, nil
+	respond(c, http.StatusOK, Envelope{Data: SongTagsResponse{SongID: song.ID.String(), Tags: tags}},
+		gin.H{"song_id": song.ID.String(), "tags": tags})
 }
 
-// Format multiple songs with group data
-func (h *SongHandler) formatBulkSongs(c *gin.Context, songs []database.GetSongsWithPaginationRow) ([]SongResponse, error) {
-	var formattedSongs []SongResponse
+// formatSong formats a single song row like the songs of a list
+func (h *SongHandler) formatSong(c *gin.Context, song database.GetSongsWithPaginationRow) (SongResponse, error) {
+	formatted, err := h.formatBulkSongs(c, []database.GetSongsWithPaginationRow{song}, true)
+	if err != nil {
+		return SongResponse{}, err
+	}
+	return formatted[0], nil
+}
 
-	groupCache := make(map[string]database.Group)
+// formatBulkSongs formats songs from their rows alone, the group, artworks, play counts and ratings
+// are read with the songs so formatting makes no further queries
+func (h *SongHandler) formatBulkSongs(c *gin.Context, songs []database.GetSongsWithPaginationRow, includeGroup bool) ([]SongResponse, error) {
+	formattedSongs := make([]SongResponse, 0, len(songs))
 
 	for _, song := range songs {
-		var lyricsData struct {
-			Text   string   `json:"text"`
-			Verses []string `json:"verses"`
-		}
-
-		if err := json.Unmarshal(song.Lyrics, &lyricsData); err != nil {
+		lyrics, err := lyricsText(song.Lyrics)
+		if err != nil {
 			return nil, err
 		}
 
-		lyrics := lyricsData.Text
-		if lyrics == "" && len(lyricsData.Verses) > 0 {
-			lyrics = strings.Join(lyricsData.Verses, "\n")
+		formattedSong := SongResponse{
+			ID:            song.ID.String(),
+			GroupID:       song.GroupID.String(),
+			Title:         song.Title,
+			Runtime:       song.Runtime,
+			Lyrics:        lyrics,
+			ReleaseDate:   song.ReleaseDate.Time,
+			Link:          song.Link,
+			PlayCount:     song.PlayCount,
+			RatingAverage: song.RatingAverage,
+			RatingCount:   song.RatingCount,
+			CreatedAt:     song.CreatedAt.Time,
+			UpdatedAt:     song.UpdatedAt.Time,
 		}
 
-		groupID := song.GroupID.String()
-		var group database.Group
-		var ok bool
-
-		if group, ok = groupCache[groupID]; !ok {
-			groupId, err := uuid.Parse(song.GroupID.String())
-			if err != nil {
-				return nil, err
-			}
-			group, err = h.groupService.GetGroup(c, groupId)
-			if err != nil {
-				return nil, err
-			}
-			groupCache[groupID] = group
+		if song.ArtworkFormat.Valid {
+			formattedSong.Artwork = newArtworkData(h.artworkService, database.Artwork{
+				EntityType: repository.ArtworkEntitySong,
+				EntityID:   song.ID,
+				Width:      song.ArtworkWidth.Int32,
+				Height:     song.ArtworkHeight.Int32,
+				Format:     song.ArtworkFormat.String,
+				UpdatedAt:  song.ArtworkUpdatedAt,
+			})
 		}
 
-		formattedSong := SongResponse{
-			ID: song.ID.String(),
-			Group: GroupData{
-				ID:        group.ID.String(),
-				Name:      group.Name,
-				CreatedAt: group.CreatedAt.Time,
-				UpdatedAt: group.UpdatedAt.Time,
-			},
-			Title:       song.Title,
-			Runtime:     song.Runtime,
-			Lyrics:      lyrics,
-			ReleaseDate: song.ReleaseDate.Time,
-			Link:        song.Link,
-			CreatedAt:   song.CreatedAt.Time,
-			UpdatedAt:   song.UpdatedAt.Time,
+		if includeGroup {
+			formattedSong.Group = newGroupResponse(database.Group{
+				ID:        song.GroupID,
+				Name:      song.GroupName,
+				CreatedAt: song.GroupCreatedAt,
+				UpdatedAt: song.GroupUpdatedAt,
+			}, nil)
+			if song.GroupArtworkFormat.Valid {
+				formattedSong.Group.Artwork = newArtworkData(h.artworkService, database.Artwork{
+					EntityType: repository.ArtworkEntityGroup,
+					EntityID:   song.GroupID,
+					Width:      song.GroupArtworkWidth.Int32,
+					Height:     song.GroupArtworkHeight.Int32,
+					Format:     song.GroupArtworkFormat.String,
+					UpdatedAt:  song.GroupArtworkUpdatedAt,
+				})
+			}
 		}
 
 		formattedSongs = append(formattedSongs, formattedSong)
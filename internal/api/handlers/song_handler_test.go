@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/blob"
+	"music-service/internal/storage/database/dbtest"
+	"music-service/internal/storage/database/repository"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// queryCounter counts the queries sent to the database, every one of them is a round trip
+type queryCounter struct {
+	queries atomic.Int64
+}
+
+func (q *queryCounter) TraceQueryStart(ctx context.Context, _ *pgx.Conn, _ pgx.TraceQueryStartData) context.Context {
+	q.queries.Add(1)
+	return ctx
+}
+
+func (q *queryCounter) TraceQueryEnd(context.Context, *pgx.Conn, pgx.TraceQueryEndData) {}
+
+type benchStorage struct {
+	blob.StorageInterface
+}
+
+func (benchStorage) URL(key string) string { return "/" + key }
+
+// newBenchSongHandler returns a song handler on a database holding songs spread over groups. Every song was
+// played and rated, every other song and group has an artwork. The IDs of the songs are returned in creation order.
+func newBenchSongHandler(b *testing.B, songs, groups int) (*SongHandler, *queryCounter, []uuid.UUID) {
+	b.Helper()
+
+	counter := &queryCounter{}
+	pool := dbtest.OpenTraced(b, counter)
+	m := repository.NewManager(pool)
+	ratings := services.NewRatingService(m)
+	ctx := context.Background()
+
+	user, err := m.Users.CreateUser(ctx, "listener", "not-a-real-hash", services.RoleViewer)
+	if err != nil {
+		b.Fatalf("creating user: %v", err)
+	}
+	userID := uuid.UUID(user.ID.Bytes)
+
+	groupIDs := make([]uuid.UUID, groups)
+	for i := range groupIDs {
+		group, err := m.Groups.CreateGroup(ctx, fmt.Sprintf("Group %d", i))
+		if err != nil {
+			b.Fatalf("creating group: %v", err)
+		}
+		groupIDs[i] = group.ID.Bytes
+		if i%2 == 0 {
+			seedArtwork(b, m, repository.ArtworkEntityGroup, groupIDs[i])
+		}
+	}
+
+	songIDs := make([]uuid.UUID, songs)
+	for i := range songIDs {
+		song, err := m.Songs.CreateSong(ctx, repository.SongCreateParams{
+			GroupID:     groupIDs[i%groups],
+			Title:       fmt.Sprintf("Song %d", i),
+			Runtime:     240,
+			Lyrics:      []byte(`{"text":"la la la"}`),
+			ReleaseDate: time.Date(2006, 7, 16, 0, 0, 0, 0, time.UTC),
+		})
+		if err != nil {
+			b.Fatalf("creating song: %v", err)
+		}
+		songIDs[i] = song.ID.Bytes
+
+		if _, err = m.Library.CreatePlayEvent(ctx, repository.PlayEventCreateParams{
+			UserID:   userID,
+			SongID:   songIDs[i],
+			PlayedAt: time.Now(),
+		}); err != nil {
+			b.Fatalf("recording play: %v", err)
+		}
+		if _, err = ratings.RateSong(ctx, userID, songIDs[i], int32(i%5+1)); err != nil {
+			b.Fatalf("rating song: %v", err)
+		}
+		if i%2 == 0 {
+			seedArtwork(b, m, repository.ArtworkEntitySong, songIDs[i])
+		}
+	}
+
+	similarityService := services.NewSimilarityService(m.Songs, slog.New(slog.DiscardHandler))
+	return NewSongHandler(
+		services.NewSongService(m.Songs, m.Groups, similarityService),
+		services.NewArtworkService(m.Artworks, benchStorage{}),
+		similarityService,
+		nil,
+	), counter, songIDs
+}
+
+func seedArtwork(b *testing.B, m *repository.Manager, entityType string, entityID uuid.UUID) {
+	b.Helper()
+
+	if _, err := m.Artworks.UpsertArtwork(context.Background(), repository.ArtworkUpsertParams{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Width:      600,
+		Height:     600,
+		Format:     "jpeg",
+	}); err != nil {
+		b.Fatalf("creating artwork: %v", err)
+	}
+}
+
+// benchmarkRequest serves GET target with handle and reports the queries it took
+func benchmarkRequest(b *testing.B, counter *queryCounter, handle gin.HandlerFunc, target string) {
+	gin.SetMode(gin.TestMode)
+	counter.queries.Store(0)
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", target, nil)
+		c.Set(apiVersionKey, APIVersion2)
+		handle(c)
+		if w.Code != 200 {
+			b.Fatalf("%s answered %d: %s", target, w.Code, w.Body)
+		}
+	}
+
+	b.ReportMetric(float64(counter.queries.Load())/float64(b.N), "queries/op")
+}
+
+// BenchmarkGetAllSongs lists a page of 50 songs, which takes one query however many groups are on the page
+func BenchmarkGetAllSongs(b *testing.B) {
+	for _, groups := range []int{1, 10, 50} {
+		b.Run(fmt.Sprintf("groups=%d", groups), func(b *testing.B) {
+			handler, counter, _ := newBenchSongHandler(b, 50, groups)
+			benchmarkRequest(b, counter, handler.GetAllSongs, "/songs?limit=50")
+		})
+	}
+}
+
+// BenchmarkGetSong reads a single song, which is loaded together with its group in one query
+func BenchmarkGetSong(b *testing.B) {
+	handler, counter, songIDs := newBenchSongHandler(b, 1, 1)
+	benchmarkRequest(b, counter, func(c *gin.Context) {
+		c.Params = gin.Params{{Key: "id", Value: songIDs[0].String()}}
+		handler.GetSong(c)
+	}, "/songs/"+songIDs[0].String())
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TrashHandler struct {
+	trashService *services.TrashService
+}
+
+// NewTrashHandler creates a new trash handler
+func NewTrashHandler(trashService *services.TrashService) *TrashHandler {
+	return &TrashHandler{
+		trashService: trashService,
+	}
+}
+
+// TrashItemResponse is a deleted group or song and when it will be purged
+type TrashItemResponse struct {
+	Type             string    `json:"type"`
+	ID               string    `json:"id"`
+	Name             string    `json:"name"`
+	GroupID          string    `json:"group_id,omitempty"`
+	DeletedWithGroup bool      `json:"deleted_with_group"`
+	DeletedAt        time.Time `json:"deleted_at"`
+	PurgeAt          time.Time `json:"purge_at"`
+}
+
+// GetTrash godoc
+// @Summary List deleted groups and songs
+// @Description List soft-deleted groups and songs, most recently deleted first, with when each is permanently purged.
+// @Description Songs deleted along with their group come back by restoring the group.
+// @Tags trash
+// @Produce json
+// @Param type query string false "Only list groups or songs" Enums(group, song)
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Success 200 {object} object{data=[]TrashItemResponse,page=int,limit=int,pages=int,total=int} "Paginated trash"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid type"
+// @Failure 403 {object} middleware.Problem "Editor role required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /trash [get]
+func (h *TrashHandler) GetTrash(c *gin.Context) {
+	page, limit, offset := parsePagination(c)
+	entityType := c.Query("type")
+
+	items, err := h.trashService.GetTrashWithPagination(c, entityType, int32(limit), int32(offset))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.trashService.GetTrashCount(c, entityType)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]TrashItemResponse, 0, len(items))
+	for _, item := range items {
+		data = append(data, h.formatTrashItem(item))
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+// PurgeTrash godoc
+// @Summary Purge the trash now
+// @Description Permanently delete the groups and songs whose retention period is over without waiting for the purge worker.
+// @Description With all=true everything in the trash is purged regardless of age.
+// @Tags admin
+// @Produce json
+// @Param all query bool false "Purge the whole trash" default(false)
+// @Success 200 {object} object{data=services.PurgeReport} "Number of groups and songs purged"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid all flag"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /admin/trash/purge [post]
+func (h *TrashHandler) PurgeTrash(c *gin.Context) {
+	all := false
+	if value := c.Query("all"); value != "" {
+		var err error
+		if all, err = strconv.ParseBool(value); err != nil {
+			respondError(c, invalidParam("all", "must be true or false"))
+			return
+		}
+	}
+
+	var report services.PurgeReport
+	var err error
+	if all {
+		report, err = h.trashService.Purge(c, time.Now())
+	} else {
+		report, err = h.trashService.PurgeExpired(c)
+	}
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, report)
+}
+
+func (h *TrashHandler) formatTrashItem(item database.GetTrashWithPaginationRow) TrashItemResponse {
+	response := TrashItemResponse{
+		Type:             item.EntityType,
+		ID:               item.ID.String(),
+		Name:             item.Name,
+		DeletedWithGroup: item.DeletedWithGroup,
+		DeletedAt:        item.DeletedAt.Time,
+		PurgeAt:          item.DeletedAt.Time.Add(h.trashService.Retention()),
+	}
+	if item.GroupID.Valid {
+		response.GroupID = item.GroupID.String()
+	}
+	return response
+}
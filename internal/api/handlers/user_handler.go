@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"music-service/internal/api/services"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"strconv"
+)
+
+type UserHandler struct {
+	userService *services.UserService
+}
+
+// NewUserHandler creates a new user handler
+func NewUserHandler(userService *services.UserService) *UserHandler {
+	return &UserHandler{
+		userService: userService,
+	}
+}
+
+// GetAllUsers godoc
+// @Summary List users
+// @Description Retrieve users with pagination, admin only
+// @Tags admin
+// @Produce json
+// @Param page query int false "Page number" default(1)
+// @Param limit query int false "Items per page" default(10)
+// @Param role query string false "Filter by role" Enums(viewer, editor, admin)
+// @Success 200 {object} object{data=[]UserResponse,page=int,limit=int,pages=int,total=int} "Paginated list of users"
+// @Failure 401 {object} middleware.Problem "Authentication required"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /admin/users [get]
+func (h *UserHandler) GetAllUsers(c *gin.Context) {
+	page, err := strconv.Atoi(c.Query("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+
+	limit, err := strconv.Atoi(c.Query("limit"))
+	if err != nil || limit < 1 {
+		limit = 10
+	}
+
+	offset := (page - 1) * limit
+	role := c.Query("role")
+
+	users, err := h.userService.GetUsersWithPagination(c, repository.UserFilterParams{
+		Role:   role,
+		Limit:  int32(limit),
+		Offset: int32(offset),
+	})
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	total, err := h.userService.GetUsersCount(c, role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	data := make([]UserResponse, 0, len(users))
+	for _, user := range users {
+		data = append(data, formatUser(user))
+	}
+
+	respondPage(c, data, page, limit, total)
+}
+
+// GetUser godoc
+// @Summary Get a user by ID
+// @Description Retrieve a single user, admin only
+// @Tags admin
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Success 200 {object} UserResponse
+// @Failure 400 {object} middleware.Problem "Bad request"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 404 {object} middleware.Problem "User not found"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /admin/users/{id} [get]
+func (h *UserHandler) GetUser(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "user"))
+		return
+	}
+
+	user, err := h.userService.GetUser(c, id)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	response := formatUser(user)
+	respond(c, http.StatusOK, Envelope{Data: response}, response)
+}
+
+// UpdateUserRole godoc
+// @Summary Change a user's role
+// @Description Set the role of a user to viewer, editor or admin, admin only. The last admin cannot be demoted.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID" format(uuid)
+// @Param role body object{role=string} true "New role"
+// @Success 200 {object} object{data=UserResponse} "Updated user"
+// @Failure 400 {object} middleware.Problem "Bad request - Invalid role"
+// @Failure 403 {object} middleware.Problem "Admin role required"
+// @Failure 404 {object} middleware.Problem "User not found"
+// @Failure 409 {object} middleware.Problem "The last admin cannot be demoted"
+// @Failure 500 {object} middleware.Problem "Internal server error"
+// @Router /admin/users/{id}/role [put]
+func (h *UserHandler) UpdateUserRole(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		respondError(c, invalidID("id", "user"))
+		return
+	}
+
+	var body struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if !bindJSON(c, &body) {
+		return
+	}
+
+	user, err := h.userService.UpdateUserRole(c, id, body.Role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondData(c, http.StatusOK, formatUser(user))
+}
@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/services"
+	"net/http"
+	"strings"
+)
+
+// principalKey is the gin context key the authenticated principal is stored under
+const principalKey = "principal"
+
+// Errors of requests that are not authenticated or not allowed, invalid credentials are reported by the auth service
+var (
+	ErrAuthenticationRequired = services.NewError(services.KindUnauthenticated, "authentication_required", "Authentication required")
+	ErrInsufficientRole       = services.NewError(services.KindForbidden, "insufficient_role", "Insufficient role")
+)
+
+// APIKeyHeader is the header service-to-service callers can send their API key in
+const APIKeyHeader = "X-API-Key"
+
+// AuthOptions configures the Authenticate middleware
+type AuthOptions struct {
+	// PublicReads lets GET, HEAD and OPTIONS requests through without credentials
+	PublicReads bool
+	// PublicPaths are route paths, as registered with gin, that accept any method without credentials
+	PublicPaths []string
+}
+
+// Authenticate resolves the caller from a bearer token or API key and rejects
+// unauthenticated requests unless they are reads and reads are public.
+// Credentials that are present but invalid are always rejected.
+func Authenticate(authService *services.AuthService, opts AuthOptions) gin.HandlerFunc {
+	publicPaths := make(map[string]bool, len(opts.PublicPaths))
+	for _, p := range opts.PublicPaths {
+		publicPaths[p] = true
+	}
+
+	return func(c *gin.Context) {
+		principal, found, err := resolvePrincipal(c, authService)
+		if err != nil {
+			if errors.Is(err, services.ErrInvalidToken) || errors.Is(err, services.ErrInvalidAPIKey) {
+				abortUnauthorized(c, err)
+				return
+			}
+			abortWithError(c, err)
+			return
+		}
+
+		if found {
+			c.Set(principalKey, principal)
+			c.Next()
+			return
+		}
+
+		// Unmatched routes fall through so they still answer 404
+		if c.FullPath() == "" || publicPaths[c.FullPath()] || (opts.PublicReads && isReadMethod(c.Request.Method)) {
+			c.Next()
+			return
+		}
+
+		abortUnauthorized(c, ErrAuthenticationRequired)
+	}
+}
+
+// RequireAuth rejects requests that were not authenticated, for routes that stay private even when reads are public
+func RequireAuth() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := GetPrincipal(c); !ok {
+			abortUnauthorized(c, ErrAuthenticationRequired)
+			return
+		}
+		c.Next()
+	}
+}
+
+// GetPrincipal returns the authenticated caller of the request, if any
+func GetPrincipal(c *gin.Context) (services.Principal, bool) {
+	value, ok := c.Get(principalKey)
+	if !ok {
+		return services.Principal{}, false
+	}
+	principal, ok := value.(services.Principal)
+	return principal, ok
+}
+
+func resolvePrincipal(c *gin.Context, authService *services.AuthService) (services.Principal, bool, error) {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		principal, err := authService.AuthenticateAPIKey(c, key)
+		return principal, err == nil, err
+	}
+
+	scheme, credentials, ok := strings.Cut(c.GetHeader("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return services.Principal{}, false, nil
+	}
+
+	credentials = strings.TrimSpace(credentials)
+	if strings.HasPrefix(credentials, services.APIKeyPrefix) {
+		principal, err := authService.AuthenticateAPIKey(c, credentials)
+		return principal, err == nil, err
+	}
+
+	principal, err := authService.AuthenticateToken(c, credentials)
+	return principal, err == nil, err
+}
+
+func isReadMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func abortUnauthorized(c *gin.Context, err error) {
+	c.Header("WWW-Authenticate", `Bearer realm="music-service"`)
+	abortWithError(c, err)
+}
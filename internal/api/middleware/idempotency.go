@@ -0,0 +1,116 @@
+package middleware
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"github.com/gin-gonic/gin"
+	"io"
+	"log/slog"
+	"music-service/internal/api/services"
+	"net/http"
+)
+
+// IdempotencyKeyHeader is the header clients send to make a create request safe to retry
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+// IdempotentReplayedHeader marks a response replayed from an earlier request with the same key
+const IdempotentReplayedHeader = "Idempotent-Replayed"
+
+// maxIdempotencyKeyLength is the longest key the idempotency_keys table stores
+const maxIdempotencyKeyLength = 255
+
+var errInvalidIdempotencyKey = services.NewValidationError("invalid_idempotency_key", "Idempotency-Key must be at most 255 characters",
+	services.FieldError{Field: IdempotencyKeyHeader, Message: "must be at most 255 characters"})
+
+// Idempotency replays the response to the first request when a request with an Idempotency-Key header is
+// retried. Keys belong to the authenticated user and only match the same method, path, query and body, a key reused
+// for another request is rejected with 422. Server errors are not stored so retrying them runs the request
+// again. Requests without the header are processed as usual.
+func Idempotency(idempotencyService *services.IdempotencyService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := c.GetHeader(IdempotencyKeyHeader)
+		if key == "" {
+			c.Next()
+			return
+		}
+		if len(key) > maxIdempotencyKeyLength {
+			abortWithError(c, errInvalidIdempotencyKey)
+			return
+		}
+
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortUnauthorized(c, ErrAuthenticationRequired)
+			return
+		}
+
+		body, err := io.ReadAll(c.Request.Body)
+		if err != nil {
+			abortWithError(c, services.ErrInvalidBody.Wrap(err))
+			return
+		}
+		c.Request.Body = io.NopCloser(bytes.NewReader(body))
+
+		hash := sha256.New()
+		hash.Write([]byte(c.Request.Method + " " + c.Request.URL.RequestURI() + "\n"))
+		hash.Write(body)
+
+		response, replay, err := idempotencyService.Start(c, principal.UserID, key, hash.Sum(nil))
+		switch {
+		case err != nil:
+			abortWithError(c, err)
+			return
+		case replay:
+			c.Header(IdempotentReplayedHeader, "true")
+			c.Data(response.StatusCode, response.ContentType, response.Body)
+			c.Abort()
+			return
+		}
+
+		// The client may have given up on the request, the key must be settled regardless
+		ctx := context.WithoutCancel(c.Request.Context())
+		recorder := &responseRecorder{ResponseWriter: c.Writer}
+		c.Writer = recorder
+
+		stored := false
+		defer func() {
+			// Panics and server errors release the key so the retry runs the request again
+			if !stored {
+				_ = idempotencyService.Release(ctx, principal.UserID, key)
+			}
+		}()
+
+		c.Next()
+		// Errors recorded by the handler are answered now so that their problem is stored for retries
+		writeErrors(c)
+
+		if recorder.Status() >= http.StatusInternalServerError {
+			return
+		}
+		stored = true
+		if err := idempotencyService.Complete(ctx, principal.UserID, key, services.IdempotentResponse{
+			StatusCode:  recorder.Status(),
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		}); err != nil {
+			logger(c).Error("failed to store idempotent response", slog.String("request_id", GetRequestID(c)), slog.Any("error", err))
+		}
+	}
+}
+
+// responseRecorder keeps a copy of the response body written through it
+type responseRecorder struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *responseRecorder) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"log/slog"
+	"music-service/internal/api/services"
+	"music-service/internal/config"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type fakeIdempotencyRepo struct {
+	repository.IdempotencyRepositoryInterface
+	keys map[string]database.IdempotencyKey
+}
+
+func (r *fakeIdempotencyRepo) StartIdempotentRequest(_ context.Context, _ uuid.UUID, key string, requestHash []byte, _, _ time.Time) (database.IdempotencyKey, error) {
+	if _, ok := r.keys[key]; ok {
+		return database.IdempotencyKey{}, pgx.ErrNoRows
+	}
+	r.keys[key] = database.IdempotencyKey{Key: key, RequestHash: requestHash}
+	return r.keys[key], nil
+}
+
+func (r *fakeIdempotencyRepo) GetIdempotencyKey(_ context.Context, _ uuid.UUID, key string) (database.IdempotencyKey, error) {
+	record, ok := r.keys[key]
+	if !ok {
+		return database.IdempotencyKey{}, pgx.ErrNoRows
+	}
+	return record, nil
+}
+
+func (r *fakeIdempotencyRepo) CompleteIdempotentRequest(_ context.Context, _ uuid.UUID, key string, statusCode int32, contentType string, body []byte) error {
+	record := r.keys[key]
+	record.StatusCode = pgtype.Int4{Int32: statusCode, Valid: true}
+	record.ContentType = contentType
+	record.ResponseBody = body
+	r.keys[key] = record
+	return nil
+}
+
+func (r *fakeIdempotencyRepo) DeleteIdempotencyKey(_ context.Context, _ uuid.UUID, key string) error {
+	delete(r.keys, key)
+	return nil
+}
+
+func TestIdempotency(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cfg := &config.Config{}
+	cfg.Internal.Idempotency.KeyTTL = time.Hour
+	idempotencyService := services.NewIdempotencyService(&repository.Manager{
+		Idempotency: &fakeIdempotencyRepo{keys: map[string]database.IdempotencyKey{}},
+	}, cfg)
+
+	created := 0
+	router := gin.New()
+	router.Use(Problems(slog.New(slog.DiscardHandler)), func(c *gin.Context) {
+		c.Set("principal", services.Principal{UserID: uuid.New(), Role: services.RoleEditor})
+	}, Idempotency(idempotencyService))
+	router.POST("/playlists/import", func(c *gin.Context) {
+		created++
+		c.JSON(http.StatusCreated, gin.H{"created": created})
+	})
+
+	send := func(target, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, target, strings.NewReader(body))
+		req.Header.Set(IdempotencyKeyHeader, "key")
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	first := send("/playlists/import?format=m3u", "#EXTM3U")
+	if first.Code != http.StatusCreated {
+		t.Fatalf("status = %d, want %d: %s", first.Code, http.StatusCreated, first.Body)
+	}
+
+	retry := send("/playlists/import?format=m3u", "#EXTM3U")
+	if retry.Code != http.StatusCreated || retry.Header().Get(IdempotentReplayedHeader) != "true" || retry.Body.String() != first.Body.String() {
+		t.Errorf("retry = %d %s, want the replayed first response", retry.Code, retry.Body)
+	}
+	if created != 1 {
+		t.Errorf("handler ran %d times, want 1", created)
+	}
+
+	tests := []struct {
+		name   string
+		target string
+		body   string
+	}{
+		{"other query", "/playlists/import?format=xspf", "#EXTM3U"},
+		{"query left out", "/playlists/import", "#EXTM3U"},
+		{"other body", "/playlists/import?format=m3u", "#EXTM3U\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := send(tt.target, tt.body)
+			if w.Code != http.StatusUnprocessableEntity {
+				t.Fatalf("status = %d, want %d: %s", w.Code, http.StatusUnprocessableEntity, w.Body)
+			}
+			var problem struct {
+				Code string `json:"code"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatalf("decoding problem: %v", err)
+			}
+			if problem.Code != "idempotency_key_reused" {
+				t.Errorf("code = %q, want %q", problem.Code, "idempotency_key_reused")
+			}
+		})
+	}
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/gin-gonic/gin"
+	"log/slog"
+	"music-service/internal/api/services"
+	"net/http"
+	"sort"
+)
+
+// ProblemContentType is the media type of error responses, see RFC 7807
+const ProblemContentType = "application/problem+json"
+
+// problemTypePrefix is prepended to the code of a problem to form its type URI
+const problemTypePrefix = "urn:music-service:problem:"
+
+// internalErrorCode is the code of errors the service did not expect, their cause is only logged
+const internalErrorCode = "internal_error"
+
+// loggerKey is the gin context key the logger for unexpected errors is stored under
+const loggerKey = "problem_logger"
+
+// Problem is the body of every error response
+type Problem struct {
+	Type      string                `json:"type" example:"urn:music-service:problem:song_not_found"`
+	Title     string                `json:"title" example:"Not Found"`
+	Status    int                   `json:"status" example:"404"`
+	Detail    string                `json:"detail,omitempty" example:"Song not found"`
+	Instance  string                `json:"instance,omitempty" example:"/api/v1/songs/4b0f3c7e-59a5-4d0e-9a53-2b8d8f4c8a11"`
+	Code      string                `json:"code" example:"song_not_found"`
+	RequestID string                `json:"request_id,omitempty" example:"0f8fad5b-d9cb-469f-a165-70867728950e"`
+	Errors    []services.FieldError `json:"errors,omitempty"`
+	// Extensions are further members of the problem, such as the ID of a conflicting entity
+	Extensions map[string]any `json:"-"`
+}
+
+// MarshalJSON writes the extensions as members of the problem next to the standard ones
+func (p Problem) MarshalJSON() ([]byte, error) {
+	type problem Problem
+	data, err := json.Marshal(problem(p))
+	if err != nil || len(p.Extensions) == 0 {
+		return data, err
+	}
+
+	keys := make([]string, 0, len(p.Extensions))
+	for key := range p.Extensions {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var buf bytes.Buffer
+	buf.Write(data[:len(data)-1])
+	for _, key := range keys {
+		if problemMembers[key] {
+			continue
+		}
+		name, _ := json.Marshal(key)
+		value, err := json.Marshal(p.Extensions[key])
+		if err != nil {
+			return nil, err
+		}
+		buf.WriteByte(',')
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// problemMembers are the members extensions may not replace
+var problemMembers = map[string]bool{
+	"type": true, "title": true, "status": true, "detail": true, "instance": true,
+	"code": true, "request_id": true, "errors": true,
+}
+
+// problemStatus is the status of each kind of service error
+var problemStatus = map[services.ErrorKind]int{
+	services.KindValidation:           http.StatusBadRequest,
+	services.KindNotFound:             http.StatusNotFound,
+	services.KindConflict:             http.StatusConflict,
+	services.KindReference:            http.StatusUnprocessableEntity,
+	services.KindUnprocessable:        http.StatusUnprocessableEntity,
+	services.KindPreconditionFailed:   http.StatusPreconditionFailed,
+	services.KindPreconditionRequired: http.StatusPreconditionRequired,
+	services.KindUnsupportedMedia:     http.StatusUnsupportedMediaType,
+	services.KindTooLarge:             http.StatusRequestEntityTooLarge,
+	services.KindUnauthenticated:      http.StatusUnauthorized,
+	services.KindForbidden:            http.StatusForbidden,
+}
+
+// Problems answers requests whose handlers recorded an error with c.Error and wrote no response with
+// the problem details of the last error. Errors with a services.Error in their chain keep their status,
+// code and message, any other error is unexpected: it is logged and answered with a generic 500.
+func Problems(log *slog.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(loggerKey, log)
+		c.Next()
+		writeErrors(c)
+	}
+}
+
+// abortWithError records err for the Problems middleware and stops the handler chain
+func abortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+// writeErrors writes the problem for the errors recorded by the handlers unless a response was written
+func writeErrors(c *gin.Context) {
+	if len(c.Errors) == 0 || c.Writer.Written() {
+		return
+	}
+	WriteProblem(c, c.Errors.Last().Err)
+}
+
+// WriteProblem answers the request with the problem details of err
+func WriteProblem(c *gin.Context, err error) {
+	problem := Problem{
+		Instance:  c.Request.URL.Path,
+		RequestID: GetRequestID(c),
+	}
+
+	if serviceErr := services.AsError(err); serviceErr != nil {
+		problem.Status = problemStatus[serviceErr.Kind]
+		problem.Code = serviceErr.Code
+		problem.Detail = serviceErr.Message
+		problem.Errors = serviceErr.Fields
+		problem.Extensions = serviceErr.Details
+	} else {
+		problem.Status = http.StatusInternalServerError
+		problem.Code = internalErrorCode
+		problem.Detail = "An unexpected error occurred, quote the request ID when reporting it"
+		logger(c).Error("request failed",
+			slog.String("request_id", problem.RequestID),
+			slog.String("method", c.Request.Method),
+			slog.String("path", c.Request.URL.Path),
+			slog.Any("error", err))
+	}
+	if problem.Status == 0 {
+		problem.Status = http.StatusBadRequest
+	}
+	problem.Type = problemTypePrefix + problem.Code
+	problem.Title = http.StatusText(problem.Status)
+
+	data, err := json.Marshal(problem)
+	if err != nil {
+		logger(c).Error("failed to encode problem", slog.Any("error", err))
+		c.AbortWithStatus(problem.Status)
+		return
+	}
+	c.Data(problem.Status, ProblemContentType, data)
+	c.Abort()
+}
+
+// Recovery answers handlers that panicked with an internal error problem
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		if c.Writer.Written() {
+			c.Abort()
+			return
+		}
+		WriteProblem(c, panicError{value: recovered})
+	})
+}
+
+// panicError carries the value a handler panicked with
+type panicError struct {
+	value any
+}
+
+func (e panicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+func logger(c *gin.Context) *slog.Logger {
+	if value, ok := c.Get(loggerKey); ok {
+		if log, ok := value.(*slog.Logger); ok {
+			return log
+		}
+	}
+	return slog.Default()
+}
@@ -0,0 +1,28 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/services"
+)
+
+// RequireRole rejects requests whose caller does not have at least the given role.
+// Unauthenticated requests get 401, authenticated ones without the role get 403.
+func RequireRole(role string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		principal, ok := GetPrincipal(c)
+		if !ok {
+			abortUnauthorized(c, ErrAuthenticationRequired)
+			return
+		}
+
+		if !services.RoleAllows(principal.Role, role) {
+			abortWithError(c, ErrInsufficientRole.
+				WithMessage("This action requires the "+role+" role").
+				WithDetail("required_role", role).
+				WithDetail("role", principal.Role))
+			return
+		}
+
+		c.Next()
+	}
+}
@@ -0,0 +1,18 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterAdminRoutes(r *gin.RouterGroup, userHandler *handlers.UserHandler, trashHandler *handlers.TrashHandler) {
+	admin := r.Group("/admin", middleware.RequireRole(services.RoleAdmin))
+	{
+		admin.GET("/users", userHandler.GetAllUsers)
+		admin.GET("/users/:id", userHandler.GetUser)
+		admin.PUT("/users/:id/role", userHandler.UpdateUserRole)
+		admin.POST("/trash/purge", trashHandler.PurgeTrash)
+	}
+}
@@ -0,0 +1,15 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterArtworkRoutes(r *gin.RouterGroup, handler *handlers.ArtworkHandler) {
+	r.POST("/songs/:id/artwork", middleware.RequireRole(services.RoleEditor), handler.UploadSongArtwork)
+	r.DELETE("/songs/:id/artwork", middleware.RequireRole(services.RoleEditor), handler.DeleteSongArtwork)
+	r.POST("/groups/:id/artwork", middleware.RequireRole(services.RoleEditor), handler.UploadGroupArtwork)
+	r.DELETE("/groups/:id/artwork", middleware.RequireRole(services.RoleEditor), handler.DeleteGroupArtwork)
+}
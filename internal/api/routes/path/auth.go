@@ -0,0 +1,23 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+)
+
+func RegisterAuthRoutes(r *gin.RouterGroup, handler *handlers.AuthHandler) {
+	auth := r.Group("/auth")
+	{
+		auth.POST("/register", handler.Register)
+		auth.POST("/login", handler.Login)
+	}
+
+	account := auth.Group("", middleware.RequireAuth())
+	{
+		account.GET("/me", handler.GetCurrentUser)
+		account.POST("/api-keys", handler.CreateAPIKey)
+		account.GET("/api-keys", handler.GetAPIKeys)
+		account.DELETE("/api-keys/:id", handler.RevokeAPIKey)
+	}
+}
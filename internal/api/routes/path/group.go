@@ -3,15 +3,24 @@ package path
 import (
 	"github.com/gin-gonic/gin"
 	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
 )
 
-func RegisterGroupRoutes(r *gin.RouterGroup, handler *handlers.GroupHandler) {
+func RegisterGroupRoutes(r *gin.RouterGroup, handler *handlers.GroupHandler, idempotent gin.HandlerFunc) {
 	groups := r.Group("/groups")
 	{
-		groups.POST("", handler.CreateGroup)
+		groups.POST("", middleware.RequireRole(services.RoleEditor), idempotent, handler.CreateGroup)
 		groups.GET("", handler.GetAllGroups)
+		groups.POST("/get-or-create", middleware.RequireRole(services.RoleEditor), handler.GetOrCreateGroup)
 		groups.GET("/:id", handler.GetGroup)
-		groups.PUT("/:id", handler.UpdateGroup)
-		groups.DELETE("/:id", handler.DeleteGroup)
+		groups.PUT("/:id", middleware.RequireRole(services.RoleEditor), handler.UpdateGroup)
+		groups.PATCH("/:id", middleware.RequireRole(services.RoleEditor), handler.PatchGroup)
+		groups.DELETE("/:id", middleware.RequireRole(services.RoleAdmin), handler.DeleteGroup)
+		groups.POST("/:id/restore", middleware.RequireRole(services.RoleAdmin), handler.RestoreGroup)
+		groups.POST("/:id/merge", middleware.RequireRole(services.RoleAdmin), handler.MergeGroups)
+		groups.GET("/:id/aliases", handler.GetGroupAliases)
+		groups.POST("/:id/aliases", middleware.RequireRole(services.RoleEditor), idempotent, handler.CreateGroupAlias)
+		groups.DELETE("/:id/aliases/:alias_id", middleware.RequireRole(services.RoleEditor), handler.DeleteGroupAlias)
 	}
 }
@@ -0,0 +1,21 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterMeRoutes(r *gin.RouterGroup, handler *handlers.LibraryHandler, idempotent gin.HandlerFunc) {
+	me := r.Group("/me", middleware.RequireRole(services.RoleViewer))
+	{
+		me.GET("/favorites", handler.GetFavorites)
+		me.PUT("/favorites/songs/:id", handler.AddFavoriteSong)
+		me.DELETE("/favorites/songs/:id", handler.RemoveFavoriteSong)
+		me.PUT("/favorites/groups/:id", handler.AddFavoriteGroup)
+		me.DELETE("/favorites/groups/:id", handler.RemoveFavoriteGroup)
+		me.GET("/history", handler.GetHistory)
+		me.POST("/history", idempotent, handler.RecordPlay)
+	}
+}
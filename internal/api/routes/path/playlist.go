@@ -0,0 +1,23 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterPlaylistRoutes(r *gin.RouterGroup, handler *handlers.PlaylistHandler, idempotent gin.HandlerFunc) {
+	playlists := r.Group("/playlists")
+	{
+		playlists.POST("", middleware.RequireRole(services.RoleViewer), idempotent, handler.CreatePlaylist)
+		playlists.GET("", handler.GetAllPlaylists)
+		playlists.POST("/import", middleware.RequireRole(services.RoleViewer), idempotent, handler.ImportPlaylist)
+		playlists.GET("/:id", handler.GetPlaylist)
+		playlists.PUT("/:id", middleware.RequireRole(services.RoleViewer), handler.UpdatePlaylist)
+		playlists.DELETE("/:id", middleware.RequireRole(services.RoleViewer), handler.DeletePlaylist)
+		playlists.POST("/:id/entries", middleware.RequireRole(services.RoleViewer), idempotent, handler.AddPlaylistEntry)
+		playlists.DELETE("/:id/entries/:entry_id", middleware.RequireRole(services.RoleViewer), handler.RemovePlaylistEntry)
+		playlists.POST("/:id/entries/:entry_id/move", middleware.RequireRole(services.RoleViewer), handler.MovePlaylistEntry)
+	}
+}
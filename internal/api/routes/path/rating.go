@@ -0,0 +1,14 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterRatingRoutes(r *gin.RouterGroup, handler *handlers.RatingHandler) {
+	r.PUT("/songs/:id/rating", middleware.RequireRole(services.RoleViewer), handler.RateSong)
+	r.DELETE("/songs/:id/rating", middleware.RequireRole(services.RoleViewer), handler.RemoveRating)
+	r.GET("/me/ratings", middleware.RequireRole(services.RoleViewer), handler.GetMyRatings)
+}
@@ -0,0 +1,20 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterSmartPlaylistRoutes(r *gin.RouterGroup, handler *handlers.SmartPlaylistHandler, idempotent gin.HandlerFunc) {
+	smartPlaylists := r.Group("/smart-playlists")
+	{
+		smartPlaylists.POST("", middleware.RequireRole(services.RoleViewer), idempotent, handler.CreateSmartPlaylist)
+		smartPlaylists.GET("", handler.GetAllSmartPlaylists)
+		smartPlaylists.POST("/preview", middleware.RequireRole(services.RoleViewer), handler.PreviewSmartPlaylist)
+		smartPlaylists.GET("/:id", handler.GetSmartPlaylist)
+		smartPlaylists.PUT("/:id", middleware.RequireRole(services.RoleViewer), handler.UpdateSmartPlaylist)
+		smartPlaylists.DELETE("/:id", middleware.RequireRole(services.RoleViewer), handler.DeleteSmartPlaylist)
+	}
+}
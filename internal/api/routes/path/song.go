@@ -3,16 +3,25 @@ package path
 import (
 	"github.com/gin-gonic/gin"
 	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
 )
 
-func RegisterSongRoutes(r *gin.RouterGroup, handler *handlers.SongHandler) {
+func RegisterSongRoutes(r *gin.RouterGroup, handler *handlers.SongHandler, idempotent gin.HandlerFunc) {
 	songs := r.Group("/songs")
 	{
-		songs.POST("", handler.CreateSong)
+		songs.POST("", middleware.RequireRole(services.RoleEditor), idempotent, handler.CreateSong)
 		songs.GET("", handler.GetAllSongs)
+		songs.GET("/duplicates", middleware.RequireRole(services.RoleEditor), handler.GetDuplicateSongs)
 		songs.GET("/:id", handler.GetSong)
 		songs.GET("/:id/verses", handler.GetSongVerses)
-		songs.PUT("/:id", handler.UpdateSong)
-		songs.DELETE("/:id", handler.DeleteSong)
+		songs.GET("/:id/similar", handler.GetSimilarSongs)
+		songs.GET("/:id/tags", handler.GetSongTags)
+		songs.PUT("/:id/tags", middleware.RequireRole(services.RoleEditor), handler.ReplaceSongTags)
+		songs.PUT("/:id", middleware.RequireRole(services.RoleEditor), handler.UpdateSong)
+		songs.PATCH("/:id", middleware.RequireRole(services.RoleEditor), handler.PatchSong)
+		songs.DELETE("/:id", middleware.RequireRole(services.RoleEditor), handler.DeleteSong)
+		songs.POST("/:id/restore", middleware.RequireRole(services.RoleEditor), handler.RestoreSong)
+		songs.POST("/:id/merge", middleware.RequireRole(services.RoleEditor), handler.MergeSong)
 	}
 }
@@ -0,0 +1,15 @@
+package path
+
+import (
+	"github.com/gin-gonic/gin"
+	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
+)
+
+func RegisterTrashRoutes(r *gin.RouterGroup, handler *handlers.TrashHandler) {
+	trash := r.Group("/trash", middleware.RequireRole(services.RoleEditor))
+	{
+		trash.GET("", handler.GetTrash)
+	}
+}
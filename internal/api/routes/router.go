@@ -3,9 +3,29 @@ package routes
 import (
 	"fmt"
 	"github.com/gin-gonic/gin"
+	"log/slog"
+	"music-service/internal/api/middleware"
+	"music-service/internal/api/services"
 	"music-service/internal/config"
 )
 
+// API routes are registered under both prefixes, v2 routes answer in the handlers.Envelope
+const (
+	apiBasePath   = "/api/v1"
+	apiV2BasePath = "/api/v2"
+)
+
+// publicWritePaths accept writes without credentials so that callers can obtain them
+var publicWritePaths = []string{
+	apiBasePath + "/auth/register",
+	apiBasePath + "/auth/login",
+	apiV2BasePath + "/auth/register",
+	apiV2BasePath + "/auth/login",
+}
+
+// errNoRoute answers requests for paths no route is registered for
+var errNoRoute = services.NewError(services.KindNotFound, "route_not_found", "No endpoint matches the requested path")
+
 // Router wraps the gin engine
 type Router struct {
 	engine *gin.Engine
@@ -13,7 +33,7 @@ type Router struct {
 }
 
 // NewRouter creates a new router instance
-func NewRouter(cfg *config.Config) *Router {
+func NewRouter(cfg *config.Config, authService *services.AuthService, log *slog.Logger) *Router {
 	if cfg.Env == config.ReleaseEnv {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -21,8 +41,18 @@ func NewRouter(cfg *config.Config) *Router {
 	r := gin.New()
 
 	// Middleware
+	r.Use(middleware.RequestID())
 	r.Use(gin.Logger())
-	r.Use(gin.Recovery())
+	r.Use(middleware.Recovery())
+	r.Use(middleware.Problems(log))
+	r.Use(middleware.Authenticate(authService, middleware.AuthOptions{
+		PublicReads: cfg.Internal.Auth.PublicReads,
+		PublicPaths: publicWritePaths,
+	}))
+
+	r.NoRoute(func(c *gin.Context) {
+		_ = c.Error(errNoRoute)
+	})
 
 	return &Router{
 		engine: r,
@@ -1,22 +1,49 @@
 package routes
 
 import (
+	"github.com/gin-gonic/gin"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 	"music-service/internal/api/handlers"
+	"music-service/internal/api/middleware"
 	"music-service/internal/api/routes/path"
+	"music-service/internal/api/services"
 )
 
 func RegisterRoutes(router *Router,
 	groupHandler *handlers.GroupHandler,
 	songHandler *handlers.SongHandler,
+	artworkHandler *handlers.ArtworkHandler,
+	playlistHandler *handlers.PlaylistHandler,
+	smartPlaylistHandler *handlers.SmartPlaylistHandler,
+	authHandler *handlers.AuthHandler,
+	userHandler *handlers.UserHandler,
+	libraryHandler *handlers.LibraryHandler,
+	ratingHandler *handlers.RatingHandler,
+	trashHandler *handlers.TrashHandler,
+	idempotencyService *services.IdempotencyService,
 ) {
 	// Swagger docs
 	router.Engine().GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
-	api := router.Engine().Group("/api/v1")
-	{
-		path.RegisterGroupRoutes(api, groupHandler)
-		path.RegisterSongRoutes(api, songHandler)
+	// Uploaded files
+	router.Engine().Static(router.config.Internal.Storage.BaseURL, router.config.Internal.Storage.Path)
+
+	// Create routes replay their first response when retried with the same Idempotency-Key
+	idempotent := middleware.Idempotency(idempotencyService)
+
+	register := func(api *gin.RouterGroup) {
+		path.RegisterAuthRoutes(api, authHandler)
+		path.RegisterGroupRoutes(api, groupHandler, idempotent)
+		path.RegisterSongRoutes(api, songHandler, idempotent)
+		path.RegisterArtworkRoutes(api, artworkHandler)
+		path.RegisterPlaylistRoutes(api, playlistHandler, idempotent)
+		path.RegisterSmartPlaylistRoutes(api, smartPlaylistHandler, idempotent)
+		path.RegisterRatingRoutes(api, ratingHandler)
+		path.RegisterMeRoutes(api, libraryHandler, idempotent)
+		path.RegisterTrashRoutes(api, trashHandler)
+		path.RegisterAdminRoutes(api, userHandler, trashHandler)
 	}
+	register(router.Engine().Group(apiBasePath))
+	register(router.Engine().Group(apiV2BasePath, handlers.UseAPIVersion(handlers.APIVersion2)))
 }
@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"io"
+	"music-service/internal/pkg/utils/imaging"
+	"music-service/internal/storage/blob"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+)
+
+// ArtworkService handles cover art uploads and thumbnail generation
+type ArtworkService struct {
+	artworkRepo repository.ArtworkRepositoryInterface
+	storage     blob.StorageInterface
+}
+
+// NewArtworkService creates a new artwork service
+func NewArtworkService(artworkRepo repository.ArtworkRepositoryInterface, storage blob.StorageInterface) *ArtworkService {
+	return &ArtworkService{
+		artworkRepo: artworkRepo,
+		storage:     storage,
+	}
+}
+
+// UploadArtwork decodes the image, stores the original with every thumbnail and records the artwork.
+// The original of a replaced artwork is deleted when it was stored in another format.
+func (s *ArtworkService) UploadArtwork(ctx context.Context, entityType string, entityID uuid.UUID, r io.ReadSeeker) (database.Artwork, error) {
+	img, format, err := imaging.Decode(r)
+	if err != nil {
+		return database.Artwork{}, err
+	}
+
+	previous, replaced, err := s.GetArtwork(ctx, entityType, entityID)
+	if err != nil {
+		return database.Artwork{}, err
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return database.Artwork{}, err
+	}
+	if err = s.storage.Put(ctx, originalKey(entityType, entityID, format), r); err != nil {
+		return database.Artwork{}, fmt.Errorf("failed to store original image: %w", err)
+	}
+
+	for _, size := range imaging.ThumbnailSizes {
+		var buf bytes.Buffer
+		if err = imaging.EncodeJPEG(&buf, imaging.Thumbnail(img, size.Size)); err != nil {
+			return database.Artwork{}, fmt.Errorf("failed to encode %s thumbnail: %w", size.Name, err)
+		}
+		if err = s.storage.Put(ctx, thumbnailKey(entityType, entityID, size.Name), &buf); err != nil {
+			return database.Artwork{}, fmt.Errorf("failed to store %s thumbnail: %w", size.Name, err)
+		}
+	}
+
+	bounds := img.Bounds()
+	artwork, err := s.artworkRepo.UpsertArtwork(ctx, repository.ArtworkUpsertParams{
+		EntityType: entityType,
+		EntityID:   entityID,
+		Width:      int32(bounds.Dx()),
+		Height:     int32(bounds.Dy()),
+		Format:     format,
+	})
+	if err != nil {
+		return database.Artwork{}, err
+	}
+
+	// Thumbnails are always JPEG and were overwritten above, only the original can be left behind
+	if oldKey := originalKey(entityType, entityID, previous.Format); replaced && oldKey != originalKey(entityType, entityID, format) {
+		if err = s.storage.Delete(ctx, oldKey); err != nil {
+			return database.Artwork{}, fmt.Errorf("failed to delete previous original %s: %w", oldKey, err)
+		}
+	}
+	return artwork, nil
+}
+
+// GetArtwork returns the artwork of an entity, ok is false when none was uploaded
+func (s *ArtworkService) GetArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (database.Artwork, bool, error) {
+	artwork, err := s.artworkRepo.GetArtwork(ctx, entityType, entityID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Artwork{}, false, nil
+	}
+	if err != nil {
+		return database.Artwork{}, false, err
+	}
+	return artwork, true, nil
+}
+
+// GetArtworks returns the artworks of several entities of one type keyed by entity id
+func (s *ArtworkService) GetArtworks(ctx context.Context, entityType string, entityIDs []uuid.UUID) (map[uuid.UUID]database.Artwork, error) {
+	artworks := make(map[uuid.UUID]database.Artwork)
+	if len(entityIDs) == 0 {
+		return artworks, nil
+	}
+
+	rows, err := s.artworkRepo.GetArtworksByEntities(ctx, entityType, entityIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		artworks[row.EntityID.Bytes] = row
+	}
+	return artworks, nil
+}
+
+// DeleteArtwork removes the artwork record and every stored rendition, ok is false when none existed
+func (s *ArtworkService) DeleteArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (bool, error) {
+	artwork, ok, err := s.GetArtwork(ctx, entityType, entityID)
+	if err != nil || !ok {
+		return false, err
+	}
+
+	if _, err = s.artworkRepo.DeleteArtwork(ctx, entityType, entityID); err != nil {
+		return false, err
+	}
+
+	return true, s.DeleteArtworkFiles(ctx, artwork)
+}
+
+// DeleteArtworkFiles removes every stored rendition of an artwork whose record is already gone
+func (s *ArtworkService) DeleteArtworkFiles(ctx context.Context, artwork database.Artwork) error {
+	entityID := uuid.UUID(artwork.EntityID.Bytes)
+	keys := []string{originalKey(artwork.EntityType, entityID, artwork.Format)}
+	for _, size := range imaging.ThumbnailSizes {
+		keys = append(keys, thumbnailKey(artwork.EntityType, entityID, size.Name))
+	}
+	for _, key := range keys {
+		if err := s.storage.Delete(ctx, key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// ArtworkURLs returns the public URL of the original and of every thumbnail keyed by size name.
+// updated_at is appended as a version so clients and caches pick up replaced images.
+func (s *ArtworkService) ArtworkURLs(artwork database.Artwork) map[string]string {
+	entityID := uuid.UUID(artwork.EntityID.Bytes)
+	version := fmt.Sprintf("?v=%d", artwork.UpdatedAt.Time.Unix())
+
+	urls := map[string]string{
+		"original": s.storage.URL(originalKey(artwork.EntityType, entityID, artwork.Format)) + version,
+	}
+	for _, size := range imaging.ThumbnailSizes {
+		urls[size.Name] = s.storage.URL(thumbnailKey(artwork.EntityType, entityID, size.Name)) + version
+	}
+	return urls
+}
+
+func originalKey(entityType string, entityID uuid.UUID, format string) string {
+	ext := "jpg"
+	if format == imaging.FormatPNG {
+		ext = "png"
+	}
+	return fmt.Sprintf("artwork/%s/%s/original.%s", entityType, entityID, ext)
+}
+
+func thumbnailKey(entityType string, entityID uuid.UUID, size string) string {
+	return fmt.Sprintf("artwork/%s/%s/%s.jpg", entityType, entityID, size)
+}
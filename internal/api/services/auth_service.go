@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"golang.org/x/crypto/bcrypt"
+	"music-service/internal/config"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyPrefix marks API keys so they can be told apart from JWTs and recognised in leaked text
+	APIKeyPrefix = "msk_"
+	// apiKeyDisplayLength is how many leading characters of a key are kept to identify it in listings
+	apiKeyDisplayLength = 12
+
+	MinPasswordLength = 8
+)
+
+var (
+	ErrInvalidCredentials = NewError(KindUnauthenticated, "invalid_credentials", "Invalid username or password")
+	ErrUsernameTaken      = NewError(KindConflict, "username_taken", "Username is already taken")
+	ErrInvalidToken       = NewError(KindUnauthenticated, "invalid_credentials", "Invalid or expired token")
+	ErrInvalidAPIKey      = NewError(KindUnauthenticated, "invalid_credentials", "Invalid, expired or revoked API key")
+	ErrAPIKeyNotFound     = NewError(KindNotFound, "api_key_not_found", "API key not found")
+	ErrUserNotFound       = NewError(KindNotFound, "user_not_found", "User not found")
+
+	// ErrAdminUsernameTaken is returned at startup when the configured admin username belongs to a user who is not an admin
+	ErrAdminUsernameTaken = errors.New("configured admin username belongs to a user who is not an admin")
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	UserID   uuid.UUID
+	Username string
+	Role     string
+	APIKeyID *uuid.UUID // set when the request was authenticated with an API key
+}
+
+// tokenClaims are the claims carried by issued access tokens, the subject is the user ID
+type tokenClaims struct {
+	Username string `json:"username"`
+	jwt.RegisteredClaims
+}
+
+// AuthService handles user accounts, access tokens and API keys
+type AuthService struct {
+	userRepo repository.UserRepositoryInterface
+	secret   []byte
+	tokenTTL time.Duration
+	appName  string
+}
+
+// NewAuthService creates a new auth service
+func NewAuthService(cfg *config.Config, userRepo repository.UserRepositoryInterface) *AuthService {
+	return &AuthService{
+		userRepo: userRepo,
+		secret:   []byte(cfg.Internal.Auth.JWTSecret),
+		tokenTTL: cfg.Internal.Auth.TokenTTL,
+		appName:  cfg.AppName,
+	}
+}
+
+// NormalizeUsername trims and lower-cases a username, usernames are unique case-insensitively
+func NormalizeUsername(username string) string {
+	return strings.ToLower(strings.TrimSpace(username))
+}
+
+// Register creates a user with a bcrypt hash of the password
+func (s *AuthService) Register(ctx context.Context, username, password string) (database.User, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return database.User{}, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	user, err := s.userRepo.CreateUser(ctx, NormalizeUsername(username), string(hash), RoleViewer)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return database.User{}, ErrUsernameTaken
+	}
+	return user, err
+}
+
+// EnsureAdmin creates the configured admin account unless a user with its username exists.
+// It reports whether the account was created, an existing admin keeps its password.
+func (s *AuthService) EnsureAdmin(ctx context.Context, username, password string) (bool, error) {
+	username = NormalizeUsername(username)
+
+	user, err := s.userRepo.GetUserByUsername(ctx, username)
+	if err == nil {
+		if user.Role != RoleAdmin {
+			return false, ErrAdminUsernameTaken
+		}
+		return false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return false, err
+	}
+
+	if len(password) < MinPasswordLength {
+		return false, fmt.Errorf("admin password must be at least %d characters", MinPasswordLength)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return false, fmt.Errorf("failed to hash password: %w", err)
+	}
+
+	_, err = s.userRepo.CreateUser(ctx, username, string(hash), RoleAdmin)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		// Another instance created it first
+		return s.EnsureAdmin(ctx, username, password)
+	}
+	return err == nil, err
+}
+
+// Login checks the credentials and issues a signed access token
+func (s *AuthService) Login(ctx context.Context, username, password string) (database.User, string, time.Time, error) {
+	user, err := s.userRepo.GetUserByUsername(ctx, NormalizeUsername(username))
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Compare against a throwaway hash so unknown usernames take as long as wrong passwords
+		_ = bcrypt.CompareHashAndPassword(dummyPasswordHash, []byte(password))
+		return database.User{}, "", time.Time{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return database.User{}, "", time.Time{}, err
+	}
+
+	if err = bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return database.User{}, "", time.Time{}, ErrInvalidCredentials
+	}
+
+	token, expiresAt, err := s.issueToken(user)
+	if err != nil {
+		return database.User{}, "", time.Time{}, err
+	}
+	return user, token, expiresAt, nil
+}
+
+func (s *AuthService) issueToken(user database.User) (string, time.Time, error) {
+	now := time.Now()
+	expiresAt := now.Add(s.tokenTTL)
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, tokenClaims{
+		Username: user.Username,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID.String(),
+			Issuer:    s.appName,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(expiresAt),
+		},
+	})
+
+	signed, err := token.SignedString(s.secret)
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("failed to sign token: %w", err)
+	}
+	return signed, expiresAt, nil
+}
+
+// AuthenticateToken verifies an access token and returns its principal.
+// The user is loaded on every request so role changes apply without waiting for the token to expire.
+func (s *AuthService) AuthenticateToken(ctx context.Context, tokenString string) (Principal, error) {
+	var claims tokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &claims, func(*jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.appName),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return Principal{}, ErrInvalidToken
+	}
+
+	userID, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Principal{}, ErrInvalidToken
+	}
+
+	user, err := s.userRepo.GetUser(ctx, userID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrInvalidToken
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{UserID: userID, Username: user.Username, Role: user.Role}, nil
+}
+
+// AuthenticateAPIKey looks up an active API key and returns the principal of its owner
+func (s *AuthService) AuthenticateAPIKey(ctx context.Context, key string) (Principal, error) {
+	if !strings.HasPrefix(key, APIKeyPrefix) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.userRepo.GetActiveApiKeyByHash(ctx, hashAPIKey(key))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	user, err := s.userRepo.GetUser(ctx, apiKey.UserID.Bytes)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return Principal{}, ErrInvalidAPIKey
+	}
+	if err != nil {
+		return Principal{}, err
+	}
+
+	if err = s.userRepo.TouchApiKey(ctx, apiKey.ID.Bytes); err != nil {
+		return Principal{}, err
+	}
+
+	keyID := uuid.UUID(apiKey.ID.Bytes)
+	return Principal{UserID: user.ID.Bytes, Username: user.Username, Role: user.Role, APIKeyID: &keyID}, nil
+}
+
+func (s *AuthService) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	user, err := s.userRepo.GetUser(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.User{}, ErrUserNotFound
+	}
+	return user, err
+}
+
+// CreateAPIKey generates a new API key for the user, the plain key is only ever returned here
+func (s *AuthService) CreateAPIKey(ctx context.Context, userID uuid.UUID, name string, expiresAt *time.Time) (database.ApiKey, string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return database.ApiKey{}, "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	key := APIKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey, err := s.userRepo.CreateApiKey(ctx, repository.ApiKeyCreateParams{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   hashAPIKey(key),
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return database.ApiKey{}, "", err
+	}
+	return apiKey, key, nil
+}
+
+func (s *AuthService) GetAPIKeys(ctx context.Context, userID uuid.UUID) ([]database.ApiKey, error) {
+	return s.userRepo.GetApiKeysByUser(ctx, userID)
+}
+
+func (s *AuthService) RevokeAPIKey(ctx context.Context, userID, id uuid.UUID) error {
+	revoked, err := s.userRepo.RevokeApiKey(ctx, id, userID)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	return nil
+}
+
+// hashAPIKey returns the hex SHA-256 of a key, keys carry enough entropy that a slow hash is not needed
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// dummyPasswordHash is a bcrypt hash of a random string, used to keep failed logins constant time
+var dummyPasswordHash, _ = bcrypt.GenerateFromPassword([]byte(uuid.NewString()), bcrypt.DefaultCost)
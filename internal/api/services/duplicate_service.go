@@ -0,0 +1,301 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"math"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+const (
+	DefaultDuplicateMinScore = 0.75
+
+	// Weights of the signals in the duplicate score, the lyrics weight is left out when either song has no lyrics
+	duplicateTitleWeight   = 0.4
+	duplicateGroupWeight   = 0.2
+	duplicateRuntimeWeight = 0.15
+	duplicateLyricsWeight  = 0.25
+
+	// duplicateRuntimeTolerance is the runtime difference in seconds at which the runtime signal drops to zero
+	duplicateRuntimeTolerance = 30
+	// duplicateMinTitleSimilarity skips pairs in the same group whose titles are too far apart to be worth scoring
+	duplicateMinTitleSimilarity = 0.5
+	// duplicateLyricsNeighbours is how many songs with the closest lyrics are compared with each song across groups
+	duplicateLyricsNeighbours = 5
+)
+
+var ErrInvalidMerge = NewValidationError("invalid_merge", "A song cannot be merged into itself")
+
+// bracketedText matches "(Remastered 2011)", "[Live]" and similar title decorations
+var bracketedText = regexp.MustCompile(`\([^)]*\)|\[[^\]]*]`)
+
+// DuplicateSignals are the similarities a duplicate score is made of, each from 0 to 1.
+// Lyrics is nil when either song has no lyrics.
+type DuplicateSignals struct {
+	Title   float64  `json:"title"`
+	Group   float64  `json:"group"`
+	Runtime float64  `json:"runtime"`
+	Lyrics  *float64 `json:"lyrics"`
+}
+
+// DuplicateCandidate is a pair of songs that are likely the same song
+type DuplicateCandidate struct {
+	Songs   [2]database.GetSongsForDuplicateScanRow
+	Score   float64
+	Signals DuplicateSignals
+}
+
+// MergeReport counts what was moved from the merged song to the kept one
+type MergeReport struct {
+	PlaylistEntries int64 `json:"playlist_entries"`
+	Favorites       int64 `json:"favorites"`
+	Ratings         int64 `json:"ratings"`
+	PlayEvents      int64 `json:"play_events"`
+	Tags            int64 `json:"tags"`
+}
+
+// DuplicateService finds songs entered more than once and merges them
+type DuplicateService struct {
+	dbManager         *repository.Manager
+	similarityService *SimilarityService
+}
+
+// NewDuplicateService creates a new duplicate service
+func NewDuplicateService(dbManager *repository.Manager, similarityService *SimilarityService) *DuplicateService {
+	return &DuplicateService{
+		dbManager:         dbManager,
+		similarityService: similarityService,
+	}
+}
+
+// FindDuplicates scores candidate pairs of live songs and returns those scoring at least minScore, best first.
+// Songs are compared with every other song of a group with the same normalised name
+// and with the songs whose lyrics are closest to theirs.
+func (s *DuplicateService) FindDuplicates(ctx context.Context, minScore float64) ([]DuplicateCandidate, error) {
+	songs, err := s.dbManager.Songs.GetSongsForDuplicateScan(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]database.GetSongsForDuplicateScanRow, len(songs))
+	blocks := make(map[string][]database.GetSongsForDuplicateScanRow)
+	for _, song := range songs {
+		byID[song.ID.Bytes] = song
+		key := normalizeName(song.GroupName)
+		blocks[key] = append(blocks[key], song)
+	}
+
+	seen := make(map[[2]uuid.UUID]bool)
+	var candidates []DuplicateCandidate
+	consider := func(a, b database.GetSongsForDuplicateScanRow, checkTitle bool) {
+		if bytes.Compare(a.ID.Bytes[:], b.ID.Bytes[:]) > 0 {
+			a, b = b, a
+		}
+		key := [2]uuid.UUID{a.ID.Bytes, b.ID.Bytes}
+		if seen[key] {
+			return
+		}
+		seen[key] = true
+
+		if checkTitle && stringSimilarity(normalizeTitle(a.Title), normalizeTitle(b.Title)) < duplicateMinTitleSimilarity {
+			return
+		}
+
+		candidate := s.scorePair(a, b)
+		if candidate.Score >= minScore {
+			candidates = append(candidates, candidate)
+		}
+	}
+
+	for _, block := range blocks {
+		for i := range block {
+			for j := i + 1; j < len(block); j++ {
+				consider(block[i], block[j], true)
+			}
+		}
+	}
+
+	for _, song := range songs {
+		for _, match := range s.similarityService.NearestSongs(song.ID.Bytes, duplicateLyricsNeighbours) {
+			if other, ok := byID[match.ID]; ok {
+				consider(song, other, false)
+			}
+		}
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		if candidates[i].Score != candidates[j].Score {
+			return candidates[i].Score > candidates[j].Score
+		}
+		return bytes.Compare(candidates[i].Songs[0].ID.Bytes[:], candidates[j].Songs[0].ID.Bytes[:]) < 0
+	})
+	return candidates, nil
+}
+
+func (s *DuplicateService) scorePair(a, b database.GetSongsForDuplicateScanRow) DuplicateCandidate {
+	signals := DuplicateSignals{
+		Title:   stringSimilarity(normalizeTitle(a.Title), normalizeTitle(b.Title)),
+		Runtime: math.Max(0, 1-math.Abs(float64(a.Runtime-b.Runtime))/duplicateRuntimeTolerance),
+	}
+	if a.GroupID == b.GroupID {
+		signals.Group = 1
+	} else {
+		signals.Group = stringSimilarity(normalizeName(a.GroupName), normalizeName(b.GroupName))
+	}
+
+	score := duplicateTitleWeight*signals.Title + duplicateGroupWeight*signals.Group + duplicateRuntimeWeight*signals.Runtime
+	weights := duplicateTitleWeight + duplicateGroupWeight + duplicateRuntimeWeight
+	if lyrics, ok := s.similarityService.LyricsSimilarity(a.ID.Bytes, b.ID.Bytes); ok {
+		signals.Lyrics = &lyrics
+		score += duplicateLyricsWeight * lyrics
+		weights += duplicateLyricsWeight
+	}
+
+	return DuplicateCandidate{
+		Songs:   [2]database.GetSongsForDuplicateScanRow{a, b},
+		Score:   score / weights,
+		Signals: signals,
+	}
+}
+
+// MergeSongs moves the playlist entries, favorites, ratings, plays and tags of the duplicate
+// to the kept song and soft-deletes the duplicate, all in one transaction.
+// It returns the kept song with its group
+func (s *DuplicateService) MergeSongs(ctx context.Context, keepID, duplicateID uuid.UUID) (database.GetSongsWithPaginationRow, MergeReport, error) {
+	if keepID == duplicateID {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, ErrInvalidMerge
+	}
+
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	for _, id := range []uuid.UUID{keepID, duplicateID} {
+		song, err := tx.Repos.Songs.GetSong(ctx, id)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, ErrSongNotFound
+		}
+		if err != nil {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+		}
+	}
+
+	// Lock the stats rows of both songs in a fixed order so concurrent plays, ratings and merges cannot deadlock
+	locked := []uuid.UUID{keepID, duplicateID}
+	if bytes.Compare(locked[0][:], locked[1][:]) > 0 {
+		locked[0], locked[1] = locked[1], locked[0]
+	}
+	for _, id := range locked {
+		if _, err = tx.Repos.Ratings.LockSongRatingStats(ctx, id); err != nil {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+		}
+		if _, err = tx.Repos.Library.LockSongPlayStats(ctx, id); err != nil {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+		}
+	}
+
+	var report MergeReport
+	if report.PlaylistEntries, err = tx.Repos.Playlists.MoveSongEntries(ctx, duplicateID, keepID); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+	if report.Favorites, err = tx.Repos.Library.MoveSongFavorites(ctx, duplicateID, keepID); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+	if report.PlayEvents, err = tx.Repos.Library.MoveSongPlayEvents(ctx, duplicateID, keepID); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+	if report.Ratings, err = tx.Repos.Ratings.MoveSongRatings(ctx, duplicateID, keepID); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+	for _, id := range locked {
+		if _, err = tx.Repos.Ratings.RecountSongRatingStats(ctx, id); err != nil {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+		}
+		if _, err = tx.Repos.Library.RecountSongPlayStats(ctx, id); err != nil {
+			return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+		}
+	}
+	if report.Tags, err = tx.Repos.Songs.CopySongTags(ctx, duplicateID, keepID); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+
+	if _, err = tx.Repos.Songs.DeleteSong(ctx, duplicateID, time.Time{}); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+
+	// Read the kept song with the moved plays and ratings counted
+	kept, err := tx.Repos.Songs.GetSongWithGroup(ctx, keepID, true)
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.GetSongsWithPaginationRow{}, MergeReport{}, err
+	}
+
+	s.similarityService.RemoveSong(duplicateID)
+	return kept, report, nil
+}
+
+// normalizeTitle drops bracketed decorations such as "(Remastered)" before normalising the title
+func normalizeTitle(title string) string {
+	return normalizeName(bracketedText.ReplaceAllString(title, " "))
+}
+
+// normalizeName lower-cases a name, drops punctuation and a leading "the" and collapses whitespace
+func normalizeName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) || unicode.IsNumber(r) {
+			return unicode.ToLower(r)
+		}
+		return ' '
+	}, name)
+
+	words := strings.Fields(name)
+	if len(words) > 1 && words[0] == "the" {
+		words = words[1:]
+	}
+	return strings.Join(words, " ")
+}
+
+// stringSimilarity is one minus the edit distance of the strings relative to the longer one
+func stringSimilarity(a, b string) float64 {
+	ra, rb := []rune(a), []rune(b)
+	longest := max(len(ra), len(rb))
+	if longest == 0 {
+		return 1
+	}
+	return 1 - float64(levenshtein(ra, rb))/float64(longest)
+}
+
+func levenshtein(a, b []rune) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
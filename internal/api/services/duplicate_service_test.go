@@ -0,0 +1,200 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"music-service/internal/storage/database/dbtest"
+	"music-service/internal/storage/database/repository"
+	"testing"
+	"time"
+)
+
+func TestMergeSongsRatings(t *testing.T) {
+	pool := dbtest.Open(t)
+	m := repository.NewManager(pool)
+	ratings := NewRatingService(m)
+	duplicates := NewDuplicateService(m, NewSimilarityService(m.Songs, slog.New(slog.DiscardHandler)))
+	ctx := context.Background()
+
+	users := []uuid.UUID{createTestUser(t, m, "alice"), createTestUser(t, m, "bob")}
+	groupID := createTestGroup(t, m, "Merges")
+
+	// rating is applied in order, duplicate picks the song merged away instead of the kept one
+	type rating struct {
+		duplicate bool
+		user      int
+		rating    int32
+	}
+
+	tests := []struct {
+		name        string
+		ratings     []rating
+		wantMoved   int64
+		wantCount   int32
+		wantSum     int32
+		wantAverage float64
+	}{
+		{
+			name:        "only the duplicate was rated",
+			ratings:     []rating{{duplicate: true, user: 0, rating: 4}},
+			wantMoved:   1,
+			wantCount:   1,
+			wantSum:     4,
+			wantAverage: 4,
+		},
+		{
+			name:        "different users rated the songs",
+			ratings:     []rating{{user: 0, rating: 2}, {duplicate: true, user: 1, rating: 4}},
+			wantMoved:   1,
+			wantCount:   2,
+			wantSum:     6,
+			wantAverage: 3,
+		},
+		{
+			name:        "same user rated the duplicate last",
+			ratings:     []rating{{user: 0, rating: 2}, {duplicate: true, user: 0, rating: 5}},
+			wantMoved:   1,
+			wantCount:   1,
+			wantSum:     5,
+			wantAverage: 5,
+		},
+		{
+			name:        "same user rated the kept song last",
+			ratings:     []rating{{duplicate: true, user: 0, rating: 5}, {user: 0, rating: 2}},
+			wantMoved:   0,
+			wantCount:   1,
+			wantSum:     2,
+			wantAverage: 2,
+		},
+		{
+			name: "same user rated both and another user only the duplicate",
+			ratings: []rating{
+				{user: 0, rating: 1},
+				{duplicate: true, user: 0, rating: 3},
+				{duplicate: true, user: 1, rating: 5},
+			},
+			wantMoved:   2,
+			wantCount:   2,
+			wantSum:     8,
+			wantAverage: 4,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepID := createTestSong(t, m, groupID, tt.name)
+			duplicateID := createTestSong(t, m, groupID, tt.name+" (Remastered)")
+
+			for _, r := range tt.ratings {
+				songID := keepID
+				if r.duplicate {
+					songID = duplicateID
+				}
+				if _, err := ratings.RateSong(ctx, users[r.user], songID, r.rating); err != nil {
+					t.Fatalf("rating song: %v", err)
+				}
+			}
+
+			_, report, err := duplicates.MergeSongs(ctx, keepID, duplicateID)
+			if err != nil {
+				t.Fatalf("merging songs: %v", err)
+			}
+			if report.Ratings != tt.wantMoved {
+				t.Errorf("moved ratings = %d, want %d", report.Ratings, tt.wantMoved)
+			}
+
+			got := getRatingStats(t, pool, keepID)
+			if got.RatingCount != tt.wantCount || got.RatingSum != tt.wantSum || got.RatingAverage != tt.wantAverage {
+				t.Errorf("kept song stats = count %d, sum %d, average %v, want count %d, sum %d, average %v",
+					got.RatingCount, got.RatingSum, got.RatingAverage, tt.wantCount, tt.wantSum, tt.wantAverage)
+			}
+			if dup := getRatingStats(t, pool, duplicateID); dup.RatingCount != 0 || dup.RatingSum != 0 {
+				t.Errorf("duplicate stats = count %d, sum %d, want none", dup.RatingCount, dup.RatingSum)
+			}
+
+			assertRatingStatsMatchRatings(t, pool, keepID)
+			assertRatingStatsMatchRatings(t, pool, duplicateID)
+
+			duplicate, err := m.Songs.GetSong(ctx, duplicateID)
+			if err != nil {
+				t.Fatalf("getting duplicate: %v", err)
+			}
+			if !duplicate.DeletedAt.Valid {
+				t.Error("duplicate was not deleted")
+			}
+		})
+	}
+}
+
+func TestMergeSongsPlays(t *testing.T) {
+	pool := dbtest.Open(t)
+	m := repository.NewManager(pool)
+	duplicates := NewDuplicateService(m, NewSimilarityService(m.Songs, slog.New(slog.DiscardHandler)))
+	ctx := context.Background()
+
+	userID := createTestUser(t, m, "alice")
+	groupID := createTestGroup(t, m, "Merges")
+
+	tests := []struct {
+		name           string
+		keptPlays      int
+		duplicatePlays int
+		wantCount      int64
+	}{
+		{"no plays", 0, 0, 0},
+		{"only the duplicate was played", 0, 3, 3},
+		{"only the kept song was played", 2, 0, 2},
+		{"both were played", 2, 3, 5},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keepID := createTestSong(t, m, groupID, tt.name)
+			duplicateID := createTestSong(t, m, groupID, tt.name+" (Remastered)")
+
+			play := func(songID uuid.UUID, plays int) {
+				for i := 0; i < plays; i++ {
+					if _, err := m.Library.CreatePlayEvent(ctx, repository.PlayEventCreateParams{
+						UserID:   userID,
+						SongID:   songID,
+						PlayedAt: time.Now(),
+					}); err != nil {
+						t.Fatalf("recording play: %v", err)
+					}
+				}
+			}
+			play(keepID, tt.keptPlays)
+			play(duplicateID, tt.duplicatePlays)
+
+			kept, report, err := duplicates.MergeSongs(ctx, keepID, duplicateID)
+			if err != nil {
+				t.Fatalf("merging songs: %v", err)
+			}
+			if report.PlayEvents != int64(tt.duplicatePlays) {
+				t.Errorf("moved plays = %d, want %d", report.PlayEvents, tt.duplicatePlays)
+			}
+			if kept.PlayCount != tt.wantCount {
+				t.Errorf("kept song play count = %d, want %d", kept.PlayCount, tt.wantCount)
+			}
+			if got := getPlayCount(t, pool, duplicateID); got != 0 {
+				t.Errorf("duplicate play count = %d, want 0", got)
+			}
+		})
+	}
+}
+
+// getPlayCount reads the maintained play count of a song, zero when it has no stats row
+func getPlayCount(t *testing.T, pool *pgxpool.Pool, songID uuid.UUID) int64 {
+	t.Helper()
+
+	var count int64
+	err := pool.QueryRow(context.Background(), "SELECT play_count FROM song_play_stats WHERE song_id = $1", songID).Scan(&count)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("reading play stats: %v", err)
+	}
+	return count
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"errors"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"music-service/internal/storage/database/repository"
+)
+
+// ErrorKind classifies errors so the API answers every error of a kind with the same status
+type ErrorKind string
+
+const (
+	KindValidation           ErrorKind = "validation"             // the request is malformed or a value is invalid
+	KindNotFound             ErrorKind = "not_found"              // the addressed entity does not exist
+	KindConflict             ErrorKind = "conflict"               // the request clashes with the current state
+	KindReference            ErrorKind = "reference"              // the request refers to an entity that does not exist
+	KindUnprocessable        ErrorKind = "unprocessable"          // the request is well-formed but cannot be carried out
+	KindPreconditionFailed   ErrorKind = "precondition_failed"    // the entity changed since the version the request names
+	KindPreconditionRequired ErrorKind = "precondition_required"  // the request must name the version it changes
+	KindUnsupportedMedia     ErrorKind = "unsupported_media_type" // the body is in a format the endpoint does not accept
+	KindTooLarge             ErrorKind = "too_large"              // the body is larger than the endpoint accepts
+	KindUnauthenticated      ErrorKind = "unauthenticated"        // the caller is not known
+	KindForbidden            ErrorKind = "forbidden"              // the caller may not do this
+)
+
+// Error is an error with a stable machine-readable code and a message that is safe to show to clients
+type Error struct {
+	Kind    ErrorKind
+	Code    string
+	Message string
+	Fields  []FieldError   // the invalid fields of a validation error
+	Details map[string]any // further members for clients, such as the ID of a conflicting entity
+	Err     error          // the underlying cause, which is logged but never shown
+}
+
+// FieldError explains why a field of the request is invalid
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// NewError creates an error of the given kind
+func NewError(kind ErrorKind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+// NewValidationError creates a validation error for the given fields
+func NewValidationError(code, message string, fields ...FieldError) *Error {
+	return &Error{Kind: KindValidation, Code: code, Message: message, Fields: fields}
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return e.Message + ": " + e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches errors of the same kind and code, so copies made with the With methods still match the original
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Kind == e.Kind && t.Code == e.Code
+}
+
+// WithMessage returns a copy of the error with another message
+func (e *Error) WithMessage(message string) *Error {
+	c := *e
+	c.Message = message
+	return &c
+}
+
+// WithFields returns a copy of the error with the given invalid fields
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	c := *e
+	c.Fields = fields
+	return &c
+}
+
+// WithDetail returns a copy of the error with a further member for clients
+func (e *Error) WithDetail(key string, value any) *Error {
+	c := *e
+	c.Details = make(map[string]any, len(e.Details)+1)
+	for k, v := range e.Details {
+		c.Details[k] = v
+	}
+	c.Details[key] = value
+	return &c
+}
+
+// Wrap returns a copy of the error caused by err
+func (e *Error) Wrap(err error) *Error {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// ErrInvalidBody is returned for request bodies that cannot be read or decoded
+var ErrInvalidBody = NewValidationError("invalid_body", "The request body is invalid")
+
+// ErrInvalidCursor is returned for cursors that were not issued by the listing they are sent to
+var ErrInvalidCursor = NewValidationError("invalid_cursor", "The cursor is invalid",
+	FieldError{Field: "cursor", Message: "must be a cursor returned by the same listing with the same sort"})
+
+// cursorError reports cursors the repository could not use as ErrInvalidCursor
+func cursorError(err error) error {
+	if errors.Is(err, repository.ErrInvalidCursor) {
+		return ErrInvalidCursor
+	}
+	return err
+}
+
+// PostgreSQL error codes of constraint violations, see https://www.postgresql.org/docs/current/errcodes-appendix.html
+const (
+	foreignKeyViolationCode = "23503"
+	uniqueViolationCode     = "23505"
+	checkViolationCode      = "23514"
+	stringTooLongCode       = "22001"
+)
+
+// Errors for database failures that no service turned into a more specific error
+var (
+	ErrNotFound           = NewError(KindNotFound, "not_found", "The requested entity does not exist")
+	ErrReferenceNotFound  = NewError(KindReference, "reference_not_found", "The request refers to an entity that does not exist")
+	ErrAlreadyExists      = NewError(KindConflict, "already_exists", "An entity with these values already exists")
+	ErrConstraintViolated = NewError(KindValidation, "invalid_value", "A value is not allowed")
+)
+
+// AsError returns err as an *Error. Missing rows and constraint violations from the database become the
+// matching general errors, other errors are unexpected and nil is returned.
+func AsError(err error) *Error {
+	var serviceErr *Error
+	if errors.As(err, &serviceErr) {
+		return serviceErr
+	}
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrNotFound.Wrap(err)
+	}
+
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return nil
+	}
+	switch pgErr.Code {
+	case foreignKeyViolationCode:
+		return ErrReferenceNotFound.Wrap(err)
+	case uniqueViolationCode:
+		return ErrAlreadyExists.Wrap(err)
+	case checkViolationCode, stringTooLongCode:
+		return ErrConstraintViolated.Wrap(err)
+	}
+	return nil
+}
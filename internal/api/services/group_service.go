@@ -2,43 +2,440 @@ package services
 
 import (
 	"context"
+	"errors"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"music-service/internal/storage/database"
 	"music-service/internal/storage/database/repository"
+	"regexp"
+	"strings"
+	"time"
 )
 
+// Alias types, search aliases are alternative spellings that only help people find the group
+const (
+	AliasTypeLegal  = "legal"
+	AliasTypeStage  = "stage"
+	AliasTypeFormer = "former"
+	AliasTypeSearch = "search"
+)
+
+var AliasTypes = []string{AliasTypeLegal, AliasTypeStage, AliasTypeFormer, AliasTypeSearch}
+
+var (
+	ErrInvalidGroupMerge = NewValidationError("invalid_group_merge", "A group cannot be merged into itself")
+	ErrInvalidAlias      = NewValidationError("invalid_alias", "Alias needs a name, a type of legal, stage, former or search and an optional language tag as locale")
+	ErrAliasTaken        = NewError(KindConflict, "alias_taken", "The group already has this alias")
+	ErrAliasNotFound     = NewError(KindNotFound, "alias_not_found", "Alias not found")
+	ErrGroupNameTaken    = NewError(KindConflict, "group_name_taken", "A group with this name already exists")
+	ErrInvalidGroupName  = NewValidationError("invalid_group_name", "Group name cannot be blank", FieldError{Field: "name", Message: "cannot be blank"})
+)
+
+// groupNameIndex is the unique index on the normalised names of live groups
+const groupNameIndex = "uq_groups_name_normalized"
+
+// GroupNameConflictError is returned when another live group already has the name, it matches ErrGroupNameTaken
+type GroupNameConflictError struct {
+	Existing database.Group
+}
+
+func (e *GroupNameConflictError) Error() string {
+	return ErrGroupNameTaken.Error()
+}
+
+func (e *GroupNameConflictError) Is(target error) bool {
+	return target == ErrGroupNameTaken
+}
+
+// Unwrap exposes the conflict as ErrGroupNameTaken naming the group that holds the name
+func (e *GroupNameConflictError) Unwrap() error {
+	return ErrGroupNameTaken.WithDetail("existing_id", uuid.UUID(e.Existing.ID.Bytes).String())
+}
+
+// localePattern accepts BCP 47 style language tags such as "ja", "pt-BR" or "sr-Latn"
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// GroupMergeSource is a group merged into another and its number of live songs
+type GroupMergeSource struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Songs int64  `json:"songs"`
+}
+
+// GroupMergeReport describes what a group merge moved, or would move in a dry run
+type GroupMergeReport struct {
+	DryRun         bool               `json:"dry_run"`
+	Target         database.Group     `json:"target"`
+	Sources        []GroupMergeSource `json:"sources"`
+	SongsMoved     int64              `json:"songs_moved"`
+	FavoritesMoved int64              `json:"favorites_moved"`
+	AliasesAdded   int64              `json:"aliases_added"`
+}
+
 // GroupService handles business logic for groups
 type GroupService struct {
-	groupRepo repository.GroupRepositoryInterface
+	groupRepo         repository.GroupRepositoryInterface
+	dbManager         *repository.Manager
+	similarityService *SimilarityService
 }
 
 // NewGroupService creates a new group service
-func NewGroupService(groupRepo repository.GroupRepositoryInterface) *GroupService {
+func NewGroupService(groupRepo repository.GroupRepositoryInterface, dbManager *repository.Manager, similarityService *SimilarityService) *GroupService {
 	return &GroupService{
-		groupRepo: groupRepo,
+		groupRepo:         groupRepo,
+		dbManager:         dbManager,
+		similarityService: similarityService,
 	}
 }
 
+// CreateGroup creates a group with the trimmed name, names are unique among live groups ignoring case
 func (s *GroupService) CreateGroup(ctx context.Context, name string) (database.Group, error) {
-	return s.groupRepo.CreateGroup(ctx, name)
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return database.Group{}, ErrInvalidGroupName
+	}
+
+	group, err := s.groupRepo.CreateGroup(ctx, name)
+	if err != nil {
+		return database.Group{}, s.nameConflict(ctx, name, err)
+	}
+	return group, nil
+}
+
+// GetOrCreateGroup returns the live group with the name, creating it when there is none.
+// The boolean reports whether the group was created.
+func (s *GroupService) GetOrCreateGroup(ctx context.Context, name string) (database.Group, bool, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return database.Group{}, false, ErrInvalidGroupName
+	}
+
+	group, err := s.groupRepo.GetGroupByNormalizedName(ctx, name)
+	if err == nil {
+		return group, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return database.Group{}, false, err
+	}
+
+	group, err = s.CreateGroup(ctx, name)
+	// Another request created it in the meantime
+	var conflict *GroupNameConflictError
+	if errors.As(err, &conflict) {
+		return conflict.Existing, false, nil
+	}
+	if err != nil {
+		return database.Group{}, false, err
+	}
+	return group, true, nil
 }
 
+// GetGroup returns a live group, deleted groups are reported as ErrGroupNotFound
 func (s *GroupService) GetGroup(ctx context.Context, id uuid.UUID) (database.Group, error) {
-	return s.groupRepo.GetGroup(ctx, id)
+	return getLiveGroup(ctx, s.groupRepo, id)
 }
 
 func (s *GroupService) GetGroupsCount(ctx context.Context) (int64, error) {
 	return s.groupRepo.GetGroupsCount(ctx)
 }
 
+// SearchGroupsWithPagination returns groups whose name or one of whose aliases contains name
+func (s *GroupService) SearchGroupsWithPagination(ctx context.Context, name string, limit, offset int32) ([]database.GetGroupsWithPaginationRow, error) {
+	return s.groupRepo.SearchGroupsWithPagination(ctx, name, limit, offset)
+}
+
+func (s *GroupService) SearchGroupsCount(ctx context.Context, name string) (int64, error) {
+	return s.groupRepo.SearchGroupsCount(ctx, name)
+}
+
+// GetGroupsWithCursor returns the page of groups after or before cursor together with the cursors around it
+func (s *GroupService) GetGroupsWithCursor(ctx context.Context, name string, limit int32, cursor string) ([]database.GetGroupsWithPaginationRow, repository.CursorPage, error) {
+	groups, page, err := s.groupRepo.GetGroupsWithCursor(ctx, name, limit, cursor)
+	return groups, page, cursorError(err)
+}
+
 func (s *GroupService) GetGroupsWithPagination(ctx context.Context, limit, offset int32) ([]database.GetGroupsWithPaginationRow, error) {
 	return s.groupRepo.GetGroupsWithPagination(ctx, limit, offset)
 }
 
-func (s *GroupService) UpdateGroup(ctx context.Context, id uuid.UUID, name string) (database.Group, error) {
-	return s.groupRepo.UpdateGroup(ctx, id, name)
+// UpdateGroup renames a live group. With a non-zero ifUpdatedAt the group is only renamed if it is still
+// at that version, otherwise ErrPreconditionFailed is returned.
+func (s *GroupService) UpdateGroup(ctx context.Context, id uuid.UUID, name string, ifUpdatedAt time.Time) (database.Group, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return database.Group{}, ErrInvalidGroupName
+	}
+
+	if _, err := getLiveGroup(ctx, s.groupRepo, id); err != nil {
+		return database.Group{}, err
+	}
+
+	group, err := s.groupRepo.UpdateGroup(ctx, id, name, ifUpdatedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Group{}, groupWriteMissed(ctx, s.groupRepo, id)
+	}
+	if err != nil {
+		return database.Group{}, s.nameConflict(ctx, name, err)
+	}
+	return group, nil
 }
 
-func (s *GroupService) DeleteGroup(ctx context.Context, id uuid.UUID) error {
-	return s.groupRepo.DeleteGroup(ctx, id)
+// PatchGroup updates the supplied fields of a live group, a new name is trimmed and must be free.
+// With a non-zero IfUpdatedAt the group must still be at that version.
+func (s *GroupService) PatchGroup(ctx context.Context, params repository.GroupPatchParams) (database.Group, error) {
+	if params.Name != nil {
+		name := strings.TrimSpace(*params.Name)
+		if name == "" {
+			return database.Group{}, ErrInvalidGroupName
+		}
+		params.Name = &name
+	}
+
+	current, err := getLiveGroup(ctx, s.groupRepo, params.ID)
+	if err != nil || params.Name == nil {
+		return current, err
+	}
+
+	group, err := s.groupRepo.PatchGroup(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Group{}, groupWriteMissed(ctx, s.groupRepo, params.ID)
+	}
+	if err != nil {
+		return database.Group{}, s.nameConflict(ctx, *params.Name, err)
+	}
+	return group, nil
+}
+
+// nameConflict turns a violation of the unique group name index into a GroupNameConflictError
+// carrying the group that holds the name, other errors are returned unchanged
+func (s *GroupService) nameConflict(ctx context.Context, name string, err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != uniqueViolationCode || pgErr.ConstraintName != groupNameIndex {
+		return err
+	}
+
+	existing, lookupErr := s.groupRepo.GetGroupByNormalizedName(ctx, name)
+	if lookupErr != nil {
+		return err
+	}
+	return &GroupNameConflictError{Existing: existing}
+}
+
+// DeleteGroup soft-deletes a group together with its live songs in one transaction.
+// With a non-zero ifUpdatedAt the group must still be at that version.
+func (s *GroupService) DeleteGroup(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) error {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = getLiveGroup(ctx, tx.Repos.Groups, id); err != nil {
+		return err
+	}
+	deleted, err := tx.Repos.Groups.DeleteGroup(ctx, id, ifUpdatedAt)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return groupWriteMissed(ctx, tx.Repos.Groups, id)
+	}
+	songIDs, err := tx.Repos.Groups.CascadeDeleteGroupSongs(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return err
+	}
+
+	for _, songID := range songIDs {
+		s.similarityService.RemoveSong(songID)
+	}
+	return nil
+}
+
+// RestoreGroup undeletes a group and the songs that were deleted along with it, songs deleted on their own
+// stay deleted. It returns the group and the number of songs restored, restoring a live group changes nothing.
+func (s *GroupService) RestoreGroup(ctx context.Context, id uuid.UUID) (database.Group, int, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.Group{}, 0, err
+	}
+	defer tx.Rollback(ctx)
+
+	group, err := tx.Repos.Groups.GetGroup(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Group{}, 0, ErrGroupNotFound
+	}
+	if err != nil {
+		return database.Group{}, 0, err
+	}
+	if !group.DeletedAt.Valid {
+		return group, 0, nil
+	}
+
+	// Another group may have taken the name while this one was deleted
+	restored, err := tx.Repos.Groups.RestoreGroup(ctx, id)
+	if err != nil {
+		return database.Group{}, 0, s.nameConflict(ctx, group.Name, err)
+	}
+	songs, err := tx.Repos.Groups.RestoreGroupSongs(ctx, id)
+	if err != nil {
+		return database.Group{}, 0, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.Group{}, 0, err
+	}
+
+	for _, song := range songs {
+		s.similarityService.IndexSong(song)
+	}
+	return restored, len(songs), nil
+}
+
+// MergeGroups re-parents the live songs of the source groups to the target, moves their favorites,
+// records their names and aliases as aliases of the target and soft-deletes them, all in one transaction.
+// A dry run does the same work and rolls it back, so the report shows exactly what would move.
+func (s *GroupService) MergeGroups(ctx context.Context, targetID uuid.UUID, sourceIDs []uuid.UUID, dryRun bool) (GroupMergeReport, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return GroupMergeReport{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	target, err := getLiveGroup(ctx, tx.Repos.Groups, targetID)
+	if err != nil {
+		return GroupMergeReport{}, err
+	}
+
+	report := GroupMergeReport{DryRun: dryRun, Target: target}
+	seen := make(map[uuid.UUID]bool, len(sourceIDs))
+	ids := make([]uuid.UUID, 0, len(sourceIDs))
+	var names []string
+	for _, id := range sourceIDs {
+		if id == targetID {
+			return GroupMergeReport{}, ErrInvalidGroupMerge
+		}
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+
+		source, err := getLiveGroup(ctx, tx.Repos.Groups, id)
+		if err != nil {
+			return GroupMergeReport{}, err
+		}
+		ids = append(ids, id)
+		report.Sources = append(report.Sources, GroupMergeSource{ID: id.String(), Name: source.Name})
+		if !strings.EqualFold(strings.TrimSpace(source.Name), strings.TrimSpace(target.Name)) {
+			names = append(names, strings.TrimSpace(source.Name))
+		}
+	}
+
+	counts, err := tx.Repos.Groups.GetGroupsSongCounts(ctx, ids)
+	if err != nil {
+		return GroupMergeReport{}, err
+	}
+	for _, count := range counts {
+		for i := range report.Sources {
+			if report.Sources[i].ID == count.GroupID.String() {
+				report.Sources[i].Songs = count.SongCount
+			}
+		}
+	}
+
+	if report.SongsMoved, err = tx.Repos.Groups.MoveGroupSongs(ctx, ids, targetID); err != nil {
+		return GroupMergeReport{}, err
+	}
+	if report.FavoritesMoved, err = tx.Repos.Library.MoveGroupFavorites(ctx, ids, targetID); err != nil {
+		return GroupMergeReport{}, err
+	}
+
+	copied, err := tx.Repos.Groups.CopyGroupAliases(ctx, ids, targetID)
+	if err != nil {
+		return GroupMergeReport{}, err
+	}
+	added, err := tx.Repos.Groups.AddGroupAliases(ctx, targetID, AliasTypeFormer, names)
+	if err != nil {
+		return GroupMergeReport{}, err
+	}
+	report.AliasesAdded = copied + added
+
+	if _, err = tx.Repos.Groups.DeleteGroups(ctx, ids); err != nil {
+		return GroupMergeReport{}, err
+	}
+
+	if dryRun {
+		return report, nil
+	}
+	return report, tx.Commit(ctx)
+}
+
+// CreateGroupAlias adds an alias to a live group, the type defaults to search and the locale may be empty
+func (s *GroupService) CreateGroupAlias(ctx context.Context, params repository.GroupAliasCreateParams) (database.GroupAlias, error) {
+	params.Name = strings.TrimSpace(params.Name)
+	params.Locale = strings.TrimSpace(params.Locale)
+	if params.Type == "" {
+		params.Type = AliasTypeSearch
+	}
+	if params.Name == "" || !validAliasType(params.Type) || (params.Locale != "" && !localePattern.MatchString(params.Locale)) {
+		return database.GroupAlias{}, ErrInvalidAlias
+	}
+
+	if _, err := getLiveGroup(ctx, s.groupRepo, params.GroupID); err != nil {
+		return database.GroupAlias{}, err
+	}
+
+	alias, err := s.groupRepo.CreateGroupAlias(ctx, params)
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolationCode {
+		return database.GroupAlias{}, ErrAliasTaken
+	}
+	return alias, err
+}
+
+func (s *GroupService) GetGroupAliases(ctx context.Context, groupID uuid.UUID) ([]database.GroupAlias, error) {
+	if _, err := getLiveGroup(ctx, s.groupRepo, groupID); err != nil {
+		return nil, err
+	}
+	return s.groupRepo.GetGroupAliases(ctx, groupID)
+}
+
+func (s *GroupService) DeleteGroupAlias(ctx context.Context, groupID, id uuid.UUID) error {
+	deleted, err := s.groupRepo.DeleteGroupAlias(ctx, groupID, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrAliasNotFound
+	}
+	return nil
+}
+
+func validAliasType(aliasType string) bool {
+	for _, t := range AliasTypes {
+		if t == aliasType {
+			return true
+		}
+	}
+	return false
+}
+
+func getLiveGroup(ctx context.Context, groupRepo repository.GroupRepositoryInterface, id uuid.UUID) (database.Group, error) {
+	group, err := groupRepo.GetGroup(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && group.DeletedAt.Valid) {
+		return database.Group{}, ErrGroupNotFound
+	}
+	return group, err
+}
+
+// groupWriteMissed explains a conditional write that matched no row: the group is gone or it has changed
+func groupWriteMissed(ctx context.Context, groupRepo repository.GroupRepositoryInterface, id uuid.UUID) error {
+	if _, err := getLiveGroup(ctx, groupRepo, id); err != nil {
+		return err
+	}
+	return ErrPreconditionFailed
 }
@@ -0,0 +1,268 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"log/slog"
+	"music-service/internal/storage/database/dbtest"
+	"music-service/internal/storage/database/repository"
+	"testing"
+	"time"
+)
+
+func TestGroupNameUniqueness(t *testing.T) {
+	m := repository.NewManager(dbtest.Open(t))
+	service := NewGroupService(m.Groups, m, NewSimilarityService(m.Songs, slog.New(slog.DiscardHandler)))
+	ctx := context.Background()
+
+	const (
+		create      = "create"
+		getOrCreate = "get or create"
+		rename      = "rename"
+	)
+
+	tests := []struct {
+		name          string
+		existing      string
+		deleteFirst   bool
+		action        string
+		input         string
+		wantErr       error
+		wantExisting  bool
+		wantCreated   bool
+		wantGroupName string
+	}{
+		{
+			name:          "create a new name",
+			existing:      "Queen",
+			action:        create,
+			input:         "Queen II",
+			wantGroupName: "Queen II",
+		},
+		{
+			name:         "create a name differing in case",
+			existing:     "Abba",
+			action:       create,
+			input:        "ABBA",
+			wantErr:      ErrGroupNameTaken,
+			wantExisting: true,
+		},
+		{
+			name:         "create a name differing in surrounding whitespace",
+			existing:     "Blur",
+			action:       create,
+			input:        "  blur ",
+			wantErr:      ErrGroupNameTaken,
+			wantExisting: true,
+		},
+		{
+			name:          "create the name of a deleted group",
+			existing:      "Oasis",
+			deleteFirst:   true,
+			action:        create,
+			input:         "oasis",
+			wantGroupName: "oasis",
+		},
+		{
+			name:     "create a blank name",
+			existing: "Muse",
+			action:   create,
+			input:    "   ",
+			wantErr:  ErrInvalidGroupName,
+		},
+		{
+			name:          "get an existing group ignoring case",
+			existing:      "Radiohead",
+			action:        getOrCreate,
+			input:         " RADIOHEAD",
+			wantExisting:  true,
+			wantGroupName: "Radiohead",
+		},
+		{
+			name:          "get or create a new group",
+			existing:      "Pulp",
+			action:        getOrCreate,
+			input:         "Suede ",
+			wantCreated:   true,
+			wantGroupName: "Suede",
+		},
+		{
+			name:         "rename to the name of another group",
+			existing:     "Genesis",
+			action:       rename,
+			input:        "genesis",
+			wantErr:      ErrGroupNameTaken,
+			wantExisting: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			existing, err := service.CreateGroup(ctx, tt.existing)
+			if err != nil {
+				t.Fatalf("creating existing group: %v", err)
+			}
+			existingID := uuid.UUID(existing.ID.Bytes)
+			if tt.deleteFirst {
+				if err = service.DeleteGroup(ctx, existingID, time.Time{}); err != nil {
+					t.Fatalf("deleting existing group: %v", err)
+				}
+			}
+
+			var groupID uuid.UUID
+			var groupName string
+			var created bool
+			switch tt.action {
+			case create:
+				group, createErr := service.CreateGroup(ctx, tt.input)
+				groupID, groupName, err = group.ID.Bytes, group.Name, createErr
+			case getOrCreate:
+				group, wasCreated, getErr := service.GetOrCreateGroup(ctx, tt.input)
+				groupID, groupName, created, err = group.ID.Bytes, group.Name, wasCreated, getErr
+			case rename:
+				other, createErr := service.CreateGroup(ctx, tt.existing+" (other)")
+				if createErr != nil {
+					t.Fatalf("creating group to rename: %v", createErr)
+				}
+				group, updateErr := service.UpdateGroup(ctx, other.ID.Bytes, tt.input, time.Time{})
+				groupID, groupName, err = group.ID.Bytes, group.Name, updateErr
+			}
+
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("error = %v, want %v", err, tt.wantErr)
+			}
+			if created != tt.wantCreated {
+				t.Errorf("created = %v, want %v", created, tt.wantCreated)
+			}
+
+			var conflict *GroupNameConflictError
+			if errors.As(err, &conflict) {
+				groupID = conflict.Existing.ID.Bytes
+			}
+			if (groupID == existingID) != tt.wantExisting {
+				t.Errorf("returned existing group = %v, want %v", groupID == existingID, tt.wantExisting)
+			}
+			if tt.wantGroupName != "" && groupName != tt.wantGroupName {
+				t.Errorf("group name = %q, want %q", groupName, tt.wantGroupName)
+			}
+		})
+	}
+}
+
+func TestGroupCascadeDeleteAndRestore(t *testing.T) {
+	m := repository.NewManager(dbtest.Open(t))
+	similarity := NewSimilarityService(m.Songs, slog.New(slog.DiscardHandler))
+	groups := NewGroupService(m.Groups, m, similarity)
+	songs := NewSongService(m.Songs, m.Groups, similarity)
+	ctx := context.Background()
+
+	const (
+		deleteSong   = "delete song"
+		deleteGroup  = "delete group"
+		restoreSong  = "restore song"
+		restoreGroup = "restore group"
+	)
+
+	type step struct {
+		op      string
+		song    int
+		wantErr error
+	}
+
+	tests := []struct {
+		name         string
+		steps        []step
+		wantRestored int
+		wantLive     []bool
+	}{
+		{
+			name:         "restoring the group brings back its songs",
+			steps:        []step{{op: deleteGroup}, {op: restoreGroup}},
+			wantRestored: 3,
+			wantLive:     []bool{true, true, true},
+		},
+		{
+			name:         "songs deleted before the group stay deleted",
+			steps:        []step{{op: deleteSong, song: 0}, {op: deleteGroup}, {op: restoreGroup}},
+			wantRestored: 2,
+			wantLive:     []bool{false, true, true},
+		},
+		{
+			name:     "a song of a deleted group cannot be restored on its own",
+			steps:    []step{{op: deleteGroup}, {op: restoreSong, song: 1, wantErr: ErrSongGroupDeleted}},
+			wantLive: []bool{false, false, false},
+		},
+		{
+			name: "a song deleted before the group can be restored after it",
+			steps: []step{
+				{op: deleteSong, song: 0},
+				{op: deleteGroup},
+				{op: restoreGroup},
+				{op: restoreSong, song: 0},
+			},
+			wantRestored: 2,
+			wantLive:     []bool{true, true, true},
+		},
+		{
+			name: "a second cascade only restores the songs deleted with it",
+			steps: []step{
+				{op: deleteGroup},
+				{op: restoreGroup},
+				{op: deleteSong, song: 2},
+				{op: deleteGroup},
+				{op: restoreGroup},
+			},
+			wantRestored: 2,
+			wantLive:     []bool{true, true, false},
+		},
+		{
+			name:         "restoring a live group changes nothing",
+			steps:        []step{{op: deleteSong, song: 1}, {op: restoreGroup}},
+			wantRestored: 0,
+			wantLive:     []bool{true, false, true},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groupID := createTestGroup(t, m, tt.name)
+			songIDs := make([]uuid.UUID, len(tt.wantLive))
+			for i := range songIDs {
+				songIDs[i] = createTestSong(t, m, groupID, fmt.Sprintf("Song %d", i))
+			}
+
+			var restored int
+			for i, s := range tt.steps {
+				var err error
+				switch s.op {
+				case deleteSong:
+					err = songs.DeleteSong(ctx, songIDs[s.song], time.Time{})
+				case deleteGroup:
+					err = groups.DeleteGroup(ctx, groupID, time.Time{})
+				case restoreSong:
+					_, err = songs.RestoreSong(ctx, songIDs[s.song])
+				case restoreGroup:
+					_, restored, err = groups.RestoreGroup(ctx, groupID)
+				}
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d (%s): error = %v, want %v", i, s.op, err, s.wantErr)
+				}
+			}
+
+			if restored != tt.wantRestored {
+				t.Errorf("restored songs = %d, want %d", restored, tt.wantRestored)
+			}
+			for i, songID := range songIDs {
+				song, err := m.Songs.GetSong(ctx, songID)
+				if err != nil {
+					t.Fatalf("getting song %d: %v", i, err)
+				}
+				if live := !song.DeletedAt.Valid; live != tt.wantLive[i] {
+					t.Errorf("song %d live = %v, want %v", i, live, tt.wantLive[i])
+				}
+			}
+		})
+	}
+}
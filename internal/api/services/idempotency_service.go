@@ -0,0 +1,92 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/config"
+	"music-service/internal/storage/database/repository"
+	"time"
+)
+
+// idempotencyLockTimeout is how long a request holds its key before a retry may take the key over,
+// so a key is not stuck until it expires when the server stopped in the middle of the request
+const idempotencyLockTimeout = time.Minute
+
+var (
+	ErrIdempotencyKeyReused     = NewError(KindUnprocessable, "idempotency_key_reused", "Idempotency-Key was already used for a different request")
+	ErrIdempotentRequestRunning = NewError(KindConflict, "idempotent_request_in_progress", "A request with this Idempotency-Key is still being processed")
+)
+
+// IdempotentResponse is the response to the first request made with an idempotency key
+type IdempotentResponse struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// IdempotencyService remembers the responses to requests sent with an idempotency key so retries replay them
+type IdempotencyService struct {
+	idempotencyRepo repository.IdempotencyRepositoryInterface
+	keyTTL          time.Duration
+}
+
+// NewIdempotencyService creates a new idempotency service
+func NewIdempotencyService(dbManager *repository.Manager, cfg *config.Config) *IdempotencyService {
+	return &IdempotencyService{
+		idempotencyRepo: dbManager.Idempotency,
+		keyTTL:          cfg.Internal.Idempotency.KeyTTL,
+	}
+}
+
+// Start claims the key of a user for a request with the given hash. When the key was already used for the
+// same request its response is returned with replay set, otherwise the caller processes the request and
+// then stores the response with Complete or gives the key up with Release.
+func (s *IdempotencyService) Start(ctx context.Context, userID uuid.UUID, key string, requestHash []byte) (IdempotentResponse, bool, error) {
+	now := time.Now()
+	_, err := s.idempotencyRepo.StartIdempotentRequest(ctx, userID, key, requestHash, now.Add(s.keyTTL), now.Add(-idempotencyLockTimeout))
+	if err == nil {
+		return IdempotentResponse{}, false, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return IdempotentResponse{}, false, err
+	}
+
+	record, err := s.idempotencyRepo.GetIdempotencyKey(ctx, userID, key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// The request holding the key failed and released it in the meantime
+		return IdempotentResponse{}, false, ErrIdempotentRequestRunning
+	}
+	if err != nil {
+		return IdempotentResponse{}, false, err
+	}
+
+	if !bytes.Equal(record.RequestHash, requestHash) {
+		return IdempotentResponse{}, false, ErrIdempotencyKeyReused
+	}
+	if !record.StatusCode.Valid {
+		return IdempotentResponse{}, false, ErrIdempotentRequestRunning
+	}
+	return IdempotentResponse{
+		StatusCode:  int(record.StatusCode.Int32),
+		ContentType: record.ContentType,
+		Body:        record.ResponseBody,
+	}, true, nil
+}
+
+// Complete stores the response to replay for retries with the key
+func (s *IdempotencyService) Complete(ctx context.Context, userID uuid.UUID, key string, response IdempotentResponse) error {
+	return s.idempotencyRepo.CompleteIdempotentRequest(ctx, userID, key, int32(response.StatusCode), response.ContentType, response.Body)
+}
+
+// Release gives up the key of a request that failed, so a retry is processed again
+func (s *IdempotencyService) Release(ctx context.Context, userID uuid.UUID, key string) error {
+	return s.idempotencyRepo.DeleteIdempotencyKey(ctx, userID, key)
+}
+
+// PurgeExpired deletes the keys whose responses are no longer replayed
+func (s *IdempotencyService) PurgeExpired(ctx context.Context) (int64, error) {
+	return s.idempotencyRepo.DeleteExpiredIdempotencyKeys(ctx)
+}
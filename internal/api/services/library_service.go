@@ -0,0 +1,108 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"time"
+)
+
+var (
+	ErrGroupNotFound    = NewError(KindNotFound, "group_not_found", "Group not found")
+	ErrFavoriteNotFound = NewError(KindNotFound, "favorite_not_found", "Favorite not found")
+	ErrInvalidPlayEvent = NewValidationError("invalid_play_event", "played_at cannot be in the future and duration_played cannot be negative")
+)
+
+// playedAtSkew is how far in the future a client clock may report a play
+const playedAtSkew = 5 * time.Minute
+
+// LibraryService handles the favorites and listening history of users
+type LibraryService struct {
+	libraryRepo repository.LibraryRepositoryInterface
+	songRepo    repository.SongRepositoryInterface
+	groupRepo   repository.GroupRepositoryInterface
+}
+
+// NewLibraryService creates a new library service
+func NewLibraryService(libraryRepo repository.LibraryRepositoryInterface, songRepo repository.SongRepositoryInterface, groupRepo repository.GroupRepositoryInterface) *LibraryService {
+	return &LibraryService{
+		libraryRepo: libraryRepo,
+		songRepo:    songRepo,
+		groupRepo:   groupRepo,
+	}
+}
+
+// AddFavorite favorites a song or group, favoriting twice is not an error
+func (s *LibraryService) AddFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	switch entityType {
+	case repository.FavoriteEntitySong:
+		if _, err := s.getAvailableSong(ctx, entityID); err != nil {
+			return err
+		}
+	case repository.FavoriteEntityGroup:
+		group, err := s.groupRepo.GetGroup(ctx, entityID)
+		if errors.Is(err, pgx.ErrNoRows) || (err == nil && group.DeletedAt.Valid) {
+			return ErrGroupNotFound
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.libraryRepo.AddFavorite(ctx, userID, entityType, entityID)
+}
+
+func (s *LibraryService) RemoveFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	removed, err := s.libraryRepo.RemoveFavorite(ctx, userID, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrFavoriteNotFound
+	}
+	return nil
+}
+
+func (s *LibraryService) GetFavoritesWithPagination(ctx context.Context, params repository.FavoriteFilterParams) ([]database.GetFavoritesWithPaginationRow, error) {
+	return s.libraryRepo.GetFavoritesWithPagination(ctx, params)
+}
+
+func (s *LibraryService) GetFavoritesCount(ctx context.Context, userID uuid.UUID, entityType string) (int64, error) {
+	return s.libraryRepo.GetFavoritesCount(ctx, userID, entityType)
+}
+
+// RecordPlay stores a play event, a zero PlayedAt means the song was played just now
+func (s *LibraryService) RecordPlay(ctx context.Context, params repository.PlayEventCreateParams) (database.PlayEvent, error) {
+	now := time.Now()
+	if params.PlayedAt.IsZero() {
+		params.PlayedAt = now
+	}
+	if params.PlayedAt.After(now.Add(playedAtSkew)) || params.DurationPlayed < 0 {
+		return database.PlayEvent{}, ErrInvalidPlayEvent
+	}
+
+	if _, err := s.getAvailableSong(ctx, params.SongID); err != nil {
+		return database.PlayEvent{}, err
+	}
+
+	return s.libraryRepo.CreatePlayEvent(ctx, params)
+}
+
+func (s *LibraryService) GetPlayHistoryWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetPlayHistoryWithPaginationRow, error) {
+	return s.libraryRepo.GetPlayHistoryWithPagination(ctx, userID, limit, offset)
+}
+
+func (s *LibraryService) GetPlayHistoryCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.libraryRepo.GetPlayHistoryCount(ctx, userID)
+}
+
+func (s *LibraryService) getAvailableSong(ctx context.Context, id uuid.UUID) (database.Song, error) {
+	song, err := s.songRepo.GetSong(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return database.Song{}, ErrSongNotFound
+	}
+	return song, err
+}
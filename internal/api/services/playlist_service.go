@@ -0,0 +1,306 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"math"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+)
+
+const (
+	PlaylistVisibilityPublic   = "public"
+	PlaylistVisibilityUnlisted = "unlisted"
+	PlaylistVisibilityPrivate  = "private"
+)
+
+var (
+	ErrPlaylistNotFound      = NewError(KindNotFound, "playlist_not_found", "Playlist not found")
+	ErrPlaylistEntryNotFound = NewError(KindNotFound, "playlist_entry_not_found", "Playlist entry not found")
+	ErrSongNotFound          = NewError(KindNotFound, "song_not_found", "Song not found")
+	ErrInvalidPosition       = NewValidationError("invalid_position", "Position is out of range", FieldError{Field: "position", Message: "is out of range"})
+	ErrNotOwner              = NewError(KindForbidden, "not_owner", "Only the owner of a playlist or an admin can change it")
+)
+
+// PlaylistStats holds the aggregated figures of a playlist computed from its entries
+type PlaylistStats struct {
+	EntryCount       int64
+	TotalRuntime     int64
+	UnavailableCount int64
+}
+
+// PlaylistService handles business logic for playlists and their ordered entries
+type PlaylistService struct {
+	dbManager *repository.Manager
+}
+
+// NewPlaylistService creates a new playlist service
+func NewPlaylistService(dbManager *repository.Manager) *PlaylistService {
+	return &PlaylistService{
+		dbManager: dbManager,
+	}
+}
+
+func (s *PlaylistService) CreatePlaylist(ctx context.Context, params repository.PlaylistCreateParams) (database.Playlist, error) {
+	return s.dbManager.Playlists.CreatePlaylist(ctx, params)
+}
+
+// GetPlaylist returns a playlist the viewer may read, private playlists of others are reported as not found
+func (s *PlaylistService) GetPlaylist(ctx context.Context, viewer Principal, id uuid.UUID) (database.Playlist, error) {
+	playlist, err := s.dbManager.Playlists.GetPlaylist(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !canReadPlaylist(viewer, playlist.OwnerID, playlist.Visibility)) {
+		return database.Playlist{}, ErrPlaylistNotFound
+	}
+	return playlist, err
+}
+
+func (s *PlaylistService) GetPlaylistsWithPagination(ctx context.Context, viewer Principal, params repository.PlaylistFilterParams) ([]database.Playlist, error) {
+	return s.dbManager.Playlists.GetPlaylistsWithPagination(ctx, withViewer(viewer, params))
+}
+
+func (s *PlaylistService) GetPlaylistsCount(ctx context.Context, viewer Principal, params repository.PlaylistFilterParams) (int64, error) {
+	return s.dbManager.Playlists.GetPlaylistsCount(ctx, withViewer(viewer, params))
+}
+
+// GetPlaylistsStats returns entry count and runtime per playlist, playlists without entries get zero stats
+func (s *PlaylistService) GetPlaylistsStats(ctx context.Context, ids []uuid.UUID) (map[uuid.UUID]PlaylistStats, error) {
+	stats := make(map[uuid.UUID]PlaylistStats, len(ids))
+	if len(ids) == 0 {
+		return stats, nil
+	}
+
+	rows, err := s.dbManager.Playlists.GetPlaylistsStats(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		stats[row.PlaylistID.Bytes] = PlaylistStats{
+			EntryCount:       row.EntryCount,
+			TotalRuntime:     row.TotalRuntime,
+			UnavailableCount: row.UnavailableCount,
+		}
+	}
+	return stats, nil
+}
+
+func (s *PlaylistService) GetPlaylistEntries(ctx context.Context, id uuid.UUID) ([]database.GetPlaylistEntriesRow, error) {
+	return s.dbManager.Playlists.GetPlaylistEntries(ctx, id)
+}
+
+// UpdatePlaylist changes a playlist owned by the viewer, admins can change any playlist
+func (s *PlaylistService) UpdatePlaylist(ctx context.Context, viewer Principal, params repository.PlaylistUpdateParams) (database.Playlist, error) {
+	if err := checkPlaylistOwner(ctx, s.dbManager.Playlists, viewer, params.ID); err != nil {
+		return database.Playlist{}, err
+	}
+
+	playlist, err := s.dbManager.Playlists.UpdatePlaylist(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.Playlist{}, ErrPlaylistNotFound
+	}
+	return playlist, err
+}
+
+// DeletePlaylist deletes a playlist owned by the viewer, admins can delete any playlist
+func (s *PlaylistService) DeletePlaylist(ctx context.Context, viewer Principal, id uuid.UUID) error {
+	if err := checkPlaylistOwner(ctx, s.dbManager.Playlists, viewer, id); err != nil {
+		return err
+	}
+
+	deleted, err := s.dbManager.Playlists.DeletePlaylist(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrPlaylistNotFound
+	}
+	return nil
+}
+
+// AddEntry inserts a song at the given 1-based position, or appends it when position is nil.
+// Entries at and after the position are shifted down by one.
+func (s *PlaylistService) AddEntry(ctx context.Context, viewer Principal, playlistID, songID uuid.UUID, position *int32) (database.PlaylistEntry, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	count, err := lockPlaylist(ctx, tx, viewer, playlistID)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	song, err := tx.Repos.Songs.GetSong(ctx, songID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return database.PlaylistEntry{}, ErrSongNotFound
+	}
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	newPosition := int32(count) + 1
+	if position != nil {
+		if *position < 1 || *position > newPosition {
+			return database.PlaylistEntry{}, ErrInvalidPosition
+		}
+		newPosition = *position
+
+		if err = tx.Repos.Playlists.ShiftPlaylistEntries(ctx, playlistID, newPosition, math.MaxInt32, 1); err != nil {
+			return database.PlaylistEntry{}, err
+		}
+	}
+
+	entry, err := tx.Repos.Playlists.CreatePlaylistEntry(ctx, playlistID, songID, newPosition)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.PlaylistEntry{}, err
+	}
+	return entry, nil
+}
+
+// RemoveEntry deletes an entry and closes the gap it leaves in the ordering
+func (s *PlaylistService) RemoveEntry(ctx context.Context, viewer Principal, playlistID, entryID uuid.UUID) error {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err = lockPlaylist(ctx, tx, viewer, playlistID); err != nil {
+		return err
+	}
+
+	entry, err := getPlaylistEntry(ctx, tx, playlistID, entryID)
+	if err != nil {
+		return err
+	}
+
+	if err = tx.Repos.Playlists.DeletePlaylistEntry(ctx, entryID); err != nil {
+		return err
+	}
+
+	if err = tx.Repos.Playlists.ShiftPlaylistEntries(ctx, playlistID, entry.Position+1, math.MaxInt32, -1); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// MoveEntry moves an entry to a new 1-based position, shifting the entries in between
+func (s *PlaylistService) MoveEntry(ctx context.Context, viewer Principal, playlistID, entryID uuid.UUID, position int32) (database.PlaylistEntry, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	count, err := lockPlaylist(ctx, tx, viewer, playlistID)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	entry, err := getPlaylistEntry(ctx, tx, playlistID, entryID)
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	if position < 1 || int64(position) > count {
+		return database.PlaylistEntry{}, ErrInvalidPosition
+	}
+
+	switch {
+	case position < entry.Position:
+		err = tx.Repos.Playlists.ShiftPlaylistEntries(ctx, playlistID, position, entry.Position-1, 1)
+	case position > entry.Position:
+		err = tx.Repos.Playlists.ShiftPlaylistEntries(ctx, playlistID, entry.Position+1, position, -1)
+	default:
+		return entry, nil
+	}
+	if err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	if err = tx.Repos.Playlists.SetPlaylistEntryPosition(ctx, entryID, position); err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.PlaylistEntry{}, err
+	}
+
+	entry.Position = position
+	return entry, nil
+}
+
+// lockPlaylist checks that the viewer owns the playlist, locks its row for the transaction and returns its current entry count
+func lockPlaylist(ctx context.Context, tx *repository.Tx, viewer Principal, playlistID uuid.UUID) (int64, error) {
+	if err := checkPlaylistOwner(ctx, tx.Repos.Playlists, viewer, playlistID); err != nil {
+		return 0, err
+	}
+
+	found, err := tx.Repos.Playlists.TouchPlaylist(ctx, playlistID)
+	if err != nil {
+		return 0, err
+	}
+	if !found {
+		return 0, ErrPlaylistNotFound
+	}
+	return tx.Repos.Playlists.GetPlaylistEntryCount(ctx, playlistID)
+}
+
+// canReadPlaylist reports whether the viewer may see a playlist, private ones are only shown to their owner and admins
+func canReadPlaylist(viewer Principal, ownerID pgtype.UUID, visibility string) bool {
+	return visibility != PlaylistVisibilityPrivate || ownsPlaylist(viewer, ownerID)
+}
+
+// ownsPlaylist reports whether the viewer is the owner of a playlist or an admin
+func ownsPlaylist(viewer Principal, ownerID pgtype.UUID) bool {
+	return viewer.Role == RoleAdmin || (viewer.UserID != uuid.Nil && viewer.UserID == ownerID.Bytes)
+}
+
+// checkPlaylistOwner refuses changes to a playlist by anyone but its owner and admins.
+// Private playlists of others stay hidden and are reported as not found.
+func checkPlaylistOwner(ctx context.Context, playlists repository.PlaylistRepositoryInterface, viewer Principal, id uuid.UUID) error {
+	playlist, err := playlists.GetPlaylist(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return ownerError(viewer, playlist.OwnerID, playlist.Visibility, ErrPlaylistNotFound)
+}
+
+// ownerError is the error of a change by the viewer to a playlist with the given owner and visibility, nil when it is allowed
+func ownerError(viewer Principal, ownerID pgtype.UUID, visibility string, notFound error) error {
+	switch {
+	case ownsPlaylist(viewer, ownerID):
+		return nil
+	case !canReadPlaylist(viewer, ownerID, visibility):
+		return notFound
+	default:
+		return ErrNotOwner
+	}
+}
+
+// withViewer restricts a listing to the playlists the viewer may see
+func withViewer(viewer Principal, params repository.PlaylistFilterParams) repository.PlaylistFilterParams {
+	params.ViewerID = viewer.UserID
+	params.ViewerIsAdmin = viewer.Role == RoleAdmin
+	return params
+}
+
+func getPlaylistEntry(ctx context.Context, tx *repository.Tx, playlistID, entryID uuid.UUID) (database.PlaylistEntry, error) {
+	entry, err := tx.Repos.Playlists.GetPlaylistEntry(ctx, playlistID, entryID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.PlaylistEntry{}, ErrPlaylistEntryNotFound
+	}
+	return entry, err
+}
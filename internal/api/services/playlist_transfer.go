@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/pkg/utils/playlistfile"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+)
+
+// UnmatchedTrack describes an imported line that could not be matched to a song
+type UnmatchedTrack struct {
+	Line   int    `json:"line"`
+	Text   string `json:"text"`
+	Reason string `json:"reason"`
+}
+
+// ImportReport summarises how the tracks of an imported file were matched
+type ImportReport struct {
+	Total     int              `json:"total"`
+	Matched   int              `json:"matched"`
+	Unmatched []UnmatchedTrack `json:"unmatched"`
+}
+
+// ExportPlaylist converts a playlist the viewer may read into its exchange representation, the creator is the owner's username.
+// Entries whose song was deleted are left out since other players cannot resolve them.
+func (s *PlaylistService) ExportPlaylist(ctx context.Context, viewer Principal, id uuid.UUID) (playlistfile.Playlist, error) {
+	playlist, err := s.GetPlaylist(ctx, viewer, id)
+	if err != nil {
+		return playlistfile.Playlist{}, err
+	}
+
+	owner, err := s.dbManager.Users.GetUser(ctx, playlist.OwnerID.Bytes)
+	if err != nil {
+		return playlistfile.Playlist{}, err
+	}
+
+	entries, err := s.dbManager.Playlists.GetPlaylistEntries(ctx, id)
+	if err != nil {
+		return playlistfile.Playlist{}, err
+	}
+
+	export := playlistfile.Playlist{
+		Name:        playlist.Name,
+		Description: playlist.Description,
+		Owner:       owner.Username,
+		Tracks:      make([]playlistfile.Track, 0, len(entries)),
+	}
+
+	for _, entry := range entries {
+		if entry.SongDeletedAt.Valid {
+			continue
+		}
+		export.Tracks = append(export.Tracks, playlistfile.Track{
+			Title:   entry.Title,
+			Group:   entry.GroupName,
+			Runtime: entry.Runtime,
+			Link:    entry.Link,
+		})
+	}
+
+	return export, nil
+}
+
+// ImportPlaylist creates a playlist from an imported file in one transaction.
+// Tracks are matched to existing songs by group name and title, unmatched tracks are reported.
+func (s *PlaylistService) ImportPlaylist(ctx context.Context, params repository.PlaylistCreateParams, file playlistfile.Playlist) (database.Playlist, ImportReport, error) {
+	report := ImportReport{
+		Total:     len(file.Tracks),
+		Unmatched: []UnmatchedTrack{},
+	}
+
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.Playlist{}, report, err
+	}
+	defer tx.Rollback(ctx)
+
+	playlist, err := tx.Repos.Playlists.CreatePlaylist(ctx, params)
+	if err != nil {
+		return database.Playlist{}, report, err
+	}
+
+	var position int32
+	for _, track := range file.Tracks {
+		if track.Title == "" || track.Group == "" {
+			report.Unmatched = append(report.Unmatched, UnmatchedTrack{
+				Line:   track.Line,
+				Text:   track.Raw,
+				Reason: "missing group or title",
+			})
+			continue
+		}
+
+		song, err := tx.Repos.Songs.FindSongByGroupAndTitle(ctx, track.Group, track.Title)
+		if errors.Is(err, pgx.ErrNoRows) {
+			report.Unmatched = append(report.Unmatched, UnmatchedTrack{
+				Line:   track.Line,
+				Text:   track.Raw,
+				Reason: "no song with this group and title",
+			})
+			continue
+		}
+		if err != nil {
+			return database.Playlist{}, report, err
+		}
+
+		position++
+		if _, err = tx.Repos.Playlists.CreatePlaylistEntry(ctx, playlist.ID.Bytes, song.ID.Bytes, position); err != nil {
+			return database.Playlist{}, report, err
+		}
+		report.Matched++
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.Playlist{}, report, err
+	}
+	return playlist, report, nil
+}
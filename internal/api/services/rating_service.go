@@ -0,0 +1,123 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+)
+
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+var (
+	ErrInvalidRating  = NewValidationError("invalid_rating", "Rating must be between 1 and 5", FieldError{Field: "rating", Message: "must be between 1 and 5"})
+	ErrRatingNotFound = NewError(KindNotFound, "rating_not_found", "Rating not found")
+)
+
+// RatingService handles song ratings and keeps the per-song average and count up to date
+type RatingService struct {
+	dbManager *repository.Manager
+}
+
+// NewRatingService creates a new rating service
+func NewRatingService(dbManager *repository.Manager) *RatingService {
+	return &RatingService{
+		dbManager: dbManager,
+	}
+}
+
+// RateSong creates or replaces the user's rating of a song and returns the updated song stats.
+// The stats row is locked first so concurrent ratings of the same song are applied one at a time.
+func (s *RatingService) RateSong(ctx context.Context, userID, songID uuid.UUID, rating int32) (database.SongRatingStat, error) {
+	if rating < MinRating || rating > MaxRating {
+		return database.SongRatingStat{}, ErrInvalidRating
+	}
+
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	song, err := tx.Repos.Songs.GetSong(ctx, songID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return database.SongRatingStat{}, ErrSongNotFound
+	}
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	if _, err = tx.Repos.Ratings.LockSongRatingStats(ctx, songID); err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	countDelta, sumDelta := int32(1), rating
+	previous, err := tx.Repos.Ratings.GetSongRating(ctx, userID, songID)
+	switch {
+	case err == nil:
+		countDelta, sumDelta = 0, rating-previous.Rating
+	case !errors.Is(err, pgx.ErrNoRows):
+		return database.SongRatingStat{}, err
+	}
+
+	if _, err = tx.Repos.Ratings.UpsertSongRating(ctx, userID, songID, rating); err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	stats, err := tx.Repos.Ratings.AdjustSongRatingStats(ctx, songID, countDelta, sumDelta)
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	return stats, tx.Commit(ctx)
+}
+
+// RemoveRating deletes the user's rating of a song and returns the updated song stats
+func (s *RatingService) RemoveRating(ctx context.Context, userID, songID uuid.UUID) (database.SongRatingStat, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	song, err := tx.Repos.Songs.GetSong(ctx, songID)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return database.SongRatingStat{}, ErrSongNotFound
+	}
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	if _, err = tx.Repos.Ratings.LockSongRatingStats(ctx, songID); err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	previous, err := tx.Repos.Ratings.DeleteSongRating(ctx, userID, songID)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.SongRatingStat{}, ErrRatingNotFound
+	}
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	stats, err := tx.Repos.Ratings.AdjustSongRatingStats(ctx, songID, -1, -previous)
+	if err != nil {
+		return database.SongRatingStat{}, err
+	}
+
+	return stats, tx.Commit(ctx)
+}
+
+// GetUserRatingsWithPagination lists the ratings of a user, ratings of deleted songs are left out
+func (s *RatingService) GetUserRatingsWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetUserRatingsWithPaginationRow, error) {
+	return s.dbManager.Ratings.GetUserRatingsWithPagination(ctx, userID, limit, offset)
+}
+
+func (s *RatingService) GetUserRatingsCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	return s.dbManager.Ratings.GetUserRatingsCount(ctx, userID)
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/dbtest"
+	"music-service/internal/storage/database/repository"
+	"testing"
+	"time"
+)
+
+func createTestUser(t *testing.T, m *repository.Manager, username string) uuid.UUID {
+	t.Helper()
+
+	user, err := m.Users.CreateUser(context.Background(), username, "not-a-real-hash", RoleViewer)
+	if err != nil {
+		t.Fatalf("creating user: %v", err)
+	}
+	return user.ID.Bytes
+}
+
+func createTestGroup(t *testing.T, m *repository.Manager, name string) uuid.UUID {
+	t.Helper()
+
+	group, err := m.Groups.CreateGroup(context.Background(), name)
+	if err != nil {
+		t.Fatalf("creating group: %v", err)
+	}
+	return group.ID.Bytes
+}
+
+func createTestSong(t *testing.T, m *repository.Manager, groupID uuid.UUID, title string) uuid.UUID {
+	t.Helper()
+
+	song, err := m.Songs.CreateSong(context.Background(), repository.SongCreateParams{
+		GroupID:     groupID,
+		Title:       title,
+		Runtime:     180,
+		ReleaseDate: time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		t.Fatalf("creating song: %v", err)
+	}
+	return song.ID.Bytes
+}
+
+func TestRatingServiceStats(t *testing.T) {
+	pool := dbtest.Open(t)
+	m := repository.NewManager(pool)
+	service := NewRatingService(m)
+	ctx := context.Background()
+
+	users := []uuid.UUID{createTestUser(t, m, "alice"), createTestUser(t, m, "bob")}
+	groupID := createTestGroup(t, m, "Ratings")
+
+	// remove marks a step that removes the user's rating instead of rating the song
+	const remove = 0
+
+	type step struct {
+		user    int
+		rating  int32
+		wantErr error
+	}
+
+	tests := []struct {
+		name        string
+		steps       []step
+		wantCount   int32
+		wantSum     int32
+		wantAverage float64
+	}{
+		{
+			name:        "rate",
+			steps:       []step{{user: 0, rating: 4}},
+			wantCount:   1,
+			wantSum:     4,
+			wantAverage: 4,
+		},
+		{
+			name:        "re-rate replaces the previous rating",
+			steps:       []step{{user: 0, rating: 4}, {user: 0, rating: 2}},
+			wantCount:   1,
+			wantSum:     2,
+			wantAverage: 2,
+		},
+		{
+			name:        "two users",
+			steps:       []step{{user: 0, rating: 4}, {user: 1, rating: 5}},
+			wantCount:   2,
+			wantSum:     9,
+			wantAverage: 4.5,
+		},
+		{
+			name:        "remove one of two ratings",
+			steps:       []step{{user: 0, rating: 4}, {user: 1, rating: 5}, {user: 0, rating: remove}},
+			wantCount:   1,
+			wantSum:     5,
+			wantAverage: 5,
+		},
+		{
+			name:        "remove the only rating",
+			steps:       []step{{user: 0, rating: 3}, {user: 0, rating: remove}},
+			wantCount:   0,
+			wantSum:     0,
+			wantAverage: 0,
+		},
+		{
+			name:        "rating out of range is rejected",
+			steps:       []step{{user: 0, rating: 2}, {user: 1, rating: 6, wantErr: ErrInvalidRating}},
+			wantCount:   1,
+			wantSum:     2,
+			wantAverage: 2,
+		},
+		{
+			name:        "removing a missing rating is rejected",
+			steps:       []step{{user: 0, rating: 5}, {user: 1, rating: remove, wantErr: ErrRatingNotFound}},
+			wantCount:   1,
+			wantSum:     5,
+			wantAverage: 5,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			songID := createTestSong(t, m, groupID, tt.name)
+
+			for i, s := range tt.steps {
+				var err error
+				if s.rating == remove {
+					_, err = service.RemoveRating(ctx, users[s.user], songID)
+				} else {
+					_, err = service.RateSong(ctx, users[s.user], songID, s.rating)
+				}
+				if !errors.Is(err, s.wantErr) {
+					t.Fatalf("step %d: error = %v, want %v", i, err, s.wantErr)
+				}
+			}
+
+			got := getRatingStats(t, pool, songID)
+			if got.RatingCount != tt.wantCount || got.RatingSum != tt.wantSum || got.RatingAverage != tt.wantAverage {
+				t.Errorf("stats = count %d, sum %d, average %v, want count %d, sum %d, average %v",
+					got.RatingCount, got.RatingSum, got.RatingAverage, tt.wantCount, tt.wantSum, tt.wantAverage)
+			}
+
+			assertRatingStatsMatchRatings(t, pool, songID)
+		})
+	}
+}
+
+func TestRatingServiceDeletedSong(t *testing.T) {
+	m := repository.NewManager(dbtest.Open(t))
+	service := NewRatingService(m)
+	ctx := context.Background()
+
+	userID := createTestUser(t, m, "alice")
+	songID := createTestSong(t, m, createTestGroup(t, m, "Deleted"), "Gone")
+	if _, err := m.Songs.DeleteSong(ctx, songID, time.Time{}); err != nil {
+		t.Fatalf("deleting song: %v", err)
+	}
+
+	if _, err := service.RateSong(ctx, userID, songID, 4); !errors.Is(err, ErrSongNotFound) {
+		t.Errorf("rating a deleted song: error = %v, want %v", err, ErrSongNotFound)
+	}
+	if _, err := service.RateSong(ctx, userID, uuid.New(), 4); !errors.Is(err, ErrSongNotFound) {
+		t.Errorf("rating an unknown song: error = %v, want %v", err, ErrSongNotFound)
+	}
+}
+
+// assertRatingStatsMatchRatings recomputes the stats of a song from its ratings and compares them with the maintained ones
+// getRatingStats reads the maintained rating stats of a song, zero for songs without ratings
+func getRatingStats(t *testing.T, pool *pgxpool.Pool, songID uuid.UUID) database.SongRatingStat {
+	t.Helper()
+
+	var stats database.SongRatingStat
+	err := pool.QueryRow(context.Background(), `
+SELECT rating_count, rating_sum, rating_average FROM song_rating_stats WHERE song_id = $1`, songID).
+		Scan(&stats.RatingCount, &stats.RatingSum, &stats.RatingAverage)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		t.Fatalf("reading rating stats: %v", err)
+	}
+	return stats
+}
+
+func assertRatingStatsMatchRatings(t *testing.T, pool *pgxpool.Pool, songID uuid.UUID) {
+	t.Helper()
+
+	var count, sum int32
+	var maintainedCount, maintainedSum int32
+	err := pool.QueryRow(context.Background(), `
+SELECT (SELECT count(*) FROM song_ratings WHERE song_id = $1)::INT,
+       (SELECT COALESCE(sum(rating), 0) FROM song_ratings WHERE song_id = $1)::INT,
+       COALESCE((SELECT rating_count FROM song_rating_stats WHERE song_id = $1), 0),
+       COALESCE((SELECT rating_sum FROM song_rating_stats WHERE song_id = $1), 0)`, songID).
+		Scan(&count, &sum, &maintainedCount, &maintainedSum)
+	if err != nil {
+		t.Fatalf("recomputing rating stats: %v", err)
+	}
+	if maintainedCount != count || maintainedSum != sum {
+		t.Errorf("maintained stats = count %d, sum %d, recomputed = count %d, sum %d", maintainedCount, maintainedSum, count, sum)
+	}
+}
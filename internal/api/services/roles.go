@@ -0,0 +1,29 @@
+package services
+
+// Roles a user can have, each role can do everything the roles before it can
+const (
+	RoleViewer = "viewer" // read the catalogue and manage their own playlists
+	RoleEditor = "editor" // create and edit groups, songs and artwork
+	RoleAdmin  = "admin"  // delete groups, purge data and manage users
+)
+
+// Roles lists every role from least to most privileged
+var Roles = []string{RoleViewer, RoleEditor, RoleAdmin}
+
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ValidRole reports whether role is a known role
+func ValidRole(role string) bool {
+	_, ok := roleRanks[role]
+	return ok
+}
+
+// RoleAllows reports whether a user with role may do what required grants
+func RoleAllows(role, required string) bool {
+	rank, ok := roleRanks[role]
+	return ok && rank >= roleRanks[required]
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"log/slog"
+	"music-service/internal/pkg/utils/parser"
+	"music-service/internal/pkg/utils/similarity"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+)
+
+const (
+	DefaultSimilarLimit = 10
+	MaxSimilarLimit     = 50
+
+	// similarityLoadBatch is how many songs are read at a time when the index is built at startup
+	similarityLoadBatch = 500
+)
+
+// SimilarSong is a song with its lyrics similarity to another song, from 0 to 1
+type SimilarSong struct {
+	Song  database.GetSongsWithPaginationRow
+	Score float64
+}
+
+// SimilarityService recommends songs with similar lyrics from an in-memory index of all live songs.
+// The index is built at startup and kept up to date by the song service on every write,
+// recommendations reflect a write once the index has rebuilt its vectors in the background.
+type SimilarityService struct {
+	songRepo repository.SongRepositoryInterface
+	index    *similarity.Index
+	log      *slog.Logger
+}
+
+// NewSimilarityService creates a new similarity service with an empty index
+func NewSimilarityService(songRepo repository.SongRepositoryInterface, log *slog.Logger) *SimilarityService {
+	return &SimilarityService{
+		songRepo: songRepo,
+		index:    similarity.NewIndex(),
+		log:      log,
+	}
+}
+
+// LoadIndex indexes the lyrics of every live song
+func (s *SimilarityService) LoadIndex(ctx context.Context) error {
+	var afterID uuid.UUID
+	for {
+		rows, err := s.songRepo.GetSongsLyricsAfter(ctx, afterID, similarityLoadBatch)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			s.setLyrics(row.ID.Bytes, row.Lyrics)
+		}
+
+		if len(rows) < similarityLoadBatch {
+			s.index.Refresh()
+			return nil
+		}
+		afterID = rows[len(rows)-1].ID.Bytes
+	}
+}
+
+// IndexSong adds or refreshes a song in the index, deleted songs are removed from it
+func (s *SimilarityService) IndexSong(song database.Song) {
+	if song.DeletedAt.Valid {
+		s.index.Remove(song.ID.Bytes)
+		return
+	}
+	s.setLyrics(song.ID.Bytes, song.Lyrics)
+}
+
+// IndexLyrics adds or refreshes the lyrics of a live song in the index
+func (s *SimilarityService) IndexLyrics(id uuid.UUID, lyrics []byte) {
+	s.setLyrics(id, lyrics)
+}
+
+// RemoveSong drops a song from the index
+func (s *SimilarityService) RemoveSong(id uuid.UUID) {
+	s.index.Remove(id)
+}
+
+// GetSimilarSongs returns up to limit live songs whose lyrics are most similar to the song's
+func (s *SimilarityService) GetSimilarSongs(ctx context.Context, id uuid.UUID, limit int) ([]SimilarSong, error) {
+	song, err := s.songRepo.GetSong(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return nil, ErrSongNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	matches := s.index.Similar(id, limit)
+	if len(matches) == 0 {
+		return []SimilarSong{}, nil
+	}
+
+	ids := make([]uuid.UUID, 0, len(matches))
+	for _, match := range matches {
+		ids = append(ids, match.ID)
+	}
+
+	songs, err := s.songRepo.GetSongsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uuid.UUID]database.GetSongsWithPaginationRow, len(songs))
+	for _, row := range songs {
+		byID[row.ID.Bytes] = row
+	}
+
+	similar := make([]SimilarSong, 0, len(matches))
+	for _, match := range matches {
+		// Songs deleted since they were indexed are skipped
+		if row, ok := byID[match.ID]; ok {
+			similar = append(similar, SimilarSong{Song: row, Score: match.Score})
+		}
+	}
+	return similar, nil
+}
+
+// LyricsSimilarity returns how similar the lyrics of two songs are, ok is false when either has no indexed lyrics
+func (s *SimilarityService) LyricsSimilarity(a, b uuid.UUID) (float64, bool) {
+	return s.index.Score(a, b)
+}
+
+// NearestSongs returns the IDs and scores of up to limit songs with the most similar lyrics, without database lookups
+func (s *SimilarityService) NearestSongs(id uuid.UUID, limit int) []similarity.Match {
+	return s.index.Similar(id, limit)
+}
+
+func (s *SimilarityService) setLyrics(id uuid.UUID, lyrics []byte) {
+	verses, err := parser.LyricsVerses(lyrics)
+	if err != nil {
+		s.log.Warn("Failed to parse lyrics for similarity index", "song_id", id.String(), "error", err)
+		s.index.Remove(id)
+		return
+	}
+	s.index.Set(id, verses)
+}
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/pkg/utils/constants"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"strings"
+	"time"
+)
+
+const (
+	DefaultSmartPlaylistLimit = 100
+	MaxSmartPlaylistLimit     = 500
+)
+
+var (
+	ErrSmartPlaylistNotFound = NewError(KindNotFound, "smart_playlist_not_found", "Smart playlist not found")
+	ErrInvalidRules          = NewValidationError("invalid_rules", "Invalid smart playlist rules")
+)
+
+// SmartPlaylistService handles smart playlists whose songs are selected by stored rules on every read
+type SmartPlaylistService struct {
+	smartPlaylistRepo repository.SmartPlaylistRepositoryInterface
+	songRepo          repository.SongRepositoryInterface
+}
+
+// NewSmartPlaylistService creates a new smart playlist service
+func NewSmartPlaylistService(smartPlaylistRepo repository.SmartPlaylistRepositoryInterface, songRepo repository.SongRepositoryInterface) *SmartPlaylistService {
+	return &SmartPlaylistService{
+		smartPlaylistRepo: smartPlaylistRepo,
+		songRepo:          songRepo,
+	}
+}
+
+func (s *SmartPlaylistService) CreateSmartPlaylist(ctx context.Context, params repository.SmartPlaylistCreateParams, rules repository.SongRules) (database.SmartPlaylist, error) {
+	rulesJSON, err := encodeRules(rules)
+	if err != nil {
+		return database.SmartPlaylist{}, err
+	}
+
+	params.Rules = rulesJSON
+	return s.smartPlaylistRepo.CreateSmartPlaylist(ctx, params)
+}
+
+// GetSmartPlaylist returns a smart playlist the viewer may read, private ones of others are reported as not found
+func (s *SmartPlaylistService) GetSmartPlaylist(ctx context.Context, viewer Principal, id uuid.UUID) (database.SmartPlaylist, error) {
+	playlist, err := s.smartPlaylistRepo.GetSmartPlaylist(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && !canReadPlaylist(viewer, playlist.OwnerID, playlist.Visibility)) {
+		return database.SmartPlaylist{}, ErrSmartPlaylistNotFound
+	}
+	return playlist, err
+}
+
+func (s *SmartPlaylistService) GetSmartPlaylistsWithPagination(ctx context.Context, viewer Principal, params repository.PlaylistFilterParams) ([]database.SmartPlaylist, error) {
+	return s.smartPlaylistRepo.GetSmartPlaylistsWithPagination(ctx, withViewer(viewer, params))
+}
+
+func (s *SmartPlaylistService) GetSmartPlaylistsCount(ctx context.Context, viewer Principal, params repository.PlaylistFilterParams) (int64, error) {
+	return s.smartPlaylistRepo.GetSmartPlaylistsCount(ctx, withViewer(viewer, params))
+}
+
+// UpdateSmartPlaylist changes a smart playlist owned by the viewer, admins can change any smart playlist
+func (s *SmartPlaylistService) UpdateSmartPlaylist(ctx context.Context, viewer Principal, params repository.SmartPlaylistUpdateParams, rules repository.SongRules) (database.SmartPlaylist, error) {
+	rulesJSON, err := encodeRules(rules)
+	if err != nil {
+		return database.SmartPlaylist{}, err
+	}
+
+	if err = s.checkOwner(ctx, viewer, params.ID); err != nil {
+		return database.SmartPlaylist{}, err
+	}
+
+	params.Rules = rulesJSON
+	playlist, err := s.smartPlaylistRepo.UpdateSmartPlaylist(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.SmartPlaylist{}, ErrSmartPlaylistNotFound
+	}
+	return playlist, err
+}
+
+// DeleteSmartPlaylist deletes a smart playlist owned by the viewer, admins can delete any smart playlist
+func (s *SmartPlaylistService) DeleteSmartPlaylist(ctx context.Context, viewer Principal, id uuid.UUID) error {
+	if err := s.checkOwner(ctx, viewer, id); err != nil {
+		return err
+	}
+
+	deleted, err := s.smartPlaylistRepo.DeleteSmartPlaylist(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return ErrSmartPlaylistNotFound
+	}
+	return nil
+}
+
+// checkOwner refuses changes to a smart playlist by anyone but its owner and admins
+func (s *SmartPlaylistService) checkOwner(ctx context.Context, viewer Principal, id uuid.UUID) error {
+	playlist, err := s.smartPlaylistRepo.GetSmartPlaylist(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return ErrSmartPlaylistNotFound
+	}
+	if err != nil {
+		return err
+	}
+	return ownerError(viewer, playlist.OwnerID, playlist.Visibility, ErrSmartPlaylistNotFound)
+}
+
+// DecodeRules reads the rules stored with a smart playlist
+func (s *SmartPlaylistService) DecodeRules(playlist database.SmartPlaylist) (repository.SongRules, error) {
+	var rules repository.SongRules
+	if err := json.Unmarshal(playlist.Rules, &rules); err != nil {
+		return repository.SongRules{}, err
+	}
+	return rules, nil
+}
+
+// EvaluateRules validates the rules and returns the songs they currently select
+func (s *SmartPlaylistService) EvaluateRules(ctx context.Context, rules repository.SongRules) ([]repository.RuledSongRow, error) {
+	if err := NormalizeRules(&rules); err != nil {
+		return nil, err
+	}
+	return s.songRepo.GetSongsByRules(ctx, rules)
+}
+
+// NormalizeRules validates the rules in place, applying defaults and normalising tags
+func NormalizeRules(rules *repository.SongRules) error {
+	var problems []FieldError
+
+	var from, to time.Time
+	var err error
+	if rules.ReleaseDateFrom != "" {
+		if from, err = time.Parse(constants.DateFormat, rules.ReleaseDateFrom); err != nil {
+			problems = append(problems, FieldError{Field: "release_date_from", Message: "must be in YYYY-MM-DD format"})
+		}
+	}
+	if rules.ReleaseDateTo != "" {
+		if to, err = time.Parse(constants.DateFormat, rules.ReleaseDateTo); err != nil {
+			problems = append(problems, FieldError{Field: "release_date_to", Message: "must be in YYYY-MM-DD format"})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		problems = append(problems, FieldError{Field: "release_date_to", Message: "must not be before release_date_from"})
+	}
+
+	if rules.RuntimeMin != nil && *rules.RuntimeMin < 0 {
+		problems = append(problems, FieldError{Field: "runtime_min", Message: "must not be negative"})
+	}
+	if rules.RuntimeMax != nil && *rules.RuntimeMax < 0 {
+		problems = append(problems, FieldError{Field: "runtime_max", Message: "must not be negative"})
+	}
+	if rules.RuntimeMin != nil && rules.RuntimeMax != nil && *rules.RuntimeMax < *rules.RuntimeMin {
+		problems = append(problems, FieldError{Field: "runtime_max", Message: "must not be less than runtime_min"})
+	}
+
+	rules.Tags = NormalizeTags(rules.Tags)
+	switch rules.TagsMatch {
+	case "":
+		rules.TagsMatch = repository.TagsMatchAny
+	case repository.TagsMatchAny, repository.TagsMatchAll:
+	default:
+		problems = append(problems, FieldError{Field: "tags_match", Message: "must be either any or all"})
+	}
+
+	for _, field := range rules.Sort {
+		if _, ok := repository.SongSortColumns[strings.TrimPrefix(field, "-")]; !ok {
+			problems = append(problems, FieldError{Field: "sort", Message: fmt.Sprintf("has unknown field %q", field)})
+		}
+	}
+
+	switch {
+	case rules.Limit == 0:
+		rules.Limit = DefaultSmartPlaylistLimit
+	case rules.Limit < 0 || rules.Limit > MaxSmartPlaylistLimit:
+		problems = append(problems, FieldError{Field: "limit", Message: fmt.Sprintf("must be between 1 and %d", MaxSmartPlaylistLimit)})
+	}
+
+	if len(problems) > 0 {
+		return ErrInvalidRules.WithFields(problems...)
+	}
+	return nil
+}
+
+func encodeRules(rules repository.SongRules) ([]byte, error) {
+	if err := NormalizeRules(&rules); err != nil {
+		return nil, err
+	}
+	return json.Marshal(rules)
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"errors"
+	"music-service/internal/storage/database/repository"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeRules(t *testing.T) {
+	runtime := func(v int32) *int32 { return &v }
+
+	tests := []struct {
+		name     string
+		rules    repository.SongRules
+		want     repository.SongRules
+		problems []string
+	}{
+		{
+			name:  "defaults",
+			rules: repository.SongRules{},
+			want:  repository.SongRules{Tags: []string{}, TagsMatch: repository.TagsMatchAny, Limit: DefaultSmartPlaylistLimit},
+		},
+		{
+			name:  "tags are lower-cased, trimmed and deduplicated",
+			rules: repository.SongRules{Tags: []string{" Rock", "rock", "", "LIVE "}, TagsMatch: repository.TagsMatchAll, Limit: 5},
+			want:  repository.SongRules{Tags: []string{"rock", "live"}, TagsMatch: repository.TagsMatchAll, Limit: 5},
+		},
+		{
+			name:  "valid ranges and sort",
+			rules: repository.SongRules{ReleaseDateFrom: "1975-01-01", ReleaseDateTo: "1975-01-01", RuntimeMin: runtime(60), RuntimeMax: runtime(60), Sort: []string{"-group", "title"}},
+			want: repository.SongRules{ReleaseDateFrom: "1975-01-01", ReleaseDateTo: "1975-01-01", RuntimeMin: runtime(60), RuntimeMax: runtime(60),
+				Tags: []string{}, TagsMatch: repository.TagsMatchAny, Sort: []string{"-group", "title"}, Limit: DefaultSmartPlaylistLimit},
+		},
+		{
+			name:     "malformed dates",
+			rules:    repository.SongRules{ReleaseDateFrom: "1975", ReleaseDateTo: "31.12.1975"},
+			problems: []string{"release_date_from must be in YYYY-MM-DD format", "release_date_to must be in YYYY-MM-DD format"},
+		},
+		{
+			name:     "reversed ranges",
+			rules:    repository.SongRules{ReleaseDateFrom: "1980-01-01", ReleaseDateTo: "1975-01-01", RuntimeMin: runtime(300), RuntimeMax: runtime(60)},
+			problems: []string{"release_date_to must not be before release_date_from", "runtime_max must not be less than runtime_min"},
+		},
+		{
+			name:     "negative runtimes",
+			rules:    repository.SongRules{RuntimeMin: runtime(-1), RuntimeMax: runtime(-1)},
+			problems: []string{"runtime_min must not be negative", "runtime_max must not be negative"},
+		},
+		{
+			name:     "tag match, sort and limit",
+			rules:    repository.SongRules{TagsMatch: "some", Sort: []string{"-lyrics"}, Limit: MaxSmartPlaylistLimit + 1},
+			problems: []string{"tags_match must be either any or all", `sort has unknown field "-lyrics"`, "limit must be between 1 and 500"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := tt.rules
+			err := NormalizeRules(&rules)
+
+			if tt.problems != nil {
+				var invalid *Error
+				if !errors.Is(err, ErrInvalidRules) || !errors.As(err, &invalid) {
+					t.Fatalf("NormalizeRules() error = %v, want ErrInvalidRules", err)
+				}
+				problems := make([]string, len(invalid.Fields))
+				for i, field := range invalid.Fields {
+					problems[i] = field.Field + " " + field.Message
+				}
+				if !reflect.DeepEqual(problems, tt.problems) {
+					t.Errorf("problems = %q, want %q", problems, tt.problems)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeRules() error = %v", err)
+			}
+			if !reflect.DeepEqual(rules, tt.want) {
+				t.Errorf("rules = %+v, want %+v", rules, tt.want)
+			}
+		})
+	}
+}
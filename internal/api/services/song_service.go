@@ -2,29 +2,76 @@ package services
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/pkg/utils/constants"
+	"music-service/internal/pkg/utils/songquery"
 	"music-service/internal/storage/database"
 	"music-service/internal/storage/database/repository"
+	"strings"
+	"time"
+)
+
+var (
+	ErrSongGroupDeleted = NewError(KindConflict, "song_group_deleted", "The group of the song is deleted, restore the group instead")
+	// ErrUnknownGroup is returned when a song is given a group that does not exist or is deleted
+	ErrUnknownGroup = NewError(KindReference, "unknown_group", "Group not found").WithFields(FieldError{Field: "group_id", Message: "does not refer to a group"})
+	// ErrPreconditionFailed is returned by conditional writes to a song or group that changed since the version given
+	ErrPreconditionFailed = NewError(KindPreconditionFailed, "precondition_failed", "The resource has been modified since it was read")
+	// ErrInvalidSongFilter is returned for song listings with invalid filters or sort
+	ErrInvalidSongFilter = NewValidationError("invalid_filter", "The filter or sort of the song listing is invalid")
+	// ErrInvalidSongQuery is returned for search queries that cannot be parsed, every problem is a field error of query
+	ErrInvalidSongQuery = NewValidationError("invalid_query", "The search query is invalid")
 )
 
 // SongService handles business logic for songs
 type SongService struct {
-	songRepo repository.SongRepositoryInterface
+	songRepo          repository.SongRepositoryInterface
+	groupRepo         repository.GroupRepositoryInterface
+	similarityService *SimilarityService
 }
 
 // NewSongService creates a new song service
-func NewSongService(songRepo repository.SongRepositoryInterface) *SongService {
+func NewSongService(songRepo repository.SongRepositoryInterface, groupRepo repository.GroupRepositoryInterface, similarityService *SimilarityService) *SongService {
 	return &SongService{
-		songRepo: songRepo,
+		songRepo:          songRepo,
+		groupRepo:         groupRepo,
+		similarityService: similarityService,
 	}
 }
 
-func (s *SongService) CreateSong(ctx context.Context, params repository.SongCreateParams) (database.Song, error) {
-	return s.songRepo.CreateSong(ctx, params)
+// CreateSong creates a song in a live group and returns it with its group
+func (s *SongService) CreateSong(ctx context.Context, params repository.SongCreateParams) (database.GetSongsWithPaginationRow, error) {
+	if err := s.checkGroup(ctx, params.GroupID); err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+
+	song, err := s.songRepo.CreateSong(ctx, params)
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	s.similarityService.IndexLyrics(song.ID.Bytes, song.Lyrics)
+	return song, nil
 }
 
+// GetSong returns a live song, deleted songs are reported as ErrSongNotFound
 func (s *SongService) GetSong(ctx context.Context, id uuid.UUID) (database.Song, error) {
-	return s.songRepo.GetSong(ctx, id)
+	song, err := s.songRepo.GetSong(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) || (err == nil && song.DeletedAt.Valid) {
+		return database.Song{}, ErrSongNotFound
+	}
+	return song, err
+}
+
+// GetSongWithGroup returns a live song together with its group, its lyrics are only read when withLyrics is set
+func (s *SongService) GetSongWithGroup(ctx context.Context, id uuid.UUID, withLyrics bool) (database.GetSongsWithPaginationRow, error) {
+	song, err := s.songRepo.GetSongWithGroup(ctx, id, withLyrics)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.GetSongsWithPaginationRow{}, ErrSongNotFound
+	}
+	return song, err
 }
 
 func (s *SongService) GetSongsCount(ctx context.Context) (int64, error) {
@@ -35,22 +82,227 @@ func (s *SongService) GetSongsWithPagination(ctx context.Context, limit, offset
 	return s.songRepo.GetSongsWithPagination(ctx, limit, offset)
 }
 
-func (s *SongService) UpdateSong(ctx context.Context, params repository.SongUpdateParams) (database.Song, error) {
-	return s.songRepo.UpdateSong(ctx, params)
+// UpdateSong updates a live song, which may be moved to another live group, and returns it with its group.
+// With a non-zero IfUpdatedAt the song must still be at that version.
+func (s *SongService) UpdateSong(ctx context.Context, params repository.SongUpdateParams) (database.GetSongsWithPaginationRow, error) {
+	if _, err := s.GetSong(ctx, params.ID); err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	if err := s.checkGroup(ctx, params.GroupID); err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+
+	song, err := s.songRepo.UpdateSong(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.GetSongsWithPaginationRow{}, s.writeMissed(ctx, params.ID)
+	}
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	s.similarityService.IndexLyrics(song.ID.Bytes, song.Lyrics)
+	return song, nil
 }
 
 func (s *SongService) GetSongsByGroup(ctx context.Context, groupID uuid.UUID, limit, offset int32) ([]database.Song, error) {
 	return s.songRepo.GetSongsByGroup(ctx, groupID, limit, offset)
 }
 
-func (s *SongService) GetSongsWithFilters(ctx context.Context, params repository.SongFilterParams) ([]database.GetSongsWithPaginationRow, error) {
+// GetSongsWithFilters returns a page of the songs matching params and how many match in total, see ValidateSongFilter
+func (s *SongService) GetSongsWithFilters(ctx context.Context, params repository.SongFilterParams) ([]database.GetSongsWithPaginationRow, int64, error) {
+	if err := ValidateSongFilter(params); err != nil {
+		return nil, 0, err
+	}
 	return s.songRepo.GetSongsWithFilters(ctx, params)
 }
 
-func (s *SongService) GetSongsCountWithFilters(ctx context.Context, groupName, songTitle string) (int64, error) {
-	return s.songRepo.GetSongsCountWithFilters(ctx, groupName, songTitle)
+// GetSongsWithCursor returns the page of songs after or before cursor together with the cursors around it
+func (s *SongService) GetSongsWithCursor(ctx context.Context, params repository.SongFilterParams, cursor string) ([]database.GetSongsWithPaginationRow, repository.CursorPage, error) {
+	if err := ValidateSongFilter(params); err != nil {
+		return nil, repository.CursorPage{}, err
+	}
+	songs, page, err := s.songRepo.GetSongsWithCursor(ctx, params, cursor)
+	return songs, page, cursorError(err)
+}
+
+// ParseSongQuery parses a search query of song listings, an empty query gives a nil expression
+func ParseSongQuery(query string) (songquery.Expr, error) {
+	expr, err := songquery.Parse(query)
+	var queryErrs songquery.Errors
+	if errors.As(err, &queryErrs) {
+		problems := make([]FieldError, 0, len(queryErrs))
+		for _, queryErr := range queryErrs {
+			problems = append(problems, FieldError{Field: "query", Message: queryErr.Error()})
+		}
+		return nil, ErrInvalidSongQuery.WithFields(problems...)
+	}
+	return expr, err
+}
+
+// ValidateSongFilter checks the ranges and sort of a song listing and reports every problem at once
+func ValidateSongFilter(params repository.SongFilterParams) error {
+	var problems []FieldError
+
+	var from, to time.Time
+	var err error
+	if params.ReleaseDateFrom != "" {
+		if from, err = time.Parse(constants.DateFormat, params.ReleaseDateFrom); err != nil {
+			problems = append(problems, FieldError{Field: "release_date_from", Message: "must be in YYYY-MM-DD format"})
+		}
+	}
+	if params.ReleaseDateTo != "" {
+		if to, err = time.Parse(constants.DateFormat, params.ReleaseDateTo); err != nil {
+			problems = append(problems, FieldError{Field: "release_date_to", Message: "must be in YYYY-MM-DD format"})
+		}
+	}
+	if !from.IsZero() && !to.IsZero() && to.Before(from) {
+		problems = append(problems, FieldError{Field: "release_date_to", Message: "must not be before release_date_from"})
+	}
+
+	if params.RuntimeMin != nil && *params.RuntimeMin < 0 {
+		problems = append(problems, FieldError{Field: "runtime_min", Message: "must not be negative"})
+	}
+	if params.RuntimeMax != nil && *params.RuntimeMax < 0 {
+		problems = append(problems, FieldError{Field: "runtime_max", Message: "must not be negative"})
+	}
+	if params.RuntimeMin != nil && params.RuntimeMax != nil && *params.RuntimeMax < *params.RuntimeMin {
+		problems = append(problems, FieldError{Field: "runtime_max", Message: "must not be less than runtime_min"})
+	}
+
+	seen := make(map[string]bool, len(params.Sort))
+	for _, field := range params.Sort {
+		name := strings.TrimPrefix(field, "-")
+		if _, ok := repository.SongListSortKeys[name]; !ok {
+			problems = append(problems, FieldError{Field: "sort", Message: fmt.Sprintf("has unknown field %q", field)})
+			continue
+		}
+		if seen[name] {
+			problems = append(problems, FieldError{Field: "sort", Message: fmt.Sprintf("has field %q more than once", name)})
+		}
+		seen[name] = true
+	}
+
+	if len(problems) > 0 {
+		return ErrInvalidSongFilter.WithFields(problems...)
+	}
+	return nil
+}
+
+// DeleteSong soft-deletes a live song, with a non-zero ifUpdatedAt the song must still be at that version
+func (s *SongService) DeleteSong(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) error {
+	deleted, err := s.songRepo.DeleteSong(ctx, id, ifUpdatedAt)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return s.writeMissed(ctx, id)
+	}
+	s.similarityService.RemoveSong(id)
+	return nil
+}
+
+// PatchSong updates the supplied fields of a live song, a new group must be live as well.
+// It returns the song with its group. With a non-zero IfUpdatedAt the song must still be at that version.
+func (s *SongService) PatchSong(ctx context.Context, params repository.SongPatchParams) (database.GetSongsWithPaginationRow, error) {
+	if params.GroupID == nil && params.Title == nil && params.Runtime == nil && params.Lyrics == nil &&
+		params.ReleaseDate == nil && params.Link == nil {
+		return s.GetSongWithGroup(ctx, params.ID, true)
+	}
+	if _, err := s.GetSong(ctx, params.ID); err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	if params.GroupID != nil {
+		if err := s.checkGroup(ctx, *params.GroupID); err != nil {
+			return database.GetSongsWithPaginationRow{}, err
+		}
+	}
+
+	song, err := s.songRepo.PatchSong(ctx, params)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.GetSongsWithPaginationRow{}, s.writeMissed(ctx, params.ID)
+	}
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	if params.Lyrics != nil {
+		s.similarityService.IndexLyrics(song.ID.Bytes, song.Lyrics)
+	}
+	return song, nil
+}
+
+// writeMissed explains a conditional write that matched no row: the song is gone or it has changed
+func (s *SongService) writeMissed(ctx context.Context, id uuid.UUID) error {
+	if _, err := s.GetSong(ctx, id); err != nil {
+		return err
+	}
+	return ErrPreconditionFailed
+}
+
+// checkGroup makes sure a song is given a live group, reporting a missing one as ErrUnknownGroup
+func (s *SongService) checkGroup(ctx context.Context, groupID uuid.UUID) error {
+	_, err := getLiveGroup(ctx, s.groupRepo, groupID)
+	if errors.Is(err, ErrGroupNotFound) {
+		return ErrUnknownGroup
+	}
+	return err
 }
 
-func (s *SongService) DeleteSong(ctx context.Context, id uuid.UUID) error {
-	return s.songRepo.DeleteSong(ctx, id)
+// RestoreSong undeletes a song and returns it with its group. A song whose group is deleted cannot be restored
+// on its own, restoring the group brings back the songs deleted with it. Restoring a live song changes nothing.
+func (s *SongService) RestoreSong(ctx context.Context, id uuid.UUID) (database.GetSongsWithPaginationRow, error) {
+	song, err := s.songRepo.GetSong(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.GetSongsWithPaginationRow{}, ErrSongNotFound
+	}
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	if !song.DeletedAt.Valid {
+		return s.GetSongWithGroup(ctx, id, true)
+	}
+
+	if _, err = getLiveGroup(ctx, s.groupRepo, song.GroupID.Bytes); err != nil {
+		if errors.Is(err, ErrGroupNotFound) {
+			return database.GetSongsWithPaginationRow{}, ErrSongGroupDeleted
+		}
+		return database.GetSongsWithPaginationRow{}, err
+	}
+
+	restored, err := s.songRepo.RestoreSong(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		// Restored by a concurrent request
+		return s.GetSongWithGroup(ctx, id, true)
+	}
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	s.similarityService.IndexLyrics(restored.ID.Bytes, restored.Lyrics)
+	return restored, nil
+}
+
+func (s *SongService) GetSongTags(ctx context.Context, id uuid.UUID) ([]string, error) {
+	return s.songRepo.GetSongTags(ctx, id)
+}
+
+// ReplaceSongTags normalises the tags and makes them the complete tag set of the song
+func (s *SongService) ReplaceSongTags(ctx context.Context, id uuid.UUID, tags []string) ([]string, error) {
+	tags = NormalizeTags(tags)
+	if err := s.songRepo.ReplaceSongTags(ctx, id, tags); err != nil {
+		return nil, err
+	}
+	return tags, nil
+}
+
+// NormalizeTags lower-cases and trims tags, dropping empty and duplicate ones
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
 }
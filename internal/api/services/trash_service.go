@@ -0,0 +1,179 @@
+package services
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"log/slog"
+	"music-service/internal/config"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"time"
+)
+
+// purgeBatchSize bounds how many expired groups and songs one purge transaction takes on
+const purgeBatchSize = 500
+
+var ErrInvalidTrashType = NewValidationError("invalid_trash_type", "type must be group or song", FieldError{Field: "type", Message: "must be group or song"})
+
+// PurgeReport counts the groups and songs a purge deleted for good
+type PurgeReport struct {
+	Groups int64 `json:"groups"`
+	Songs  int64 `json:"songs"`
+}
+
+// TrashService lists soft-deleted groups and songs and purges them once the retention period is over
+type TrashService struct {
+	dbManager      *repository.Manager
+	artworkService *ArtworkService
+	retention      time.Duration
+	batchSize      int32
+	log            *slog.Logger
+}
+
+// NewTrashService creates a new trash service
+func NewTrashService(dbManager *repository.Manager, artworkService *ArtworkService, cfg *config.Config, log *slog.Logger) *TrashService {
+	return &TrashService{
+		dbManager:      dbManager,
+		artworkService: artworkService,
+		retention:      cfg.Internal.Trash.Retention,
+		batchSize:      purgeBatchSize,
+		log:            log,
+	}
+}
+
+// Retention is how long deleted groups and songs are kept
+func (s *TrashService) Retention() time.Duration {
+	return s.retention
+}
+
+// GetTrashWithPagination lists deleted groups and songs, most recently deleted first.
+// The entity type is group, song or empty for both.
+func (s *TrashService) GetTrashWithPagination(ctx context.Context, entityType string, limit, offset int32) ([]database.GetTrashWithPaginationRow, error) {
+	if !validTrashType(entityType) {
+		return nil, ErrInvalidTrashType
+	}
+	return s.dbManager.Trash.GetTrashWithPagination(ctx, entityType, limit, offset)
+}
+
+func (s *TrashService) GetTrashCount(ctx context.Context, entityType string) (int64, error) {
+	if !validTrashType(entityType) {
+		return 0, ErrInvalidTrashType
+	}
+	return s.dbManager.Trash.GetTrashCount(ctx, entityType)
+}
+
+// PurgeExpired permanently deletes the groups and songs deleted longer ago than the retention period
+func (s *TrashService) PurgeExpired(ctx context.Context) (PurgeReport, error) {
+	return s.Purge(ctx, time.Now().Add(-s.retention))
+}
+
+// Purge permanently deletes the groups and songs deleted before the cutoff together with their favorites,
+// artwork, playlist entries, tags, plays and ratings. A group is only purged once none of its songs is live,
+// its deleted songs are purged with it. The work is split into batches that each commit on their own.
+func (s *TrashService) Purge(ctx context.Context, cutoff time.Time) (PurgeReport, error) {
+	var report PurgeReport
+	for {
+		batch, more, err := s.purgeBatch(ctx, cutoff)
+		report.Groups += batch.Groups
+		report.Songs += batch.Songs
+		if err != nil || !more {
+			return report, err
+		}
+	}
+}
+
+// purgeBatch purges up to batchSize groups and songs and reports whether more may be left
+func (s *TrashService) purgeBatch(ctx context.Context, cutoff time.Time) (PurgeReport, bool, error) {
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+	defer tx.Rollback(ctx)
+
+	groupIDs, err := tx.Repos.Trash.GetPurgeableGroupIDs(ctx, cutoff, s.batchSize)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+	songIDs, err := tx.Repos.Trash.GetPurgeableSongIDs(ctx, cutoff, s.batchSize)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+	more := len(groupIDs) == int(s.batchSize) || len(songIDs) == int(s.batchSize)
+
+	// Songs of a purged group go with it however recently they were deleted
+	if len(groupIDs) > 0 {
+		groupSongIDs, err := tx.Repos.Trash.GetGroupsSongIDsForUpdate(ctx, groupIDs)
+		if err != nil {
+			return PurgeReport{}, false, err
+		}
+		songIDs = mergeIDs(songIDs, groupSongIDs)
+	}
+	if len(groupIDs) == 0 && len(songIDs) == 0 {
+		return PurgeReport{}, false, nil
+	}
+
+	playlistIDs, err := tx.Repos.Playlists.TouchSongsPlaylists(ctx, songIDs)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+	if _, err = tx.Repos.Library.DeleteEntitiesFavorites(ctx, repository.FavoriteEntitySong, songIDs); err != nil {
+		return PurgeReport{}, false, err
+	}
+	if _, err = tx.Repos.Library.DeleteEntitiesFavorites(ctx, repository.FavoriteEntityGroup, groupIDs); err != nil {
+		return PurgeReport{}, false, err
+	}
+
+	songArtworks, err := tx.Repos.Artworks.DeleteArtworksByEntities(ctx, repository.ArtworkEntitySong, songIDs)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+	groupArtworks, err := tx.Repos.Artworks.DeleteArtworksByEntities(ctx, repository.ArtworkEntityGroup, groupIDs)
+	if err != nil {
+		return PurgeReport{}, false, err
+	}
+
+	var report PurgeReport
+	if report.Songs, err = tx.Repos.Trash.PurgeSongs(ctx, songIDs); err != nil {
+		return PurgeReport{}, false, err
+	}
+	if report.Groups, err = tx.Repos.Trash.PurgeGroups(ctx, groupIDs); err != nil {
+		return PurgeReport{}, false, err
+	}
+
+	// The entries of the purged songs are gone, close the gaps they left
+	if err = tx.Repos.Playlists.RenumberPlaylistEntries(ctx, playlistIDs); err != nil {
+		return PurgeReport{}, false, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return PurgeReport{}, false, err
+	}
+
+	// The records are gone, files that fail to delete are only orphaned
+	for _, artwork := range append(songArtworks, groupArtworks...) {
+		if err := s.artworkService.DeleteArtworkFiles(ctx, artwork); err != nil {
+			s.log.Warn("Failed to delete purged artwork files", "entity_type", artwork.EntityType, "entity_id", artwork.EntityID.String(), "error", err)
+		}
+	}
+
+	return report, more, nil
+}
+
+func validTrashType(entityType string) bool {
+	return entityType == "" || entityType == repository.TrashEntityGroup || entityType == repository.TrashEntitySong
+}
+
+// mergeIDs appends the IDs of b missing from a
+func mergeIDs(a, b []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(a))
+	for _, id := range a {
+		seen[id] = true
+	}
+	for _, id := range b {
+		if !seen[id] {
+			seen[id] = true
+			a = append(a, id)
+		}
+	}
+	return a
+}
@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"log/slog"
+	"music-service/internal/config"
+	"music-service/internal/storage/database/dbtest"
+	"music-service/internal/storage/database/repository"
+	"testing"
+	"time"
+)
+
+func TestTrashPurge(t *testing.T) {
+	pool := dbtest.Open(t)
+	m := repository.NewManager(pool)
+	ctx := context.Background()
+
+	cfg := &config.Config{}
+	cfg.Internal.Trash.Retention = time.Hour
+	service := NewTrashService(m, nil, cfg, slog.New(slog.DiscardHandler))
+
+	// Deletion states of a group or song, expired ones were deleted before the cutoff
+	const (
+		live    = "live"
+		recent  = "recent"
+		expired = "expired"
+	)
+
+	tests := []struct {
+		name          string
+		batchSize     int32
+		group         string
+		songs         []string
+		wantGroups    int64
+		wantSongs     int64
+		wantGroupGone bool
+		wantSongsGone []bool
+	}{
+		{
+			name:          "expired songs are purged over several batches",
+			batchSize:     2,
+			group:         live,
+			songs:         []string{expired, expired, expired, expired, expired},
+			wantSongs:     5,
+			wantSongsGone: []bool{true, true, true, true, true},
+		},
+		{
+			name:          "recently deleted and live songs are kept",
+			batchSize:     2,
+			group:         live,
+			songs:         []string{expired, recent, live, expired},
+			wantSongs:     2,
+			wantSongsGone: []bool{true, false, false, true},
+		},
+		{
+			name:          "an expired group takes its songs however recently they were deleted",
+			batchSize:     purgeBatchSize,
+			group:         expired,
+			songs:         []string{expired, recent},
+			wantGroups:    1,
+			wantSongs:     2,
+			wantGroupGone: true,
+			wantSongsGone: []bool{true, true},
+		},
+		{
+			name:          "an expired group with a live song is kept",
+			batchSize:     purgeBatchSize,
+			group:         expired,
+			songs:         []string{live, expired},
+			wantSongs:     1,
+			wantSongsGone: []bool{false, true},
+		},
+		{
+			name:          "a recently deleted group is kept while its expired songs are purged",
+			batchSize:     1,
+			group:         recent,
+			songs:         []string{expired, recent},
+			wantSongs:     1,
+			wantSongsGone: []bool{true, false},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			cutoff := now.Add(-time.Hour)
+			deletedAt := map[string]time.Time{recent: now, expired: cutoff.Add(-time.Hour)}
+
+			groupID := createTestGroup(t, m, tt.name)
+			songIDs := make([]uuid.UUID, len(tt.songs))
+			for i, state := range tt.songs {
+				songIDs[i] = createTestSong(t, m, groupID, fmt.Sprintf("Song %d", i))
+				if state != live {
+					setDeletedAt(t, pool, "songs", songIDs[i], deletedAt[state])
+				}
+			}
+			if tt.group != live {
+				setDeletedAt(t, pool, "groups", groupID, deletedAt[tt.group])
+			}
+
+			service.batchSize = tt.batchSize
+			report, err := service.Purge(ctx, cutoff)
+			if err != nil {
+				t.Fatalf("purging: %v", err)
+			}
+			if report.Groups != tt.wantGroups || report.Songs != tt.wantSongs {
+				t.Errorf("purged %d groups and %d songs, want %d and %d", report.Groups, report.Songs, tt.wantGroups, tt.wantSongs)
+			}
+
+			_, err = m.Groups.GetGroup(ctx, groupID)
+			if gone := errors.Is(err, pgx.ErrNoRows); gone != tt.wantGroupGone {
+				t.Errorf("group gone = %v, want %v (error %v)", gone, tt.wantGroupGone, err)
+			}
+			for i, songID := range songIDs {
+				_, err = m.Songs.GetSong(ctx, songID)
+				if gone := errors.Is(err, pgx.ErrNoRows); gone != tt.wantSongsGone[i] {
+					t.Errorf("song %d gone = %v, want %v (error %v)", i, gone, tt.wantSongsGone[i], err)
+				}
+			}
+		})
+	}
+}
+
+// setDeletedAt soft-deletes a group or song as of the given time
+func setDeletedAt(t *testing.T, pool *pgxpool.Pool, table string, id uuid.UUID, deletedAt time.Time) {
+	t.Helper()
+
+	_, err := pool.Exec(context.Background(), "UPDATE "+pgx.Identifier{table}.Sanitize()+" SET deleted_at = $2 WHERE id = $1", id, deletedAt)
+	if err != nil {
+		t.Fatalf("deleting from %s: %v", table, err)
+	}
+}
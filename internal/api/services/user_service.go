@@ -0,0 +1,82 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"music-service/internal/storage/database"
+	"music-service/internal/storage/database/repository"
+	"slices"
+)
+
+var (
+	ErrInvalidRole = NewValidationError("invalid_role", "Role must be one of viewer, editor or admin", FieldError{Field: "role", Message: "must be one of viewer, editor or admin"})
+	ErrLastAdmin   = NewError(KindConflict, "last_admin", "The last admin cannot be demoted")
+)
+
+// UserService handles user administration
+type UserService struct {
+	userRepo  repository.UserRepositoryInterface
+	dbManager *repository.Manager
+}
+
+// NewUserService creates a new user service
+func NewUserService(dbManager *repository.Manager) *UserService {
+	return &UserService{
+		userRepo:  dbManager.Users,
+		dbManager: dbManager,
+	}
+}
+
+func (s *UserService) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	user, err := s.userRepo.GetUser(ctx, id)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.User{}, ErrUserNotFound
+	}
+	return user, err
+}
+
+func (s *UserService) GetUsersWithPagination(ctx context.Context, params repository.UserFilterParams) ([]database.User, error) {
+	return s.userRepo.GetUsersWithPagination(ctx, params)
+}
+
+func (s *UserService) GetUsersCount(ctx context.Context, role string) (int64, error) {
+	return s.userRepo.GetUsersCount(ctx, role)
+}
+
+// UpdateUserRole changes the role of a user, refusing to leave the service without an admin.
+// The admin rows stay locked until the change is committed so concurrent demotions cannot both pass the check.
+func (s *UserService) UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (database.User, error) {
+	if !ValidRole(role) {
+		return database.User{}, ErrInvalidRole
+	}
+
+	tx, err := s.dbManager.BeginTx(ctx)
+	if err != nil {
+		return database.User{}, err
+	}
+	defer tx.Rollback(ctx)
+
+	admins, err := tx.Repos.Users.LockAdmins(ctx)
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if role != RoleAdmin && len(admins) <= 1 && slices.Contains(admins, id) {
+		return database.User{}, ErrLastAdmin
+	}
+
+	user, err := tx.Repos.Users.UpdateUserRole(ctx, id, role)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return database.User{}, ErrUserNotFound
+	}
+	if err != nil {
+		return database.User{}, err
+	}
+
+	if err = tx.Commit(ctx); err != nil {
+		return database.User{}, err
+	}
+	return user, nil
+}
@@ -13,7 +13,15 @@ const (
 	ReleaseEnv = "release"
 )
 
-const DefaultTimeout = 10 * time.Second
+const (
+	DefaultTimeout            = 10 * time.Second
+	DefaultTokenTTL           = 24 * time.Hour
+	DefaultTrashRetention     = 30 * 24 * time.Hour
+	DefaultTrashPurgeInterval = time.Hour
+
+	DefaultIdempotencyKeyTTL        = 24 * time.Hour
+	DefaultIdempotencyPurgeInterval = time.Hour
+)
 
 type Config struct {
 	AppName  string `yaml:"app_name"`
@@ -23,8 +31,12 @@ type Config struct {
 }
 
 type Internal struct {
-	Server   Server   `yaml:"server"`
-	Database Database `yaml:"database"`
+	Server      Server      `yaml:"server"`
+	Database    Database    `yaml:"database"`
+	Storage     Storage     `yaml:"storage"`
+	Auth        Auth        `yaml:"auth"`
+	Trash       Trash       `yaml:"trash"`
+	Idempotency Idempotency `yaml:"idempotency"`
 }
 
 type Server struct {
@@ -42,6 +54,29 @@ type Database struct {
 	Timezone string // will be set in MustLoad
 }
 
+type Storage struct {
+	Path    string `yaml:"path"`     // root directory for uploaded files
+	BaseURL string `yaml:"base_url"` // public URL prefix the files are served from
+}
+
+type Auth struct {
+	JWTSecret     string        `yaml:"jwt_secret"`     // HMAC key used to sign access tokens
+	TokenTTL      time.Duration `yaml:"token_ttl"`      // lifetime of issued access tokens
+	PublicReads   bool          `yaml:"public_reads"`   // allow GET requests without credentials
+	AdminUsername string        `yaml:"admin_username"` // admin account created at startup when it does not exist yet
+	AdminPassword string        `yaml:"admin_password"` // initial password of the admin account
+}
+
+type Trash struct {
+	Retention     time.Duration `yaml:"retention"`      // how long deleted groups and songs are kept before they are purged
+	PurgeInterval time.Duration `yaml:"purge_interval"` // how often the purge worker runs
+}
+
+type Idempotency struct {
+	KeyTTL        time.Duration `yaml:"key_ttl"`        // how long the response to a request with an Idempotency-Key is replayed
+	PurgeInterval time.Duration `yaml:"purge_interval"` // how often expired keys are deleted
+}
+
 func MustLoad() *Config {
 	const configPath = "configs/config.yml"
 
@@ -85,6 +120,7 @@ func MustLoad() *Config {
 		if envCfg.ProductionConfigs != nil {
 			cfg.Internal = *envCfg.ProductionConfigs
 			updateDbCredentials(&cfg.Internal.Database)
+			updateAuthSecrets(&cfg.Internal.Auth)
 		} else {
 			panic("production configs are not found")
 		}
@@ -96,6 +132,28 @@ func MustLoad() *Config {
 		}
 	}
 
+	if cfg.Internal.Auth.JWTSecret == "" {
+		log.Fatalf("auth jwt_secret is not set")
+	}
+	if (cfg.Internal.Auth.AdminUsername == "") != (cfg.Internal.Auth.AdminPassword == "") {
+		log.Fatalf("auth admin_username and admin_password must be set together")
+	}
+	if cfg.Internal.Auth.TokenTTL <= 0 {
+		cfg.Internal.Auth.TokenTTL = DefaultTokenTTL
+	}
+	if cfg.Internal.Trash.Retention <= 0 {
+		cfg.Internal.Trash.Retention = DefaultTrashRetention
+	}
+	if cfg.Internal.Trash.PurgeInterval <= 0 {
+		cfg.Internal.Trash.PurgeInterval = DefaultTrashPurgeInterval
+	}
+	if cfg.Internal.Idempotency.KeyTTL <= 0 {
+		cfg.Internal.Idempotency.KeyTTL = DefaultIdempotencyKeyTTL
+	}
+	if cfg.Internal.Idempotency.PurgeInterval <= 0 {
+		cfg.Internal.Idempotency.PurgeInterval = DefaultIdempotencyPurgeInterval
+	}
+
 	log.Println("Configurations loaded")
 	setTimezone(&cfg)
 
@@ -119,3 +177,15 @@ func updateDbCredentials(db *Database) {
 		db.Port = port
 	}
 }
+
+func updateAuthSecrets(auth *Auth) {
+	if secret := os.Getenv("JWT_SECRET"); secret != "" {
+		auth.JWTSecret = secret
+	}
+	if username := os.Getenv("ADMIN_USERNAME"); username != "" {
+		auth.AdminUsername = username
+	}
+	if password := os.Getenv("ADMIN_PASSWORD"); password != "" {
+		auth.AdminPassword = password
+	}
+}
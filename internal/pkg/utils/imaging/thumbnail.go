@@ -0,0 +1,102 @@
+package imaging
+
+import (
+	"errors"
+	"image"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+
+	"golang.org/x/image/draw"
+)
+
+const (
+	FormatJPEG = "jpeg"
+	FormatPNG  = "png"
+
+	// MaxPixels guards against decompression bombs with huge declared dimensions
+	MaxPixels = 40_000_000
+
+	jpegQuality = 85
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported image format, only JPEG and PNG are accepted")
+var ErrImageTooLarge = errors.New("image dimensions are too large")
+
+// ThumbnailSize describes one generated rendition, Size is the bounding box edge in pixels
+type ThumbnailSize struct {
+	Name string
+	Size int
+}
+
+// ThumbnailSizes is the fixed set of renditions generated for every uploaded artwork
+var ThumbnailSizes = []ThumbnailSize{
+	{Name: "small", Size: 64},
+	{Name: "medium", Size: 300},
+	{Name: "large", Size: 600},
+}
+
+// Decode reads a JPEG or PNG image and returns it together with its format name
+func Decode(r io.ReadSeeker) (image.Image, string, error) {
+	cfg, format, err := image.DecodeConfig(r)
+	if err != nil {
+		if errors.Is(err, image.ErrFormat) {
+			return nil, "", ErrUnsupportedFormat
+		}
+		return nil, "", err
+	}
+
+	if format != FormatJPEG && format != FormatPNG {
+		return nil, "", ErrUnsupportedFormat
+	}
+
+	if cfg.Width*cfg.Height > MaxPixels {
+		return nil, "", ErrImageTooLarge
+	}
+
+	if _, err = r.Seek(0, io.SeekStart); err != nil {
+		return nil, "", err
+	}
+
+	img, _, err := image.Decode(r)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return img, format, nil
+}
+
+// Thumbnail scales the image to fit into a size x size box keeping the aspect ratio.
+// Images that already fit are never upscaled.
+func Thumbnail(img image.Image, size int) image.Image {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+
+	if width <= size && height <= size {
+		dst := image.NewRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(dst, dst.Bounds(), img, bounds.Min, draw.Src)
+		return dst
+	}
+
+	if width >= height {
+		height = max(1, height*size/width)
+		width = size
+	} else {
+		width = max(1, width*size/height)
+		height = size
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.CatmullRom.Scale(dst, dst.Bounds(), img, bounds, draw.Src, nil)
+	return dst
+}
+
+// EncodeJPEG writes the image as JPEG, flattening any transparency onto white
+func EncodeJPEG(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	flat := image.NewRGBA(bounds)
+	draw.Draw(flat, bounds, image.White, image.Point{}, draw.Src)
+	draw.Draw(flat, bounds, img, bounds.Min, draw.Over)
+
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: jpegQuality})
+}
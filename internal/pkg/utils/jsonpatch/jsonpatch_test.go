@@ -0,0 +1,182 @@
+package jsonpatch
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func mustDecode(t *testing.T, data string) any {
+	t.Helper()
+
+	value, err := Decode([]byte(data))
+	if err != nil {
+		t.Fatalf("decoding %s: %v", data, err)
+	}
+	return value
+}
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		doc   string
+		patch string
+		want  string
+	}{
+		{"add member", `{"a":1}`, `[{"op":"add","path":"/b","value":2}]`, `{"a":1,"b":2}`},
+		{"add replaces member", `{"a":1}`, `[{"op":"add","path":"/a","value":[1]}]`, `{"a":[1]}`},
+		{"add nested member", `{"a":{"b":1}}`, `[{"op":"add","path":"/a/c","value":null}]`, `{"a":{"b":1,"c":null}}`},
+		{"add inserts into array", `{"a":[1,3]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2,3]}`},
+		{"add at array end", `{"a":[1]}`, `[{"op":"add","path":"/a/1","value":2}]`, `{"a":[1,2]}`},
+		{"add appends with dash", `{"a":[1]}`, `[{"op":"add","path":"/a/-","value":2}]`, `{"a":[1,2]}`},
+		{"add whole document", `{"a":1}`, `[{"op":"add","path":"","value":{"b":2}}]`, `{"b":2}`},
+		{"remove member", `{"a":1,"b":2}`, `[{"op":"remove","path":"/a"}]`, `{"b":2}`},
+		{"remove array element", `{"a":[1,2,3]}`, `[{"op":"remove","path":"/a/1"}]`, `{"a":[1,3]}`},
+		{"replace member", `{"a":1}`, `[{"op":"replace","path":"/a","value":"x"}]`, `{"a":"x"}`},
+		{"replace array element", `{"a":[1,2]}`, `[{"op":"replace","path":"/a/0","value":0}]`, `{"a":[0,2]}`},
+		{"move member", `{"a":{"b":1},"c":{}}`, `[{"op":"move","from":"/a/b","path":"/c/d"}]`, `{"a":{},"c":{"d":1}}`},
+		{"move array element", `{"a":[1,2,3]}`, `[{"op":"move","from":"/a/0","path":"/a/2"}]`, `{"a":[2,3,1]}`},
+		{"copy member", `{"a":{"b":[1]}}`, `[{"op":"copy","from":"/a","path":"/c"}]`, `{"a":{"b":[1]},"c":{"b":[1]}}`},
+		{"copy is independent", `{"a":{"b":1}}`, `[{"op":"copy","from":"/a","path":"/c"},{"op":"replace","path":"/c/b","value":2}]`, `{"a":{"b":1},"c":{"b":2}}`},
+		{"test passes", `{"a":{"b":[1,"x"]}}`, `[{"op":"test","path":"/a","value":{"b":[1,"x"]}}]`, `{"a":{"b":[1,"x"]}}`},
+		{"test null", `{"a":null}`, `[{"op":"test","path":"/a","value":null}]`, `{"a":null}`},
+		{"slash escape", `{"a/b":1}`, `[{"op":"replace","path":"/a~1b","value":2}]`, `{"a/b":2}`},
+		{"tilde escape", `{"m~n":1}`, `[{"op":"remove","path":"/m~0n"}]`, `{}`},
+		{"escapes are decoded once", `{}`, `[{"op":"add","path":"/~01","value":1}]`, `{"~1":1}`},
+		{"empty member name", `{}`, `[{"op":"add","path":"/","value":1}]`, `{"":1}`},
+		{"operations apply in order", `{"a":1}`, `[{"op":"add","path":"/b","value":2},{"op":"move","from":"/a","path":"/c"},{"op":"test","path":"/c","value":1}]`, `{"b":2,"c":1}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := ParsePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParsePatch() error = %v", err)
+			}
+
+			got, err := Apply(mustDecode(t, tt.doc), ops)
+			if err != nil {
+				t.Fatalf("Apply() error = %v", err)
+			}
+			if want := mustDecode(t, tt.want); !reflect.DeepEqual(got, want) {
+				t.Errorf("Apply() = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		doc     string
+		patch   string
+		wantErr error
+		index   int
+	}{
+		{"failing test", `{"a":1}`, `[{"op":"test","path":"/a","value":2}]`, ErrTestFailed, 0},
+		{"failing test on type", `{"a":1}`, `[{"op":"test","path":"/a","value":"1"}]`, ErrTestFailed, 0},
+		{"failing test after changes", `{"a":1}`, `[{"op":"replace","path":"/a","value":2},{"op":"test","path":"/a","value":1}]`, ErrTestFailed, 1},
+		{"test of missing member", `{}`, `[{"op":"test","path":"/a","value":null}]`, ErrInvalidPatch, 0},
+		{"unknown operation", `{}`, `[{"op":"merge","path":"/a"}]`, ErrInvalidPatch, 0},
+		{"missing value", `{}`, `[{"op":"add","path":"/a"}]`, ErrInvalidPatch, 0},
+		{"path without slash", `{"a":1}`, `[{"op":"remove","path":"a"}]`, ErrInvalidPatch, 0},
+		{"remove missing member", `{}`, `[{"op":"remove","path":"/a"}]`, ErrInvalidPatch, 0},
+		{"remove whole document", `{}`, `[{"op":"remove","path":""}]`, ErrInvalidPatch, 0},
+		{"replace missing member", `{}`, `[{"op":"replace","path":"/a","value":1}]`, ErrInvalidPatch, 0},
+		{"add to missing parent", `{}`, `[{"op":"add","path":"/a/b","value":1}]`, ErrInvalidPatch, 0},
+		{"add to scalar", `{"a":1}`, `[{"op":"add","path":"/a/b","value":1}]`, ErrInvalidPatch, 0},
+		{"array index out of range", `{"a":[1]}`, `[{"op":"add","path":"/a/2","value":1}]`, ErrInvalidPatch, 0},
+		{"array index with leading zero", `{"a":[1,2]}`, `[{"op":"replace","path":"/a/01","value":1}]`, ErrInvalidPatch, 0},
+		{"negative array index", `{"a":[1]}`, `[{"op":"remove","path":"/a/-1"}]`, ErrInvalidPatch, 0},
+		{"dash outside add", `{"a":[1]}`, `[{"op":"remove","path":"/a/-"}]`, ErrInvalidPatch, 0},
+		{"move from missing member", `{}`, `[{"op":"move","from":"/a","path":"/b"}]`, ErrInvalidPatch, 0},
+		{"move into itself", `{"a":{"b":1}}`, `[{"op":"move","from":"/a","path":"/a/c"}]`, ErrInvalidPatch, 0},
+		{"copy from invalid path", `{"a":1}`, `[{"op":"copy","from":"a","path":"/b"}]`, ErrInvalidPatch, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ops, err := ParsePatch([]byte(tt.patch))
+			if err != nil {
+				t.Fatalf("ParsePatch() error = %v", err)
+			}
+
+			_, err = Apply(mustDecode(t, tt.doc), ops)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Apply() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var opErr *OperationError
+			if !errors.As(err, &opErr) {
+				t.Fatalf("Apply() error = %T, want *OperationError", err)
+			}
+			if opErr.Index != tt.index || opErr.Op != ops[tt.index].Op || opErr.Path != ops[tt.index].Path {
+				t.Errorf("OperationError = %+v, want operation %d", opErr, tt.index)
+			}
+		})
+	}
+}
+
+func TestParsePatch(t *testing.T) {
+	ops, err := ParsePatch([]byte(`[{"op":"add","path":"/a","value":null},{"op":"remove","path":"/b"}]`))
+	if err != nil {
+		t.Fatalf("ParsePatch() error = %v", err)
+	}
+	if string(ops[0].Value) != "null" {
+		t.Errorf("null value = %q, want %q", ops[0].Value, "null")
+	}
+	if ops[1].Value != nil {
+		t.Errorf("missing value = %q, want nil", ops[1].Value)
+	}
+
+	for _, patch := range []string{`{"op":"add","path":"/a","value":1}`, `[{"op":1}]`, `not json`} {
+		if _, err = ParsePatch([]byte(patch)); !errors.Is(err, ErrInvalidPatch) {
+			t.Errorf("ParsePatch(%s) error = %v, want %v", patch, err, ErrInvalidPatch)
+		}
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	// The examples of RFC 7396 appendix A
+	tests := []struct {
+		doc   string
+		patch string
+		want  string
+	}{
+		{`{"a":"b"}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"b"}`, `{"b":"c"}`, `{"a":"b","b":"c"}`},
+		{`{"a":"b"}`, `{"a":null}`, `{}`},
+		{`{"a":"b","b":"c"}`, `{"a":null}`, `{"b":"c"}`},
+		{`{"a":["b"]}`, `{"a":"c"}`, `{"a":"c"}`},
+		{`{"a":"c"}`, `{"a":["b"]}`, `{"a":["b"]}`},
+		{`{"a":{"b":"c"}}`, `{"a":{"b":"d","c":null}}`, `{"a":{"b":"d"}}`},
+		{`{"a":[{"b":"c"}]}`, `{"a":[1]}`, `{"a":[1]}`},
+		{`["a","b"]`, `["c","d"]`, `["c","d"]`},
+		{`{"a":"b"}`, `["c"]`, `["c"]`},
+		{`{"a":"foo"}`, `null`, `null`},
+		{`{"a":"foo"}`, `"bar"`, `"bar"`},
+		{`{"e":null}`, `{"a":1}`, `{"e":null,"a":1}`},
+		{`[1,2]`, `{"a":"b","c":null}`, `{"a":"b"}`},
+		{`{}`, `{"a":{"bb":{"ccc":null}}}`, `{"a":{"bb":{}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.doc+" "+tt.patch, func(t *testing.T) {
+			got := MergePatch(mustDecode(t, tt.doc), mustDecode(t, tt.patch))
+			if want := mustDecode(t, tt.want); !reflect.DeepEqual(got, want) {
+				gotJSON, _ := json.Marshal(got)
+				t.Errorf("MergePatch() = %s, want %s", gotJSON, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecode(t *testing.T) {
+	if _, err := Decode([]byte(`{"a":1} {"b":2}`)); err == nil {
+		t.Error("Decode() accepted data after the JSON value")
+	}
+	if _, err := Decode([]byte(`{"a":`)); err == nil {
+		t.Error("Decode() accepted a truncated value")
+	}
+}
@@ -26,3 +26,20 @@ func ParseLyrics(rawLyrics string) ([]byte, error) {
 
 	return json.Marshal(lyricsData)
 }
+
+// LyricsVerses returns the verses of lyrics stored by ParseLyrics, splitting the text when no verses were stored
+func LyricsVerses(lyricsJSON []byte) ([]string, error) {
+	var lyricsData struct {
+		Text   string   `json:"text"`
+		Verses []string `json:"verses"`
+	}
+
+	if err := json.Unmarshal(lyricsJSON, &lyricsData); err != nil {
+		return nil, err
+	}
+
+	if len(lyricsData.Verses) == 0 && lyricsData.Text != "" {
+		return strings.Split(strings.ReplaceAll(lyricsData.Text, "\r\n", "\n"), "\n"), nil
+	}
+	return lyricsData.Verses, nil
+}
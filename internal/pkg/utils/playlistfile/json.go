@@ -0,0 +1,31 @@
+package playlistfile
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// EncodeJSON writes the playlist as an indented JSON document
+func EncodeJSON(w io.Writer, playlist Playlist) error {
+	if playlist.Tracks == nil {
+		playlist.Tracks = []Track{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(playlist)
+}
+
+// DecodeJSON reads a playlist written by EncodeJSON, track numbers are reported as lines
+func DecodeJSON(r io.Reader) (Playlist, error) {
+	var playlist Playlist
+	if err := json.NewDecoder(r).Decode(&playlist); err != nil {
+		return Playlist{}, err
+	}
+
+	for i := range playlist.Tracks {
+		playlist.Tracks[i].Line = i + 1
+		playlist.Tracks[i].Raw = playlist.Tracks[i].Group + " - " + playlist.Tracks[i].Title
+	}
+
+	return playlist, nil
+}
@@ -0,0 +1,77 @@
+package playlistfile
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// EncodeM3U8 writes an extended M3U playlist with one #EXTINF line per track
+func EncodeM3U8(w io.Writer, playlist Playlist) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintln(bw, "#EXTM3U")
+	fmt.Fprintf(bw, "#PLAYLIST:%s\n", oneLine(playlist.Name))
+	for _, track := range playlist.Tracks {
+		fmt.Fprintf(bw, "#EXTINF:%d,%s - %s\n", track.Runtime, oneLine(track.Group), oneLine(track.Title))
+		fmt.Fprintln(bw, oneLine(track.Link))
+	}
+
+	return bw.Flush()
+}
+
+// DecodeM3U8 reads a plain or extended M3U playlist.
+// Tracks without an #EXTINF title fall back to the location as their raw text.
+func DecodeM3U8(r io.Reader) (Playlist, error) {
+	var playlist Playlist
+	var pending *Track
+
+	scanner := bufio.NewScanner(r)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+
+		switch {
+		case line == "" || line == "#EXTM3U":
+			continue
+
+		case strings.HasPrefix(line, "#PLAYLIST:"):
+			playlist.Name = strings.TrimSpace(strings.TrimPrefix(line, "#PLAYLIST:"))
+
+		case strings.HasPrefix(line, "#EXTINF:"):
+			info := strings.TrimPrefix(line, "#EXTINF:")
+			duration, display, _ := strings.Cut(info, ",")
+			track := Track{Line: lineNumber, Raw: line}
+			track.Group, track.Title = splitDisplayTitle(display)
+			if seconds, err := strconv.ParseFloat(strings.TrimSpace(duration), 64); err == nil && seconds > 0 {
+				track.Runtime = int32(seconds)
+			}
+			pending = &track
+
+		case strings.HasPrefix(line, "#"):
+			// other directives and comments carry nothing we can match on
+			continue
+
+		default:
+			if pending == nil {
+				pending = &Track{Line: lineNumber, Raw: line}
+			}
+			pending.Link = line
+			playlist.Tracks = append(playlist.Tracks, *pending)
+			pending = nil
+		}
+	}
+
+	if pending != nil {
+		playlist.Tracks = append(playlist.Tracks, *pending)
+	}
+
+	return playlist, scanner.Err()
+}
+
+func oneLine(value string) string {
+	return strings.Join(strings.Fields(value), " ")
+}
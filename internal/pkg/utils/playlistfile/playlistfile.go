@@ -0,0 +1,92 @@
+package playlistfile
+
+import (
+	"errors"
+	"io"
+	"strings"
+)
+
+const (
+	FormatM3U8 = "m3u8"
+	FormatXSPF = "xspf"
+	FormatJSON = "json"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported playlist format, expected m3u8, xspf or json")
+
+// Track is a single playlist item as it is exchanged with other players.
+// Runtime is in seconds, Line is the position of the track in the source file.
+type Track struct {
+	Title   string `json:"title"`
+	Group   string `json:"group"`
+	Runtime int32  `json:"runtime,omitempty"`
+	Link    string `json:"link,omitempty"`
+	Line    int    `json:"-"`
+	Raw     string `json:"-"`
+}
+
+// Playlist is the format independent representation of an exported or imported playlist
+type Playlist struct {
+	Name        string  `json:"name"`
+	Description string  `json:"description,omitempty"`
+	Owner       string  `json:"owner,omitempty"`
+	Tracks      []Track `json:"tracks"`
+}
+
+// ContentType returns the media type served for a format
+func ContentType(format string) string {
+	switch format {
+	case FormatM3U8:
+		return "audio/x-mpegurl; charset=utf-8"
+	case FormatXSPF:
+		return "application/xspf+xml; charset=utf-8"
+	default:
+		return "application/json; charset=utf-8"
+	}
+}
+
+// Encode writes the playlist in the requested format
+func Encode(w io.Writer, format string, playlist Playlist) error {
+	switch format {
+	case FormatM3U8:
+		return EncodeM3U8(w, playlist)
+	case FormatXSPF:
+		return EncodeXSPF(w, playlist)
+	case FormatJSON:
+		return EncodeJSON(w, playlist)
+	default:
+		return ErrUnsupportedFormat
+	}
+}
+
+// Decode reads a playlist in the requested format
+func Decode(r io.Reader, format string) (Playlist, error) {
+	switch format {
+	case FormatM3U8:
+		return DecodeM3U8(r)
+	case FormatXSPF:
+		return DecodeXSPF(r)
+	case FormatJSON:
+		return DecodeJSON(r)
+	default:
+		return Playlist{}, ErrUnsupportedFormat
+	}
+}
+
+// SplitFormat splits a trailing ".m3u8", ".xspf" or ".json" extension off a path parameter
+func SplitFormat(value string) (string, string) {
+	for _, format := range []string{FormatM3U8, FormatXSPF, FormatJSON} {
+		if base, ok := strings.CutSuffix(value, "."+format); ok {
+			return base, format
+		}
+	}
+	return value, ""
+}
+
+// splitDisplayTitle splits the conventional "Group - Title" display string
+func splitDisplayTitle(value string) (string, string) {
+	if group, title, ok := strings.Cut(value, " - "); ok {
+		return strings.TrimSpace(group), strings.TrimSpace(title)
+	}
+	return "", strings.TrimSpace(value)
+}
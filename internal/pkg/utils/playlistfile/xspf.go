@@ -0,0 +1,82 @@
+package playlistfile
+
+import (
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+const xspfNamespace = "http://xspf.org/ns/0/"
+
+type xspfPlaylist struct {
+	XMLName    xml.Name    `xml:"playlist"`
+	Version    string      `xml:"version,attr"`
+	Namespace  string      `xml:"xmlns,attr"`
+	Title      string      `xml:"title,omitempty"`
+	Creator    string      `xml:"creator,omitempty"`
+	Annotation string      `xml:"annotation,omitempty"`
+	Tracks     []xspfTrack `xml:"trackList>track"`
+}
+
+type xspfTrack struct {
+	Location string `xml:"location,omitempty"`
+	Title    string `xml:"title,omitempty"`
+	Creator  string `xml:"creator,omitempty"`
+	Duration int64  `xml:"duration,omitempty"` // milliseconds
+}
+
+// EncodeXSPF writes an XSPF version 1 playlist
+func EncodeXSPF(w io.Writer, playlist Playlist) error {
+	doc := xspfPlaylist{
+		Version:    "1",
+		Namespace:  xspfNamespace,
+		Title:      playlist.Name,
+		Creator:    playlist.Owner,
+		Annotation: playlist.Description,
+	}
+	for _, track := range playlist.Tracks {
+		doc.Tracks = append(doc.Tracks, xspfTrack{
+			Location: track.Link,
+			Title:    track.Title,
+			Creator:  track.Group,
+			Duration: int64(track.Runtime) * 1000,
+		})
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(doc); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// DecodeXSPF reads an XSPF playlist, track numbers are reported as lines
+func DecodeXSPF(r io.Reader) (Playlist, error) {
+	var doc xspfPlaylist
+	if err := xml.NewDecoder(r).Decode(&doc); err != nil {
+		return Playlist{}, err
+	}
+
+	playlist := Playlist{
+		Name:        strings.TrimSpace(doc.Title),
+		Description: strings.TrimSpace(doc.Annotation),
+		Owner:       strings.TrimSpace(doc.Creator),
+	}
+	for i, track := range doc.Tracks {
+		playlist.Tracks = append(playlist.Tracks, Track{
+			Title:   strings.TrimSpace(track.Title),
+			Group:   strings.TrimSpace(track.Creator),
+			Runtime: int32(track.Duration / 1000),
+			Link:    strings.TrimSpace(track.Location),
+			Line:    i + 1,
+			Raw:     strings.TrimSpace(track.Creator + " - " + track.Title),
+		})
+	}
+
+	return playlist, nil
+}
@@ -0,0 +1,261 @@
+package similarity
+
+import (
+	"bytes"
+	"maps"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// minTokenLength drops single letters, which carry no meaning on their own
+	minTokenLength = 2
+	// rebuildDelay is how long changes are collected before the weighted vectors are rebuilt
+	rebuildDelay = time.Second
+)
+
+// Match is a document similar to the one queried and its cosine similarity, from 0 to 1
+type Match struct {
+	ID    uuid.UUID
+	Score float64
+}
+
+type posting struct {
+	id     uuid.UUID
+	weight float64
+}
+
+// snapshot holds the unit length TF-IDF vector of every document and the postings lists built from them
+type snapshot struct {
+	vectors  map[uuid.UUID]map[string]float64
+	postings map[string][]posting
+}
+
+// Index compares documents by the cosine similarity of their TF-IDF weighted word and shingle vectors.
+// Term counts and document frequencies are updated on every change, the weighted vectors are rebuilt
+// in the background once changes stop arriving for rebuildDelay. Queries never wait for a rebuild,
+// they read the last built snapshot and only see a change once it has been rebuilt.
+type Index struct {
+	mu        sync.Mutex // guards counts, docFreq, dirty and scheduled
+	counts    map[uuid.UUID]map[string]int
+	docFreq   map[string]int
+	dirty     bool
+	scheduled bool
+
+	rebuildMu sync.Mutex // lets one rebuild run at a time
+	current   atomic.Pointer[snapshot]
+}
+
+// NewIndex creates an empty index
+func NewIndex() *Index {
+	i := &Index{
+		counts:  make(map[uuid.UUID]map[string]int),
+		docFreq: make(map[string]int),
+	}
+	i.current.Store(&snapshot{})
+	return i
+}
+
+// Terms returns the lower-cased words of the verses and the shingles of adjacent words.
+// Shingles never span two verses.
+func Terms(verses []string) map[string]int {
+	counts := make(map[string]int)
+	for _, verse := range verses {
+		words := strings.FieldsFunc(strings.ToLower(verse), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsNumber(r) && r != '\''
+		})
+
+		previous := ""
+		for _, word := range words {
+			word = strings.Trim(word, "'")
+			if len([]rune(word)) < minTokenLength {
+				previous = ""
+				continue
+			}
+			counts[word]++
+			if previous != "" {
+				counts[previous+" "+word]++
+			}
+			previous = word
+		}
+	}
+	return counts
+}
+
+// Set adds a document or replaces its terms, documents without terms are removed
+func (i *Index) Set(id uuid.UUID, verses []string) {
+	counts := Terms(verses)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+	if len(counts) == 0 {
+		return
+	}
+	i.counts[id] = counts
+	for term := range counts {
+		i.docFreq[term]++
+	}
+	i.changed()
+}
+
+// Remove drops a document from the index
+func (i *Index) Remove(id uuid.UUID) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	i.remove(id)
+}
+
+func (i *Index) remove(id uuid.UUID) {
+	counts, ok := i.counts[id]
+	if !ok {
+		return
+	}
+	for term := range counts {
+		if i.docFreq[term]--; i.docFreq[term] == 0 {
+			delete(i.docFreq, term)
+		}
+	}
+	delete(i.counts, id)
+	i.changed()
+}
+
+// changed schedules a rebuild unless one is already waiting, the caller holds mu
+func (i *Index) changed() {
+	i.dirty = true
+	if !i.scheduled {
+		i.scheduled = true
+		time.AfterFunc(rebuildDelay, i.Refresh)
+	}
+}
+
+// Len returns the number of indexed documents
+func (i *Index) Len() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	return len(i.counts)
+}
+
+// Similar returns up to limit documents sharing terms with the given one, most similar first.
+// It returns nothing when the document is not indexed.
+func (i *Index) Similar(id uuid.UUID, limit int) []Match {
+	current := i.current.Load()
+
+	vector, ok := current.vectors[id]
+	if !ok || limit < 1 {
+		return nil
+	}
+
+	scores := make(map[uuid.UUID]float64)
+	for term, weight := range vector {
+		for _, p := range current.postings[term] {
+			if p.id != id {
+				scores[p.id] += weight * p.weight
+			}
+		}
+	}
+
+	matches := make([]Match, 0, len(scores))
+	for other, score := range scores {
+		if score > 0 {
+			matches = append(matches, Match{ID: other, Score: math.Min(score, 1)})
+		}
+	}
+
+	sort.Slice(matches, func(a, b int) bool {
+		if matches[a].Score != matches[b].Score {
+			return matches[a].Score > matches[b].Score
+		}
+		return bytes.Compare(matches[a].ID[:], matches[b].ID[:]) < 0
+	})
+
+	if len(matches) > limit {
+		matches = matches[:limit]
+	}
+	return matches
+}
+
+// Score returns the cosine similarity of two documents, ok is false when either is not indexed
+func (i *Index) Score(a, b uuid.UUID) (float64, bool) {
+	current := i.current.Load()
+
+	vectorA, okA := current.vectors[a]
+	vectorB, okB := current.vectors[b]
+	if !okA || !okB {
+		return 0, false
+	}
+
+	if len(vectorB) < len(vectorA) {
+		vectorA, vectorB = vectorB, vectorA
+	}
+	var score float64
+	for term, weight := range vectorA {
+		score += weight * vectorB[term]
+	}
+	return math.Min(score, 1), true
+}
+
+// Refresh rebuilds the vectors right away if anything changed since the last rebuild.
+// Changes are only held up while their terms are copied, the vectors are computed without any lock.
+func (i *Index) Refresh() {
+	i.rebuildMu.Lock()
+	defer i.rebuildMu.Unlock()
+
+	i.mu.Lock()
+	i.scheduled = false
+	if !i.dirty {
+		i.mu.Unlock()
+		return
+	}
+	i.dirty = false
+	// The term counts of a document are never modified once set, so a shallow copy is enough
+	documents := maps.Clone(i.counts)
+	docFreq := maps.Clone(i.docFreq)
+	i.mu.Unlock()
+
+	i.current.Store(build(documents, docFreq))
+}
+
+// build computes the unit length TF-IDF vector of every document and the postings lists
+func build(documents map[uuid.UUID]map[string]int, docFreq map[string]int) *snapshot {
+	total := float64(len(documents))
+	vectors := make(map[uuid.UUID]map[string]float64, len(documents))
+	postings := make(map[string][]posting)
+
+	for id, counts := range documents {
+		vector := make(map[string]float64, len(counts))
+		var norm float64
+		for term, count := range counts {
+			// Terms found in every document get no weight
+			idf := math.Log((1 + total) / (1 + float64(docFreq[term])))
+			weight := (1 + math.Log(float64(count))) * idf
+			if weight == 0 {
+				continue
+			}
+			vector[term] = weight
+			norm += weight * weight
+		}
+		if norm == 0 {
+			continue
+		}
+
+		norm = math.Sqrt(norm)
+		for term, weight := range vector {
+			vector[term] = weight / norm
+			postings[term] = append(postings[term], posting{id: id, weight: vector[term]})
+		}
+		vectors[id] = vector
+	}
+
+	return &snapshot{vectors: vectors, postings: postings}
+}
@@ -0,0 +1,106 @@
+// Package songquery parses the search language of song listings, for example
+//
+//	group:queen year:1975..1980 runtime:<300 "bohemian"
+//
+// Terms are joined with AND unless OR stands between them, a leading - negates a term and parentheses group.
+// A term is a word or "quoted phrase" matched against the title, or field:value for one of the Fields.
+package songquery
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Kind says how the values of a field are written and compared
+type Kind int
+
+const (
+	KindText   Kind = iota // matches values containing the text, ignoring case
+	KindExact              // matches values equal to the text, ignoring case
+	KindNumber             // compared with a number, a comparison such as >=3 or a range such as 1975..1980
+)
+
+// Fields are the fields a term can name
+var Fields = map[string]Kind{
+	"title":   KindText,
+	"group":   KindText,
+	"lyrics":  KindText,
+	"tag":     KindExact,
+	"year":    KindNumber,
+	"runtime": KindNumber,
+	"rating":  KindNumber,
+}
+
+// Comparison operators of number terms
+const (
+	OpEqual        = "="
+	OpLess         = "<"
+	OpLessEqual    = "<="
+	OpGreater      = ">"
+	OpGreaterEqual = ">="
+)
+
+// Expr is a node of a parsed query
+type Expr interface {
+	// Pos is the position of the node in the query, counted in characters from 1
+	Pos() int
+}
+
+// And matches songs matched by all of its expressions
+type And struct {
+	Exprs []Expr
+}
+
+// Or matches songs matched by any of its expressions
+type Or struct {
+	Exprs []Expr
+}
+
+// Not matches songs its expression does not match
+type Not struct {
+	Expr     Expr
+	Position int
+}
+
+// Match matches songs whose field contains, or for KindExact fields equals, the value.
+// Free text terms match the title.
+type Match struct {
+	Field    string
+	Value    string
+	Position int
+}
+
+// Compare matches songs whose number field compares to the value with the operator
+type Compare struct {
+	Field    string
+	Op       string
+	Value    float64
+	Position int
+}
+
+func (e *And) Pos() int     { return e.Exprs[0].Pos() }
+func (e *Or) Pos() int      { return e.Exprs[0].Pos() }
+func (e *Not) Pos() int     { return e.Position }
+func (e *Match) Pos() int   { return e.Position }
+func (e *Compare) Pos() int { return e.Position }
+
+// Error is a problem with a query at a position, counted in characters from 1
+type Error struct {
+	Pos     int
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("at position %d: %s", e.Pos, e.Message)
+}
+
+// Errors is every problem found in a query, in the order of their positions
+type Errors []*Error
+
+func (e Errors) Error() string {
+	messages := make([]string, 0, len(e))
+	for _, err := range e {
+		messages = append(messages, err.Error())
+	}
+	return strings.Join(messages, "; ")
+}
@@ -0,0 +1,113 @@
+package songquery
+
+import (
+	"strings"
+	"unicode"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenTerm
+	tokenOr
+	tokenNot
+	tokenLeftParen
+	tokenRightParen
+)
+
+// token is a lexical element of a query. Terms keep their field, empty for free text, and their value
+// with its own position so errors can point at either.
+type token struct {
+	kind     tokenKind
+	pos      int
+	field    string
+	value    string
+	valuePos int
+	quoted   bool
+}
+
+// lex splits a query into tokens, it fails only on unterminated quotes
+func lex(input string) ([]token, *Error) {
+	runes := []rune(input)
+	var tokens []token
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		pos := i + 1
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(':
+			tokens = append(tokens, token{kind: tokenLeftParen, pos: pos})
+			i++
+		case r == ')':
+			tokens = append(tokens, token{kind: tokenRightParen, pos: pos})
+			i++
+		case r == '-' && i+1 < len(runes) && !unicode.IsSpace(runes[i+1]) && runes[i+1] != ')':
+			tokens = append(tokens, token{kind: tokenNot, pos: pos})
+			i++
+		case r == '"':
+			value, next, err := lexQuoted(runes, i)
+			if err != nil {
+				return nil, err
+			}
+			tokens = append(tokens, token{kind: tokenTerm, pos: pos, value: value, valuePos: pos, quoted: true})
+			i = next
+		default:
+			start := i
+			for i < len(runes) && !unicode.IsSpace(runes[i]) && !strings.ContainsRune(`()"`, runes[i]) {
+				i++
+			}
+			word := string(runes[start:i])
+
+			if word == "OR" {
+				tokens = append(tokens, token{kind: tokenOr, pos: pos})
+				continue
+			}
+			if word == "AND" {
+				// Terms are joined with AND anyway
+				continue
+			}
+
+			field, value, found := strings.Cut(word, ":")
+			if !found {
+				tokens = append(tokens, token{kind: tokenTerm, pos: pos, value: word, valuePos: pos})
+				continue
+			}
+
+			t := token{kind: tokenTerm, pos: pos, field: strings.ToLower(field), value: value, valuePos: start + len([]rune(field)) + 2}
+			if value == "" && i < len(runes) && runes[i] == '"' {
+				quoted, next, err := lexQuoted(runes, i)
+				if err != nil {
+					return nil, err
+				}
+				t.value, t.quoted = quoted, true
+				i = next
+			}
+			tokens = append(tokens, t)
+		}
+	}
+
+	return append(tokens, token{kind: tokenEOF, pos: len(runes) + 1}), nil
+}
+
+// lexQuoted reads the phrase of the quote starting at runes[start], a backslash escapes the next character.
+// It returns the phrase and the index after the closing quote.
+func lexQuoted(runes []rune, start int) (string, int, *Error) {
+	var sb strings.Builder
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '\\':
+			if i+1 < len(runes) {
+				i++
+				sb.WriteRune(runes[i])
+			}
+		case '"':
+			return sb.String(), i + 1, nil
+		default:
+			sb.WriteRune(runes[i])
+		}
+	}
+	return "", 0, &Error{Pos: start + 1, Message: "quote is never closed"}
+}
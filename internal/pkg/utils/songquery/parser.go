@@ -0,0 +1,230 @@
+package songquery
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// MaxLength is the number of characters a query may have at most
+const MaxLength = 500
+
+// maxDepth limits how deeply parentheses and negations may nest
+const maxDepth = 32
+
+// Parse parses a query into an expression, an empty query gives a nil expression. Problems with single terms,
+// such as unknown fields or malformed numbers, are collected and returned together as Errors, a problem with
+// the structure of the query, such as an unclosed parenthesis, ends parsing.
+func Parse(input string) (Expr, error) {
+	if utf8.RuneCountInString(input) > MaxLength {
+		return nil, Errors{{Pos: MaxLength + 1, Message: fmt.Sprintf("the query is longer than %d characters", MaxLength)}}
+	}
+
+	tokens, err := lex(input)
+	if err != nil {
+		return nil, Errors{err}
+	}
+
+	p := &parser{tokens: tokens}
+	if p.peek().kind == tokenEOF {
+		return nil, nil
+	}
+
+	expr := p.parseOr()
+	if !p.stopped && p.peek().kind != tokenEOF {
+		p.stop(p.peek().pos, "closing parenthesis has no opening one")
+	}
+	if len(p.errs) > 0 {
+		slices.SortStableFunc(p.errs, func(a, b *Error) int { return a.Pos - b.Pos })
+		return nil, p.errs
+	}
+	return expr, nil
+}
+
+type parser struct {
+	tokens  []token
+	next    int
+	depth   int
+	errs    Errors
+	stopped bool // the structure of the query is broken, nothing after it can be parsed
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.next]
+}
+
+func (p *parser) take() token {
+	t := p.tokens[p.next]
+	if t.kind != tokenEOF {
+		p.next++
+	}
+	return t
+}
+
+func (p *parser) errorf(pos int, format string, args ...any) {
+	p.errs = append(p.errs, &Error{Pos: pos, Message: fmt.Sprintf(format, args...)})
+}
+
+func (p *parser) stop(pos int, message string) {
+	p.errorf(pos, "%s", message)
+	p.stopped = true
+}
+
+// parseOr parses terms joined by OR, which binds weaker than the AND between adjacent terms
+func (p *parser) parseOr() Expr {
+	exprs := []Expr{p.parseAnd()}
+	for !p.stopped && p.peek().kind == tokenOr {
+		p.take()
+		exprs = append(exprs, p.parseAnd())
+	}
+	if len(exprs) == 1 {
+		return exprs[0]
+	}
+	return &Or{Exprs: exprs}
+}
+
+// parseAnd parses adjacent terms up to the next OR, closing parenthesis or the end of the query
+func (p *parser) parseAnd() Expr {
+	var exprs []Expr
+	for !p.stopped {
+		switch t := p.peek(); t.kind {
+		case tokenEOF, tokenOr, tokenRightParen:
+			if len(exprs) == 0 {
+				p.stop(t.pos, missingTermMessage(t.kind))
+				return nil
+			}
+			if len(exprs) == 1 {
+				return exprs[0]
+			}
+			return &And{Exprs: exprs}
+		default:
+			exprs = append(exprs, p.parseUnary())
+		}
+	}
+	return nil
+}
+
+func missingTermMessage(kind tokenKind) string {
+	switch kind {
+	case tokenOr:
+		return "OR needs a term on both sides"
+	case tokenRightParen:
+		return "expected a term before the closing parenthesis"
+	default:
+		return "expected a term at the end of the query"
+	}
+}
+
+// parseUnary parses a term, a negated term or a parenthesised query
+func (p *parser) parseUnary() Expr {
+	t := p.take()
+	switch t.kind {
+	case tokenTerm:
+		return p.parseTerm(t)
+	case tokenNot, tokenLeftParen:
+		if p.depth == maxDepth {
+			p.stop(t.pos, fmt.Sprintf("parentheses and negations nest deeper than %d levels", maxDepth))
+			return nil
+		}
+		p.depth++
+		defer func() { p.depth-- }()
+	default:
+		p.stop(t.pos, "expected a term")
+		return nil
+	}
+
+	if t.kind == tokenNot {
+		expr := p.parseUnary()
+		if p.stopped {
+			return nil
+		}
+		return &Not{Expr: expr, Position: t.pos}
+	}
+
+	expr := p.parseOr()
+	if p.stopped {
+		return nil
+	}
+	if p.peek().kind != tokenRightParen {
+		p.stop(t.pos, "parenthesis is never closed")
+		return nil
+	}
+	p.take()
+	return expr
+}
+
+// parseTerm checks the field of a term and parses its value
+func (p *parser) parseTerm(t token) Expr {
+	if t.field == "" {
+		return &Match{Value: t.value, Position: t.pos}
+	}
+
+	kind, ok := Fields[t.field]
+	if !ok {
+		p.errorf(t.pos, "unknown field %q, expected one of %s", t.field, strings.Join(fieldNames(), ", "))
+		return nil
+	}
+	if t.value == "" {
+		p.errorf(t.valuePos, "%s needs a value", t.field)
+		return nil
+	}
+	if kind != KindNumber {
+		return &Match{Field: t.field, Value: t.value, Position: t.pos}
+	}
+
+	if from, to, isRange := strings.Cut(t.value, ".."); isRange {
+		if from == "" && to == "" {
+			p.errorf(t.valuePos, "%s range needs at least one bound", t.field)
+			return nil
+		}
+
+		var exprs []Expr
+		if from != "" {
+			if n, ok := p.number(t.field, from, t.valuePos); ok {
+				exprs = append(exprs, &Compare{Field: t.field, Op: OpGreaterEqual, Value: n, Position: t.pos})
+			}
+		}
+		if to != "" {
+			if n, ok := p.number(t.field, to, t.valuePos+utf8.RuneCountInString(from)+2); ok {
+				exprs = append(exprs, &Compare{Field: t.field, Op: OpLessEqual, Value: n, Position: t.pos})
+			}
+		}
+		if len(exprs) == 1 {
+			return exprs[0]
+		}
+		return &And{Exprs: exprs}
+	}
+
+	op, value := OpEqual, t.value
+	for _, candidate := range []string{OpGreaterEqual, OpLessEqual, OpGreater, OpLess, OpEqual} {
+		if strings.HasPrefix(value, candidate) {
+			op, value = candidate, value[len(candidate):]
+			break
+		}
+	}
+	n, ok := p.number(t.field, value, t.valuePos+len(t.value)-len(value))
+	if !ok {
+		return nil
+	}
+	return &Compare{Field: t.field, Op: op, Value: n, Position: t.pos}
+}
+
+func (p *parser) number(field, value string, pos int) (float64, bool) {
+	n, err := strconv.ParseFloat(value, 64)
+	if err != nil || strings.ContainsAny(value, "eEnN") {
+		p.errorf(pos, "%s expects a number, a comparison such as >=3 or a range such as 1975..1980", field)
+		return 0, false
+	}
+	return n, true
+}
+
+func fieldNames() []string {
+	names := make([]string, 0, len(Fields))
+	for name := range Fields {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
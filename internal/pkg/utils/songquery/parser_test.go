@@ -0,0 +1,248 @@
+package songquery
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  Expr
+	}{
+		{"empty", "", nil},
+		{"only spaces", "  \t", nil},
+		{"word", "queen", &Match{Value: "queen", Position: 1}},
+		{"phrase", `"bohemian rhapsody"`, &Match{Value: "bohemian rhapsody", Position: 1}},
+		{"escaped quote", `"say \"hi\""`, &Match{Value: `say "hi"`, Position: 1}},
+		{"text field", "title:love", &Match{Field: "title", Value: "love", Position: 1}},
+		{"quoted field value", `group:"the who"`, &Match{Field: "group", Value: "the who", Position: 1}},
+		{"field names ignore case", "LYRICS:Love", &Match{Field: "lyrics", Value: "Love", Position: 1}},
+		{"exact field", "tag:rock", &Match{Field: "tag", Value: "rock", Position: 1}},
+		{"number", "year:1975", &Compare{Field: "year", Op: OpEqual, Value: 1975, Position: 1}},
+		{"equal", "year:=1975", &Compare{Field: "year", Op: OpEqual, Value: 1975, Position: 1}},
+		{"less", "runtime:<300", &Compare{Field: "runtime", Op: OpLess, Value: 300, Position: 1}},
+		{"less or equal", "runtime:<=300", &Compare{Field: "runtime", Op: OpLessEqual, Value: 300, Position: 1}},
+		{"greater", "rating:>4", &Compare{Field: "rating", Op: OpGreater, Value: 4, Position: 1}},
+		{"greater or equal", "rating:>=4.5", &Compare{Field: "rating", Op: OpGreaterEqual, Value: 4.5, Position: 1}},
+		{"negative number", "rating:>-1", &Compare{Field: "rating", Op: OpGreater, Value: -1, Position: 1}},
+		{"range", "year:1975..1980", &And{Exprs: []Expr{
+			&Compare{Field: "year", Op: OpGreaterEqual, Value: 1975, Position: 1},
+			&Compare{Field: "year", Op: OpLessEqual, Value: 1980, Position: 1},
+		}}},
+		{"range from", "year:1975..", &Compare{Field: "year", Op: OpGreaterEqual, Value: 1975, Position: 1}},
+		{"range to", "year:..1980", &Compare{Field: "year", Op: OpLessEqual, Value: 1980, Position: 1}},
+		{"adjacent terms", `group:queen year:1975..1980 "bohemian"`, &And{Exprs: []Expr{
+			&Match{Field: "group", Value: "queen", Position: 1},
+			&And{Exprs: []Expr{
+				&Compare{Field: "year", Op: OpGreaterEqual, Value: 1975, Position: 13},
+				&Compare{Field: "year", Op: OpLessEqual, Value: 1980, Position: 13},
+			}},
+			&Match{Value: "bohemian", Position: 29},
+		}}},
+		{"AND is implied", "a AND b", &And{Exprs: []Expr{
+			&Match{Value: "a", Position: 1},
+			&Match{Value: "b", Position: 7},
+		}}},
+		{"OR", "a OR b OR c", &Or{Exprs: []Expr{
+			&Match{Value: "a", Position: 1},
+			&Match{Value: "b", Position: 6},
+			&Match{Value: "c", Position: 11},
+		}}},
+		{"AND binds before OR", "a b OR c", &Or{Exprs: []Expr{
+			&And{Exprs: []Expr{&Match{Value: "a", Position: 1}, &Match{Value: "b", Position: 3}}},
+			&Match{Value: "c", Position: 8},
+		}}},
+		{"AND binds before OR on the right", "a OR b c", &Or{Exprs: []Expr{
+			&Match{Value: "a", Position: 1},
+			&And{Exprs: []Expr{&Match{Value: "b", Position: 6}, &Match{Value: "c", Position: 8}}},
+		}}},
+		{"lowercase or is a word", "a or b", &And{Exprs: []Expr{
+			&Match{Value: "a", Position: 1},
+			&Match{Value: "or", Position: 3},
+			&Match{Value: "b", Position: 6},
+		}}},
+		{"parentheses", "(a OR b) c", &And{Exprs: []Expr{
+			&Or{Exprs: []Expr{&Match{Value: "a", Position: 2}, &Match{Value: "b", Position: 7}}},
+			&Match{Value: "c", Position: 10},
+		}}},
+		{"redundant parentheses", "((a))", &Match{Value: "a", Position: 3}},
+		{"negation", "-live", &Not{Expr: &Match{Value: "live", Position: 2}, Position: 1}},
+		{"negated field", "-tag:live", &Not{Expr: &Match{Field: "tag", Value: "live", Position: 2}, Position: 1}},
+		{"negation binds before AND", "-a b", &And{Exprs: []Expr{
+			&Not{Expr: &Match{Value: "a", Position: 2}, Position: 1},
+			&Match{Value: "b", Position: 4},
+		}}},
+		{"negated parentheses", "-(a OR b)", &Not{Expr: &Or{Exprs: []Expr{
+			&Match{Value: "a", Position: 3},
+			&Match{Value: "b", Position: 8},
+		}}, Position: 1}},
+		{"double negation", "--a", &Not{Expr: &Not{Expr: &Match{Value: "a", Position: 3}, Position: 2}, Position: 1}},
+		{"dash before a space is a word", "a - b", &And{Exprs: []Expr{
+			&Match{Value: "a", Position: 1},
+			&Match{Value: "-", Position: 3},
+			&Match{Value: "b", Position: 5},
+		}}},
+		{"dash inside a word", "a-ha", &Match{Value: "a-ha", Position: 1}},
+		{"positions count characters", `"ÄÖÜ" b`, &And{Exprs: []Expr{
+			&Match{Value: "ÄÖÜ", Position: 1},
+			&Match{Value: "b", Position: 7},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Parse(%q) = %s, want %s", tt.input, format(got), format(tt.want))
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	const numberMessage = " expects a number, a comparison such as >=3 or a range such as 1975..1980"
+
+	tests := []struct {
+		name  string
+		input string
+		want  Errors
+	}{
+		{"unclosed quote", `a "queen`, Errors{{Pos: 3, Message: "quote is never closed"}}},
+		{"unclosed quoted value", `title:"queen`, Errors{{Pos: 7, Message: "quote is never closed"}}},
+		{"unknown field", "album:queen", Errors{{Pos: 1, Message: `unknown field "album", expected one of group, lyrics, rating, runtime, tag, title, year`}}},
+		{"missing value", "a title:", Errors{{Pos: 9, Message: "title needs a value"}}},
+		{"not a number", "year:abc", Errors{{Pos: 6, Message: "year" + numberMessage}}},
+		{"comparison without number", "runtime:>=", Errors{{Pos: 11, Message: "runtime" + numberMessage}}},
+		{"comparison with a word", "runtime:>=x", Errors{{Pos: 11, Message: "runtime" + numberMessage}}},
+		{"exponent", "year:1e3", Errors{{Pos: 6, Message: "year" + numberMessage}}},
+		{"NaN", "rating:NaN", Errors{{Pos: 8, Message: "rating" + numberMessage}}},
+		{"range without bounds", "year:..", Errors{{Pos: 6, Message: "year range needs at least one bound"}}},
+		{"range with a bad upper bound", "year:1975..x", Errors{{Pos: 12, Message: "year" + numberMessage}}},
+		{"range with bad bounds", "year:x..y", Errors{
+			{Pos: 6, Message: "year" + numberMessage},
+			{Pos: 9, Message: "year" + numberMessage},
+		}},
+		{"term problems are collected", "album:x runtime:y tag:", Errors{
+			{Pos: 1, Message: `unknown field "album", expected one of group, lyrics, rating, runtime, tag, title, year`},
+			{Pos: 17, Message: "runtime" + numberMessage},
+			{Pos: 23, Message: "tag needs a value"},
+		}},
+		{"leading OR", "OR a", Errors{{Pos: 1, Message: "OR needs a term on both sides"}}},
+		{"double OR", "a OR OR b", Errors{{Pos: 6, Message: "OR needs a term on both sides"}}},
+		{"trailing OR", "a OR", Errors{{Pos: 5, Message: "expected a term at the end of the query"}}},
+		{"trailing negation", "a -(", Errors{{Pos: 5, Message: "expected a term at the end of the query"}}},
+		{"empty parentheses", "a ()", Errors{{Pos: 4, Message: "expected a term before the closing parenthesis"}}},
+		{"unclosed parenthesis", "(a OR b", Errors{{Pos: 1, Message: "parenthesis is never closed"}}},
+		{"unclosed inner parenthesis", "(a (b)", Errors{{Pos: 1, Message: "parenthesis is never closed"}}},
+		{"unopened parenthesis", "a) b", Errors{{Pos: 2, Message: "closing parenthesis has no opening one"}}},
+		{"structure problem after term problems", "album:x (a", Errors{
+			{Pos: 1, Message: `unknown field "album", expected one of group, lyrics, rating, runtime, tag, title, year`},
+			{Pos: 9, Message: "parenthesis is never closed"},
+		}},
+		{"problems are sorted by position", "(year:x", Errors{
+			{Pos: 1, Message: "parenthesis is never closed"},
+			{Pos: 7, Message: "year" + numberMessage},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.input)
+			if got != nil {
+				t.Errorf("Parse(%q) = %s, want nil", tt.input, format(got))
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) {
+				t.Fatalf("Parse(%q) error = %v, want Errors", tt.input, err)
+			}
+			if !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("Parse(%q) error = %v, want %v", tt.input, errs, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	nested := func(depth int) string {
+		return strings.Repeat("(", depth) + "a" + strings.Repeat(")", depth)
+	}
+	longMessage := "the query is longer than 500 characters"
+	depthMessage := "parentheses and negations nest deeper than 32 levels"
+
+	tests := []struct {
+		name  string
+		input string
+		want  Errors // nil when the query parses
+	}{
+		{"longest query", strings.Repeat("a", MaxLength), nil},
+		{"length counts characters", strings.Repeat("ä", MaxLength), nil},
+		{"too long", strings.Repeat("a", MaxLength+1), Errors{{Pos: MaxLength + 1, Message: longMessage}}},
+		{"too long before other problems", strings.Repeat(")", MaxLength+1), Errors{{Pos: MaxLength + 1, Message: longMessage}}},
+		{"deepest parentheses", nested(maxDepth), nil},
+		{"parentheses too deep", nested(maxDepth + 1), Errors{{Pos: maxDepth + 1, Message: depthMessage}}},
+		{"deepest negations", strings.Repeat("-", maxDepth) + "a", nil},
+		{"negations too deep", strings.Repeat("-", maxDepth+1) + "a", Errors{{Pos: maxDepth + 1, Message: depthMessage}}},
+		{"parentheses and negations count together", strings.Repeat("-(", maxDepth/2) + "-a" + strings.Repeat(")", maxDepth/2), Errors{{Pos: maxDepth + 1, Message: depthMessage}}},
+		{"depth is counted per branch", nested(maxDepth) + " OR " + nested(maxDepth), nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse(tt.input)
+			if tt.want == nil {
+				if err != nil {
+					t.Fatalf("Parse() error = %v", err)
+				}
+				return
+			}
+
+			var errs Errors
+			if !errors.As(err, &errs) || !reflect.DeepEqual(errs, tt.want) {
+				t.Errorf("Parse() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestErrorsError(t *testing.T) {
+	errs := Errors{{Pos: 1, Message: "first"}, {Pos: 7, Message: "second"}}
+	if got, want := errs.Error(), "at position 1: first; at position 7: second"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+// format writes an expression in a form that shows its structure in failure messages
+func format(expr Expr) string {
+	switch e := expr.(type) {
+	case nil:
+		return "nil"
+	case *And:
+		return formatList("And", e.Exprs)
+	case *Or:
+		return formatList("Or", e.Exprs)
+	case *Not:
+		return fmt.Sprintf("Not@%d(%s)", e.Position, format(e.Expr))
+	case *Match:
+		return fmt.Sprintf("Match@%d(%s:%q)", e.Position, e.Field, e.Value)
+	case *Compare:
+		return fmt.Sprintf("Compare@%d(%s%s%g)", e.Position, e.Field, e.Op, e.Value)
+	}
+	return fmt.Sprintf("%T", expr)
+}
+
+func formatList(name string, exprs []Expr) string {
+	parts := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		parts = append(parts, format(expr))
+	}
+	return name + "(" + strings.Join(parts, " ") + ")"
+}
@@ -8,6 +8,36 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+type ApiKey struct {
+	ID         pgtype.UUID
+	UserID     pgtype.UUID
+	Name       string
+	Prefix     string
+	KeyHash    string
+	CreatedAt  pgtype.Timestamptz
+	LastUsedAt pgtype.Timestamptz
+	ExpiresAt  pgtype.Timestamptz
+	RevokedAt  pgtype.Timestamptz
+}
+
+type Artwork struct {
+	ID         pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+	Width      int32
+	Height     int32
+	Format     string
+	CreatedAt  pgtype.Timestamptz
+	UpdatedAt  pgtype.Timestamptz
+}
+
+type Favorite struct {
+	UserID     pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+	CreatedAt  pgtype.Timestamptz
+}
+
 type Group struct {
 	ID        pgtype.UUID
 	Name      string
@@ -16,6 +46,73 @@ type Group struct {
 	DeletedAt pgtype.Timestamptz
 }
 
+type GroupAlias struct {
+	ID        pgtype.UUID
+	GroupID   pgtype.UUID
+	Name      string
+	CreatedAt pgtype.Timestamptz
+	Locale    string
+	Type      string
+}
+
+type GroupDeletedSong struct {
+	SongID    pgtype.UUID
+	GroupID   pgtype.UUID
+	DeletedAt pgtype.Timestamptz
+}
+
+type IdempotencyKey struct {
+	UserID       pgtype.UUID
+	Key          string
+	RequestHash  []byte
+	StatusCode   pgtype.Int4
+	ContentType  string
+	ResponseBody []byte
+	CreatedAt    pgtype.Timestamptz
+	ExpiresAt    pgtype.Timestamptz
+}
+
+type PlayEvent struct {
+	ID             pgtype.UUID
+	UserID         pgtype.UUID
+	SongID         pgtype.UUID
+	PlayedAt       pgtype.Timestamptz
+	DurationPlayed int32
+	Client         string
+	CreatedAt      pgtype.Timestamptz
+}
+
+type Playlist struct {
+	ID          pgtype.UUID
+	Name        string
+	Description string
+	OwnerID     pgtype.UUID
+	Visibility  string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	DeletedAt   pgtype.Timestamptz
+}
+
+type PlaylistEntry struct {
+	ID         pgtype.UUID
+	PlaylistID pgtype.UUID
+	SongID     pgtype.UUID
+	Position   int32
+	AddedAt    pgtype.Timestamptz
+}
+
+type SmartPlaylist struct {
+	ID          pgtype.UUID
+	Name        string
+	Description string
+	OwnerID     pgtype.UUID
+	Visibility  string
+	Rules       []byte
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+	DeletedAt   pgtype.Timestamptz
+}
+
 type Song struct {
 	ID          pgtype.UUID
 	GroupID     pgtype.UUID
@@ -28,3 +125,67 @@ type Song struct {
 	UpdatedAt   pgtype.Timestamptz
 	DeletedAt   pgtype.Timestamptz
 }
+
+type SongListing struct {
+	ID                    pgtype.UUID
+	GroupID               pgtype.UUID
+	Title                 string
+	Runtime               int32
+	Lyrics                []byte
+	ReleaseDate           pgtype.Timestamptz
+	Link                  string
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	DeletedAt             pgtype.Timestamptz
+	GroupName             string
+	GroupCreatedAt        pgtype.Timestamptz
+	GroupUpdatedAt        pgtype.Timestamptz
+	GroupDeletedAt        pgtype.Timestamptz
+	PlayCount             int64
+	RatingAverage         float64
+	RatingCount           int32
+	ArtworkWidth          pgtype.Int4
+	ArtworkHeight         pgtype.Int4
+	ArtworkFormat         pgtype.Text
+	ArtworkUpdatedAt      pgtype.Timestamptz
+	GroupArtworkWidth     pgtype.Int4
+	GroupArtworkHeight    pgtype.Int4
+	GroupArtworkFormat    pgtype.Text
+	GroupArtworkUpdatedAt pgtype.Timestamptz
+}
+
+type SongPlayStat struct {
+	SongID    pgtype.UUID
+	PlayCount int64
+	UpdatedAt pgtype.Timestamptz
+}
+
+type SongRating struct {
+	UserID    pgtype.UUID
+	SongID    pgtype.UUID
+	Rating    int32
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+type SongRatingStat struct {
+	SongID        pgtype.UUID
+	RatingCount   int32
+	RatingSum     int32
+	RatingAverage float64
+	UpdatedAt     pgtype.Timestamptz
+}
+
+type SongTag struct {
+	SongID pgtype.UUID
+	Tag    string
+}
+
+type User struct {
+	ID           pgtype.UUID
+	Username     string
+	PasswordHash string
+	CreatedAt    pgtype.Timestamptz
+	UpdatedAt    pgtype.Timestamptz
+	Role         string
+}
@@ -12,6 +12,286 @@ import (
 	"github.com/jackc/pgx/v5/pgtype"
 )
 
+const addFavorite = `-- name: AddFavorite :exec
+
+INSERT INTO favorites (user_id, entity_type, entity_id)
+VALUES ($1, $2, $3)
+ON CONFLICT DO NOTHING
+`
+
+type AddFavoriteParams struct {
+	UserID     pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+// Favorites Table
+func (q *Queries) AddFavorite(ctx context.Context, arg AddFavoriteParams) error {
+	_, err := q.db.Exec(ctx, addFavorite, arg.UserID, arg.EntityType, arg.EntityID)
+	return err
+}
+
+const addGroupAliases = `-- name: AddGroupAliases :execrows
+INSERT INTO group_aliases (group_id, name, type)
+SELECT $1::UUID, unnest($2::VARCHAR[]), $3::VARCHAR
+ON CONFLICT DO NOTHING
+`
+
+type AddGroupAliasesParams struct {
+	GroupID pgtype.UUID
+	Names   []string
+	Type    string
+}
+
+func (q *Queries) AddGroupAliases(ctx context.Context, arg AddGroupAliasesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, addGroupAliases, arg.GroupID, arg.Names, arg.Type)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const adjustSongRatingStats = `-- name: AdjustSongRatingStats :one
+UPDATE song_rating_stats
+SET rating_count = rating_count + $1::INT,
+    rating_sum = rating_sum + $2::INT,
+    rating_average = COALESCE((rating_sum + $2::INT)::FLOAT8 / NULLIF(rating_count + $1::INT, 0), 0),
+    updated_at = NOW()
+WHERE song_id = $3
+RETURNING song_id, rating_count, rating_sum, rating_average, updated_at
+`
+
+type AdjustSongRatingStatsParams struct {
+	CountDelta int32
+	SumDelta   int32
+	SongID     pgtype.UUID
+}
+
+func (q *Queries) AdjustSongRatingStats(ctx context.Context, arg AdjustSongRatingStatsParams) (SongRatingStat, error) {
+	row := q.db.QueryRow(ctx, adjustSongRatingStats, arg.CountDelta, arg.SumDelta, arg.SongID)
+	var i SongRatingStat
+	err := row.Scan(
+		&i.SongID,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.RatingAverage,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const cascadeDeleteGroupSongs = `-- name: CascadeDeleteGroupSongs :many
+
+WITH deleted AS (
+    UPDATE songs
+    SET deleted_at = NOW()
+    WHERE group_id = $1 AND deleted_at IS NULL
+    RETURNING id, group_id, deleted_at
+)
+INSERT INTO group_deleted_songs (song_id, group_id, deleted_at)
+SELECT id, group_id, deleted_at FROM deleted
+ON CONFLICT (song_id) DO UPDATE
+SET group_id = EXCLUDED.group_id,
+    deleted_at = EXCLUDED.deleted_at
+RETURNING song_id
+`
+
+// Group Deletion
+func (q *Queries) CascadeDeleteGroupSongs(ctx context.Context, groupID pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, cascadeDeleteGroupSongs, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var song_id pgtype.UUID
+		if err := rows.Scan(&song_id); err != nil {
+			return nil, err
+		}
+		items = append(items, song_id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeIdempotentRequest = `-- name: CompleteIdempotentRequest :exec
+UPDATE idempotency_keys
+SET status_code = $1,
+    content_type = $2,
+    response_body = $3
+WHERE user_id = $4 AND key = $5
+`
+
+type CompleteIdempotentRequestParams struct {
+	StatusCode   pgtype.Int4
+	ContentType  string
+	ResponseBody []byte
+	UserID       pgtype.UUID
+	Key          string
+}
+
+func (q *Queries) CompleteIdempotentRequest(ctx context.Context, arg CompleteIdempotentRequestParams) error {
+	_, err := q.db.Exec(ctx, completeIdempotentRequest,
+		arg.StatusCode,
+		arg.ContentType,
+		arg.ResponseBody,
+		arg.UserID,
+		arg.Key,
+	)
+	return err
+}
+
+const copyGroupAliases = `-- name: CopyGroupAliases :execrows
+INSERT INTO group_aliases (group_id, name, locale, type, created_at)
+SELECT $1::UUID, name, locale, type, created_at
+FROM group_aliases
+WHERE group_id = ANY($2::UUID[])
+ON CONFLICT DO NOTHING
+`
+
+type CopyGroupAliasesParams struct {
+	ToGroupID    pgtype.UUID
+	FromGroupIds []pgtype.UUID
+}
+
+func (q *Queries) CopyGroupAliases(ctx context.Context, arg CopyGroupAliasesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyGroupAliases, arg.ToGroupID, arg.FromGroupIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copyGroupFavorites = `-- name: CopyGroupFavorites :execrows
+INSERT INTO favorites (user_id, entity_type, entity_id, created_at)
+SELECT DISTINCT ON (user_id) user_id, entity_type, $1::UUID, created_at
+FROM favorites
+WHERE entity_type = 'group' AND entity_id = ANY($2::UUID[])
+ORDER BY user_id, created_at
+ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING
+`
+
+type CopyGroupFavoritesParams struct {
+	ToGroupID    pgtype.UUID
+	FromGroupIds []pgtype.UUID
+}
+
+func (q *Queries) CopyGroupFavorites(ctx context.Context, arg CopyGroupFavoritesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copyGroupFavorites, arg.ToGroupID, arg.FromGroupIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copySongFavorites = `-- name: CopySongFavorites :execrows
+INSERT INTO favorites (user_id, entity_type, entity_id, created_at)
+SELECT user_id, entity_type, $1::UUID, created_at
+FROM favorites
+WHERE entity_type = 'song' AND entity_id = $2
+ON CONFLICT (user_id, entity_type, entity_id) DO NOTHING
+`
+
+type CopySongFavoritesParams struct {
+	ToSongID   pgtype.UUID
+	FromSongID pgtype.UUID
+}
+
+func (q *Queries) CopySongFavorites(ctx context.Context, arg CopySongFavoritesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copySongFavorites, arg.ToSongID, arg.FromSongID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copySongRatings = `-- name: CopySongRatings :execrows
+INSERT INTO song_ratings (user_id, song_id, rating, created_at, updated_at)
+SELECT user_id, $1::UUID, rating, created_at, updated_at
+FROM song_ratings
+WHERE song_id = $2
+ON CONFLICT (user_id, song_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    updated_at = EXCLUDED.updated_at
+WHERE song_ratings.updated_at < EXCLUDED.updated_at
+`
+
+type CopySongRatingsParams struct {
+	ToSongID   pgtype.UUID
+	FromSongID pgtype.UUID
+}
+
+func (q *Queries) CopySongRatings(ctx context.Context, arg CopySongRatingsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copySongRatings, arg.ToSongID, arg.FromSongID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const copySongTags = `-- name: CopySongTags :execrows
+INSERT INTO song_tags (song_id, tag)
+SELECT $1::UUID, tag
+FROM song_tags
+WHERE song_id = $2
+ON CONFLICT (song_id, tag) DO NOTHING
+`
+
+type CopySongTagsParams struct {
+	ToSongID   pgtype.UUID
+	FromSongID pgtype.UUID
+}
+
+func (q *Queries) CopySongTags(ctx context.Context, arg CopySongTagsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, copySongTags, arg.ToSongID, arg.FromSongID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const createApiKey = `-- name: CreateApiKey :one
+
+INSERT INTO api_keys (user_id, name, prefix, key_hash, expires_at)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at, revoked_at
+`
+
+type CreateApiKeyParams struct {
+	UserID    pgtype.UUID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	ExpiresAt pgtype.Timestamptz
+}
+
+// API Keys Table
+func (q *Queries) CreateApiKey(ctx context.Context, arg CreateApiKeyParams) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, createApiKey,
+		arg.UserID,
+		arg.Name,
+		arg.Prefix,
+		arg.KeyHash,
+		arg.ExpiresAt,
+	)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
 const createGroup = `-- name: CreateGroup :one
 
 INSERT INTO groups (name)
@@ -33,6 +313,189 @@ func (q *Queries) CreateGroup(ctx context.Context, name string) (Group, error) {
 	return i, err
 }
 
+const createGroupAlias = `-- name: CreateGroupAlias :one
+
+INSERT INTO group_aliases (group_id, name, locale, type)
+VALUES ($1, $2, $3, $4)
+RETURNING id, group_id, name, created_at, locale, type
+`
+
+type CreateGroupAliasParams struct {
+	GroupID pgtype.UUID
+	Name    string
+	Locale  string
+	Type    string
+}
+
+// Group Aliases
+func (q *Queries) CreateGroupAlias(ctx context.Context, arg CreateGroupAliasParams) (GroupAlias, error) {
+	row := q.db.QueryRow(ctx, createGroupAlias,
+		arg.GroupID,
+		arg.Name,
+		arg.Locale,
+		arg.Type,
+	)
+	var i GroupAlias
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.Locale,
+		&i.Type,
+	)
+	return i, err
+}
+
+const createPlayEvent = `-- name: CreatePlayEvent :one
+
+WITH created AS (
+    INSERT INTO play_events (user_id, song_id, played_at, duration_played, client)
+    VALUES ($1, $2, $3, $4, $5)
+    RETURNING id, user_id, song_id, played_at, duration_played, client, created_at
+), counted AS (
+    INSERT INTO song_play_stats (song_id, play_count)
+    SELECT song_id, 1 FROM created
+    ON CONFLICT (song_id) DO UPDATE
+    SET play_count = song_play_stats.play_count + 1,
+        updated_at = NOW()
+)
+SELECT id, user_id, song_id, played_at, duration_played, client, created_at
+FROM created
+`
+
+type CreatePlayEventParams struct {
+	UserID         pgtype.UUID
+	SongID         pgtype.UUID
+	PlayedAt       pgtype.Timestamptz
+	DurationPlayed int32
+	Client         string
+}
+
+// Play Events Table
+func (q *Queries) CreatePlayEvent(ctx context.Context, arg CreatePlayEventParams) (PlayEvent, error) {
+	row := q.db.QueryRow(ctx, createPlayEvent,
+		arg.UserID,
+		arg.SongID,
+		arg.PlayedAt,
+		arg.DurationPlayed,
+		arg.Client,
+	)
+	var i PlayEvent
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.SongID,
+		&i.PlayedAt,
+		&i.DurationPlayed,
+		&i.Client,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const createPlaylist = `-- name: CreatePlaylist :one
+
+INSERT INTO playlists (name, description, owner_id, visibility)
+VALUES ($1, $2, $3, $4)
+RETURNING id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+`
+
+type CreatePlaylistParams struct {
+	Name        string
+	Description string
+	OwnerID     pgtype.UUID
+	Visibility  string
+}
+
+// Playlists Table
+func (q *Queries) CreatePlaylist(ctx context.Context, arg CreatePlaylistParams) (Playlist, error) {
+	row := q.db.QueryRow(ctx, createPlaylist,
+		arg.Name,
+		arg.Description,
+		arg.OwnerID,
+		arg.Visibility,
+	)
+	var i Playlist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const createPlaylistEntry = `-- name: CreatePlaylistEntry :one
+
+INSERT INTO playlist_entries (playlist_id, song_id, position)
+VALUES ($1, $2, $3)
+RETURNING id, playlist_id, song_id, position, added_at
+`
+
+type CreatePlaylistEntryParams struct {
+	PlaylistID pgtype.UUID
+	SongID     pgtype.UUID
+	Position   int32
+}
+
+// Playlist Entries Table
+func (q *Queries) CreatePlaylistEntry(ctx context.Context, arg CreatePlaylistEntryParams) (PlaylistEntry, error) {
+	row := q.db.QueryRow(ctx, createPlaylistEntry, arg.PlaylistID, arg.SongID, arg.Position)
+	var i PlaylistEntry
+	err := row.Scan(
+		&i.ID,
+		&i.PlaylistID,
+		&i.SongID,
+		&i.Position,
+		&i.AddedAt,
+	)
+	return i, err
+}
+
+const createSmartPlaylist = `-- name: CreateSmartPlaylist :one
+
+INSERT INTO smart_playlists (name, description, owner_id, visibility, rules)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+`
+
+type CreateSmartPlaylistParams struct {
+	Name        string
+	Description string
+	OwnerID     pgtype.UUID
+	Visibility  string
+	Rules       []byte
+}
+
+// Smart Playlists Table
+func (q *Queries) CreateSmartPlaylist(ctx context.Context, arg CreateSmartPlaylistParams) (SmartPlaylist, error) {
+	row := q.db.QueryRow(ctx, createSmartPlaylist,
+		arg.Name,
+		arg.Description,
+		arg.OwnerID,
+		arg.Visibility,
+		arg.Rules,
+	)
+	var i SmartPlaylist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const createSong = `-- name: CreateSong :one
 
 INSERT INTO songs (group_id, title, runtime, lyrics, release_date, link)
@@ -75,89 +538,2313 @@ func (q *Queries) CreateSong(ctx context.Context, arg CreateSongParams) (Song, e
 	return i, err
 }
 
-const deleteGroup = `-- name: DeleteGroup :exec
-UPDATE groups
-SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL
-`
-
-func (q *Queries) DeleteGroup(ctx context.Context, id pgtype.UUID) error {
-	_, err := q.db.Exec(ctx, deleteGroup, id)
-	return err
-}
+const createUser = `-- name: CreateUser :one
 
-const deleteSong = `-- name: DeleteSong :execresult
-UPDATE songs
-SET deleted_at = NOW()
-WHERE id = $1 AND deleted_at IS NULL
+INSERT INTO users (username, password_hash, role)
+VALUES ($1, $2, $3)
+RETURNING id, username, password_hash, created_at, updated_at, role
 `
 
-func (q *Queries) DeleteSong(ctx context.Context, id pgtype.UUID) (pgconn.CommandTag, error) {
-	return q.db.Exec(ctx, deleteSong, id)
+type CreateUserParams struct {
+	Username     string
+	PasswordHash string
+	Role         string
 }
 
-const getGroup = `-- name: GetGroup :one
-SELECT id, name, created_at, updated_at, deleted_at FROM groups
-WHERE id = $1 LIMIT 1
-`
-
-func (q *Queries) GetGroup(ctx context.Context, id pgtype.UUID) (Group, error) {
-	row := q.db.QueryRow(ctx, getGroup, id)
-	var i Group
+// Users Table
+func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row := q.db.QueryRow(ctx, createUser, arg.Username, arg.PasswordHash, arg.Role)
+	var i User
 	err := row.Scan(
 		&i.ID,
-		&i.Name,
+		&i.Username,
+		&i.PasswordHash,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-		&i.DeletedAt,
+		&i.Role,
 	)
 	return i, err
 }
 
-const getGroupsCount = `-- name: GetGroupsCount :one
-SELECT count(*) FROM groups
-WHERE deleted_at IS NULL
+const deleteArtwork = `-- name: DeleteArtwork :execresult
+DELETE FROM artworks
+WHERE entity_type = $1 AND entity_id = $2
 `
 
-func (q *Queries) GetGroupsCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, getGroupsCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type DeleteArtworkParams struct {
+	EntityType string
+	EntityID   pgtype.UUID
 }
 
-const getGroupsWithPagination = `-- name: GetGroupsWithPagination :many
-SELECT id, name, created_at, updated_at FROM groups
-WHERE deleted_at IS NULL
-ORDER BY created_at DESC LIMIT $1 OFFSET $2
+func (q *Queries) DeleteArtwork(ctx context.Context, arg DeleteArtworkParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteArtwork, arg.EntityType, arg.EntityID)
+}
+
+const deleteArtworksByEntities = `-- name: DeleteArtworksByEntities :many
+DELETE FROM artworks
+WHERE entity_type = $1 AND entity_id = ANY($2::UUID[])
+RETURNING id, entity_type, entity_id, width, height, format, created_at, updated_at
 `
 
-type GetGroupsWithPaginationParams struct {
-	Limit  int32
-	Offset int32
+type DeleteArtworksByEntitiesParams struct {
+	EntityType string
+	EntityIds  []pgtype.UUID
 }
 
-type GetGroupsWithPaginationRow struct {
-	ID        pgtype.UUID
-	Name      string
-	CreatedAt pgtype.Timestamptz
-	UpdatedAt pgtype.Timestamptz
+func (q *Queries) DeleteArtworksByEntities(ctx context.Context, arg DeleteArtworksByEntitiesParams) ([]Artwork, error) {
+	rows, err := q.db.Query(ctx, deleteArtworksByEntities, arg.EntityType, arg.EntityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Artwork
+	for rows.Next() {
+		var i Artwork
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Width,
+			&i.Height,
+			&i.Format,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const deleteEntitiesFavorites = `-- name: DeleteEntitiesFavorites :execrows
+DELETE FROM favorites
+WHERE entity_type = $1 AND entity_id = ANY($2::UUID[])
+`
+
+type DeleteEntitiesFavoritesParams struct {
+	EntityType string
+	EntityIds  []pgtype.UUID
+}
+
+func (q *Queries) DeleteEntitiesFavorites(ctx context.Context, arg DeleteEntitiesFavoritesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteEntitiesFavorites, arg.EntityType, arg.EntityIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteExpiredIdempotencyKeys = `-- name: DeleteExpiredIdempotencyKeys :execrows
+DELETE FROM idempotency_keys
+WHERE expires_at <= NOW()
+`
+
+func (q *Queries) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteExpiredIdempotencyKeys)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGroup = `-- name: DeleteGroup :execrows
+UPDATE groups
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($2::TIMESTAMPTZ IS NULL OR updated_at = $2)
+`
+
+type DeleteGroupParams struct {
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) DeleteGroup(ctx context.Context, arg DeleteGroupParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGroup, arg.ID, arg.IfUpdatedAt)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGroupAlias = `-- name: DeleteGroupAlias :execrows
+DELETE FROM group_aliases
+WHERE id = $1 AND group_id = $2
+`
+
+type DeleteGroupAliasParams struct {
+	ID      pgtype.UUID
+	GroupID pgtype.UUID
+}
+
+func (q *Queries) DeleteGroupAlias(ctx context.Context, arg DeleteGroupAliasParams) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGroupAlias, arg.ID, arg.GroupID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGroups = `-- name: DeleteGroups :execrows
+UPDATE groups
+SET deleted_at = NOW()
+WHERE id = ANY($1::UUID[]) AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteGroups(ctx context.Context, ids []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, deleteGroups, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const deleteGroupsFavorites = `-- name: DeleteGroupsFavorites :exec
+DELETE FROM favorites
+WHERE entity_type = 'group' AND entity_id = ANY($1::UUID[])
+`
+
+func (q *Queries) DeleteGroupsFavorites(ctx context.Context, groupIds []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteGroupsFavorites, groupIds)
+	return err
+}
+
+const deleteIdempotencyKey = `-- name: DeleteIdempotencyKey :exec
+DELETE FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+`
+
+type DeleteIdempotencyKeyParams struct {
+	UserID pgtype.UUID
+	Key    string
+}
+
+func (q *Queries) DeleteIdempotencyKey(ctx context.Context, arg DeleteIdempotencyKeyParams) error {
+	_, err := q.db.Exec(ctx, deleteIdempotencyKey, arg.UserID, arg.Key)
+	return err
+}
+
+const deletePlaylist = `-- name: DeletePlaylist :execresult
+UPDATE playlists
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeletePlaylist(ctx context.Context, id pgtype.UUID) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deletePlaylist, id)
+}
+
+const deletePlaylistEntry = `-- name: DeletePlaylistEntry :exec
+DELETE FROM playlist_entries
+WHERE id = $1
+`
+
+func (q *Queries) DeletePlaylistEntry(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deletePlaylistEntry, id)
+	return err
+}
+
+const deleteSmartPlaylist = `-- name: DeleteSmartPlaylist :execresult
+UPDATE smart_playlists
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) DeleteSmartPlaylist(ctx context.Context, id pgtype.UUID) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteSmartPlaylist, id)
+}
+
+const deleteSong = `-- name: DeleteSong :execresult
+UPDATE songs
+SET deleted_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+  AND ($2::TIMESTAMPTZ IS NULL OR updated_at = $2)
+`
+
+type DeleteSongParams struct {
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) DeleteSong(ctx context.Context, arg DeleteSongParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, deleteSong, arg.ID, arg.IfUpdatedAt)
+}
+
+const deleteSongFavorites = `-- name: DeleteSongFavorites :exec
+DELETE FROM favorites
+WHERE entity_type = 'song' AND entity_id = $1
+`
+
+func (q *Queries) DeleteSongFavorites(ctx context.Context, entityID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteSongFavorites, entityID)
+	return err
+}
+
+const deleteSongRating = `-- name: DeleteSongRating :one
+DELETE FROM song_ratings
+WHERE user_id = $1 AND song_id = $2
+RETURNING rating
+`
+
+type DeleteSongRatingParams struct {
+	UserID pgtype.UUID
+	SongID pgtype.UUID
+}
+
+func (q *Queries) DeleteSongRating(ctx context.Context, arg DeleteSongRatingParams) (int32, error) {
+	row := q.db.QueryRow(ctx, deleteSongRating, arg.UserID, arg.SongID)
+	var rating int32
+	err := row.Scan(&rating)
+	return rating, err
+}
+
+const deleteSongRatings = `-- name: DeleteSongRatings :exec
+DELETE FROM song_ratings
+WHERE song_id = $1
+`
+
+func (q *Queries) DeleteSongRatings(ctx context.Context, songID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, deleteSongRatings, songID)
+	return err
+}
+
+const findSongByGroupAndTitle = `-- name: FindSongByGroupAndTitle :one
+SELECT s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at, s.updated_at, s.deleted_at
+FROM songs s
+         JOIN groups g ON s.group_id = g.id
+WHERE s.deleted_at IS NULL
+  AND g.deleted_at IS NULL
+  AND (LOWER(g.name) = LOWER($1::VARCHAR)
+    OR EXISTS (SELECT 1
+               FROM group_aliases a
+               WHERE a.group_id = g.id
+                 AND LOWER(a.name) = LOWER($1::VARCHAR)))
+  AND LOWER(s.title) = LOWER($2::VARCHAR)
+ORDER BY s.created_at
+LIMIT 1
+`
+
+type FindSongByGroupAndTitleParams struct {
+	GroupName string
+	Title     string
+}
+
+func (q *Queries) FindSongByGroupAndTitle(ctx context.Context, arg FindSongByGroupAndTitleParams) (Song, error) {
+	row := q.db.QueryRow(ctx, findSongByGroupAndTitle, arg.GroupName, arg.Title)
+	var i Song
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Title,
+		&i.Runtime,
+		&i.Lyrics,
+		&i.ReleaseDate,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getActiveApiKeyByHash = `-- name: GetActiveApiKeyByHash :one
+SELECT id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at, revoked_at
+FROM api_keys
+WHERE key_hash = $1
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > NOW())
+LIMIT 1
+`
+
+func (q *Queries) GetActiveApiKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
+	row := q.db.QueryRow(ctx, getActiveApiKeyByHash, keyHash)
+	var i ApiKey
+	err := row.Scan(
+		&i.ID,
+		&i.UserID,
+		&i.Name,
+		&i.Prefix,
+		&i.KeyHash,
+		&i.CreatedAt,
+		&i.LastUsedAt,
+		&i.ExpiresAt,
+		&i.RevokedAt,
+	)
+	return i, err
+}
+
+const getApiKeysByUser = `-- name: GetApiKeysByUser :many
+SELECT id, user_id, name, prefix, key_hash, created_at, last_used_at, expires_at, revoked_at
+FROM api_keys
+WHERE user_id = $1 AND revoked_at IS NULL
+ORDER BY created_at DESC
+`
+
+func (q *Queries) GetApiKeysByUser(ctx context.Context, userID pgtype.UUID) ([]ApiKey, error) {
+	rows, err := q.db.Query(ctx, getApiKeysByUser, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []ApiKey
+	for rows.Next() {
+		var i ApiKey
+		if err := rows.Scan(
+			&i.ID,
+			&i.UserID,
+			&i.Name,
+			&i.Prefix,
+			&i.KeyHash,
+			&i.CreatedAt,
+			&i.LastUsedAt,
+			&i.ExpiresAt,
+			&i.RevokedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getArtwork = `-- name: GetArtwork :one
+SELECT id, entity_type, entity_id, width, height, format, created_at, updated_at
+FROM artworks
+WHERE entity_type = $1 AND entity_id = $2 LIMIT 1
+`
+
+type GetArtworkParams struct {
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+func (q *Queries) GetArtwork(ctx context.Context, arg GetArtworkParams) (Artwork, error) {
+	row := q.db.QueryRow(ctx, getArtwork, arg.EntityType, arg.EntityID)
+	var i Artwork
+	err := row.Scan(
+		&i.ID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Width,
+		&i.Height,
+		&i.Format,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getArtworksByEntities = `-- name: GetArtworksByEntities :many
+SELECT id, entity_type, entity_id, width, height, format, created_at, updated_at
+FROM artworks
+WHERE entity_type = $1 AND entity_id = ANY($2::UUID[])
+`
+
+type GetArtworksByEntitiesParams struct {
+	EntityType string
+	EntityIds  []pgtype.UUID
+}
+
+func (q *Queries) GetArtworksByEntities(ctx context.Context, arg GetArtworksByEntitiesParams) ([]Artwork, error) {
+	rows, err := q.db.Query(ctx, getArtworksByEntities, arg.EntityType, arg.EntityIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Artwork
+	for rows.Next() {
+		var i Artwork
+		if err := rows.Scan(
+			&i.ID,
+			&i.EntityType,
+			&i.EntityID,
+			&i.Width,
+			&i.Height,
+			&i.Format,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getFavoritesCount = `-- name: GetFavoritesCount :one
+SELECT count(*)
+FROM favorites f
+         LEFT JOIN songs s ON f.entity_type = 'song' AND s.id = f.entity_id AND s.deleted_at IS NULL
+         LEFT JOIN groups g ON f.entity_type = 'group' AND g.id = f.entity_id AND g.deleted_at IS NULL
+WHERE f.user_id = $1
+  AND ($2::VARCHAR = '' OR f.entity_type = $2::VARCHAR)
+  AND (s.id IS NOT NULL OR g.id IS NOT NULL)
+`
+
+type GetFavoritesCountParams struct {
+	UserID     pgtype.UUID
+	EntityType string
+}
+
+func (q *Queries) GetFavoritesCount(ctx context.Context, arg GetFavoritesCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getFavoritesCount, arg.UserID, arg.EntityType)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getFavoritesWithPagination = `-- name: GetFavoritesWithPagination :many
+SELECT f.entity_type,
+       f.entity_id,
+       COALESCE(s.title, g.name)::VARCHAR AS name,
+       COALESCE(sg.name, '')::VARCHAR AS group_name,
+       f.created_at
+FROM favorites f
+         LEFT JOIN songs s ON f.entity_type = 'song' AND s.id = f.entity_id AND s.deleted_at IS NULL
+         LEFT JOIN groups sg ON sg.id = s.group_id
+         LEFT JOIN groups g ON f.entity_type = 'group' AND g.id = f.entity_id AND g.deleted_at IS NULL
+WHERE f.user_id = $1
+  AND ($2::VARCHAR = '' OR f.entity_type = $2::VARCHAR)
+  AND (s.id IS NOT NULL OR g.id IS NOT NULL)
+ORDER BY f.created_at DESC
+    LIMIT $3 OFFSET $4
+`
+
+type GetFavoritesWithPaginationParams struct {
+	UserID      pgtype.UUID
+	EntityType  string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type GetFavoritesWithPaginationRow struct {
+	EntityType string
+	EntityID   pgtype.UUID
+	Name       string
+	GroupName  string
+	CreatedAt  pgtype.Timestamptz
+}
+
+func (q *Queries) GetFavoritesWithPagination(ctx context.Context, arg GetFavoritesWithPaginationParams) ([]GetFavoritesWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getFavoritesWithPagination,
+		arg.UserID,
+		arg.EntityType,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetFavoritesWithPaginationRow
+	for rows.Next() {
+		var i GetFavoritesWithPaginationRow
+		if err := rows.Scan(
+			&i.EntityType,
+			&i.EntityID,
+			&i.Name,
+			&i.GroupName,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroup = `-- name: GetGroup :one
+SELECT id, name, created_at, updated_at, deleted_at FROM groups
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetGroup(ctx context.Context, id pgtype.UUID) (Group, error) {
+	row := q.db.QueryRow(ctx, getGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getGroupAliases = `-- name: GetGroupAliases :many
+SELECT id, group_id, name, created_at, locale, type
+FROM group_aliases
+WHERE group_id = $1
+ORDER BY type, locale, name
+`
+
+func (q *Queries) GetGroupAliases(ctx context.Context, groupID pgtype.UUID) ([]GroupAlias, error) {
+	rows, err := q.db.Query(ctx, getGroupAliases, groupID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GroupAlias
+	for rows.Next() {
+		var i GroupAlias
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.Locale,
+			&i.Type,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupByNormalizedName = `-- name: GetGroupByNormalizedName :one
+
+SELECT id, name, created_at, updated_at, deleted_at
+FROM groups
+WHERE LOWER(BTRIM(name)) = LOWER(BTRIM($1::VARCHAR)) AND deleted_at IS NULL
+LIMIT 1
+`
+
+// Group Names
+func (q *Queries) GetGroupByNormalizedName(ctx context.Context, name string) (Group, error) {
+	row := q.db.QueryRow(ctx, getGroupByNormalizedName, name)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getGroupsCount = `-- name: GetGroupsCount :one
+SELECT count(*) FROM groups
+WHERE deleted_at IS NULL
+`
+
+func (q *Queries) GetGroupsCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getGroupsCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getGroupsSongCounts = `-- name: GetGroupsSongCounts :many
+
+SELECT group_id, count(*) AS song_count
+FROM songs
+WHERE group_id = ANY($1::UUID[]) AND deleted_at IS NULL
+GROUP BY group_id
+`
+
+type GetGroupsSongCountsRow struct {
+	GroupID   pgtype.UUID
+	SongCount int64
+}
+
+// Group Merges
+func (q *Queries) GetGroupsSongCounts(ctx context.Context, groupIds []pgtype.UUID) ([]GetGroupsSongCountsRow, error) {
+	rows, err := q.db.Query(ctx, getGroupsSongCounts, groupIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupsSongCountsRow
+	for rows.Next() {
+		var i GetGroupsSongCountsRow
+		if err := rows.Scan(
+			&i.GroupID,
+			&i.SongCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupsSongIDsForUpdate = `-- name: GetGroupsSongIDsForUpdate :many
+SELECT id
+FROM songs
+WHERE group_id = ANY($1::UUID[])
+FOR UPDATE
+`
+
+func (q *Queries) GetGroupsSongIDsForUpdate(ctx context.Context, groupIds []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getGroupsSongIDsForUpdate, groupIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGroupsWithPagination = `-- name: GetGroupsWithPagination :many
+SELECT id, name, created_at, updated_at FROM groups
+WHERE deleted_at IS NULL
+ORDER BY created_at DESC LIMIT $1 OFFSET $2
+`
+
+type GetGroupsWithPaginationParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetGroupsWithPaginationRow struct {
+	ID        pgtype.UUID
+	Name      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetGroupsWithPagination(ctx context.Context, arg GetGroupsWithPaginationParams) ([]GetGroupsWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getGroupsWithPagination, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetGroupsWithPaginationRow
+	for rows.Next() {
+		var i GetGroupsWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getIdempotencyKey = `-- name: GetIdempotencyKey :one
+SELECT user_id, key, request_hash, status_code, content_type, response_body, created_at, expires_at
+FROM idempotency_keys
+WHERE user_id = $1 AND key = $2
+`
+
+type GetIdempotencyKeyParams struct {
+	UserID pgtype.UUID
+	Key    string
+}
+
+func (q *Queries) GetIdempotencyKey(ctx context.Context, arg GetIdempotencyKeyParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, getIdempotencyKey, arg.UserID, arg.Key)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
+}
+
+const getPlayHistoryCount = `-- name: GetPlayHistoryCount :one
+SELECT count(*) FROM play_events
+WHERE user_id = $1
+`
+
+func (q *Queries) GetPlayHistoryCount(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, getPlayHistoryCount, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getPlayHistoryWithPagination = `-- name: GetPlayHistoryWithPagination :many
+SELECT e.id,
+       e.song_id,
+       s.title,
+       g.name AS group_name,
+       e.played_at,
+       e.duration_played,
+       e.client,
+       (s.deleted_at IS NULL)::BOOLEAN AS available
+FROM play_events e
+         JOIN songs s ON s.id = e.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE e.user_id = $1
+ORDER BY e.played_at DESC
+    LIMIT $2 OFFSET $3
+`
+
+type GetPlayHistoryWithPaginationParams struct {
+	UserID      pgtype.UUID
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type GetPlayHistoryWithPaginationRow struct {
+	ID             pgtype.UUID
+	SongID         pgtype.UUID
+	Title          string
+	GroupName      string
+	PlayedAt       pgtype.Timestamptz
+	DurationPlayed int32
+	Client         string
+	Available      bool
+}
+
+func (q *Queries) GetPlayHistoryWithPagination(ctx context.Context, arg GetPlayHistoryWithPaginationParams) ([]GetPlayHistoryWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getPlayHistoryWithPagination, arg.UserID, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPlayHistoryWithPaginationRow
+	for rows.Next() {
+		var i GetPlayHistoryWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.SongID,
+			&i.Title,
+			&i.GroupName,
+			&i.PlayedAt,
+			&i.DurationPlayed,
+			&i.Client,
+			&i.Available,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlaylist = `-- name: GetPlaylist :one
+SELECT id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+FROM playlists
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetPlaylist(ctx context.Context, id pgtype.UUID) (Playlist, error) {
+	row := q.db.QueryRow(ctx, getPlaylist, id)
+	var i Playlist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getPlaylistEntries = `-- name: GetPlaylistEntries :many
+SELECT e.id, e.playlist_id, e.song_id, e.position, e.added_at,
+       s.title, s.runtime, s.link, s.group_id, g.name AS group_name, s.deleted_at AS song_deleted_at
+FROM playlist_entries e
+         JOIN songs s ON s.id = e.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE e.playlist_id = $1
+ORDER BY e.position
+`
+
+type GetPlaylistEntriesRow struct {
+	ID            pgtype.UUID
+	PlaylistID    pgtype.UUID
+	SongID        pgtype.UUID
+	Position      int32
+	AddedAt       pgtype.Timestamptz
+	Title         string
+	Runtime       int32
+	Link          string
+	GroupID       pgtype.UUID
+	GroupName     string
+	SongDeletedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetPlaylistEntries(ctx context.Context, playlistID pgtype.UUID) ([]GetPlaylistEntriesRow, error) {
+	rows, err := q.db.Query(ctx, getPlaylistEntries, playlistID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPlaylistEntriesRow
+	for rows.Next() {
+		var i GetPlaylistEntriesRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.PlaylistID,
+			&i.SongID,
+			&i.Position,
+			&i.AddedAt,
+			&i.Title,
+			&i.Runtime,
+			&i.Link,
+			&i.GroupID,
+			&i.GroupName,
+			&i.SongDeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlaylistEntry = `-- name: GetPlaylistEntry :one
+SELECT id, playlist_id, song_id, position, added_at
+FROM playlist_entries
+WHERE id = $1 AND playlist_id = $2 LIMIT 1
+`
+
+type GetPlaylistEntryParams struct {
+	ID         pgtype.UUID
+	PlaylistID pgtype.UUID
+}
+
+func (q *Queries) GetPlaylistEntry(ctx context.Context, arg GetPlaylistEntryParams) (PlaylistEntry, error) {
+	row := q.db.QueryRow(ctx, getPlaylistEntry, arg.ID, arg.PlaylistID)
+	var i PlaylistEntry
+	err := row.Scan(
+		&i.ID,
+		&i.PlaylistID,
+		&i.SongID,
+		&i.Position,
+		&i.AddedAt,
+	)
+	return i, err
+}
+
+const getPlaylistEntryCount = `-- name: GetPlaylistEntryCount :one
+SELECT count(*) FROM playlist_entries
+WHERE playlist_id = $1
+`
+
+func (q *Queries) GetPlaylistEntryCount(ctx context.Context, playlistID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, getPlaylistEntryCount, playlistID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getPlaylistsCount = `-- name: GetPlaylistsCount :one
+SELECT count(*) FROM playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = $1::UUID OR $2::BOOLEAN)
+  AND ($3::UUID IS NULL OR owner_id = $3::UUID)
+  AND ($4::VARCHAR = '' OR visibility = $4::VARCHAR)
+`
+
+type GetPlaylistsCountParams struct {
+	ViewerID      pgtype.UUID
+	ViewerIsAdmin bool
+	OwnerID       pgtype.UUID
+	Visibility    string
+}
+
+func (q *Queries) GetPlaylistsCount(ctx context.Context, arg GetPlaylistsCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getPlaylistsCount,
+		arg.ViewerID,
+		arg.ViewerIsAdmin,
+		arg.OwnerID,
+		arg.Visibility,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getPlaylistsStats = `-- name: GetPlaylistsStats :many
+SELECT e.playlist_id,
+       count(*) AS entry_count,
+       COALESCE(SUM(s.runtime) FILTER (WHERE s.deleted_at IS NULL), 0)::BIGINT AS total_runtime,
+       count(*) FILTER (WHERE s.deleted_at IS NOT NULL) AS unavailable_count
+FROM playlist_entries e
+         JOIN songs s ON s.id = e.song_id
+WHERE e.playlist_id = ANY($1::UUID[])
+GROUP BY e.playlist_id
+`
+
+type GetPlaylistsStatsRow struct {
+	PlaylistID       pgtype.UUID
+	EntryCount       int64
+	TotalRuntime     int64
+	UnavailableCount int64
+}
+
+func (q *Queries) GetPlaylistsStats(ctx context.Context, playlistIds []pgtype.UUID) ([]GetPlaylistsStatsRow, error) {
+	rows, err := q.db.Query(ctx, getPlaylistsStats, playlistIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPlaylistsStatsRow
+	for rows.Next() {
+		var i GetPlaylistsStatsRow
+		if err := rows.Scan(
+			&i.PlaylistID,
+			&i.EntryCount,
+			&i.TotalRuntime,
+			&i.UnavailableCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlaylistsWithPagination = `-- name: GetPlaylistsWithPagination :many
+SELECT id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+FROM playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = $1::UUID OR $2::BOOLEAN)
+  AND ($3::UUID IS NULL OR owner_id = $3::UUID)
+  AND ($4::VARCHAR = '' OR visibility = $4::VARCHAR)
+ORDER BY created_at DESC
+    LIMIT $5 OFFSET $6
+`
+
+type GetPlaylistsWithPaginationParams struct {
+	ViewerID      pgtype.UUID
+	ViewerIsAdmin bool
+	OwnerID       pgtype.UUID
+	Visibility    string
+	LimitCount    int32
+	OffsetCount   int32
+}
+
+func (q *Queries) GetPlaylistsWithPagination(ctx context.Context, arg GetPlaylistsWithPaginationParams) ([]Playlist, error) {
+	rows, err := q.db.Query(ctx, getPlaylistsWithPagination,
+		arg.ViewerID,
+		arg.ViewerIsAdmin,
+		arg.OwnerID,
+		arg.Visibility,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Playlist
+	for rows.Next() {
+		var i Playlist
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.Visibility,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPurgeableGroupIDs = `-- name: GetPurgeableGroupIDs :many
+SELECT g.id
+FROM groups g
+WHERE g.deleted_at < $1::TIMESTAMPTZ
+  AND NOT EXISTS (SELECT 1 FROM songs s WHERE s.group_id = g.id AND s.deleted_at IS NULL)
+ORDER BY g.deleted_at
+LIMIT $2
+FOR UPDATE
+`
+
+type GetPurgeableGroupIDsParams struct {
+	Cutoff     pgtype.Timestamptz
+	LimitCount int32
+}
+
+func (q *Queries) GetPurgeableGroupIDs(ctx context.Context, arg GetPurgeableGroupIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getPurgeableGroupIDs, arg.Cutoff, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPurgeableSongIDs = `-- name: GetPurgeableSongIDs :many
+SELECT id
+FROM songs
+WHERE deleted_at < $1::TIMESTAMPTZ
+ORDER BY deleted_at
+LIMIT $2
+FOR UPDATE
+`
+
+type GetPurgeableSongIDsParams struct {
+	Cutoff     pgtype.Timestamptz
+	LimitCount int32
+}
+
+func (q *Queries) GetPurgeableSongIDs(ctx context.Context, arg GetPurgeableSongIDsParams) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, getPurgeableSongIDs, arg.Cutoff, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSmartPlaylist = `-- name: GetSmartPlaylist :one
+SELECT id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+FROM smart_playlists
+WHERE id = $1 AND deleted_at IS NULL LIMIT 1
+`
+
+func (q *Queries) GetSmartPlaylist(ctx context.Context, id pgtype.UUID) (SmartPlaylist, error) {
+	row := q.db.QueryRow(ctx, getSmartPlaylist, id)
+	var i SmartPlaylist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getSmartPlaylistsCount = `-- name: GetSmartPlaylistsCount :one
+SELECT count(*) FROM smart_playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = $1::UUID OR $2::BOOLEAN)
+  AND ($3::UUID IS NULL OR owner_id = $3::UUID)
+  AND ($4::VARCHAR = '' OR visibility = $4::VARCHAR)
+`
+
+type GetSmartPlaylistsCountParams struct {
+	ViewerID      pgtype.UUID
+	ViewerIsAdmin bool
+	OwnerID       pgtype.UUID
+	Visibility    string
+}
+
+func (q *Queries) GetSmartPlaylistsCount(ctx context.Context, arg GetSmartPlaylistsCountParams) (int64, error) {
+	row := q.db.QueryRow(ctx, getSmartPlaylistsCount,
+		arg.ViewerID,
+		arg.ViewerIsAdmin,
+		arg.OwnerID,
+		arg.Visibility,
+	)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getSmartPlaylistsWithPagination = `-- name: GetSmartPlaylistsWithPagination :many
+SELECT id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+FROM smart_playlists
+WHERE deleted_at IS NULL
+  AND (visibility = 'public' OR owner_id = $1::UUID OR $2::BOOLEAN)
+  AND ($3::UUID IS NULL OR owner_id = $3::UUID)
+  AND ($4::VARCHAR = '' OR visibility = $4::VARCHAR)
+ORDER BY created_at DESC
+    LIMIT $5 OFFSET $6
+`
+
+type GetSmartPlaylistsWithPaginationParams struct {
+	ViewerID      pgtype.UUID
+	ViewerIsAdmin bool
+	OwnerID       pgtype.UUID
+	Visibility    string
+	LimitCount    int32
+	OffsetCount   int32
+}
+
+func (q *Queries) GetSmartPlaylistsWithPagination(ctx context.Context, arg GetSmartPlaylistsWithPaginationParams) ([]SmartPlaylist, error) {
+	rows, err := q.db.Query(ctx, getSmartPlaylistsWithPagination,
+		arg.ViewerID,
+		arg.ViewerIsAdmin,
+		arg.OwnerID,
+		arg.Visibility,
+		arg.LimitCount,
+		arg.OffsetCount,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []SmartPlaylist
+	for rows.Next() {
+		var i SmartPlaylist
+		if err := rows.Scan(
+			&i.ID,
+			&i.Name,
+			&i.Description,
+			&i.OwnerID,
+			&i.Visibility,
+			&i.Rules,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSong = `-- name: GetSong :one
+SELECT id, group_id, title, runtime,  lyrics, release_date, link, created_at, updated_at, deleted_at
+FROM songs
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetSong(ctx context.Context, id pgtype.UUID) (Song, error) {
+	row := q.db.QueryRow(ctx, getSong, id)
+	var i Song
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Title,
+		&i.Runtime,
+		&i.Lyrics,
+		&i.ReleaseDate,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const getSongRating = `-- name: GetSongRating :one
+SELECT user_id, song_id, rating, created_at, updated_at
+FROM song_ratings
+WHERE user_id = $1 AND song_id = $2 LIMIT 1
+`
+
+type GetSongRatingParams struct {
+	UserID pgtype.UUID
+	SongID pgtype.UUID
+}
+
+func (q *Queries) GetSongRating(ctx context.Context, arg GetSongRatingParams) (SongRating, error) {
+	row := q.db.QueryRow(ctx, getSongRating, arg.UserID, arg.SongID)
+	var i SongRating
+	err := row.Scan(
+		&i.UserID,
+		&i.SongID,
+		&i.Rating,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getSongTags = `-- name: GetSongTags :many
+
+SELECT tag FROM song_tags
+WHERE song_id = $1
+ORDER BY tag
+`
+
+// Song Tags Table
+func (q *Queries) GetSongTags(ctx context.Context, songID pgtype.UUID) ([]string, error) {
+	rows, err := q.db.Query(ctx, getSongTags, songID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []string
+	for rows.Next() {
+		var tag string
+		if err := rows.Scan(&tag); err != nil {
+			return nil, err
+		}
+		items = append(items, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSongWithGroup = `-- name: GetSongWithGroup :one
+SELECT id, group_id, title, runtime, CASE WHEN $1::BOOLEAN THEN lyrics END AS lyrics,
+       release_date, link, created_at, updated_at, group_name, group_created_at, group_updated_at,
+       play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
+WHERE id = $2 AND deleted_at IS NULL
+`
+
+type GetSongWithGroupParams struct {
+	WithLyrics bool
+	ID         pgtype.UUID
+}
+
+type GetSongWithGroupRow struct {
+	ID                    pgtype.UUID
+	GroupID               pgtype.UUID
+	Title                 string
+	Runtime               int32
+	Lyrics                []byte
+	ReleaseDate           pgtype.Timestamptz
+	Link                  string
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	GroupName             string
+	GroupCreatedAt        pgtype.Timestamptz
+	GroupUpdatedAt        pgtype.Timestamptz
+	PlayCount             int64
+	RatingAverage         float64
+	RatingCount           int32
+	ArtworkWidth          pgtype.Int4
+	ArtworkHeight         pgtype.Int4
+	ArtworkFormat         pgtype.Text
+	ArtworkUpdatedAt      pgtype.Timestamptz
+	GroupArtworkWidth     pgtype.Int4
+	GroupArtworkHeight    pgtype.Int4
+	GroupArtworkFormat    pgtype.Text
+	GroupArtworkUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetSongWithGroup(ctx context.Context, arg GetSongWithGroupParams) (GetSongWithGroupRow, error) {
+	row := q.db.QueryRow(ctx, getSongWithGroup, arg.WithLyrics, arg.ID)
+	var i GetSongWithGroupRow
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Title,
+		&i.Runtime,
+		&i.Lyrics,
+		&i.ReleaseDate,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GroupName,
+		&i.GroupCreatedAt,
+		&i.GroupUpdatedAt,
+		&i.PlayCount,
+		&i.RatingAverage,
+		&i.RatingCount,
+		&i.ArtworkWidth,
+		&i.ArtworkHeight,
+		&i.ArtworkFormat,
+		&i.ArtworkUpdatedAt,
+		&i.GroupArtworkWidth,
+		&i.GroupArtworkHeight,
+		&i.GroupArtworkFormat,
+		&i.GroupArtworkUpdatedAt,
+	)
+	return i, err
+}
+
+const getSongsByGroup = `-- name: GetSongsByGroup :many
+SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
+FROM songs
+WHERE group_id = $1 AND deleted_at IS NULL
+ORDER BY release_date DESC LIMIT $2 OFFSET $3
+`
+
+type GetSongsByGroupParams struct {
+	GroupID pgtype.UUID
+	Limit   int32
+	Offset  int32
+}
+
+func (q *Queries) GetSongsByGroup(ctx context.Context, arg GetSongsByGroupParams) ([]Song, error) {
+	rows, err := q.db.Query(ctx, getSongsByGroup, arg.GroupID, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Song
+	for rows.Next() {
+		var i Song
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Title,
+			&i.Runtime,
+			&i.Lyrics,
+			&i.ReleaseDate,
+			&i.Link,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSongsByIDs = `-- name: GetSongsByIDs :many
+SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at,
+       group_name, group_created_at, group_updated_at, play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
+WHERE id = ANY($1::UUID[]) AND deleted_at IS NULL
+`
+
+type GetSongsByIDsRow struct {
+	ID                    pgtype.UUID
+	GroupID               pgtype.UUID
+	Title                 string
+	Runtime               int32
+	Lyrics                []byte
+	ReleaseDate           pgtype.Timestamptz
+	Link                  string
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	GroupName             string
+	GroupCreatedAt        pgtype.Timestamptz
+	GroupUpdatedAt        pgtype.Timestamptz
+	PlayCount             int64
+	RatingAverage         float64
+	RatingCount           int32
+	ArtworkWidth          pgtype.Int4
+	ArtworkHeight         pgtype.Int4
+	ArtworkFormat         pgtype.Text
+	ArtworkUpdatedAt      pgtype.Timestamptz
+	GroupArtworkWidth     pgtype.Int4
+	GroupArtworkHeight    pgtype.Int4
+	GroupArtworkFormat    pgtype.Text
+	GroupArtworkUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetSongsByIDs(ctx context.Context, songIds []pgtype.UUID) ([]GetSongsByIDsRow, error) {
+	rows, err := q.db.Query(ctx, getSongsByIDs, songIds)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSongsByIDsRow
+	for rows.Next() {
+		var i GetSongsByIDsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Title,
+			&i.Runtime,
+			&i.Lyrics,
+			&i.ReleaseDate,
+			&i.Link,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.GroupName,
+			&i.GroupCreatedAt,
+			&i.GroupUpdatedAt,
+			&i.PlayCount,
+			&i.RatingAverage,
+			&i.RatingCount,
+			&i.ArtworkWidth,
+			&i.ArtworkHeight,
+			&i.ArtworkFormat,
+			&i.ArtworkUpdatedAt,
+			&i.GroupArtworkWidth,
+			&i.GroupArtworkHeight,
+			&i.GroupArtworkFormat,
+			&i.GroupArtworkUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSongsCount = `-- name: GetSongsCount :one
+SELECT count(*) FROM songs
+WHERE deleted_at IS NULL
+`
+
+func (q *Queries) GetSongsCount(ctx context.Context) (int64, error) {
+	row := q.db.QueryRow(ctx, getSongsCount)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getSongsForDuplicateScan = `-- name: GetSongsForDuplicateScan :many
+
+SELECT s.id, s.group_id, g.name AS group_name, s.title, s.runtime
+FROM songs s
+         JOIN groups g ON g.id = s.group_id
+WHERE s.deleted_at IS NULL
+ORDER BY s.created_at
+`
+
+type GetSongsForDuplicateScanRow struct {
+	ID        pgtype.UUID
+	GroupID   pgtype.UUID
+	GroupName string
+	Title     string
+	Runtime   int32
+}
+
+// Song Merges
+func (q *Queries) GetSongsForDuplicateScan(ctx context.Context) ([]GetSongsForDuplicateScanRow, error) {
+	rows, err := q.db.Query(ctx, getSongsForDuplicateScan)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSongsForDuplicateScanRow
+	for rows.Next() {
+		var i GetSongsForDuplicateScanRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.GroupName,
+			&i.Title,
+			&i.Runtime,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSongsLyricsAfter = `-- name: GetSongsLyricsAfter :many
+
+SELECT id, lyrics
+FROM songs
+WHERE deleted_at IS NULL AND id > $1
+ORDER BY id
+    LIMIT $2
+`
+
+type GetSongsLyricsAfterParams struct {
+	AfterID    pgtype.UUID
+	LimitCount int32
+}
+
+type GetSongsLyricsAfterRow struct {
+	ID     pgtype.UUID
+	Lyrics []byte
+}
+
+// Song Similarity
+func (q *Queries) GetSongsLyricsAfter(ctx context.Context, arg GetSongsLyricsAfterParams) ([]GetSongsLyricsAfterRow, error) {
+	rows, err := q.db.Query(ctx, getSongsLyricsAfter, arg.AfterID, arg.LimitCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSongsLyricsAfterRow
+	for rows.Next() {
+		var i GetSongsLyricsAfterRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Lyrics,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getSongsWithPagination = `-- name: GetSongsWithPagination :many
+SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at,
+       group_name, group_created_at, group_updated_at, play_count, rating_average, rating_count,
+       artwork_width, artwork_height, artwork_format, artwork_updated_at,
+       group_artwork_width, group_artwork_height, group_artwork_format, group_artwork_updated_at
+FROM song_listings
+WHERE deleted_at IS NULL
+ORDER BY created_at DESC LIMIT $1 OFFSET $2
+`
+
+type GetSongsWithPaginationParams struct {
+	Limit  int32
+	Offset int32
+}
+
+type GetSongsWithPaginationRow struct {
+	ID                    pgtype.UUID
+	GroupID               pgtype.UUID
+	Title                 string
+	Runtime               int32
+	Lyrics                []byte
+	ReleaseDate           pgtype.Timestamptz
+	Link                  string
+	CreatedAt             pgtype.Timestamptz
+	UpdatedAt             pgtype.Timestamptz
+	GroupName             string
+	GroupCreatedAt        pgtype.Timestamptz
+	GroupUpdatedAt        pgtype.Timestamptz
+	PlayCount             int64
+	RatingAverage         float64
+	RatingCount           int32
+	ArtworkWidth          pgtype.Int4
+	ArtworkHeight         pgtype.Int4
+	ArtworkFormat         pgtype.Text
+	ArtworkUpdatedAt      pgtype.Timestamptz
+	GroupArtworkWidth     pgtype.Int4
+	GroupArtworkHeight    pgtype.Int4
+	GroupArtworkFormat    pgtype.Text
+	GroupArtworkUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetSongsWithPagination(ctx context.Context, arg GetSongsWithPaginationParams) ([]GetSongsWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getSongsWithPagination, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetSongsWithPaginationRow
+	for rows.Next() {
+		var i GetSongsWithPaginationRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.Title,
+			&i.Runtime,
+			&i.Lyrics,
+			&i.ReleaseDate,
+			&i.Link,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.GroupName,
+			&i.GroupCreatedAt,
+			&i.GroupUpdatedAt,
+			&i.PlayCount,
+			&i.RatingAverage,
+			&i.RatingCount,
+			&i.ArtworkWidth,
+			&i.ArtworkHeight,
+			&i.ArtworkFormat,
+			&i.ArtworkUpdatedAt,
+			&i.GroupArtworkWidth,
+			&i.GroupArtworkHeight,
+			&i.GroupArtworkFormat,
+			&i.GroupArtworkUpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTrashCount = `-- name: GetTrashCount :one
+SELECT (CASE WHEN $1::VARCHAR IN ('', 'group') THEN (SELECT count(*) FROM groups WHERE deleted_at IS NOT NULL) ELSE 0 END
+      + CASE WHEN $1::VARCHAR IN ('', 'song') THEN (SELECT count(*) FROM songs WHERE deleted_at IS NOT NULL) ELSE 0 END)::BIGINT AS count
+`
+
+func (q *Queries) GetTrashCount(ctx context.Context, entityType string) (int64, error) {
+	row := q.db.QueryRow(ctx, getTrashCount, entityType)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getTrashWithPagination = `-- name: GetTrashWithPagination :many
+
+SELECT t.entity_type, t.id, t.name, t.group_id, t.deleted_with_group, t.deleted_at
+FROM (SELECT 'group'::VARCHAR AS entity_type, g.id, g.name, NULL::UUID AS group_id, FALSE AS deleted_with_group, g.deleted_at
+      FROM groups g
+      WHERE g.deleted_at IS NOT NULL
+      UNION ALL
+      SELECT 'song'::VARCHAR, s.id, s.title, s.group_id,
+             EXISTS (SELECT 1 FROM group_deleted_songs d WHERE d.song_id = s.id), s.deleted_at
+      FROM songs s
+      WHERE s.deleted_at IS NOT NULL) t
+WHERE $1::VARCHAR = '' OR t.entity_type = $1::VARCHAR
+ORDER BY t.deleted_at DESC, t.id
+LIMIT $2 OFFSET $3
+`
+
+type GetTrashWithPaginationParams struct {
+	EntityType  string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type GetTrashWithPaginationRow struct {
+	EntityType       string
+	ID               pgtype.UUID
+	Name             string
+	GroupID          pgtype.UUID
+	DeletedWithGroup bool
+	DeletedAt        pgtype.Timestamptz
+}
+
+// Trash
+func (q *Queries) GetTrashWithPagination(ctx context.Context, arg GetTrashWithPaginationParams) ([]GetTrashWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getTrashWithPagination, arg.EntityType, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTrashWithPaginationRow
+	for rows.Next() {
+		var i GetTrashWithPaginationRow
+		if err := rows.Scan(
+			&i.EntityType,
+			&i.ID,
+			&i.Name,
+			&i.GroupID,
+			&i.DeletedWithGroup,
+			&i.DeletedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUser = `-- name: GetUser :one
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE id = $1 LIMIT 1
+`
+
+func (q *Queries) GetUser(ctx context.Context, id pgtype.UUID) (User, error) {
+	row := q.db.QueryRow(ctx, getUser, id)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserByUsername = `-- name: GetUserByUsername :one
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE username = $1 LIMIT 1
+`
+
+func (q *Queries) GetUserByUsername(ctx context.Context, username string) (User, error) {
+	row := q.db.QueryRow(ctx, getUserByUsername, username)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const getUserRatingsCount = `-- name: GetUserRatingsCount :one
+SELECT count(*)
+FROM song_ratings r
+         JOIN songs s ON s.id = r.song_id
+WHERE r.user_id = $1 AND s.deleted_at IS NULL
+`
+
+func (q *Queries) GetUserRatingsCount(ctx context.Context, userID pgtype.UUID) (int64, error) {
+	row := q.db.QueryRow(ctx, getUserRatingsCount, userID)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUserRatingsWithPagination = `-- name: GetUserRatingsWithPagination :many
+SELECT r.song_id,
+       s.title,
+       g.name AS group_name,
+       r.rating,
+       r.updated_at
+FROM song_ratings r
+         JOIN songs s ON s.id = r.song_id
+         JOIN groups g ON g.id = s.group_id
+WHERE r.user_id = $1 AND s.deleted_at IS NULL
+ORDER BY r.updated_at DESC
+    LIMIT $2 OFFSET $3
+`
+
+type GetUserRatingsWithPaginationParams struct {
+	UserID      pgtype.UUID
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type GetUserRatingsWithPaginationRow struct {
+	SongID    pgtype.UUID
+	Title     string
+	GroupName string
+	Rating    int32
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) GetUserRatingsWithPagination(ctx context.Context, arg GetUserRatingsWithPaginationParams) ([]GetUserRatingsWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, getUserRatingsWithPagination, arg.UserID, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetUserRatingsWithPaginationRow
+	for rows.Next() {
+		var i GetUserRatingsWithPaginationRow
+		if err := rows.Scan(
+			&i.SongID,
+			&i.Title,
+			&i.GroupName,
+			&i.Rating,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUsersCount = `-- name: GetUsersCount :one
+SELECT count(*) FROM users
+WHERE ($1::VARCHAR = '' OR role = $1::VARCHAR)
+`
+
+func (q *Queries) GetUsersCount(ctx context.Context, role string) (int64, error) {
+	row := q.db.QueryRow(ctx, getUsersCount, role)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const getUsersWithPagination = `-- name: GetUsersWithPagination :many
+SELECT id, username, password_hash, created_at, updated_at, role
+FROM users
+WHERE ($1::VARCHAR = '' OR role = $1::VARCHAR)
+ORDER BY username
+    LIMIT $2 OFFSET $3
+`
+
+type GetUsersWithPaginationParams struct {
+	Role        string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+func (q *Queries) GetUsersWithPagination(ctx context.Context, arg GetUsersWithPaginationParams) ([]User, error) {
+	rows, err := q.db.Query(ctx, getUsersWithPagination, arg.Role, arg.LimitCount, arg.OffsetCount)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []User
+	for rows.Next() {
+		var i User
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.PasswordHash,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+			&i.Role,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockAdmins = `-- name: LockAdmins :many
+SELECT id FROM users
+WHERE role = 'admin'
+ORDER BY id
+FOR UPDATE
+`
+
+func (q *Queries) LockAdmins(ctx context.Context) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, lockAdmins)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []pgtype.UUID
+	for rows.Next() {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		items = append(items, id)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const lockSongPlayStats = `-- name: LockSongPlayStats :one
+INSERT INTO song_play_stats (song_id)
+VALUES ($1)
+ON CONFLICT (song_id) DO UPDATE
+SET song_id = EXCLUDED.song_id
+RETURNING song_id, play_count, updated_at
+`
+
+func (q *Queries) LockSongPlayStats(ctx context.Context, songID pgtype.UUID) (SongPlayStat, error) {
+	row := q.db.QueryRow(ctx, lockSongPlayStats, songID)
+	var i SongPlayStat
+	err := row.Scan(
+		&i.SongID,
+		&i.PlayCount,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const lockSongRatingStats = `-- name: LockSongRatingStats :one
+
+INSERT INTO song_rating_stats (song_id)
+VALUES ($1)
+ON CONFLICT (song_id) DO UPDATE
+SET song_id = EXCLUDED.song_id
+RETURNING song_id, rating_count, rating_sum, rating_average, updated_at
+`
+
+// Song Ratings Table
+func (q *Queries) LockSongRatingStats(ctx context.Context, songID pgtype.UUID) (SongRatingStat, error) {
+	row := q.db.QueryRow(ctx, lockSongRatingStats, songID)
+	var i SongRatingStat
+	err := row.Scan(
+		&i.SongID,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.RatingAverage,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const moveGroupSongs = `-- name: MoveGroupSongs :execrows
+UPDATE songs
+SET group_id = $1
+WHERE group_id = ANY($2::UUID[]) AND deleted_at IS NULL
+`
+
+type MoveGroupSongsParams struct {
+	ToGroupID    pgtype.UUID
+	FromGroupIds []pgtype.UUID
+}
+
+func (q *Queries) MoveGroupSongs(ctx context.Context, arg MoveGroupSongsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveGroupSongs, arg.ToGroupID, arg.FromGroupIds)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveSongPlayEvents = `-- name: MoveSongPlayEvents :execrows
+UPDATE play_events
+SET song_id = $1
+WHERE song_id = $2
+`
+
+type MoveSongPlayEventsParams struct {
+	ToSongID   pgtype.UUID
+	FromSongID pgtype.UUID
+}
+
+func (q *Queries) MoveSongPlayEvents(ctx context.Context, arg MoveSongPlayEventsParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveSongPlayEvents, arg.ToSongID, arg.FromSongID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const moveSongPlaylistEntries = `-- name: MoveSongPlaylistEntries :execrows
+UPDATE playlist_entries
+SET song_id = $1
+WHERE song_id = $2
+`
+
+type MoveSongPlaylistEntriesParams struct {
+	ToSongID   pgtype.UUID
+	FromSongID pgtype.UUID
+}
+
+func (q *Queries) MoveSongPlaylistEntries(ctx context.Context, arg MoveSongPlaylistEntriesParams) (int64, error) {
+	result, err := q.db.Exec(ctx, moveSongPlaylistEntries, arg.ToSongID, arg.FromSongID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const patchGroup = `-- name: PatchGroup :one
+UPDATE groups
+SET name = COALESCE($1, name),
+    updated_at = NOW()
+WHERE id = $2 AND deleted_at IS NULL
+  AND ($3::TIMESTAMPTZ IS NULL OR updated_at = $3)
+RETURNING id, name, created_at, updated_at, deleted_at
+`
+
+type PatchGroupParams struct {
+	Name        pgtype.Text
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) PatchGroup(ctx context.Context, arg PatchGroupParams) (Group, error) {
+	row := q.db.QueryRow(ctx, patchGroup, arg.Name, arg.ID, arg.IfUpdatedAt)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const patchSong = `-- name: PatchSong :one
+UPDATE songs
+SET group_id = COALESCE($1, group_id),
+    title = COALESCE($2, title),
+    runtime = COALESCE($3, runtime),
+    lyrics = COALESCE($4, lyrics),
+    release_date = COALESCE($5, release_date),
+    link = COALESCE($6, link),
+    updated_at = NOW()
+WHERE id = $7 AND deleted_at IS NULL
+  AND ($8::TIMESTAMPTZ IS NULL OR updated_at = $8)
+RETURNING id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
+`
+
+type PatchSongParams struct {
+	GroupID     pgtype.UUID
+	Title       pgtype.Text
+	Runtime     pgtype.Int4
+	Lyrics      []byte
+	ReleaseDate pgtype.Timestamptz
+	Link        pgtype.Text
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) PatchSong(ctx context.Context, arg PatchSongParams) (Song, error) {
+	row := q.db.QueryRow(ctx, patchSong,
+		arg.GroupID,
+		arg.Title,
+		arg.Runtime,
+		arg.Lyrics,
+		arg.ReleaseDate,
+		arg.Link,
+		arg.ID,
+		arg.IfUpdatedAt,
+	)
+	var i Song
+	err := row.Scan(
+		&i.ID,
+		&i.GroupID,
+		&i.Title,
+		&i.Runtime,
+		&i.Lyrics,
+		&i.ReleaseDate,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const purgeGroups = `-- name: PurgeGroups :execrows
+DELETE FROM groups
+WHERE id = ANY($1::UUID[]) AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeGroups(ctx context.Context, ids []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeGroups, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const purgeSongs = `-- name: PurgeSongs :execrows
+DELETE FROM songs
+WHERE id = ANY($1::UUID[]) AND deleted_at IS NOT NULL
+`
+
+func (q *Queries) PurgeSongs(ctx context.Context, ids []pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, purgeSongs, ids)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected(), nil
+}
+
+const recountSongPlayStats = `-- name: RecountSongPlayStats :one
+UPDATE song_play_stats st
+SET play_count = (SELECT count(*) FROM play_events WHERE song_id = $1),
+    updated_at = NOW()
+WHERE st.song_id = $1
+RETURNING st.song_id, st.play_count, st.updated_at
+`
+
+func (q *Queries) RecountSongPlayStats(ctx context.Context, songID pgtype.UUID) (SongPlayStat, error) {
+	row := q.db.QueryRow(ctx, recountSongPlayStats, songID)
+	var i SongPlayStat
+	err := row.Scan(
+		&i.SongID,
+		&i.PlayCount,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const recountSongRatingStats = `-- name: RecountSongRatingStats :one
+UPDATE song_rating_stats st
+SET rating_count = r.rating_count,
+    rating_sum = r.rating_sum,
+    rating_average = r.rating_average,
+    updated_at = NOW()
+FROM (SELECT count(*)::INT                   AS rating_count,
+             COALESCE(sum(rating), 0)::INT   AS rating_sum,
+             COALESCE(avg(rating), 0)::FLOAT8 AS rating_average
+      FROM song_ratings
+      WHERE song_id = $1) r
+WHERE st.song_id = $1
+RETURNING st.song_id, st.rating_count, st.rating_sum, st.rating_average, st.updated_at
+`
+
+func (q *Queries) RecountSongRatingStats(ctx context.Context, songID pgtype.UUID) (SongRatingStat, error) {
+	row := q.db.QueryRow(ctx, recountSongRatingStats, songID)
+	var i SongRatingStat
+	err := row.Scan(
+		&i.SongID,
+		&i.RatingCount,
+		&i.RatingSum,
+		&i.RatingAverage,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const removeFavorite = `-- name: RemoveFavorite :execresult
+DELETE FROM favorites
+WHERE user_id = $1 AND entity_type = $2 AND entity_id = $3
+`
+
+type RemoveFavoriteParams struct {
+	UserID     pgtype.UUID
+	EntityType string
+	EntityID   pgtype.UUID
+}
+
+func (q *Queries) RemoveFavorite(ctx context.Context, arg RemoveFavoriteParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, removeFavorite, arg.UserID, arg.EntityType, arg.EntityID)
+}
+
+const renumberPlaylistEntries = `-- name: RenumberPlaylistEntries :exec
+UPDATE playlist_entries e
+SET position = r.position
+FROM (SELECT id, row_number() OVER (PARTITION BY playlist_id ORDER BY position)::INT AS position
+      FROM playlist_entries
+      WHERE playlist_id = ANY($1::UUID[])) r
+WHERE e.id = r.id AND e.position <> r.position
+`
+
+func (q *Queries) RenumberPlaylistEntries(ctx context.Context, playlistIds []pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, renumberPlaylistEntries, playlistIds)
+	return err
+}
+
+const replaceSongTags = `-- name: ReplaceSongTags :exec
+WITH removed AS (
+    DELETE FROM song_tags
+    WHERE song_tags.song_id = $1 AND tag <> ALL($2::VARCHAR[])
+)
+INSERT INTO song_tags (song_id, tag)
+SELECT $1, unnest($2::VARCHAR[])
+ON CONFLICT DO NOTHING
+`
+
+type ReplaceSongTagsParams struct {
+	SongID pgtype.UUID
+	Tags   []string
+}
+
+func (q *Queries) ReplaceSongTags(ctx context.Context, arg ReplaceSongTagsParams) error {
+	_, err := q.db.Exec(ctx, replaceSongTags, arg.SongID, arg.Tags)
+	return err
+}
+
+const restoreGroup = `-- name: RestoreGroup :one
+UPDATE groups
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, name, created_at, updated_at, deleted_at
+`
+
+func (q *Queries) RestoreGroup(ctx context.Context, id pgtype.UUID) (Group, error) {
+	row := q.db.QueryRow(ctx, restoreGroup, id)
+	var i Group
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
 }
 
-func (q *Queries) GetGroupsWithPagination(ctx context.Context, arg GetGroupsWithPaginationParams) ([]GetGroupsWithPaginationRow, error) {
-	rows, err := q.db.Query(ctx, getGroupsWithPagination, arg.Limit, arg.Offset)
+const restoreGroupSongs = `-- name: RestoreGroupSongs :many
+WITH restored AS (
+    DELETE FROM group_deleted_songs
+    WHERE group_id = $1
+    RETURNING song_id
+)
+UPDATE songs s
+SET deleted_at = NULL
+FROM restored r
+WHERE s.id = r.song_id AND s.group_id = $1 AND s.deleted_at IS NOT NULL
+RETURNING s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at, s.updated_at, s.deleted_at
+`
+
+func (q *Queries) RestoreGroupSongs(ctx context.Context, groupID pgtype.UUID) ([]Song, error) {
+	rows, err := q.db.Query(ctx, restoreGroupSongs, groupID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetGroupsWithPaginationRow
+	var items []Song
 	for rows.Next() {
-		var i GetGroupsWithPaginationRow
+		var i Song
 		if err := rows.Scan(
 			&i.ID,
-			&i.Name,
+			&i.GroupID,
+			&i.Title,
+			&i.Runtime,
+			&i.Lyrics,
+			&i.ReleaseDate,
+			&i.Link,
 			&i.CreatedAt,
 			&i.UpdatedAt,
+			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -169,14 +2856,19 @@ func (q *Queries) GetGroupsWithPagination(ctx context.Context, arg GetGroupsWith
 	return items, nil
 }
 
-const getSong = `-- name: GetSong :one
-SELECT id, group_id, title, runtime,  lyrics, release_date, link, created_at, updated_at, deleted_at
-FROM songs
-WHERE id = $1 LIMIT 1
+const restoreSong = `-- name: RestoreSong :one
+WITH unlinked AS (
+    DELETE FROM group_deleted_songs
+    WHERE song_id = $1
+)
+UPDATE songs
+SET deleted_at = NULL
+WHERE id = $1 AND deleted_at IS NOT NULL
+RETURNING id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
 `
 
-func (q *Queries) GetSong(ctx context.Context, id pgtype.UUID) (Song, error) {
-	row := q.db.QueryRow(ctx, getSong, id)
+func (q *Queries) RestoreSong(ctx context.Context, id pgtype.UUID) (Song, error) {
+	row := q.db.QueryRow(ctx, restoreSong, id)
 	var i Song
 	err := row.Scan(
 		&i.ID,
@@ -193,39 +2885,81 @@ func (q *Queries) GetSong(ctx context.Context, id pgtype.UUID) (Song, error) {
 	return i, err
 }
 
-const getSongsByGroup = `-- name: GetSongsByGroup :many
-SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
-FROM songs
-WHERE group_id = $1 AND deleted_at IS NULL
-ORDER BY release_date DESC LIMIT $2 OFFSET $3
+const revokeApiKey = `-- name: RevokeApiKey :execresult
+UPDATE api_keys
+SET revoked_at = NOW()
+WHERE id = $1 AND user_id = $2 AND revoked_at IS NULL
 `
 
-type GetSongsByGroupParams struct {
-	GroupID pgtype.UUID
-	Limit   int32
-	Offset  int32
+type RevokeApiKeyParams struct {
+	ID     pgtype.UUID
+	UserID pgtype.UUID
 }
 
-func (q *Queries) GetSongsByGroup(ctx context.Context, arg GetSongsByGroupParams) ([]Song, error) {
-	rows, err := q.db.Query(ctx, getSongsByGroup, arg.GroupID, arg.Limit, arg.Offset)
+func (q *Queries) RevokeApiKey(ctx context.Context, arg RevokeApiKeyParams) (pgconn.CommandTag, error) {
+	return q.db.Exec(ctx, revokeApiKey, arg.ID, arg.UserID)
+}
+
+const searchGroupsCount = `-- name: SearchGroupsCount :one
+SELECT count(*)
+FROM groups g
+WHERE g.deleted_at IS NULL
+  AND ($1::VARCHAR = ''
+       OR LOWER(g.name) LIKE '%' || LOWER($1::VARCHAR) || '%'
+       OR EXISTS (SELECT 1
+                  FROM group_aliases a
+                  WHERE a.group_id = g.id
+                    AND LOWER(a.name) LIKE '%' || LOWER($1::VARCHAR) || '%'))
+`
+
+func (q *Queries) SearchGroupsCount(ctx context.Context, name string) (int64, error) {
+	row := q.db.QueryRow(ctx, searchGroupsCount, name)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const searchGroupsWithPagination = `-- name: SearchGroupsWithPagination :many
+SELECT g.id, g.name, g.created_at, g.updated_at
+FROM groups g
+WHERE g.deleted_at IS NULL
+  AND ($1::VARCHAR = ''
+       OR LOWER(g.name) LIKE '%' || LOWER($1::VARCHAR) || '%'
+       OR EXISTS (SELECT 1
+                  FROM group_aliases a
+                  WHERE a.group_id = g.id
+                    AND LOWER(a.name) LIKE '%' || LOWER($1::VARCHAR) || '%'))
+ORDER BY g.created_at DESC
+    LIMIT $2 OFFSET $3
+`
+
+type SearchGroupsWithPaginationParams struct {
+	Name        string
+	LimitCount  int32
+	OffsetCount int32
+}
+
+type SearchGroupsWithPaginationRow struct {
+	ID        pgtype.UUID
+	Name      string
+	CreatedAt pgtype.Timestamptz
+	UpdatedAt pgtype.Timestamptz
+}
+
+func (q *Queries) SearchGroupsWithPagination(ctx context.Context, arg SearchGroupsWithPaginationParams) ([]SearchGroupsWithPaginationRow, error) {
+	rows, err := q.db.Query(ctx, searchGroupsWithPagination, arg.Name, arg.LimitCount, arg.OffsetCount)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Song
+	var items []SearchGroupsWithPaginationRow
 	for rows.Next() {
-		var i Song
+		var i SearchGroupsWithPaginationRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.GroupID,
-			&i.Title,
-			&i.Runtime,
-			&i.Lyrics,
-			&i.ReleaseDate,
-			&i.Link,
+			&i.Name,
 			&i.CreatedAt,
 			&i.UpdatedAt,
-			&i.DeletedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -237,150 +2971,150 @@ func (q *Queries) GetSongsByGroup(ctx context.Context, arg GetSongsByGroupParams
 	return items, nil
 }
 
-const getSongsCount = `-- name: GetSongsCount :one
-SELECT count(*) FROM songs
-WHERE deleted_at IS NULL
+const setPlaylistEntryPosition = `-- name: SetPlaylistEntryPosition :exec
+UPDATE playlist_entries
+SET position = $2
+WHERE id = $1
 `
 
-func (q *Queries) GetSongsCount(ctx context.Context) (int64, error) {
-	row := q.db.QueryRow(ctx, getSongsCount)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+type SetPlaylistEntryPositionParams struct {
+	ID       pgtype.UUID
+	Position int32
 }
 
-const getSongsCountWithFilters = `-- name: GetSongsCountWithFilters :one
-SELECT count(*)
-FROM songs s
-JOIN groups g ON s.group_id = g.id
-WHERE s.deleted_at IS NULL
-  AND (LOWER(g.name) LIKE LOWER('%' || NULLIF($1, '')::VARCHAR || '%') OR $1 = '')
-  AND (LOWER(s.title) LIKE LOWER('%' || NULLIF($2, '')::VARCHAR || '%') OR $2 = '')
+func (q *Queries) SetPlaylistEntryPosition(ctx context.Context, arg SetPlaylistEntryPositionParams) error {
+	_, err := q.db.Exec(ctx, setPlaylistEntryPosition, arg.ID, arg.Position)
+	return err
+}
+
+const shiftPlaylistEntries = `-- name: ShiftPlaylistEntries :exec
+UPDATE playlist_entries
+SET position = position + $1::INT
+WHERE playlist_id = $2
+  AND position BETWEEN $3::INT AND $4::INT
 `
 
-type GetSongsCountWithFiltersParams struct {
-	GroupName interface{}
-	SongTitle interface{}
+type ShiftPlaylistEntriesParams struct {
+	Delta        int32
+	PlaylistID   pgtype.UUID
+	FromPosition int32
+	ToPosition   int32
 }
 
-func (q *Queries) GetSongsCountWithFilters(ctx context.Context, arg GetSongsCountWithFiltersParams) (int64, error) {
-	row := q.db.QueryRow(ctx, getSongsCountWithFilters, arg.GroupName, arg.SongTitle)
-	var count int64
-	err := row.Scan(&count)
-	return count, err
+func (q *Queries) ShiftPlaylistEntries(ctx context.Context, arg ShiftPlaylistEntriesParams) error {
+	_, err := q.db.Exec(ctx, shiftPlaylistEntries,
+		arg.Delta,
+		arg.PlaylistID,
+		arg.FromPosition,
+		arg.ToPosition,
+	)
+	return err
 }
 
-const getSongsWithFilters = `-- name: GetSongsWithFilters :many
-SELECT s.id, s.group_id, s.title, s.runtime, s.lyrics, s.release_date, s.link, s.created_at,  s.updated_at
-FROM songs s
-         JOIN groups g ON s.group_id = g.id
-WHERE s.deleted_at IS NULL
-  AND (LOWER(g.name) LIKE LOWER('%' || NULLIF($3, '')::VARCHAR || '%') OR $3 = '')
-  AND (LOWER(s.title) LIKE LOWER('%' || NULLIF($4, '')::VARCHAR || '%') OR $4 = '')
-ORDER BY s.created_at DESC
-    LIMIT $1 OFFSET $2
+const startIdempotentRequest = `-- name: StartIdempotentRequest :one
+
+INSERT INTO idempotency_keys (user_id, key, request_hash, expires_at)
+VALUES ($1, $2, $3, $4)
+ON CONFLICT (user_id, key) DO UPDATE
+    SET request_hash = EXCLUDED.request_hash,
+        status_code = NULL,
+        content_type = '',
+        response_body = NULL,
+        created_at = NOW(),
+        expires_at = EXCLUDED.expires_at
+    WHERE idempotency_keys.expires_at <= NOW()
+       OR (idempotency_keys.status_code IS NULL AND idempotency_keys.created_at <= $5)
+RETURNING user_id, key, request_hash, status_code, content_type, response_body, created_at, expires_at
 `
 
-type GetSongsWithFiltersParams struct {
-	Limit   int32
-	Offset  int32
-	Column3 interface{}
-	Column4 interface{}
+type StartIdempotentRequestParams struct {
+	UserID      pgtype.UUID
+	Key         string
+	RequestHash []byte
+	ExpiresAt   pgtype.Timestamptz
+	StaleBefore pgtype.Timestamptz
 }
 
-type GetSongsWithFiltersRow struct {
-	ID          pgtype.UUID
-	GroupID     pgtype.UUID
-	Title       string
-	Runtime     int32
-	Lyrics      []byte
-	ReleaseDate pgtype.Timestamptz
-	Link        string
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
+// Idempotency Keys
+func (q *Queries) StartIdempotentRequest(ctx context.Context, arg StartIdempotentRequestParams) (IdempotencyKey, error) {
+	row := q.db.QueryRow(ctx, startIdempotentRequest,
+		arg.UserID,
+		arg.Key,
+		arg.RequestHash,
+		arg.ExpiresAt,
+		arg.StaleBefore,
+	)
+	var i IdempotencyKey
+	err := row.Scan(
+		&i.UserID,
+		&i.Key,
+		&i.RequestHash,
+		&i.StatusCode,
+		&i.ContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	return i, err
 }
 
-func (q *Queries) GetSongsWithFilters(ctx context.Context, arg GetSongsWithFiltersParams) ([]GetSongsWithFiltersRow, error) {
-	rows, err := q.db.Query(ctx, getSongsWithFilters,
-		arg.Limit,
-		arg.Offset,
-		arg.Column3,
-		arg.Column4,
-	)
+const touchApiKey = `-- name: TouchApiKey :exec
+UPDATE api_keys
+SET last_used_at = NOW()
+WHERE id = $1
+`
+
+func (q *Queries) TouchApiKey(ctx context.Context, id pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, touchApiKey, id)
+	return err
+}
+
+const touchPlaylist = `-- name: TouchPlaylist :execrows
+UPDATE playlists
+SET updated_at = NOW()
+WHERE id = $1 AND deleted_at IS NULL
+`
+
+func (q *Queries) TouchPlaylist(ctx context.Context, id pgtype.UUID) (int64, error) {
+	result, err := q.db.Exec(ctx, touchPlaylist, id)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	var items []GetSongsWithFiltersRow
-	for rows.Next() {
-		var i GetSongsWithFiltersRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.GroupID,
-			&i.Title,
-			&i.Runtime,
-			&i.Lyrics,
-			&i.ReleaseDate,
-			&i.Link,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
-	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected(), nil
 }
 
-const getSongsWithPagination = `-- name: GetSongsWithPagination :many
-SELECT id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at FROM songs
+const touchPlaylistsWithSong = `-- name: TouchPlaylistsWithSong :exec
+UPDATE playlists
+SET updated_at = NOW()
 WHERE deleted_at IS NULL
-ORDER BY created_at DESC LIMIT $1 OFFSET $2
+  AND id IN (SELECT playlist_id FROM playlist_entries WHERE song_id = $1)
 `
 
-type GetSongsWithPaginationParams struct {
-	Limit  int32
-	Offset int32
+func (q *Queries) TouchPlaylistsWithSong(ctx context.Context, songID pgtype.UUID) error {
+	_, err := q.db.Exec(ctx, touchPlaylistsWithSong, songID)
+	return err
 }
 
-type GetSongsWithPaginationRow struct {
-	ID          pgtype.UUID
-	GroupID     pgtype.UUID
-	Title       string
-	Runtime     int32
-	Lyrics      []byte
-	ReleaseDate pgtype.Timestamptz
-	Link        string
-	CreatedAt   pgtype.Timestamptz
-	UpdatedAt   pgtype.Timestamptz
-}
+const touchSongsPlaylists = `-- name: TouchSongsPlaylists :many
+UPDATE playlists
+SET updated_at = NOW()
+WHERE id IN (SELECT playlist_id FROM playlist_entries WHERE song_id = ANY($1::UUID[]))
+RETURNING id
+`
 
-func (q *Queries) GetSongsWithPagination(ctx context.Context, arg GetSongsWithPaginationParams) ([]GetSongsWithPaginationRow, error) {
-	rows, err := q.db.Query(ctx, getSongsWithPagination, arg.Limit, arg.Offset)
+func (q *Queries) TouchSongsPlaylists(ctx context.Context, songIds []pgtype.UUID) ([]pgtype.UUID, error) {
+	rows, err := q.db.Query(ctx, touchSongsPlaylists, songIds)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetSongsWithPaginationRow
+	var items []pgtype.UUID
 	for rows.Next() {
-		var i GetSongsWithPaginationRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.GroupID,
-			&i.Title,
-			&i.Runtime,
-			&i.Lyrics,
-			&i.ReleaseDate,
-			&i.Link,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
+		var id pgtype.UUID
+		if err := rows.Scan(&id); err != nil {
 			return nil, err
 		}
-		items = append(items, i)
+		items = append(items, id)
 	}
 	if err := rows.Err(); err != nil {
 		return nil, err
@@ -390,18 +3124,21 @@ func (q *Queries) GetSongsWithPagination(ctx context.Context, arg GetSongsWithPa
 
 const updateGroup = `-- name: UpdateGroup :one
 UPDATE groups
-SET name = $2
-WHERE id = $1
+SET name = $1,
+    updated_at = NOW()
+WHERE id = $2 AND deleted_at IS NULL
+  AND ($3::TIMESTAMPTZ IS NULL OR updated_at = $3)
 RETURNING id, name, created_at, updated_at, deleted_at
 `
 
 type UpdateGroupParams struct {
-	ID   pgtype.UUID
-	Name string
+	Name        string
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
 }
 
 func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (Group, error) {
-	row := q.db.QueryRow(ctx, updateGroup, arg.ID, arg.Name)
+	row := q.db.QueryRow(ctx, updateGroup, arg.Name, arg.ID, arg.IfUpdatedAt)
 	var i Group
 	err := row.Scan(
 		&i.ID,
@@ -413,38 +3150,120 @@ func (q *Queries) UpdateGroup(ctx context.Context, arg UpdateGroupParams) (Group
 	return i, err
 }
 
+const updatePlaylist = `-- name: UpdatePlaylist :one
+UPDATE playlists
+SET name = $2,
+    description = $3,
+    visibility = $4
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, description, owner_id, visibility, created_at, updated_at, deleted_at
+`
+
+type UpdatePlaylistParams struct {
+	ID          pgtype.UUID
+	Name        string
+	Description string
+	Visibility  string
+}
+
+func (q *Queries) UpdatePlaylist(ctx context.Context, arg UpdatePlaylistParams) (Playlist, error) {
+	row := q.db.QueryRow(ctx, updatePlaylist,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Visibility,
+	)
+	var i Playlist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
+const updateSmartPlaylist = `-- name: UpdateSmartPlaylist :one
+UPDATE smart_playlists
+SET name = $2,
+    description = $3,
+    visibility = $4,
+    rules = $5
+WHERE id = $1 AND deleted_at IS NULL
+RETURNING id, name, description, owner_id, visibility, rules, created_at, updated_at, deleted_at
+`
+
+type UpdateSmartPlaylistParams struct {
+	ID          pgtype.UUID
+	Name        string
+	Description string
+	Visibility  string
+	Rules       []byte
+}
+
+func (q *Queries) UpdateSmartPlaylist(ctx context.Context, arg UpdateSmartPlaylistParams) (SmartPlaylist, error) {
+	row := q.db.QueryRow(ctx, updateSmartPlaylist,
+		arg.ID,
+		arg.Name,
+		arg.Description,
+		arg.Visibility,
+		arg.Rules,
+	)
+	var i SmartPlaylist
+	err := row.Scan(
+		&i.ID,
+		&i.Name,
+		&i.Description,
+		&i.OwnerID,
+		&i.Visibility,
+		&i.Rules,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.DeletedAt,
+	)
+	return i, err
+}
+
 const updateSong = `-- name: UpdateSong :one
 UPDATE songs
 SET
-    group_id = $2,
-    title = $3,
-    runtime = $4,
-    lyrics = $5,
-    release_date = $6,
-    link = $7
-WHERE id = $1
+    group_id = $1,
+    title = $2,
+    runtime = $3,
+    lyrics = $4,
+    release_date = $5,
+    link = $6,
+    updated_at = NOW()
+WHERE id = $7 AND deleted_at IS NULL
+  AND ($8::TIMESTAMPTZ IS NULL OR updated_at = $8)
 RETURNING id, group_id, title, runtime, lyrics, release_date, link, created_at, updated_at, deleted_at
 `
 
 type UpdateSongParams struct {
-	ID          pgtype.UUID
 	GroupID     pgtype.UUID
 	Title       string
 	Runtime     int32
 	Lyrics      []byte
 	ReleaseDate pgtype.Timestamptz
 	Link        string
+	ID          pgtype.UUID
+	IfUpdatedAt pgtype.Timestamptz
 }
 
 func (q *Queries) UpdateSong(ctx context.Context, arg UpdateSongParams) (Song, error) {
 	row := q.db.QueryRow(ctx, updateSong,
-		arg.ID,
 		arg.GroupID,
 		arg.Title,
 		arg.Runtime,
 		arg.Lyrics,
 		arg.ReleaseDate,
 		arg.Link,
+		arg.ID,
+		arg.IfUpdatedAt,
 	)
 	var i Song
 	err := row.Scan(
@@ -461,3 +3280,101 @@ func (q *Queries) UpdateSong(ctx context.Context, arg UpdateSongParams) (Song, e
 	)
 	return i, err
 }
+
+const updateUserRole = `-- name: UpdateUserRole :one
+UPDATE users
+SET role = $2,
+    updated_at = NOW()
+WHERE id = $1
+RETURNING id, username, password_hash, created_at, updated_at, role
+`
+
+type UpdateUserRoleParams struct {
+	ID   pgtype.UUID
+	Role string
+}
+
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) (User, error) {
+	row := q.db.QueryRow(ctx, updateUserRole, arg.ID, arg.Role)
+	var i User
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.PasswordHash,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+	)
+	return i, err
+}
+
+const upsertArtwork = `-- name: UpsertArtwork :one
+
+INSERT INTO artworks (entity_type, entity_id, width, height, format)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (entity_type, entity_id) DO UPDATE
+SET width = EXCLUDED.width,
+    height = EXCLUDED.height,
+    format = EXCLUDED.format,
+    updated_at = NOW()
+RETURNING id, entity_type, entity_id, width, height, format, created_at, updated_at
+`
+
+type UpsertArtworkParams struct {
+	EntityType string
+	EntityID   pgtype.UUID
+	Width      int32
+	Height     int32
+	Format     string
+}
+
+// Artworks Table
+func (q *Queries) UpsertArtwork(ctx context.Context, arg UpsertArtworkParams) (Artwork, error) {
+	row := q.db.QueryRow(ctx, upsertArtwork,
+		arg.EntityType,
+		arg.EntityID,
+		arg.Width,
+		arg.Height,
+		arg.Format,
+	)
+	var i Artwork
+	err := row.Scan(
+		&i.ID,
+		&i.EntityType,
+		&i.EntityID,
+		&i.Width,
+		&i.Height,
+		&i.Format,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const upsertSongRating = `-- name: UpsertSongRating :one
+INSERT INTO song_ratings (user_id, song_id, rating)
+VALUES ($1, $2, $3)
+ON CONFLICT (user_id, song_id) DO UPDATE
+SET rating = EXCLUDED.rating,
+    updated_at = NOW()
+RETURNING user_id, song_id, rating, created_at, updated_at
+`
+
+type UpsertSongRatingParams struct {
+	UserID pgtype.UUID
+	SongID pgtype.UUID
+	Rating int32
+}
+
+func (q *Queries) UpsertSongRating(ctx context.Context, arg UpsertSongRatingParams) (SongRating, error) {
+	row := q.db.QueryRow(ctx, upsertSongRating, arg.UserID, arg.SongID, arg.Rating)
+	var i SongRating
+	err := row.Scan(
+		&i.UserID,
+		&i.SongID,
+		&i.Rating,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
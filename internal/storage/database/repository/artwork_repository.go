@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+)
+
+const (
+	ArtworkEntitySong  = "song"
+	ArtworkEntityGroup = "group"
+)
+
+type ArtworkRepositoryInterface interface {
+	UpsertArtwork(ctx context.Context, params ArtworkUpsertParams) (database.Artwork, error)
+	GetArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (database.Artwork, error)
+	GetArtworksByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]database.Artwork, error)
+	DeleteArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (bool, error)
+	DeleteArtworksByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]database.Artwork, error)
+}
+
+type ArtworkUpsertParams struct {
+	EntityType string
+	EntityID   uuid.UUID
+	Width      int32
+	Height     int32
+	Format     string
+}
+
+type ArtworkRepository struct {
+	q *database.Queries
+}
+
+func NewArtworkRepository(db database.DBTX) ArtworkRepositoryInterface {
+	return &ArtworkRepository{
+		q: database.New(db),
+	}
+}
+
+func (r *ArtworkRepository) UpsertArtwork(ctx context.Context, params ArtworkUpsertParams) (database.Artwork, error) {
+	pgEntityID := pgtype.UUID{Bytes: params.EntityID, Valid: true}
+	return r.q.UpsertArtwork(ctx, database.UpsertArtworkParams{
+		EntityType: params.EntityType,
+		EntityID:   pgEntityID,
+		Width:      params.Width,
+		Height:     params.Height,
+		Format:     params.Format,
+	})
+}
+
+func (r *ArtworkRepository) GetArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (database.Artwork, error) {
+	pgEntityID := pgtype.UUID{Bytes: entityID, Valid: true}
+	return r.q.GetArtwork(ctx, database.GetArtworkParams{
+		EntityType: entityType,
+		EntityID:   pgEntityID,
+	})
+}
+
+func (r *ArtworkRepository) GetArtworksByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]database.Artwork, error) {
+	pgEntityIDs := make([]pgtype.UUID, 0, len(entityIDs))
+	for _, id := range entityIDs {
+		pgEntityIDs = append(pgEntityIDs, pgtype.UUID{Bytes: id, Valid: true})
+	}
+
+	return r.q.GetArtworksByEntities(ctx, database.GetArtworksByEntitiesParams{
+		EntityType: entityType,
+		EntityIds:  pgEntityIDs,
+	})
+}
+
+func (r *ArtworkRepository) DeleteArtwork(ctx context.Context, entityType string, entityID uuid.UUID) (bool, error) {
+	pgEntityID := pgtype.UUID{Bytes: entityID, Valid: true}
+	result, err := r.q.DeleteArtwork(ctx, database.DeleteArtworkParams{
+		EntityType: entityType,
+		EntityID:   pgEntityID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+// DeleteArtworksByEntities deletes the artwork records of several entities of one type and returns them
+func (r *ArtworkRepository) DeleteArtworksByEntities(ctx context.Context, entityType string, entityIDs []uuid.UUID) ([]database.Artwork, error) {
+	return r.q.DeleteArtworksByEntities(ctx, database.DeleteArtworksByEntitiesParams{
+		EntityType: entityType,
+		EntityIds:  toPgUUIDs(entityIDs),
+	})
+}
@@ -2,27 +2,62 @@ package repository
 
 import (
 	"context"
+	"fmt"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
 	"music-service/internal/storage/database"
+	"time"
 )
 
 type GroupRepositoryInterface interface {
 	CreateGroup(ctx context.Context, name string) (database.Group, error)
 	GetGroup(ctx context.Context, id uuid.UUID) (database.Group, error)
+	GetGroupByNormalizedName(ctx context.Context, name string) (database.Group, error)
 	GetGroupsCount(ctx context.Context) (int64, error)
 	GetGroupsWithPagination(ctx context.Context, limit, offset int32) ([]database.GetGroupsWithPaginationRow, error)
-	UpdateGroup(ctx context.Context, id uuid.UUID, name string) (database.Group, error)
-	DeleteGroup(ctx context.Context, id uuid.UUID) error
+	UpdateGroup(ctx context.Context, id uuid.UUID, name string, ifUpdatedAt time.Time) (database.Group, error)
+	PatchGroup(ctx context.Context, params GroupPatchParams) (database.Group, error)
+	DeleteGroup(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) (int64, error)
+	DeleteGroups(ctx context.Context, ids []uuid.UUID) (int64, error)
+	CascadeDeleteGroupSongs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error)
+	RestoreGroup(ctx context.Context, id uuid.UUID) (database.Group, error)
+	RestoreGroupSongs(ctx context.Context, groupID uuid.UUID) ([]database.Song, error)
+	GetGroupsSongCounts(ctx context.Context, ids []uuid.UUID) ([]database.GetGroupsSongCountsRow, error)
+	MoveGroupSongs(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error)
+	AddGroupAliases(ctx context.Context, groupID uuid.UUID, aliasType string, names []string) (int64, error)
+	CopyGroupAliases(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error)
+	SearchGroupsWithPagination(ctx context.Context, name string, limit, offset int32) ([]database.GetGroupsWithPaginationRow, error)
+	SearchGroupsCount(ctx context.Context, name string) (int64, error)
+	GetGroupsWithCursor(ctx context.Context, name string, limit int32, cursor string) ([]database.GetGroupsWithPaginationRow, CursorPage, error)
+
+	CreateGroupAlias(ctx context.Context, params GroupAliasCreateParams) (database.GroupAlias, error)
+	GetGroupAliases(ctx context.Context, groupID uuid.UUID) ([]database.GroupAlias, error)
+	DeleteGroupAlias(ctx context.Context, groupID, id uuid.UUID) (bool, error)
+}
+
+// GroupPatchParams holds the fields of a partial update, nil fields are left unchanged
+type GroupPatchParams struct {
+	ID          uuid.UUID
+	Name        *string
+	IfUpdatedAt time.Time // zero to skip the version check
+}
+
+type GroupAliasCreateParams struct {
+	GroupID uuid.UUID
+	Name    string
+	Locale  string
+	Type    string
 }
 
 type GroupRepository struct {
-	q *database.Queries
+	q  *database.Queries
+	db database.DBTX // for queries built at runtime
 }
 
 func NewGroupRepository(db database.DBTX) GroupRepositoryInterface {
 	return &GroupRepository{
-		q: database.New(db),
+		q:  database.New(db),
+		db: db,
 	}
 }
 
@@ -30,9 +65,12 @@ func (r *GroupRepository) CreateGroup(ctx context.Context, name string) (databas
 	return r.q.CreateGroup(ctx, name)
 }
 
-func (r *GroupRepository) DeleteGroup(ctx context.Context, id uuid.UUID) error {
-	pgID := pgtype.UUID{Bytes: id, Valid: true}
-	return r.q.DeleteGroup(ctx, pgID)
+// DeleteGroup soft-deletes a live group if it was last updated at ifUpdatedAt, a zero time skips the check
+func (r *GroupRepository) DeleteGroup(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) (int64, error) {
+	return r.q.DeleteGroup(ctx, database.DeleteGroupParams{
+		ID:          pgtype.UUID{Bytes: id, Valid: true},
+		IfUpdatedAt: toPgVersion(ifUpdatedAt),
+	})
 }
 
 func (r *GroupRepository) GetGroup(ctx context.Context, id uuid.UUID) (database.Group, error) {
@@ -40,6 +78,11 @@ func (r *GroupRepository) GetGroup(ctx context.Context, id uuid.UUID) (database.
 	return r.q.GetGroup(ctx, pgID)
 }
 
+// GetGroupByNormalizedName returns the live group whose name matches ignoring case and surrounding whitespace
+func (r *GroupRepository) GetGroupByNormalizedName(ctx context.Context, name string) (database.Group, error) {
+	return r.q.GetGroupByNormalizedName(ctx, name)
+}
+
 func (r *GroupRepository) GetGroupsCount(ctx context.Context) (int64, error) {
 	return r.q.GetGroupsCount(ctx)
 }
@@ -51,10 +94,201 @@ func (r *GroupRepository) GetGroupsWithPagination(ctx context.Context, limit, of
 	})
 }
 
-func (r *GroupRepository) UpdateGroup(ctx context.Context, id uuid.UUID, name string) (database.Group, error) {
+// UpdateGroup renames a group if it was last updated at ifUpdatedAt, a zero time skips the check
+func (r *GroupRepository) UpdateGroup(ctx context.Context, id uuid.UUID, name string, ifUpdatedAt time.Time) (database.Group, error) {
 	pgID := pgtype.UUID{Bytes: id, Valid: true}
 	return r.q.UpdateGroup(ctx, database.UpdateGroupParams{
-		ID:   pgID,
-		Name: name,
+		ID:          pgID,
+		Name:        name,
+		IfUpdatedAt: toPgVersion(ifUpdatedAt),
 	})
 }
+
+// PatchGroup updates the supplied fields of a live group
+func (r *GroupRepository) PatchGroup(ctx context.Context, params GroupPatchParams) (database.Group, error) {
+	arg := database.PatchGroupParams{
+		ID:          pgtype.UUID{Bytes: params.ID, Valid: true},
+		IfUpdatedAt: toPgVersion(params.IfUpdatedAt),
+	}
+	if params.Name != nil {
+		arg.Name = pgtype.Text{String: *params.Name, Valid: true}
+	}
+	return r.q.PatchGroup(ctx, arg)
+}
+
+func (r *GroupRepository) DeleteGroups(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return r.q.DeleteGroups(ctx, toPgUUIDs(ids))
+}
+
+// CascadeDeleteGroupSongs soft-deletes the live songs of a group, remembering them so restoring the group
+// brings them back, and returns their IDs
+func (r *GroupRepository) CascadeDeleteGroupSongs(ctx context.Context, groupID uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.q.CascadeDeleteGroupSongs(ctx, pgtype.UUID{Bytes: groupID, Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	return fromPgUUIDs(rows), nil
+}
+
+func (r *GroupRepository) RestoreGroup(ctx context.Context, id uuid.UUID) (database.Group, error) {
+	return r.q.RestoreGroup(ctx, pgtype.UUID{Bytes: id, Valid: true})
+}
+
+// RestoreGroupSongs undeletes the songs deleted along with the group, songs deleted on their own stay deleted
+func (r *GroupRepository) RestoreGroupSongs(ctx context.Context, groupID uuid.UUID) ([]database.Song, error) {
+	return r.q.RestoreGroupSongs(ctx, pgtype.UUID{Bytes: groupID, Valid: true})
+}
+
+// GetGroupsSongCounts returns the number of live songs of each group that has any
+func (r *GroupRepository) GetGroupsSongCounts(ctx context.Context, ids []uuid.UUID) ([]database.GetGroupsSongCountsRow, error) {
+	return r.q.GetGroupsSongCounts(ctx, toPgUUIDs(ids))
+}
+
+// MoveGroupSongs re-parents the live songs of the groups to another group
+func (r *GroupRepository) MoveGroupSongs(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error) {
+	return r.q.MoveGroupSongs(ctx, database.MoveGroupSongsParams{
+		ToGroupID:    pgtype.UUID{Bytes: toGroupID, Valid: true},
+		FromGroupIds: toPgUUIDs(fromGroupIDs),
+	})
+}
+
+// AddGroupAliases adds aliases of one type to a group and returns how many were new, existing aliases are matched ignoring case
+func (r *GroupRepository) AddGroupAliases(ctx context.Context, groupID uuid.UUID, aliasType string, names []string) (int64, error) {
+	return r.q.AddGroupAliases(ctx, database.AddGroupAliasesParams{
+		GroupID: pgtype.UUID{Bytes: groupID, Valid: true},
+		Names:   names,
+		Type:    aliasType,
+	})
+}
+
+// CopyGroupAliases gives a group the aliases of other groups and returns how many were new
+func (r *GroupRepository) CopyGroupAliases(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error) {
+	return r.q.CopyGroupAliases(ctx, database.CopyGroupAliasesParams{
+		ToGroupID:    pgtype.UUID{Bytes: toGroupID, Valid: true},
+		FromGroupIds: toPgUUIDs(fromGroupIDs),
+	})
+}
+
+// SearchGroupsWithPagination returns live groups whose name or one of whose aliases contains name, ignoring case
+func (r *GroupRepository) SearchGroupsWithPagination(ctx context.Context, name string, limit, offset int32) ([]database.GetGroupsWithPaginationRow, error) {
+	rows, err := r.q.SearchGroupsWithPagination(ctx, database.SearchGroupsWithPaginationParams{
+		Name:        name,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make([]database.GetGroupsWithPaginationRow, 0, len(rows))
+	for _, row := range rows {
+		groups = append(groups, database.GetGroupsWithPaginationRow(row))
+	}
+	return groups, nil
+}
+
+func (r *GroupRepository) SearchGroupsCount(ctx context.Context, name string) (int64, error) {
+	return r.q.SearchGroupsCount(ctx, name)
+}
+
+// groupsByCreatedAt is the keyset ordering of group listings, newest first
+var groupsByCreatedAt = []sortKey{
+	{expr: "g.created_at", cast: "TIMESTAMPTZ", desc: true},
+	{expr: "g.id", cast: "UUID", desc: true},
+}
+
+// GetGroupsWithCursor returns the page of live groups after or before cursor, newest first. With a name only
+// groups whose name or one of whose aliases contains it are listed. An empty cursor starts at the newest group.
+func (r *GroupRepository) GetGroupsWithCursor(ctx context.Context, name string, limit int32, cursor string) ([]database.GetGroupsWithPaginationRow, CursorPage, error) {
+	k, err := newKeyset("", groupsByCreatedAt, cursor)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	b := &queryBuilder{}
+	b.where("g.deleted_at IS NULL")
+	if name != "" {
+		b.where(`(LOWER(g.name) LIKE '%' || LOWER(?::VARCHAR) || '%'
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = g.id AND LOWER(a.name) LIKE '%' || LOWER(?::VARCHAR) || '%'))`,
+			name, name)
+	}
+	k.where(b)
+
+	query := fmt.Sprintf(`SELECT g.id, g.name, g.created_at, g.updated_at, %s
+FROM groups g
+%s
+ORDER BY %s
+    LIMIT %s`, k.values(), b.whereClause(), k.orderBy(), b.arg(limit+1))
+
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+	defer rows.Close()
+
+	var groups []database.GetGroupsWithPaginationRow
+	var values [][]string
+	for rows.Next() {
+		var i database.GetGroupsWithPaginationRow
+		var v []string
+		if err = rows.Scan(&i.ID, &i.Name, &i.CreatedAt, &i.UpdatedAt, &v); err != nil {
+			return nil, CursorPage{}, err
+		}
+		groups = append(groups, i)
+		values = append(values, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	groups, page := paginate(k, groups, values, limit)
+	return groups, page, nil
+}
+
+func (r *GroupRepository) CreateGroupAlias(ctx context.Context, params GroupAliasCreateParams) (database.GroupAlias, error) {
+	return r.q.CreateGroupAlias(ctx, database.CreateGroupAliasParams{
+		GroupID: pgtype.UUID{Bytes: params.GroupID, Valid: true},
+		Name:    params.Name,
+		Locale:  params.Locale,
+		Type:    params.Type,
+	})
+}
+
+func (r *GroupRepository) GetGroupAliases(ctx context.Context, groupID uuid.UUID) ([]database.GroupAlias, error) {
+	return r.q.GetGroupAliases(ctx, pgtype.UUID{Bytes: groupID, Valid: true})
+}
+
+// DeleteGroupAlias deletes an alias of the group and reports whether it existed
+func (r *GroupRepository) DeleteGroupAlias(ctx context.Context, groupID, id uuid.UUID) (bool, error) {
+	deleted, err := r.q.DeleteGroupAlias(ctx, database.DeleteGroupAliasParams{
+		ID:      pgtype.UUID{Bytes: id, Valid: true},
+		GroupID: pgtype.UUID{Bytes: groupID, Valid: true},
+	})
+	return deleted > 0, err
+}
+
+// toPgVersion converts the expected updated_at of a conditional write, the zero time becomes NULL which skips the check
+func toPgVersion(updatedAt time.Time) pgtype.Timestamptz {
+	return pgtype.Timestamptz{Time: updatedAt, Valid: !updatedAt.IsZero()}
+}
+
+// optionalUUID converts an optional filter, uuid.Nil becomes NULL
+func optionalUUID(id uuid.UUID) pgtype.UUID {
+	return pgtype.UUID{Bytes: id, Valid: id != uuid.Nil}
+}
+
+func toPgUUIDs(ids []uuid.UUID) []pgtype.UUID {
+	pgIDs := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		pgIDs = append(pgIDs, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	return pgIDs
+}
+
+func fromPgUUIDs(pgIDs []pgtype.UUID) []uuid.UUID {
+	ids := make([]uuid.UUID, 0, len(pgIDs))
+	for _, id := range pgIDs {
+		ids = append(ids, id.Bytes)
+	}
+	return ids
+}
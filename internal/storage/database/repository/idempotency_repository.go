@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"time"
+)
+
+// IdempotencyRepositoryInterface stores the keys clients send with create requests and the responses to replay
+type IdempotencyRepositoryInterface interface {
+	StartIdempotentRequest(ctx context.Context, userID uuid.UUID, key string, requestHash []byte, expiresAt, staleBefore time.Time) (database.IdempotencyKey, error)
+	GetIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (database.IdempotencyKey, error)
+	CompleteIdempotentRequest(ctx context.Context, userID uuid.UUID, key string, statusCode int32, contentType string, body []byte) error
+	DeleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error
+	DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error)
+}
+
+type IdempotencyRepository struct {
+	q *database.Queries
+}
+
+func NewIdempotencyRepository(db database.DBTX) IdempotencyRepositoryInterface {
+	return &IdempotencyRepository{
+		q: database.New(db),
+	}
+}
+
+// StartIdempotentRequest claims a key for a request that has no response yet. A key that expired, or whose
+// request started before staleBefore without completing, is claimed again. Other keys are left alone and
+// pgx.ErrNoRows is returned.
+func (r *IdempotencyRepository) StartIdempotentRequest(ctx context.Context, userID uuid.UUID, key string, requestHash []byte, expiresAt, staleBefore time.Time) (database.IdempotencyKey, error) {
+	return r.q.StartIdempotentRequest(ctx, database.StartIdempotentRequestParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		Key:         key,
+		RequestHash: requestHash,
+		ExpiresAt:   pgtype.Timestamptz{Time: expiresAt, Valid: true},
+		StaleBefore: pgtype.Timestamptz{Time: staleBefore, Valid: true},
+	})
+}
+
+func (r *IdempotencyRepository) GetIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) (database.IdempotencyKey, error) {
+	return r.q.GetIdempotencyKey(ctx, database.GetIdempotencyKeyParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		Key:    key,
+	})
+}
+
+// CompleteIdempotentRequest stores the response to replay for the key
+func (r *IdempotencyRepository) CompleteIdempotentRequest(ctx context.Context, userID uuid.UUID, key string, statusCode int32, contentType string, body []byte) error {
+	return r.q.CompleteIdempotentRequest(ctx, database.CompleteIdempotentRequestParams{
+		StatusCode:   pgtype.Int4{Int32: statusCode, Valid: true},
+		ContentType:  contentType,
+		ResponseBody: body,
+		UserID:       pgtype.UUID{Bytes: userID, Valid: true},
+		Key:          key,
+	})
+}
+
+func (r *IdempotencyRepository) DeleteIdempotencyKey(ctx context.Context, userID uuid.UUID, key string) error {
+	return r.q.DeleteIdempotencyKey(ctx, database.DeleteIdempotencyKeyParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		Key:    key,
+	})
+}
+
+func (r *IdempotencyRepository) DeleteExpiredIdempotencyKeys(ctx context.Context) (int64, error) {
+	return r.q.DeleteExpiredIdempotencyKeys(ctx)
+}
@@ -0,0 +1,162 @@
+package repository
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strings"
+)
+
+// ErrInvalidCursor is returned for cursors that cannot be decoded or were issued for another ordering
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// CursorPage holds the cursors of the pages around a page read with a cursor, they are empty at either end
+type CursorPage struct {
+	Next string
+	Prev string
+}
+
+// sortKey is one key of a keyset ordering
+type sortKey struct {
+	expr string // SQL expression, never taken from user input
+	cast string // SQL type cursor values of the key are compared as
+	desc bool
+}
+
+// cursor is the decoded form of the opaque cursor tokens handed to clients
+type cursor struct {
+	Sort     string   `json:"s"`           // ordering the cursor was issued for
+	Values   []string `json:"v"`           // sort key values of the row the cursor points at, as text
+	Backward bool     `json:"b,omitempty"` // the page ends before the row instead of starting after it
+}
+
+func (c cursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// keyset pages through a query ordered by keys, the last of which must be unique so rows never tie.
+// Rows are found by comparing with the sort key values of the row the cursor points at rather than
+// by skipping rows, which stays fast on deep pages and does not shift when rows are inserted.
+type keyset struct {
+	sort   string
+	keys   []sortKey
+	cursor cursor
+}
+
+// newKeyset decodes token for the ordering named sort, an empty token starts at the first row
+func newKeyset(sort string, keys []sortKey, token string) (*keyset, error) {
+	k := &keyset{sort: sort, keys: keys}
+	if token == "" {
+		return k, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if err = json.Unmarshal(data, &k.cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if k.cursor.Sort != sort || len(k.cursor.Values) != len(keys) {
+		return nil, ErrInvalidCursor
+	}
+	return k, nil
+}
+
+// where restricts the query to the rows after the cursor, or before it for a backward cursor
+func (k *keyset) where(b *queryBuilder) {
+	if len(k.cursor.Values) == 0 {
+		return
+	}
+
+	// With one direction for all keys a row comparison says the same and can use an index
+	if !k.mixed() {
+		columns := make([]string, 0, len(k.keys))
+		values := make([]string, 0, len(k.keys))
+		for i, key := range k.keys {
+			columns = append(columns, key.expr)
+			values = append(values, b.arg(k.cursor.Values[i])+"::"+key.cast)
+		}
+		b.where("(" + strings.Join(columns, ", ") + ") " + k.operator(k.keys[0]) + " (" + strings.Join(values, ", ") + ")")
+		return
+	}
+
+	alternatives := make([]string, 0, len(k.keys))
+	for i, key := range k.keys {
+		terms := make([]string, 0, i+1)
+		for j, previous := range k.keys[:i] {
+			terms = append(terms, previous.expr+" = "+b.arg(k.cursor.Values[j])+"::"+previous.cast)
+		}
+		terms = append(terms, key.expr+" "+k.operator(key)+" "+b.arg(k.cursor.Values[i])+"::"+key.cast)
+		alternatives = append(alternatives, "("+strings.Join(terms, " AND ")+")")
+	}
+	b.where("(" + strings.Join(alternatives, "\n       OR ") + ")")
+}
+
+func (k *keyset) mixed() bool {
+	for _, key := range k.keys[1:] {
+		if key.desc != k.keys[0].desc {
+			return true
+		}
+	}
+	return false
+}
+
+// operator compares a key with the cursor value of the key in the direction the page is read
+func (k *keyset) operator(key sortKey) string {
+	if key.desc != k.cursor.Backward {
+		return "<"
+	}
+	return ">"
+}
+
+// orderBy is the ordering in the direction the page is read, backward pages are read in reverse
+func (k *keyset) orderBy() string {
+	keys := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		if key.desc != k.cursor.Backward {
+			keys = append(keys, key.expr+" DESC")
+		} else {
+			keys = append(keys, key.expr+" ASC")
+		}
+	}
+	return strings.Join(keys, ", ")
+}
+
+// values selects the sort key values of a row as text, the cursors of the page are made from them
+func (k *keyset) values() string {
+	exprs := make([]string, 0, len(k.keys))
+	for _, key := range k.keys {
+		exprs = append(exprs, key.expr+"::TEXT")
+	}
+	return "ARRAY[" + strings.Join(exprs, ", ") + "]"
+}
+
+// paginate trims rows read with one row more than limit to the page, puts them in order and makes the
+// cursors of the pages around it. values holds the sort key values of each row.
+func paginate[T any](k *keyset, rows []T, values [][]string, limit int32) ([]T, CursorPage) {
+	more := len(rows) > int(limit)
+	if more {
+		rows, values = rows[:limit], values[:limit]
+	}
+	if k.cursor.Backward {
+		slices.Reverse(rows)
+		slices.Reverse(values)
+	}
+
+	var page CursorPage
+	if len(rows) == 0 {
+		return rows, page
+	}
+
+	// A backward page was reached from the page after it, a forward page with a cursor from the one before it
+	if more || k.cursor.Backward {
+		page.Next = cursor{Sort: k.sort, Values: values[len(values)-1]}.encode()
+	}
+	if (more && k.cursor.Backward) || (!k.cursor.Backward && len(k.cursor.Values) > 0) {
+		page.Prev = cursor{Sort: k.sort, Values: values[0], Backward: true}.encode()
+	}
+	return rows, page
+}
@@ -0,0 +1,269 @@
+package repository
+
+import (
+	"errors"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestNewKeyset(t *testing.T) {
+	keys := []sortKey{{expr: "s.title", cast: "TEXT"}, {expr: "s.id", cast: "UUID"}}
+	valid := cursor{Sort: "title", Values: []string{"Song", "id"}}.encode()
+
+	if k, err := newKeyset("title", keys, ""); err != nil || len(k.cursor.Values) != 0 {
+		t.Errorf("newKeyset() without cursor = %+v, %v, want the first page", k, err)
+	}
+	if k, err := newKeyset("title", keys, valid); err != nil || !reflect.DeepEqual(k.cursor.Values, []string{"Song", "id"}) {
+		t.Errorf("newKeyset() = %+v, %v, want the cursor values", k, err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not base64", "not a cursor!"},
+		{"not JSON", "bm90IGpzb24"},
+		{"other ordering", cursor{Sort: "-title", Values: []string{"Song", "id"}}.encode()},
+		{"missing values", cursor{Sort: "title", Values: []string{"Song"}}.encode()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := newKeyset("title", keys, tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("newKeyset() error = %v, want %v", err, ErrInvalidCursor)
+			}
+		})
+	}
+}
+
+func TestKeysetWhere(t *testing.T) {
+	uniform := []sortKey{
+		{expr: "g.created_at", cast: "TIMESTAMPTZ", desc: true},
+		{expr: "g.id", cast: "UUID", desc: true},
+	}
+	mixed := []sortKey{
+		{expr: "s.title", cast: "TEXT"},
+		{expr: "s.runtime", cast: "INT4", desc: true},
+		{expr: "s.id", cast: "UUID"},
+	}
+
+	tests := []struct {
+		name      string
+		keys      []sortKey
+		cursor    cursor
+		wantWhere string
+		wantArgs  []any
+		wantOrder string
+	}{
+		{
+			name:      "first page",
+			keys:      uniform,
+			wantOrder: "g.created_at DESC, g.id DESC",
+		},
+		{
+			name:      "uniform forward",
+			keys:      uniform,
+			cursor:    cursor{Values: []string{"2020-01-02", "id"}},
+			wantWhere: "WHERE (g.created_at, g.id) < ($1::TIMESTAMPTZ, $2::UUID)",
+			wantArgs:  []any{"2020-01-02", "id"},
+			wantOrder: "g.created_at DESC, g.id DESC",
+		},
+		{
+			name:      "uniform backward",
+			keys:      uniform,
+			cursor:    cursor{Values: []string{"2020-01-02", "id"}, Backward: true},
+			wantWhere: "WHERE (g.created_at, g.id) > ($1::TIMESTAMPTZ, $2::UUID)",
+			wantArgs:  []any{"2020-01-02", "id"},
+			wantOrder: "g.created_at ASC, g.id ASC",
+		},
+		{
+			name:   "mixed forward",
+			keys:   mixed,
+			cursor: cursor{Values: []string{"Song", "200", "id"}},
+			wantWhere: "WHERE ((s.title > $1::TEXT)\n" +
+				"       OR (s.title = $2::TEXT AND s.runtime < $3::INT4)\n" +
+				"       OR (s.title = $4::TEXT AND s.runtime = $5::INT4 AND s.id > $6::UUID))",
+			wantArgs:  []any{"Song", "Song", "200", "Song", "200", "id"},
+			wantOrder: "s.title ASC, s.runtime DESC, s.id ASC",
+		},
+		{
+			name:   "mixed backward",
+			keys:   mixed,
+			cursor: cursor{Values: []string{"Song", "200", "id"}, Backward: true},
+			wantWhere: "WHERE ((s.title < $1::TEXT)\n" +
+				"       OR (s.title = $2::TEXT AND s.runtime > $3::INT4)\n" +
+				"       OR (s.title = $4::TEXT AND s.runtime = $5::INT4 AND s.id < $6::UUID))",
+			wantArgs:  []any{"Song", "Song", "200", "Song", "200", "id"},
+			wantOrder: "s.title DESC, s.runtime ASC, s.id DESC",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &keyset{keys: tt.keys, cursor: tt.cursor}
+			b := &queryBuilder{}
+			k.where(b)
+
+			if got := b.whereClause(); got != tt.wantWhere {
+				t.Errorf("where = %q, want %q", got, tt.wantWhere)
+			}
+			if got := k.orderBy(); got != tt.wantOrder {
+				t.Errorf("orderBy() = %q, want %q", got, tt.wantOrder)
+			}
+
+			if !reflect.DeepEqual(b.args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", b.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+func TestPaginate(t *testing.T) {
+	keys := []sortKey{{expr: "n", cast: "TEXT"}}
+	rows := func(values ...string) ([]string, [][]string) {
+		all := make([][]string, 0, len(values))
+		for _, value := range values {
+			all = append(all, []string{value})
+		}
+		return values, all
+	}
+
+	tests := []struct {
+		name     string
+		cursor   cursor
+		rows     []string // rows read in the direction of the page, one more than the limit when there are more
+		want     []string
+		wantNext string
+		wantPrev string
+	}{
+		{"empty", cursor{}, nil, nil, "", ""},
+		{"first page", cursor{}, []string{"1", "2", "3"}, []string{"1", "2"}, "2", ""},
+		{"only page", cursor{}, []string{"1", "2"}, []string{"1", "2"}, "", ""},
+		{"forward page", cursor{Values: []string{"2"}}, []string{"3", "4", "5"}, []string{"3", "4"}, "4", "3"},
+		{"last page", cursor{Values: []string{"4"}}, []string{"5"}, []string{"5"}, "", "5"},
+		{"past the last page", cursor{Values: []string{"5"}}, nil, nil, "", ""},
+		{"backward page", cursor{Values: []string{"5"}, Backward: true}, []string{"4", "3", "2"}, []string{"3", "4"}, "4", "3"},
+		{"backward to the first page", cursor{Values: []string{"3"}, Backward: true}, []string{"2", "1"}, []string{"1", "2"}, "2", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			k := &keyset{sort: "n", keys: keys, cursor: tt.cursor}
+			page, values := rows(slices.Clone(tt.rows)...)
+			got, cursors := paginate(k, page, values, 2)
+
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("rows = %v, want %v", got, tt.want)
+			}
+			if next := cursorValue(t, cursors.Next, false); next != tt.wantNext {
+				t.Errorf("next cursor at %q, want %q", next, tt.wantNext)
+			}
+			if prev := cursorValue(t, cursors.Prev, true); prev != tt.wantPrev {
+				t.Errorf("prev cursor at %q, want %q", prev, tt.wantPrev)
+			}
+		})
+	}
+}
+
+// TestPaginateMixedDirections pages through rows ordered by keys with mixed directions to the end and back
+func TestPaginateMixedDirections(t *testing.T) {
+	keys := []sortKey{{expr: "a", cast: "TEXT"}, {expr: "b", cast: "TEXT", desc: true}, {expr: "id", cast: "TEXT"}}
+	table := [][]string{
+		{"a", "3", "1"}, {"a", "1", "2"}, {"a", "1", "3"}, {"b", "9", "4"}, {"b", "2", "5"},
+		{"c", "5", "6"}, {"c", "5", "7"}, {"c", "4", "8"}, {"d", "0", "9"},
+	}
+	const limit = 2
+
+	// read stands in for the query, the rows after or before the cursor in the order the page is read
+	read := func(k *keyset) ([][]string, [][]string) {
+		compare := func(x, y []string) int {
+			for i, key := range keys {
+				if c := strings.Compare(x[i], y[i]); c != 0 {
+					if key.desc {
+						return -c
+					}
+					return c
+				}
+			}
+			return 0
+		}
+
+		var found [][]string
+		for _, row := range table {
+			if len(k.cursor.Values) == 0 {
+				found = append(found, row)
+				continue
+			}
+			if c := compare(row, k.cursor.Values); (c > 0 && !k.cursor.Backward) || (c < 0 && k.cursor.Backward) {
+				found = append(found, row)
+			}
+		}
+		slices.SortFunc(found, compare)
+		if k.cursor.Backward {
+			slices.Reverse(found)
+		}
+		if len(found) > limit+1 {
+			found = found[:limit+1]
+		}
+		return found, slices.Clone(found)
+	}
+	fetch := func(token string) ([][]string, CursorPage) {
+		k, err := newKeyset("a,-b", keys, token)
+		if err != nil {
+			t.Fatalf("newKeyset() error = %v", err)
+		}
+		rows, values := read(k)
+		return paginate(k, rows, values, limit)
+	}
+
+	var forward [][][]string
+	token := ""
+	for {
+		rows, page := fetch(token)
+		forward = append(forward, rows)
+		if page.Next == "" {
+			break
+		}
+		if len(forward) > len(table) {
+			t.Fatal("paging forward did not end")
+		}
+		token = page.Next
+	}
+	if got := slices.Concat(forward...); !reflect.DeepEqual(got, table) {
+		t.Fatalf("pages forward = %v, want %v", got, table)
+	}
+
+	_, page := fetch(token)
+	for i := len(forward) - 2; i >= 0; i-- {
+		if page.Prev == "" {
+			t.Fatalf("page %d has no previous page", i+1)
+		}
+		var rows [][]string
+		rows, page = fetch(page.Prev)
+		if !reflect.DeepEqual(rows, forward[i]) {
+			t.Errorf("page %d backward = %v, want %v", i, rows, forward[i])
+		}
+	}
+	if page.Prev != "" {
+		t.Error("first page reached backward has a previous page")
+	}
+}
+
+// cursorValue decodes a cursor of TestPaginate to the row it points at, empty for no cursor
+func cursorValue(t *testing.T, token string, backward bool) string {
+	t.Helper()
+
+	if token == "" {
+		return ""
+	}
+	k, err := newKeyset("n", []sortKey{{expr: "n", cast: "TEXT"}}, token)
+	if err != nil {
+		t.Fatalf("newKeyset() error = %v", err)
+	}
+	if k.cursor.Backward != backward {
+		t.Errorf("cursor backward = %v, want %v", k.cursor.Backward, backward)
+	}
+	return k.cursor.Values[0]
+}
@@ -0,0 +1,170 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"time"
+)
+
+const (
+	FavoriteEntitySong  = "song"
+	FavoriteEntityGroup = "group"
+)
+
+// LibraryRepositoryInterface stores what users favorite and listen to
+type LibraryRepositoryInterface interface {
+	AddFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) error
+	RemoveFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) (bool, error)
+	GetFavoritesWithPagination(ctx context.Context, params FavoriteFilterParams) ([]database.GetFavoritesWithPaginationRow, error)
+	GetFavoritesCount(ctx context.Context, userID uuid.UUID, entityType string) (int64, error)
+	CreatePlayEvent(ctx context.Context, params PlayEventCreateParams) (database.PlayEvent, error)
+	GetPlayHistoryWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetPlayHistoryWithPaginationRow, error)
+	GetPlayHistoryCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	MoveSongFavorites(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error)
+	MoveSongPlayEvents(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error)
+	LockSongPlayStats(ctx context.Context, songID uuid.UUID) (database.SongPlayStat, error)
+	RecountSongPlayStats(ctx context.Context, songID uuid.UUID) (database.SongPlayStat, error)
+	MoveGroupFavorites(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error)
+	DeleteEntitiesFavorites(ctx context.Context, entityType string, entityIDs []uuid.UUID) (int64, error)
+}
+
+type FavoriteFilterParams struct {
+	UserID     uuid.UUID
+	EntityType string // empty for songs and groups
+	Limit      int32
+	Offset     int32
+}
+
+type PlayEventCreateParams struct {
+	UserID         uuid.UUID
+	SongID         uuid.UUID
+	PlayedAt       time.Time
+	DurationPlayed int32
+	Client         string
+}
+
+type LibraryRepository struct {
+	q *database.Queries
+}
+
+func NewLibraryRepository(db database.DBTX) LibraryRepositoryInterface {
+	return &LibraryRepository{
+		q: database.New(db),
+	}
+}
+
+func (r *LibraryRepository) AddFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) error {
+	return r.q.AddFavorite(ctx, database.AddFavoriteParams{
+		UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+		EntityType: entityType,
+		EntityID:   pgtype.UUID{Bytes: entityID, Valid: true},
+	})
+}
+
+func (r *LibraryRepository) RemoveFavorite(ctx context.Context, userID uuid.UUID, entityType string, entityID uuid.UUID) (bool, error) {
+	result, err := r.q.RemoveFavorite(ctx, database.RemoveFavoriteParams{
+		UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+		EntityType: entityType,
+		EntityID:   pgtype.UUID{Bytes: entityID, Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *LibraryRepository) GetFavoritesWithPagination(ctx context.Context, params FavoriteFilterParams) ([]database.GetFavoritesWithPaginationRow, error) {
+	return r.q.GetFavoritesWithPagination(ctx, database.GetFavoritesWithPaginationParams{
+		UserID:      pgtype.UUID{Bytes: params.UserID, Valid: true},
+		EntityType:  params.EntityType,
+		LimitCount:  params.Limit,
+		OffsetCount: params.Offset,
+	})
+}
+
+func (r *LibraryRepository) GetFavoritesCount(ctx context.Context, userID uuid.UUID, entityType string) (int64, error) {
+	return r.q.GetFavoritesCount(ctx, database.GetFavoritesCountParams{
+		UserID:     pgtype.UUID{Bytes: userID, Valid: true},
+		EntityType: entityType,
+	})
+}
+
+func (r *LibraryRepository) CreatePlayEvent(ctx context.Context, params PlayEventCreateParams) (database.PlayEvent, error) {
+	return r.q.CreatePlayEvent(ctx, database.CreatePlayEventParams{
+		UserID:         pgtype.UUID{Bytes: params.UserID, Valid: true},
+		SongID:         pgtype.UUID{Bytes: params.SongID, Valid: true},
+		PlayedAt:       pgtype.Timestamptz{Time: params.PlayedAt, Valid: true},
+		DurationPlayed: params.DurationPlayed,
+		Client:         params.Client,
+	})
+}
+
+func (r *LibraryRepository) GetPlayHistoryWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetPlayHistoryWithPaginationRow, error) {
+	return r.q.GetPlayHistoryWithPagination(ctx, database.GetPlayHistoryWithPaginationParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+}
+
+func (r *LibraryRepository) GetPlayHistoryCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	pgID := pgtype.UUID{Bytes: userID, Valid: true}
+	return r.q.GetPlayHistoryCount(ctx, pgID)
+}
+
+// MoveSongFavorites moves favorites of one song to another and returns how many users gained a favorite,
+// users who favorited both songs keep a single favorite
+func (r *LibraryRepository) MoveSongFavorites(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error) {
+	pgFromSongID := pgtype.UUID{Bytes: fromSongID, Valid: true}
+	moved, err := r.q.CopySongFavorites(ctx, database.CopySongFavoritesParams{
+		ToSongID:   pgtype.UUID{Bytes: toSongID, Valid: true},
+		FromSongID: pgFromSongID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, r.q.DeleteSongFavorites(ctx, pgFromSongID)
+}
+
+// MoveSongPlayEvents attributes the plays of one song to another
+func (r *LibraryRepository) MoveSongPlayEvents(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error) {
+	return r.q.MoveSongPlayEvents(ctx, database.MoveSongPlayEventsParams{
+		ToSongID:   pgtype.UUID{Bytes: toSongID, Valid: true},
+		FromSongID: pgtype.UUID{Bytes: fromSongID, Valid: true},
+	})
+}
+
+// LockSongPlayStats creates the play stats row of a song if needed and locks it until the transaction ends
+func (r *LibraryRepository) LockSongPlayStats(ctx context.Context, songID uuid.UUID) (database.SongPlayStat, error) {
+	return r.q.LockSongPlayStats(ctx, pgtype.UUID{Bytes: songID, Valid: true})
+}
+
+// RecountSongPlayStats recomputes the play count of a song from its play events, the stats row must exist
+func (r *LibraryRepository) RecountSongPlayStats(ctx context.Context, songID uuid.UUID) (database.SongPlayStat, error) {
+	return r.q.RecountSongPlayStats(ctx, pgtype.UUID{Bytes: songID, Valid: true})
+}
+
+// MoveGroupFavorites moves favorites of groups to another group and returns how many users gained a favorite
+func (r *LibraryRepository) MoveGroupFavorites(ctx context.Context, fromGroupIDs []uuid.UUID, toGroupID uuid.UUID) (int64, error) {
+	pgFromGroupIDs := toPgUUIDs(fromGroupIDs)
+	moved, err := r.q.CopyGroupFavorites(ctx, database.CopyGroupFavoritesParams{
+		ToGroupID:    pgtype.UUID{Bytes: toGroupID, Valid: true},
+		FromGroupIds: pgFromGroupIDs,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, r.q.DeleteGroupsFavorites(ctx, pgFromGroupIDs)
+}
+
+// DeleteEntitiesFavorites removes every user's favorite of the songs or groups
+func (r *LibraryRepository) DeleteEntitiesFavorites(ctx context.Context, entityType string, entityIDs []uuid.UUID) (int64, error) {
+	return r.q.DeleteEntitiesFavorites(ctx, database.DeleteEntitiesFavoritesParams{
+		EntityType: entityType,
+		EntityIds:  toPgUUIDs(entityIDs),
+	})
+}
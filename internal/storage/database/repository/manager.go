@@ -13,10 +13,18 @@ import (
 
 // Manager wraps SQLC queries with connection management
 type Manager struct {
-	Groups     GroupRepositoryInterface
-	Songs      SongRepositoryInterface
-	rawQueries *database.Queries
-	pool       *pgxpool.Pool
+	Groups         GroupRepositoryInterface
+	Songs          SongRepositoryInterface
+	Artworks       ArtworkRepositoryInterface
+	Playlists      PlaylistRepositoryInterface
+	SmartPlaylists SmartPlaylistRepositoryInterface
+	Users          UserRepositoryInterface
+	Library        LibraryRepositoryInterface
+	Ratings        RatingRepositoryInterface
+	Trash          TrashRepositoryInterface
+	Idempotency    IdempotencyRepositoryInterface
+	rawQueries     *database.Queries
+	pool           *pgxpool.Pool
 }
 
 type Tx struct {
@@ -25,8 +33,34 @@ type Tx struct {
 }
 
 type ReposTx struct {
-	Groups GroupRepositoryInterface
-	Songs  SongRepositoryInterface
+	Groups         GroupRepositoryInterface
+	Songs          SongRepositoryInterface
+	Artworks       ArtworkRepositoryInterface
+	Playlists      PlaylistRepositoryInterface
+	SmartPlaylists SmartPlaylistRepositoryInterface
+	Users          UserRepositoryInterface
+	Library        LibraryRepositoryInterface
+	Ratings        RatingRepositoryInterface
+	Trash          TrashRepositoryInterface
+	Idempotency    IdempotencyRepositoryInterface
+}
+
+// NewManager creates a manager whose repositories share the pool
+func NewManager(pool *pgxpool.Pool) *Manager {
+	return &Manager{
+		Groups:         NewGroupRepository(pool),
+		Songs:          NewSongRepository(pool),
+		Artworks:       NewArtworkRepository(pool),
+		Playlists:      NewPlaylistRepository(pool),
+		SmartPlaylists: NewSmartPlaylistRepository(pool),
+		Users:          NewUserRepository(pool),
+		Library:        NewLibraryRepository(pool),
+		Ratings:        NewRatingRepository(pool),
+		Trash:          NewTrashRepository(pool),
+		Idempotency:    NewIdempotencyRepository(pool),
+		rawQueries:     database.New(pool),
+		pool:           pool,
+	}
 }
 
 // connectSqlcWithPool connects to the database and returns a SQLC Queries instance with the underlying pool
@@ -71,12 +105,7 @@ func connectSqlcWithPool(config *config.Config, ctx context.Context) (*Manager,
 		config.Internal.Database.Port,
 	)
 
-	return &Manager{
-		Groups:     NewGroupRepository(pool),
-		Songs:      NewSongRepository(pool),
-		rawQueries: database.New(pool),
-		pool:       pool,
-	}, nil
+	return NewManager(pool), nil
 }
 
 func MustConnectDB(config *config.Config, ctx context.Context) *Manager {
@@ -107,8 +136,16 @@ func (m *Manager) BeginTx(ctx context.Context) (*Tx, error) {
 	return &Tx{
 		tx: tx,
 		Repos: &ReposTx{
-			Groups: NewGroupRepository(tx),
-			Songs:  NewSongRepository(tx),
+			Groups:         NewGroupRepository(tx),
+			Songs:          NewSongRepository(tx),
+			Artworks:       NewArtworkRepository(tx),
+			Playlists:      NewPlaylistRepository(tx),
+			SmartPlaylists: NewSmartPlaylistRepository(tx),
+			Users:          NewUserRepository(tx),
+			Library:        NewLibraryRepository(tx),
+			Ratings:        NewRatingRepository(tx),
+			Trash:          NewTrashRepository(tx),
+			Idempotency:    NewIdempotencyRepository(tx),
 		},
 	}, nil
 }
@@ -0,0 +1,204 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+)
+
+type PlaylistRepositoryInterface interface {
+	CreatePlaylist(ctx context.Context, params PlaylistCreateParams) (database.Playlist, error)
+	GetPlaylist(ctx context.Context, id uuid.UUID) (database.Playlist, error)
+	GetPlaylistsWithPagination(ctx context.Context, params PlaylistFilterParams) ([]database.Playlist, error)
+	GetPlaylistsCount(ctx context.Context, params PlaylistFilterParams) (int64, error)
+	GetPlaylistsStats(ctx context.Context, ids []uuid.UUID) ([]database.GetPlaylistsStatsRow, error)
+	UpdatePlaylist(ctx context.Context, params PlaylistUpdateParams) (database.Playlist, error)
+	TouchPlaylist(ctx context.Context, id uuid.UUID) (bool, error)
+	DeletePlaylist(ctx context.Context, id uuid.UUID) (bool, error)
+
+	CreatePlaylistEntry(ctx context.Context, playlistID, songID uuid.UUID, position int32) (database.PlaylistEntry, error)
+	GetPlaylistEntry(ctx context.Context, playlistID, entryID uuid.UUID) (database.PlaylistEntry, error)
+	GetPlaylistEntries(ctx context.Context, playlistID uuid.UUID) ([]database.GetPlaylistEntriesRow, error)
+	GetPlaylistEntryCount(ctx context.Context, playlistID uuid.UUID) (int64, error)
+	ShiftPlaylistEntries(ctx context.Context, playlistID uuid.UUID, fromPosition, toPosition, delta int32) error
+	SetPlaylistEntryPosition(ctx context.Context, entryID uuid.UUID, position int32) error
+	DeletePlaylistEntry(ctx context.Context, entryID uuid.UUID) error
+	MoveSongEntries(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error)
+	TouchSongsPlaylists(ctx context.Context, songIDs []uuid.UUID) ([]uuid.UUID, error)
+	RenumberPlaylistEntries(ctx context.Context, playlistIDs []uuid.UUID) error
+}
+
+type PlaylistCreateParams struct {
+	Name        string
+	Description string
+	OwnerID     uuid.UUID
+	Visibility  string
+}
+
+type PlaylistUpdateParams struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Visibility  string
+}
+
+// PlaylistFilterParams filters playlist listings. Listings only hold public playlists and those of the viewer,
+// or every playlist for admins.
+type PlaylistFilterParams struct {
+	Limit         int32
+	Offset        int32
+	OwnerID       uuid.UUID // uuid.Nil for every owner
+	Visibility    string
+	ViewerID      uuid.UUID // uuid.Nil for anonymous callers
+	ViewerIsAdmin bool
+}
+
+type PlaylistRepository struct {
+	q *database.Queries
+}
+
+func NewPlaylistRepository(db database.DBTX) PlaylistRepositoryInterface {
+	return &PlaylistRepository{
+		q: database.New(db),
+	}
+}
+
+func (r *PlaylistRepository) CreatePlaylist(ctx context.Context, params PlaylistCreateParams) (database.Playlist, error) {
+	return r.q.CreatePlaylist(ctx, database.CreatePlaylistParams{
+		Name:        params.Name,
+		Description: params.Description,
+		OwnerID:     pgtype.UUID{Bytes: params.OwnerID, Valid: true},
+		Visibility:  params.Visibility,
+	})
+}
+
+func (r *PlaylistRepository) GetPlaylist(ctx context.Context, id uuid.UUID) (database.Playlist, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	return r.q.GetPlaylist(ctx, pgID)
+}
+
+func (r *PlaylistRepository) GetPlaylistsWithPagination(ctx context.Context, params PlaylistFilterParams) ([]database.Playlist, error) {
+	return r.q.GetPlaylistsWithPagination(ctx, database.GetPlaylistsWithPaginationParams{
+		ViewerID:      optionalUUID(params.ViewerID),
+		ViewerIsAdmin: params.ViewerIsAdmin,
+		OwnerID:       optionalUUID(params.OwnerID),
+		Visibility:    params.Visibility,
+		LimitCount:    params.Limit,
+		OffsetCount:   params.Offset,
+	})
+}
+
+func (r *PlaylistRepository) GetPlaylistsCount(ctx context.Context, params PlaylistFilterParams) (int64, error) {
+	return r.q.GetPlaylistsCount(ctx, database.GetPlaylistsCountParams{
+		ViewerID:      optionalUUID(params.ViewerID),
+		ViewerIsAdmin: params.ViewerIsAdmin,
+		OwnerID:       optionalUUID(params.OwnerID),
+		Visibility:    params.Visibility,
+	})
+}
+
+func (r *PlaylistRepository) GetPlaylistsStats(ctx context.Context, ids []uuid.UUID) ([]database.GetPlaylistsStatsRow, error) {
+	pgIDs := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		pgIDs = append(pgIDs, pgtype.UUID{Bytes: id, Valid: true})
+	}
+	return r.q.GetPlaylistsStats(ctx, pgIDs)
+}
+
+func (r *PlaylistRepository) UpdatePlaylist(ctx context.Context, params PlaylistUpdateParams) (database.Playlist, error) {
+	pgID := pgtype.UUID{Bytes: params.ID, Valid: true}
+	return r.q.UpdatePlaylist(ctx, database.UpdatePlaylistParams{
+		ID:          pgID,
+		Name:        params.Name,
+		Description: params.Description,
+		Visibility:  params.Visibility,
+	})
+}
+
+// TouchPlaylist bumps updated_at and locks the playlist row until the surrounding transaction ends
+func (r *PlaylistRepository) TouchPlaylist(ctx context.Context, id uuid.UUID) (bool, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	affected, err := r.q.TouchPlaylist(ctx, pgID)
+	return affected > 0, err
+}
+
+func (r *PlaylistRepository) DeletePlaylist(ctx context.Context, id uuid.UUID) (bool, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	result, err := r.q.DeletePlaylist(ctx, pgID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
+
+func (r *PlaylistRepository) CreatePlaylistEntry(ctx context.Context, playlistID, songID uuid.UUID, position int32) (database.PlaylistEntry, error) {
+	return r.q.CreatePlaylistEntry(ctx, database.CreatePlaylistEntryParams{
+		PlaylistID: pgtype.UUID{Bytes: playlistID, Valid: true},
+		SongID:     pgtype.UUID{Bytes: songID, Valid: true},
+		Position:   position,
+	})
+}
+
+func (r *PlaylistRepository) GetPlaylistEntry(ctx context.Context, playlistID, entryID uuid.UUID) (database.PlaylistEntry, error) {
+	return r.q.GetPlaylistEntry(ctx, database.GetPlaylistEntryParams{
+		ID:         pgtype.UUID{Bytes: entryID, Valid: true},
+		PlaylistID: pgtype.UUID{Bytes: playlistID, Valid: true},
+	})
+}
+
+func (r *PlaylistRepository) GetPlaylistEntries(ctx context.Context, playlistID uuid.UUID) ([]database.GetPlaylistEntriesRow, error) {
+	pgPlaylistID := pgtype.UUID{Bytes: playlistID, Valid: true}
+	return r.q.GetPlaylistEntries(ctx, pgPlaylistID)
+}
+
+func (r *PlaylistRepository) GetPlaylistEntryCount(ctx context.Context, playlistID uuid.UUID) (int64, error) {
+	pgPlaylistID := pgtype.UUID{Bytes: playlistID, Valid: true}
+	return r.q.GetPlaylistEntryCount(ctx, pgPlaylistID)
+}
+
+func (r *PlaylistRepository) ShiftPlaylistEntries(ctx context.Context, playlistID uuid.UUID, fromPosition, toPosition, delta int32) error {
+	return r.q.ShiftPlaylistEntries(ctx, database.ShiftPlaylistEntriesParams{
+		Delta:        delta,
+		PlaylistID:   pgtype.UUID{Bytes: playlistID, Valid: true},
+		FromPosition: fromPosition,
+		ToPosition:   toPosition,
+	})
+}
+
+func (r *PlaylistRepository) SetPlaylistEntryPosition(ctx context.Context, entryID uuid.UUID, position int32) error {
+	return r.q.SetPlaylistEntryPosition(ctx, database.SetPlaylistEntryPositionParams{
+		ID:       pgtype.UUID{Bytes: entryID, Valid: true},
+		Position: position,
+	})
+}
+
+func (r *PlaylistRepository) DeletePlaylistEntry(ctx context.Context, entryID uuid.UUID) error {
+	pgEntryID := pgtype.UUID{Bytes: entryID, Valid: true}
+	return r.q.DeletePlaylistEntry(ctx, pgEntryID)
+}
+
+// MoveSongEntries points every playlist entry of one song at another, keeping positions.
+// The playlists involved are touched so clients see they changed.
+func (r *PlaylistRepository) MoveSongEntries(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error) {
+	pgFromSongID := pgtype.UUID{Bytes: fromSongID, Valid: true}
+	if err := r.q.TouchPlaylistsWithSong(ctx, pgFromSongID); err != nil {
+		return 0, err
+	}
+
+	return r.q.MoveSongPlaylistEntries(ctx, database.MoveSongPlaylistEntriesParams{
+		ToSongID:   pgtype.UUID{Bytes: toSongID, Valid: true},
+		FromSongID: pgFromSongID,
+	})
+}
+
+// TouchSongsPlaylists touches and locks every playlist, deleted or not, with an entry of one of the songs and returns their IDs
+func (r *PlaylistRepository) TouchSongsPlaylists(ctx context.Context, songIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.q.TouchSongsPlaylists(ctx, toPgUUIDs(songIDs))
+	return fromPgUUIDs(rows), err
+}
+
+// RenumberPlaylistEntries closes the gaps in the entry positions of the playlists, keeping their order
+func (r *PlaylistRepository) RenumberPlaylistEntries(ctx context.Context, playlistIDs []uuid.UUID) error {
+	return r.q.RenumberPlaylistEntries(ctx, toPgUUIDs(playlistIDs))
+}
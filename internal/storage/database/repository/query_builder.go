@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryBuilder assembles WHERE clauses for queries whose shape depends on user input.
+// Values are always passed as positional arguments, only whitelisted identifiers are
+// ever written into the SQL text.
+type queryBuilder struct {
+	conditions []string
+	args       []any
+}
+
+// arg registers a value and returns its positional placeholder
+func (b *queryBuilder) arg(value any) string {
+	b.args = append(b.args, value)
+	return fmt.Sprintf("$%d", len(b.args))
+}
+
+// where adds a condition, every ? in it is replaced with the placeholder of the matching value
+func (b *queryBuilder) where(condition string, values ...any) {
+	b.conditions = append(b.conditions, b.bind(condition, values...))
+}
+
+// bind replaces every ? in a piece of SQL with the placeholder of the matching value
+func (b *queryBuilder) bind(sql string, values ...any) string {
+	var sb strings.Builder
+	next := 0
+	for _, r := range sql {
+		if r == '?' && next < len(values) {
+			sb.WriteString(b.arg(values[next]))
+			next++
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// whereClause joins the collected conditions with AND, it is empty when there are none
+func (b *queryBuilder) whereClause() string {
+	if len(b.conditions) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(b.conditions, "\n  AND ")
+}
@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+)
+
+type RatingRepositoryInterface interface {
+	LockSongRatingStats(ctx context.Context, songID uuid.UUID) (database.SongRatingStat, error)
+	GetSongRating(ctx context.Context, userID, songID uuid.UUID) (database.SongRating, error)
+	UpsertSongRating(ctx context.Context, userID, songID uuid.UUID, rating int32) (database.SongRating, error)
+	DeleteSongRating(ctx context.Context, userID, songID uuid.UUID) (int32, error)
+	AdjustSongRatingStats(ctx context.Context, songID uuid.UUID, countDelta, sumDelta int32) (database.SongRatingStat, error)
+	GetUserRatingsWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetUserRatingsWithPaginationRow, error)
+	GetUserRatingsCount(ctx context.Context, userID uuid.UUID) (int64, error)
+	MoveSongRatings(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error)
+	RecountSongRatingStats(ctx context.Context, songID uuid.UUID) (database.SongRatingStat, error)
+}
+
+type RatingRepository struct {
+	q *database.Queries
+}
+
+func NewRatingRepository(db database.DBTX) RatingRepositoryInterface {
+	return &RatingRepository{
+		q: database.New(db),
+	}
+}
+
+// LockSongRatingStats creates the stats row of a song if needed and locks it until the transaction ends
+func (r *RatingRepository) LockSongRatingStats(ctx context.Context, songID uuid.UUID) (database.SongRatingStat, error) {
+	pgID := pgtype.UUID{Bytes: songID, Valid: true}
+	return r.q.LockSongRatingStats(ctx, pgID)
+}
+
+func (r *RatingRepository) GetSongRating(ctx context.Context, userID, songID uuid.UUID) (database.SongRating, error) {
+	return r.q.GetSongRating(ctx, database.GetSongRatingParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		SongID: pgtype.UUID{Bytes: songID, Valid: true},
+	})
+}
+
+func (r *RatingRepository) UpsertSongRating(ctx context.Context, userID, songID uuid.UUID, rating int32) (database.SongRating, error) {
+	return r.q.UpsertSongRating(ctx, database.UpsertSongRatingParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		SongID: pgtype.UUID{Bytes: songID, Valid: true},
+		Rating: rating,
+	})
+}
+
+// DeleteSongRating removes a rating and returns the value it had
+func (r *RatingRepository) DeleteSongRating(ctx context.Context, userID, songID uuid.UUID) (int32, error) {
+	return r.q.DeleteSongRating(ctx, database.DeleteSongRatingParams{
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+		SongID: pgtype.UUID{Bytes: songID, Valid: true},
+	})
+}
+
+func (r *RatingRepository) AdjustSongRatingStats(ctx context.Context, songID uuid.UUID, countDelta, sumDelta int32) (database.SongRatingStat, error) {
+	return r.q.AdjustSongRatingStats(ctx, database.AdjustSongRatingStatsParams{
+		CountDelta: countDelta,
+		SumDelta:   sumDelta,
+		SongID:     pgtype.UUID{Bytes: songID, Valid: true},
+	})
+}
+
+func (r *RatingRepository) GetUserRatingsWithPagination(ctx context.Context, userID uuid.UUID, limit, offset int32) ([]database.GetUserRatingsWithPaginationRow, error) {
+	return r.q.GetUserRatingsWithPagination(ctx, database.GetUserRatingsWithPaginationParams{
+		UserID:      pgtype.UUID{Bytes: userID, Valid: true},
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+}
+
+func (r *RatingRepository) GetUserRatingsCount(ctx context.Context, userID uuid.UUID) (int64, error) {
+	pgID := pgtype.UUID{Bytes: userID, Valid: true}
+	return r.q.GetUserRatingsCount(ctx, pgID)
+}
+
+// MoveSongRatings moves ratings of one song to another and returns how many ratings were added or replaced.
+// When a user rated both songs the most recent rating wins. The stats of both songs must be recounted afterwards.
+func (r *RatingRepository) MoveSongRatings(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error) {
+	pgFromSongID := pgtype.UUID{Bytes: fromSongID, Valid: true}
+	moved, err := r.q.CopySongRatings(ctx, database.CopySongRatingsParams{
+		ToSongID:   pgtype.UUID{Bytes: toSongID, Valid: true},
+		FromSongID: pgFromSongID,
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return moved, r.q.DeleteSongRatings(ctx, pgFromSongID)
+}
+
+// RecountSongRatingStats recomputes the stats of a song from its ratings, the stats row must exist
+func (r *RatingRepository) RecountSongRatingStats(ctx context.Context, songID uuid.UUID) (database.SongRatingStat, error) {
+	return r.q.RecountSongRatingStats(ctx, pgtype.UUID{Bytes: songID, Valid: true})
+}
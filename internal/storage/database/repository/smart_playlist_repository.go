@@ -0,0 +1,98 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+)
+
+type SmartPlaylistRepositoryInterface interface {
+	CreateSmartPlaylist(ctx context.Context, params SmartPlaylistCreateParams) (database.SmartPlaylist, error)
+	GetSmartPlaylist(ctx context.Context, id uuid.UUID) (database.SmartPlaylist, error)
+	GetSmartPlaylistsWithPagination(ctx context.Context, params PlaylistFilterParams) ([]database.SmartPlaylist, error)
+	GetSmartPlaylistsCount(ctx context.Context, params PlaylistFilterParams) (int64, error)
+	UpdateSmartPlaylist(ctx context.Context, params SmartPlaylistUpdateParams) (database.SmartPlaylist, error)
+	DeleteSmartPlaylist(ctx context.Context, id uuid.UUID) (bool, error)
+}
+
+type SmartPlaylistCreateParams struct {
+	Name        string
+	Description string
+	OwnerID     uuid.UUID
+	Visibility  string
+	Rules       []byte
+}
+
+type SmartPlaylistUpdateParams struct {
+	ID          uuid.UUID
+	Name        string
+	Description string
+	Visibility  string
+	Rules       []byte
+}
+
+type SmartPlaylistRepository struct {
+	q *database.Queries
+}
+
+func NewSmartPlaylistRepository(db database.DBTX) SmartPlaylistRepositoryInterface {
+	return &SmartPlaylistRepository{
+		q: database.New(db),
+	}
+}
+
+func (r *SmartPlaylistRepository) CreateSmartPlaylist(ctx context.Context, params SmartPlaylistCreateParams) (database.SmartPlaylist, error) {
+	return r.q.CreateSmartPlaylist(ctx, database.CreateSmartPlaylistParams{
+		Name:        params.Name,
+		Description: params.Description,
+		OwnerID:     pgtype.UUID{Bytes: params.OwnerID, Valid: true},
+		Visibility:  params.Visibility,
+		Rules:       params.Rules,
+	})
+}
+
+func (r *SmartPlaylistRepository) GetSmartPlaylist(ctx context.Context, id uuid.UUID) (database.SmartPlaylist, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	return r.q.GetSmartPlaylist(ctx, pgID)
+}
+
+func (r *SmartPlaylistRepository) GetSmartPlaylistsWithPagination(ctx context.Context, params PlaylistFilterParams) ([]database.SmartPlaylist, error) {
+	return r.q.GetSmartPlaylistsWithPagination(ctx, database.GetSmartPlaylistsWithPaginationParams{
+		ViewerID:      optionalUUID(params.ViewerID),
+		ViewerIsAdmin: params.ViewerIsAdmin,
+		OwnerID:       optionalUUID(params.OwnerID),
+		Visibility:    params.Visibility,
+		LimitCount:    params.Limit,
+		OffsetCount:   params.Offset,
+	})
+}
+
+func (r *SmartPlaylistRepository) GetSmartPlaylistsCount(ctx context.Context, params PlaylistFilterParams) (int64, error) {
+	return r.q.GetSmartPlaylistsCount(ctx, database.GetSmartPlaylistsCountParams{
+		ViewerID:      optionalUUID(params.ViewerID),
+		ViewerIsAdmin: params.ViewerIsAdmin,
+		OwnerID:       optionalUUID(params.OwnerID),
+		Visibility:    params.Visibility,
+	})
+}
+
+func (r *SmartPlaylistRepository) UpdateSmartPlaylist(ctx context.Context, params SmartPlaylistUpdateParams) (database.SmartPlaylist, error) {
+	pgID := pgtype.UUID{Bytes: params.ID, Valid: true}
+	return r.q.UpdateSmartPlaylist(ctx, database.UpdateSmartPlaylistParams{
+		ID:          pgID,
+		Name:        params.Name,
+		Description: params.Description,
+		Visibility:  params.Visibility,
+		Rules:       params.Rules,
+	})
+}
+
+func (r *SmartPlaylistRepository) DeleteSmartPlaylist(ctx context.Context, id uuid.UUID) (bool, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	result, err := r.q.DeleteSmartPlaylist(ctx, pgID)
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
@@ -0,0 +1,229 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"strings"
+)
+
+// SongListSortKeys maps the sort fields of song listings to the keys they order by
+var SongListSortKeys = map[string][]sortKey{
+	"title":        {{expr: "s.title", cast: "VARCHAR"}},
+	"release_date": {{expr: "s.release_date", cast: "TIMESTAMPTZ"}},
+	"runtime":      {{expr: "s.runtime", cast: "INT"}},
+	"created_at":   {{expr: "s.created_at", cast: "TIMESTAMPTZ"}},
+	"updated_at":   {{expr: "s.updated_at", cast: "TIMESTAMPTZ"}},
+	"group":        {{expr: "s.group_name", cast: "VARCHAR"}},
+	"rating": {
+		{expr: "s.rating_average", cast: "FLOAT8"},
+		{expr: "s.rating_count", cast: "INT"},
+	},
+}
+
+// songsByCreatedAt is the ordering of song listings without a sort, newest first
+var songsByCreatedAt = []sortKey{
+	{expr: "s.created_at", cast: "TIMESTAMPTZ", desc: true},
+	{expr: "s.id", cast: "UUID", desc: true},
+}
+
+// songListFrom is what the filters, sort keys and columns of song listings refer to
+const songListFrom = "FROM song_listings s"
+
+// songListOrdering returns the keyset ordering of a song listing and the name its cursors are issued for.
+// Sort fields are expected to be validated by the caller, s.id keeps the order stable.
+func songListOrdering(sort []string) (string, []sortKey) {
+	if len(sort) == 0 {
+		return "", songsByCreatedAt
+	}
+
+	var keys []sortKey
+	for _, field := range sort {
+		desc := strings.HasPrefix(field, "-")
+		for _, key := range SongListSortKeys[strings.TrimPrefix(field, "-")] {
+			key.desc = desc
+			keys = append(keys, key)
+		}
+	}
+	return strings.Join(sort, ","), append(keys, sortKey{expr: "s.id", cast: "UUID"})
+}
+
+// songListColumns selects the columns of database.GetSongsWithPaginationRow, lyrics are NULL unless asked for
+func songListColumns(params SongFilterParams) string {
+	lyrics := "NULL::JSONB"
+	if params.WithLyrics {
+		lyrics = "s.lyrics"
+	}
+	return "s.id, s.group_id, s.title, s.runtime, " + lyrics + ", s.release_date, s.link, s.created_at, s.updated_at, " +
+		"s.group_name, s.group_created_at, s.group_updated_at, s.play_count, s.rating_average, s.rating_count, " +
+		"s.artwork_width, s.artwork_height, s.artwork_format, s.artwork_updated_at, " +
+		"s.group_artwork_width, s.group_artwork_height, s.group_artwork_format, s.group_artwork_updated_at"
+}
+
+// songListWhere adds the filters of a song listing, only live songs of live groups are listed
+func songListWhere(b *queryBuilder, params SongFilterParams) {
+	b.where("s.deleted_at IS NULL")
+	b.where("s.group_deleted_at IS NULL")
+
+	if params.GroupID != uuid.Nil {
+		b.where("s.group_id = ?", pgtype.UUID{Bytes: params.GroupID, Valid: true})
+	}
+	if params.GroupName != "" {
+		b.where(`(LOWER(s.group_name) LIKE '%' || LOWER(?::VARCHAR) || '%'
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = s.group_id AND LOWER(a.name) LIKE '%' || LOWER(?::VARCHAR) || '%'))`,
+			params.GroupName, params.GroupName)
+	}
+	if params.SongTitle != "" {
+		b.where("LOWER(s.title) LIKE LOWER('%' || ?::VARCHAR || '%')", params.SongTitle)
+	}
+	if params.MinRating > 0 {
+		b.where("s.rating_count > 0 AND s.rating_average >= ?::FLOAT8", params.MinRating)
+	}
+	if params.ReleaseDateFrom != "" {
+		b.where("s.release_date >= ?::DATE", params.ReleaseDateFrom)
+	}
+	if params.ReleaseDateTo != "" {
+		b.where("s.release_date < ?::DATE + 1", params.ReleaseDateTo)
+	}
+	if params.RuntimeMin != nil {
+		b.where("s.runtime >= ?", *params.RuntimeMin)
+	}
+	if params.RuntimeMax != nil {
+		b.where("s.runtime <= ?", *params.RuntimeMax)
+	}
+	if !params.CreatedSince.IsZero() {
+		b.where("s.created_at >= ?", params.CreatedSince)
+	}
+	if !params.UpdatedSince.IsZero() {
+		b.where("s.updated_at >= ?", params.UpdatedSince)
+	}
+	if params.Query != nil {
+		b.where(songQueryCondition(b, params.Query))
+	}
+}
+
+// GetSongsWithFilters returns a page of the songs matching params in the order params asks for and how many
+// songs match in total. The total is counted along with the page, only a page past the end takes a second query.
+func (r *SongRepository) GetSongsWithFilters(ctx context.Context, params SongFilterParams) ([]database.GetSongsWithPaginationRow, int64, error) {
+	_, keys := songListOrdering(params.Sort)
+	k := &keyset{keys: keys}
+
+	b := &queryBuilder{}
+	songListWhere(b, params)
+
+	query := fmt.Sprintf(`SELECT %s, count(*) OVER ()
+%s
+%s
+ORDER BY %s
+    LIMIT %s OFFSET %s`, songListColumns(params), songListFrom, b.whereClause(), k.orderBy(), b.arg(params.Limit), b.arg(params.Offset))
+
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	songs := []database.GetSongsWithPaginationRow{}
+	var total int64
+	for rows.Next() {
+		var i database.GetSongsWithPaginationRow
+		if err = scanSongListRow(rows, &i, &total); err != nil {
+			return nil, 0, err
+		}
+		songs = append(songs, i)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	if len(songs) == 0 && params.Offset > 0 {
+		total, err = r.countSongsWithFilters(ctx, params)
+	}
+	return songs, total, err
+}
+
+// countSongsWithFilters counts the songs matching params when the page of a listing has no row to count them on
+func (r *SongRepository) countSongsWithFilters(ctx context.Context, params SongFilterParams) (int64, error) {
+	b := &queryBuilder{}
+	songListWhere(b, params)
+
+	var count int64
+	err := r.db.QueryRow(ctx, fmt.Sprintf("SELECT count(*)\n%s\n%s", songListFrom, b.whereClause()), b.args...).Scan(&count)
+	return count, err
+}
+
+// GetSongsWithCursor returns the page of songs after or before cursor, filtered and sorted like
+// GetSongsWithFilters. An empty cursor starts at the first song, cursors only fit the sort they were issued for.
+func (r *SongRepository) GetSongsWithCursor(ctx context.Context, params SongFilterParams, cursor string) ([]database.GetSongsWithPaginationRow, CursorPage, error) {
+	sort, keys := songListOrdering(params.Sort)
+	k, err := newKeyset(sort, keys, cursor)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	b := &queryBuilder{}
+	songListWhere(b, params)
+	k.where(b)
+
+	query := fmt.Sprintf(`SELECT %s, %s
+%s
+%s
+ORDER BY %s
+    LIMIT %s`, songListColumns(params), k.values(), songListFrom, b.whereClause(), k.orderBy(), b.arg(params.Limit+1))
+
+	rows, err := r.db.Query(ctx, query, b.args...)
+	if err != nil {
+		return nil, CursorPage{}, err
+	}
+	defer rows.Close()
+
+	var songs []database.GetSongsWithPaginationRow
+	var values [][]string
+	for rows.Next() {
+		var i database.GetSongsWithPaginationRow
+		var v []string
+		if err = scanSongListRow(rows, &i, &v); err != nil {
+			return nil, CursorPage{}, err
+		}
+		songs = append(songs, i)
+		values = append(values, v)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, CursorPage{}, err
+	}
+
+	songs, page := paginate(k, songs, values, params.Limit)
+	return songs, page, nil
+}
+
+// scanSongListRow scans the columns of a listing row selected by songListColumns followed by extra columns
+func scanSongListRow(rows pgx.Row, i *database.GetSongsWithPaginationRow, extra ...any) error {
+	return rows.Scan(append([]any{
+		&i.ID,
+		&i.GroupID,
+		&i.Title,
+		&i.Runtime,
+		&i.Lyrics,
+		&i.ReleaseDate,
+		&i.Link,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.GroupName,
+		&i.GroupCreatedAt,
+		&i.GroupUpdatedAt,
+		&i.PlayCount,
+		&i.RatingAverage,
+		&i.RatingCount,
+		&i.ArtworkWidth,
+		&i.ArtworkHeight,
+		&i.ArtworkFormat,
+		&i.ArtworkUpdatedAt,
+		&i.GroupArtworkWidth,
+		&i.GroupArtworkHeight,
+		&i.GroupArtworkFormat,
+		&i.GroupArtworkUpdatedAt,
+	}, extra...)...)
+}
@@ -0,0 +1,53 @@
+package repository
+
+import (
+	"fmt"
+	"music-service/internal/pkg/utils/songquery"
+	"strings"
+)
+
+// songQueryColumns maps the number fields of search queries to their SQL expressions
+var songQueryColumns = map[string]string{
+	"year":    "EXTRACT(YEAR FROM s.release_date)",
+	"runtime": "s.runtime",
+	"rating":  "s.rating_average",
+}
+
+// songQueryCondition translates a parsed search query into a condition on the song_listings view.
+// Values only ever become positional arguments, operators and columns come from fixed lists.
+func songQueryCondition(b *queryBuilder, expr songquery.Expr) string {
+	switch e := expr.(type) {
+	case *songquery.And:
+		return joinSongQuery(b, e.Exprs, " AND ")
+	case *songquery.Or:
+		return joinSongQuery(b, e.Exprs, " OR ")
+	case *songquery.Not:
+		return "NOT (" + songQueryCondition(b, e.Expr) + ")"
+	case *songquery.Match:
+		switch e.Field {
+		case "", "title":
+			return b.bind("LOWER(s.title) LIKE '%' || LOWER(?::VARCHAR) || '%'", e.Value)
+		case "group":
+			return b.bind(`(LOWER(s.group_name) LIKE '%' || LOWER(?::VARCHAR) || '%'
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = s.group_id AND LOWER(a.name) LIKE '%' || LOWER(?::VARCHAR) || '%'))`,
+				e.Value, e.Value)
+		case "lyrics":
+			return b.bind("LOWER(s.lyrics->>'text') LIKE '%' || LOWER(?::VARCHAR) || '%'", e.Value)
+		case "tag":
+			return b.bind("EXISTS (SELECT 1 FROM song_tags t WHERE t.song_id = s.id AND t.tag = LOWER(?::VARCHAR))", e.Value)
+		}
+	case *songquery.Compare:
+		if column, ok := songQueryColumns[e.Field]; ok {
+			return b.bind(column+" "+e.Op+" ?::FLOAT8", e.Value)
+		}
+	}
+	panic(fmt.Sprintf("songquery: no translation for %T %+v", expr, expr))
+}
+
+func joinSongQuery(b *queryBuilder, exprs []songquery.Expr, separator string) string {
+	conditions := make([]string, 0, len(exprs))
+	for _, expr := range exprs {
+		conditions = append(conditions, songQueryCondition(b, expr))
+	}
+	return "(" + strings.Join(conditions, separator) + ")"
+}
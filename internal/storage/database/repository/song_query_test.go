@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"music-service/internal/pkg/utils/songquery"
+	"reflect"
+	"testing"
+)
+
+func TestSongQueryCondition(t *testing.T) {
+	const (
+		lyrics = "LOWER(s.lyrics->>'text') LIKE '%' || LOWER($1::VARCHAR) || '%'"
+		tag    = "EXISTS (SELECT 1 FROM song_tags t WHERE t.song_id = s.id AND t.tag = LOWER($1::VARCHAR))"
+		group  = `(LOWER(s.group_name) LIKE '%' || LOWER($1::VARCHAR) || '%'
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = s.group_id AND LOWER(a.name) LIKE '%' || LOWER($2::VARCHAR) || '%'))`
+	)
+	title := func(placeholder string) string {
+		return "LOWER(s.title) LIKE '%' || LOWER(" + placeholder + "::VARCHAR) || '%'"
+	}
+
+	tests := []struct {
+		name     string
+		query    string
+		wantSQL  string
+		wantArgs []any
+	}{
+		{"free text", "queen", title("$1"), []any{"queen"}},
+		{"title", `title:"we will"`, title("$1"), []any{"we will"}},
+		{"group and aliases", "group:queen", group, []any{"queen", "queen"}},
+		{"lyrics", "lyrics:love", lyrics, []any{"love"}},
+		{"tag", "tag:Rock", tag, []any{"Rock"}},
+		{"year", "year:1975", "EXTRACT(YEAR FROM s.release_date) = $1::FLOAT8", []any{1975.0}},
+		{"runtime", "runtime:<300", "s.runtime < $1::FLOAT8", []any{300.0}},
+		{"rating", "rating:>=4.5", "s.rating_average >= $1::FLOAT8", []any{4.5}},
+		{"range", "year:1975..1980",
+			"(EXTRACT(YEAR FROM s.release_date) >= $1::FLOAT8 AND EXTRACT(YEAR FROM s.release_date) <= $2::FLOAT8)",
+			[]any{1975.0, 1980.0}},
+		{"and", "a b", "(" + title("$1") + " AND " + title("$2") + ")", []any{"a", "b"}},
+		{"or", "a OR b", "(" + title("$1") + " OR " + title("$2") + ")", []any{"a", "b"}},
+		{"not", "-a", "NOT (" + title("$1") + ")", []any{"a"}},
+		{"precedence", "a OR -b c",
+			"(" + title("$1") + " OR (NOT (" + title("$2") + ") AND " + title("$3") + "))",
+			[]any{"a", "b", "c"}},
+		{"parentheses", "-(a OR b) runtime:>60",
+			"(NOT ((" + title("$1") + " OR " + title("$2") + ")) AND s.runtime > $3::FLOAT8)",
+			[]any{"a", "b", 60.0}},
+		{"values are never spliced into the SQL", `"'; DROP TABLE songs; --"`, title("$1"), []any{"'; DROP TABLE songs; --"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := songquery.Parse(tt.query)
+			if err != nil {
+				t.Fatalf("Parse(%q) error = %v", tt.query, err)
+			}
+
+			b := &queryBuilder{}
+			if got := songQueryCondition(b, expr); got != tt.wantSQL {
+				t.Errorf("condition = %q, want %q", got, tt.wantSQL)
+			}
+			if !reflect.DeepEqual(b.args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", b.args, tt.wantArgs)
+			}
+		})
+	}
+}
+
+// TestSongQueryConditionPlaceholders checks that the query continues the placeholders of the other filters
+func TestSongQueryConditionPlaceholders(t *testing.T) {
+	expr, err := songquery.Parse("a OR runtime:<300")
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	params := SongFilterParams{SongTitle: "love", Query: expr}
+	b := &queryBuilder{}
+	songListWhere(b, params)
+
+	want := "WHERE s.deleted_at IS NULL\n" +
+		"  AND s.group_deleted_at IS NULL\n" +
+		"  AND LOWER(s.title) LIKE LOWER('%' || $1::VARCHAR || '%')\n" +
+		"  AND (LOWER(s.title) LIKE '%' || LOWER($2::VARCHAR) || '%' OR s.runtime < $3::FLOAT8)"
+	if got := b.whereClause(); got != want {
+		t.Errorf("where = %q, want %q", got, want)
+	}
+	if wantArgs := []any{"love", "a", 300.0}; !reflect.DeepEqual(b.args, wantArgs) {
+		t.Errorf("args = %#v, want %#v", b.args, wantArgs)
+	}
+}
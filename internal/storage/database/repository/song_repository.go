@@ -4,20 +4,32 @@ import (
 	"context"
 	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/pkg/utils/songquery"
 	"music-service/internal/storage/database"
 	"time"
 )
 
 type SongRepositoryInterface interface {
-	CreateSong(ctx context.Context, params SongCreateParams) (database.Song, error)
+	CreateSong(ctx context.Context, params SongCreateParams) (database.GetSongsWithPaginationRow, error)
 	GetSong(ctx context.Context, id uuid.UUID) (database.Song, error)
+	GetSongWithGroup(ctx context.Context, id uuid.UUID, withLyrics bool) (database.GetSongsWithPaginationRow, error)
 	GetSongsCount(ctx context.Context) (int64, error)
 	GetSongsWithPagination(ctx context.Context, limit, offset int32) ([]database.GetSongsWithPaginationRow, error)
-	UpdateSong(ctx context.Context, params SongUpdateParams) (database.Song, error)
+	UpdateSong(ctx context.Context, params SongUpdateParams) (database.GetSongsWithPaginationRow, error)
+	PatchSong(ctx context.Context, params SongPatchParams) (database.GetSongsWithPaginationRow, error)
 	GetSongsByGroup(ctx context.Context, groupID uuid.UUID, limit, offset int32) ([]database.Song, error)
-	GetSongsWithFilters(ctx context.Context, params SongFilterParams) ([]database.GetSongsWithPaginationRow, error)
-	GetSongsCountWithFilters(ctx context.Context, groupName, songTitle string) (int64, error)
-	DeleteSong(ctx context.Context, id uuid.UUID) error
+	GetSongsWithFilters(ctx context.Context, params SongFilterParams) ([]database.GetSongsWithPaginationRow, int64, error)
+	GetSongsWithCursor(ctx context.Context, params SongFilterParams, cursor string) ([]database.GetSongsWithPaginationRow, CursorPage, error)
+	DeleteSong(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) (int64, error)
+	RestoreSong(ctx context.Context, id uuid.UUID) (database.GetSongsWithPaginationRow, error)
+	GetSongsByRules(ctx context.Context, rules SongRules) ([]RuledSongRow, error)
+	FindSongByGroupAndTitle(ctx context.Context, groupName, title string) (database.Song, error)
+	GetSongTags(ctx context.Context, songID uuid.UUID) ([]string, error)
+	ReplaceSongTags(ctx context.Context, songID uuid.UUID, tags []string) error
+	GetSongsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetSongsWithPaginationRow, error)
+	GetSongsLyricsAfter(ctx context.Context, afterID uuid.UUID, limit int32) ([]database.GetSongsLyricsAfterRow, error)
+	GetSongsForDuplicateScan(ctx context.Context) ([]database.GetSongsForDuplicateScanRow, error)
+	CopySongTags(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error)
 }
 
 type SongCreateParams struct {
@@ -37,30 +49,58 @@ type SongUpdateParams struct {
 	Lyrics      []byte
 	ReleaseDate time.Time
 	Link        string
+	IfUpdatedAt time.Time // zero to skip the version check
 }
 
+// SongPatchParams holds the fields of a partial update, nil fields are left unchanged
+type SongPatchParams struct {
+	ID          uuid.UUID
+	GroupID     *uuid.UUID
+	Title       *string
+	Runtime     *int32
+	Lyrics      []byte
+	ReleaseDate *time.Time
+	Link        *string
+	IfUpdatedAt time.Time // zero to skip the version check
+}
+
+// SongFilterParams selects and orders the songs of a listing, zero values do not filter
 type SongFilterParams struct {
-	Limit     int32
-	Offset    int32
-	GroupName string
-	SongTitle string
+	Limit           int32
+	Offset          int32 // ignored when paging with a cursor
+	GroupID         uuid.UUID
+	GroupName       string
+	SongTitle       string
+	MinRating       float64   // zero to include unrated songs
+	ReleaseDateFrom string    // DateFormat, inclusive
+	ReleaseDateTo   string    // DateFormat, inclusive
+	RuntimeMin      *int32    // seconds
+	RuntimeMax      *int32    // seconds
+	CreatedSince    time.Time // inclusive
+	UpdatedSince    time.Time // inclusive
+	Sort            []string  // SongListSortKeys field or -field for descending, newest first when empty
+	Query           songquery.Expr
+	WithLyrics      bool // lyrics are large and only read when set, Lyrics is nil otherwise
 }
 
 type SongRepository struct {
-	q *database.Queries
+	q  *database.Queries
+	db database.DBTX // for queries built at runtime
 }
 
 func NewSongRepository(db database.DBTX) SongRepositoryInterface {
 	return &SongRepository{
-		q: database.New(db),
+		q:  database.New(db),
+		db: db,
 	}
 }
 
-func (r *SongRepository) CreateSong(ctx context.Context, params SongCreateParams) (database.Song, error) {
+// CreateSong inserts a song and returns it like a listing row
+func (r *SongRepository) CreateSong(ctx context.Context, params SongCreateParams) (database.GetSongsWithPaginationRow, error) {
 	pgGroupID := pgtype.UUID{Bytes: params.GroupID, Valid: true}
 	pgReleaseDate := pgtype.Timestamptz{Time: params.ReleaseDate, Valid: true}
 
-	return r.q.CreateSong(ctx, database.CreateSongParams{
+	song, err := r.q.CreateSong(ctx, database.CreateSongParams{
 		GroupID:     pgGroupID,
 		Title:       params.Title,
 		Runtime:     params.Runtime,
@@ -68,7 +108,7 @@ func (r *SongRepository) CreateSong(ctx context.Context, params SongCreateParams
 		ReleaseDate: pgReleaseDate,
 		Link:        params.Link,
 	})
-
+	return r.listing(ctx, song, err)
 }
 
 func (r *SongRepository) GetSong(ctx context.Context, id uuid.UUID) (database.Song, error) {
@@ -76,6 +116,16 @@ func (r *SongRepository) GetSong(ctx context.Context, id uuid.UUID) (database.So
 	return r.q.GetSong(ctx, pgID)
 }
 
+// GetSongWithGroup returns a live song as listed by song_listings, with the columns of its group, its artworks,
+// play count and rating, Lyrics is nil unless withLyrics
+func (r *SongRepository) GetSongWithGroup(ctx context.Context, id uuid.UUID, withLyrics bool) (database.GetSongsWithPaginationRow, error) {
+	row, err := r.q.GetSongWithGroup(ctx, database.GetSongWithGroupParams{
+		WithLyrics: withLyrics,
+		ID:         pgtype.UUID{Bytes: id, Valid: true},
+	})
+	return database.GetSongsWithPaginationRow(row), err
+}
+
 func (r *SongRepository) GetSongsCount(ctx context.Context) (int64, error) {
 	return r.q.GetSongsCount(ctx)
 }
@@ -87,12 +137,13 @@ func (r *SongRepository) GetSongsWithPagination(ctx context.Context, limit, offs
 	})
 }
 
-func (r *SongRepository) UpdateSong(ctx context.Context, params SongUpdateParams) (database.Song, error) {
+// UpdateSong replaces the fields of a song and returns it like a listing row
+func (r *SongRepository) UpdateSong(ctx context.Context, params SongUpdateParams) (database.GetSongsWithPaginationRow, error) {
 	pgID := pgtype.UUID{Bytes: params.ID, Valid: true}
 	pgGroupID := pgtype.UUID{Bytes: params.GroupID, Valid: true}
 	pgReleaseDate := pgtype.Timestamptz{Time: params.ReleaseDate, Valid: true}
 
-	return r.q.UpdateSong(ctx, database.UpdateSongParams{
+	song, err := r.q.UpdateSong(ctx, database.UpdateSongParams{
 		ID:          pgID,
 		GroupID:     pgGroupID,
 		Title:       params.Title,
@@ -100,13 +151,60 @@ func (r *SongRepository) UpdateSong(ctx context.Context, params SongUpdateParams
 		Lyrics:      params.Lyrics,
 		ReleaseDate: pgReleaseDate,
 		Link:        params.Link,
+		IfUpdatedAt: toPgVersion(params.IfUpdatedAt),
 	})
+	return r.listing(ctx, song, err)
 }
 
-func (r *SongRepository) DeleteSong(ctx context.Context, id uuid.UUID) error {
-	pgID := pgtype.UUID{Bytes: id, Valid: true}
-	_, err := r.q.DeleteSong(ctx, pgID)
-	return err
+// DeleteSong soft-deletes a live song if it was last updated at ifUpdatedAt, a zero time skips the check
+func (r *SongRepository) DeleteSong(ctx context.Context, id uuid.UUID, ifUpdatedAt time.Time) (int64, error) {
+	result, err := r.q.DeleteSong(ctx, database.DeleteSongParams{
+		ID:          pgtype.UUID{Bytes: id, Valid: true},
+		IfUpdatedAt: toPgVersion(ifUpdatedAt),
+	})
+	return result.RowsAffected(), err
+}
+
+// PatchSong updates the supplied fields of a live song and returns it like a listing row
+func (r *SongRepository) PatchSong(ctx context.Context, params SongPatchParams) (database.GetSongsWithPaginationRow, error) {
+	arg := database.PatchSongParams{
+		ID:          pgtype.UUID{Bytes: params.ID, Valid: true},
+		Lyrics:      params.Lyrics,
+		IfUpdatedAt: toPgVersion(params.IfUpdatedAt),
+	}
+	if params.GroupID != nil {
+		arg.GroupID = pgtype.UUID{Bytes: *params.GroupID, Valid: true}
+	}
+	if params.Title != nil {
+		arg.Title = pgtype.Text{String: *params.Title, Valid: true}
+	}
+	if params.Runtime != nil {
+		arg.Runtime = pgtype.Int4{Int32: *params.Runtime, Valid: true}
+	}
+	if params.ReleaseDate != nil {
+		arg.ReleaseDate = pgtype.Timestamptz{Time: *params.ReleaseDate, Valid: true}
+	}
+	if params.Link != nil {
+		arg.Link = pgtype.Text{String: *params.Link, Valid: true}
+	}
+	song, err := r.q.PatchSong(ctx, arg)
+	return r.listing(ctx, song, err)
+}
+
+// RestoreSong undeletes a song, forgetting that it was deleted along with its group, and returns it like a listing row
+func (r *SongRepository) RestoreSong(ctx context.Context, id uuid.UUID) (database.GetSongsWithPaginationRow, error) {
+	song, err := r.q.RestoreSong(ctx, pgtype.UUID{Bytes: id, Valid: true})
+	return r.listing(ctx, song, err)
+}
+
+// listing reads a song that was just written back from song_listings with its lyrics. A statement that writes
+// a song cannot read its own write from the view, so this takes a second query.
+func (r *SongRepository) listing(ctx context.Context, song database.Song, err error) (database.GetSongsWithPaginationRow, error) {
+	if err != nil {
+		return database.GetSongsWithPaginationRow{}, err
+	}
+	row, err := r.q.GetSongWithGroup(ctx, database.GetSongWithGroupParams{WithLyrics: true, ID: song.ID})
+	return database.GetSongsWithPaginationRow(row), err
 }
 
 func (r *SongRepository) GetSongsByGroup(ctx context.Context, groupID uuid.UUID, limit, offset int32) ([]database.Song, error) {
@@ -118,27 +216,50 @@ func (r *SongRepository) GetSongsByGroup(ctx context.Context, groupID uuid.UUID,
 	})
 }
 
-func (r *SongRepository) GetSongsWithFilters(ctx context.Context, params SongFilterParams) ([]database.GetSongsWithPaginationRow, error) {
-	rows, err := r.q.GetSongsWithFilters(ctx, database.GetSongsWithFiltersParams{
-		Limit:   params.Limit,
-		Offset:  params.Offset,
-		Column3: params.GroupName,
-		Column4: params.SongTitle,
+// FindSongByGroupAndTitle looks up a live song by exact group name and title ignoring case
+func (r *SongRepository) FindSongByGroupAndTitle(ctx context.Context, groupName, title string) (database.Song, error) {
+	return r.q.FindSongByGroupAndTitle(ctx, database.FindSongByGroupAndTitleParams{
+		GroupName: groupName,
+		Title:     title,
 	})
+}
+
+// GetSongsByIDs returns the live songs among the given IDs, in no particular order
+func (r *SongRepository) GetSongsByIDs(ctx context.Context, ids []uuid.UUID) ([]database.GetSongsWithPaginationRow, error) {
+	pgIDs := make([]pgtype.UUID, 0, len(ids))
+	for _, id := range ids {
+		pgIDs = append(pgIDs, pgtype.UUID{Bytes: id, Valid: true})
+	}
+
+	rows, err := r.q.GetSongsByIDs(ctx, pgIDs)
 	if err != nil {
 		return nil, err
 	}
 
-	var songs []database.GetSongsWithPaginationRow
+	songs := make([]database.GetSongsWithPaginationRow, 0, len(rows))
 	for _, row := range rows {
 		songs = append(songs, database.GetSongsWithPaginationRow(row))
 	}
 	return songs, nil
 }
 
-func (r *SongRepository) GetSongsCountWithFilters(ctx context.Context, groupName, songTitle string) (int64, error) {
-	return r.q.GetSongsCountWithFilters(ctx, database.GetSongsCountWithFiltersParams{
-		GroupName: groupName,
-		SongTitle: songTitle,
+// GetSongsLyricsAfter pages through the lyrics of live songs in ID order, starting after afterID
+func (r *SongRepository) GetSongsLyricsAfter(ctx context.Context, afterID uuid.UUID, limit int32) ([]database.GetSongsLyricsAfterRow, error) {
+	return r.q.GetSongsLyricsAfter(ctx, database.GetSongsLyricsAfterParams{
+		AfterID:    pgtype.UUID{Bytes: afterID, Valid: true},
+		LimitCount: limit,
+	})
+}
+
+// GetSongsForDuplicateScan returns the fields of every live song the duplicate finder compares
+func (r *SongRepository) GetSongsForDuplicateScan(ctx context.Context) ([]database.GetSongsForDuplicateScanRow, error) {
+	return r.q.GetSongsForDuplicateScan(ctx)
+}
+
+// CopySongTags adds the tags of one song to another and returns how many were new
+func (r *SongRepository) CopySongTags(ctx context.Context, fromSongID, toSongID uuid.UUID) (int64, error) {
+	return r.q.CopySongTags(ctx, database.CopySongTagsParams{
+		ToSongID:   pgtype.UUID{Bytes: toSongID, Valid: true},
+		FromSongID: pgtype.UUID{Bytes: fromSongID, Valid: true},
 	})
 }
@@ -0,0 +1,154 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"strings"
+)
+
+const (
+	TagsMatchAny = "any"
+	TagsMatchAll = "all"
+)
+
+// SongRules is a declarative song selection, stored as JSON for smart playlists
+// and translated into a single parameterised query on read.
+type SongRules struct {
+	GroupName       string   `json:"group_name,omitempty"`
+	Title           string   `json:"title,omitempty"`
+	ReleaseDateFrom string   `json:"release_date_from,omitempty"` // DateFormat, inclusive
+	ReleaseDateTo   string   `json:"release_date_to,omitempty"`   // DateFormat, inclusive
+	RuntimeMin      *int32   `json:"runtime_min,omitempty"`
+	RuntimeMax      *int32   `json:"runtime_max,omitempty"`
+	Tags            []string `json:"tags,omitempty"`
+	TagsMatch       string   `json:"tags_match,omitempty"` // any (default) or all
+	Sort            []string `json:"sort,omitempty"`       // field or -field for descending
+	Limit           int32    `json:"limit,omitempty"`
+}
+
+// SongSortColumns maps the sort fields accepted from clients to their SQL expressions
+var SongSortColumns = map[string]string{
+	"title":        "s.title",
+	"release_date": "s.release_date",
+	"runtime":      "s.runtime",
+	"created_at":   "s.created_at",
+	"group":        "g.name",
+}
+
+// RuledSongRow is a song selected by rules together with its group name, lyrics are not loaded
+type RuledSongRow struct {
+	ID          pgtype.UUID
+	GroupID     pgtype.UUID
+	GroupName   string
+	Title       string
+	Runtime     int32
+	ReleaseDate pgtype.Timestamptz
+	Link        string
+	CreatedAt   pgtype.Timestamptz
+	UpdatedAt   pgtype.Timestamptz
+}
+
+// GetSongsByRules evaluates the rules into SQL, rules are expected to be validated by the caller
+func (r *SongRepository) GetSongsByRules(ctx context.Context, rules SongRules) ([]RuledSongRow, error) {
+	query, args := songRulesQuery(rules)
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return pgx.CollectRows(rows, func(row pgx.CollectableRow) (RuledSongRow, error) {
+		var i RuledSongRow
+		err := row.Scan(
+			&i.ID,
+			&i.GroupID,
+			&i.GroupName,
+			&i.Title,
+			&i.Runtime,
+			&i.ReleaseDate,
+			&i.Link,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		)
+		return i, err
+	})
+}
+
+// songRulesQuery translates the rules into a query and its arguments
+func songRulesQuery(rules SongRules) (string, []any) {
+	b := &queryBuilder{}
+	b.where("s.deleted_at IS NULL")
+
+	if rules.GroupName != "" {
+		b.where(`(LOWER(g.name) LIKE LOWER('%' || ?::VARCHAR || '%')
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = g.id AND LOWER(a.name) LIKE LOWER('%' || ?::VARCHAR || '%')))`,
+			rules.GroupName, rules.GroupName)
+	}
+	if rules.Title != "" {
+		b.where("LOWER(s.title) LIKE LOWER('%' || ?::VARCHAR || '%')", rules.Title)
+	}
+	if rules.ReleaseDateFrom != "" {
+		b.where("s.release_date >= ?::DATE", rules.ReleaseDateFrom)
+	}
+	if rules.ReleaseDateTo != "" {
+		b.where("s.release_date < ?::DATE + 1", rules.ReleaseDateTo)
+	}
+	if rules.RuntimeMin != nil {
+		b.where("s.runtime >= ?", *rules.RuntimeMin)
+	}
+	if rules.RuntimeMax != nil {
+		b.where("s.runtime <= ?", *rules.RuntimeMax)
+	}
+	if len(rules.Tags) > 0 {
+		if rules.TagsMatch == TagsMatchAll {
+			b.where("(SELECT count(*) FROM song_tags t WHERE t.song_id = s.id AND t.tag = ANY(?::VARCHAR[])) = ?",
+				rules.Tags, len(rules.Tags))
+		} else {
+			b.where("EXISTS (SELECT 1 FROM song_tags t WHERE t.song_id = s.id AND t.tag = ANY(?::VARCHAR[]))", rules.Tags)
+		}
+	}
+
+	query := fmt.Sprintf(`SELECT s.id, s.group_id, g.name, s.title, s.runtime, s.release_date, s.link, s.created_at, s.updated_at
+FROM songs s
+         JOIN groups g ON s.group_id = g.id
+%s
+ORDER BY %s
+    LIMIT %s`, b.whereClause(), songOrderBy(rules.Sort), b.arg(rules.Limit))
+	return query, b.args
+}
+
+// songOrderBy builds an ORDER BY list from whitelisted sort fields, s.id keeps the order stable
+func songOrderBy(sort []string) string {
+	var keys []string
+	for _, field := range sort {
+		direction := "ASC"
+		if strings.HasPrefix(field, "-") {
+			direction = "DESC"
+			field = field[1:]
+		}
+		if column, ok := SongSortColumns[field]; ok {
+			keys = append(keys, column+" "+direction)
+		}
+	}
+	if len(keys) == 0 {
+		keys = append(keys, "s.created_at DESC")
+	}
+	return strings.Join(append(keys, "s.id"), ", ")
+}
+
+func (r *SongRepository) GetSongTags(ctx context.Context, songID uuid.UUID) ([]string, error) {
+	pgSongID := pgtype.UUID{Bytes: songID, Valid: true}
+	return r.q.GetSongTags(ctx, pgSongID)
+}
+
+// ReplaceSongTags sets the tags of a song to exactly the given list in a single statement
+func (r *SongRepository) ReplaceSongTags(ctx context.Context, songID uuid.UUID, tags []string) error {
+	pgSongID := pgtype.UUID{Bytes: songID, Valid: true}
+	return r.q.ReplaceSongTags(ctx, database.ReplaceSongTagsParams{
+		SongID: pgSongID,
+		Tags:   tags,
+	})
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSongRulesQuery(t *testing.T) {
+	runtime := func(v int32) *int32 { return &v }
+
+	tests := []struct {
+		name      string
+		rules     SongRules
+		wantWhere []string
+		wantOrder string
+		wantArgs  []any
+	}{
+		{
+			name:      "no rules select every live song",
+			rules:     SongRules{Limit: 100},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{int32(100)},
+		},
+		{
+			name:  "group name or alias and title",
+			rules: SongRules{GroupName: "queen", Title: "rhapsody", Limit: 10},
+			wantWhere: []string{
+				`(LOWER(g.name) LIKE LOWER('%' || $1::VARCHAR || '%')
+       OR EXISTS (SELECT 1 FROM group_aliases a WHERE a.group_id = g.id AND LOWER(a.name) LIKE LOWER('%' || $2::VARCHAR || '%')))`,
+				"LOWER(s.title) LIKE LOWER('%' || $3::VARCHAR || '%')",
+			},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{"queen", "queen", "rhapsody", int32(10)},
+		},
+		{
+			name:  "inclusive release date range",
+			rules: SongRules{ReleaseDateFrom: "1975-01-01", ReleaseDateTo: "1975-12-31", Limit: 10},
+			wantWhere: []string{
+				"s.release_date >= $1::DATE",
+				"s.release_date < $2::DATE + 1",
+			},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{"1975-01-01", "1975-12-31", int32(10)},
+		},
+		{
+			name:      "runtime range",
+			rules:     SongRules{RuntimeMin: runtime(120), RuntimeMax: runtime(300), Limit: 10},
+			wantWhere: []string{"s.runtime >= $1", "s.runtime <= $2"},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{int32(120), int32(300), int32(10)},
+		},
+		{
+			name:      "any tag",
+			rules:     SongRules{Tags: []string{"rock", "live"}, TagsMatch: TagsMatchAny, Limit: 10},
+			wantWhere: []string{"EXISTS (SELECT 1 FROM song_tags t WHERE t.song_id = s.id AND t.tag = ANY($1::VARCHAR[]))"},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{[]string{"rock", "live"}, int32(10)},
+		},
+		{
+			name:      "every tag",
+			rules:     SongRules{Tags: []string{"rock", "live"}, TagsMatch: TagsMatchAll, Limit: 10},
+			wantWhere: []string{"(SELECT count(*) FROM song_tags t WHERE t.song_id = s.id AND t.tag = ANY($1::VARCHAR[])) = $2"},
+			wantOrder: "s.created_at DESC, s.id",
+			wantArgs:  []any{[]string{"rock", "live"}, 2, int32(10)},
+		},
+		{
+			name:      "sort keys in order",
+			rules:     SongRules{Sort: []string{"group", "-release_date"}, Limit: 10},
+			wantOrder: "g.name ASC, s.release_date DESC, s.id",
+			wantArgs:  []any{int32(10)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			query, args := songRulesQuery(tt.rules)
+
+			where := "WHERE " + strings.Join(append([]string{"s.deleted_at IS NULL"}, tt.wantWhere...), "\n  AND ")
+			if !strings.Contains(query, "\n"+where+"\n") {
+				t.Errorf("query = %q, want condition %q", query, where)
+			}
+			if order := "ORDER BY " + tt.wantOrder + "\n"; !strings.Contains(query, order) {
+				t.Errorf("query = %q, want %q", query, order)
+			}
+			if limit := fmt.Sprintf("LIMIT $%d", len(tt.wantArgs)); !strings.HasSuffix(query, limit) {
+				t.Errorf("query = %q, want it to end with %q", query, limit)
+			}
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %#v, want %#v", args, tt.wantArgs)
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"time"
+)
+
+const (
+	TrashEntityGroup = "group"
+	TrashEntitySong  = "song"
+)
+
+// TrashRepositoryInterface lists soft-deleted groups and songs and deletes them for good
+type TrashRepositoryInterface interface {
+	GetTrashWithPagination(ctx context.Context, entityType string, limit, offset int32) ([]database.GetTrashWithPaginationRow, error)
+	GetTrashCount(ctx context.Context, entityType string) (int64, error)
+	GetPurgeableGroupIDs(ctx context.Context, cutoff time.Time, limit int32) ([]uuid.UUID, error)
+	GetPurgeableSongIDs(ctx context.Context, cutoff time.Time, limit int32) ([]uuid.UUID, error)
+	GetGroupsSongIDsForUpdate(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error)
+	PurgeSongs(ctx context.Context, ids []uuid.UUID) (int64, error)
+	PurgeGroups(ctx context.Context, ids []uuid.UUID) (int64, error)
+}
+
+type TrashRepository struct {
+	q *database.Queries
+}
+
+func NewTrashRepository(db database.DBTX) TrashRepositoryInterface {
+	return &TrashRepository{
+		q: database.New(db),
+	}
+}
+
+// GetTrashWithPagination returns deleted groups and songs, most recently deleted first.
+// An empty entity type lists both.
+func (r *TrashRepository) GetTrashWithPagination(ctx context.Context, entityType string, limit, offset int32) ([]database.GetTrashWithPaginationRow, error) {
+	return r.q.GetTrashWithPagination(ctx, database.GetTrashWithPaginationParams{
+		EntityType:  entityType,
+		LimitCount:  limit,
+		OffsetCount: offset,
+	})
+}
+
+func (r *TrashRepository) GetTrashCount(ctx context.Context, entityType string) (int64, error) {
+	return r.q.GetTrashCount(ctx, entityType)
+}
+
+// GetPurgeableGroupIDs locks and returns groups deleted before the cutoff that have no live songs left
+func (r *TrashRepository) GetPurgeableGroupIDs(ctx context.Context, cutoff time.Time, limit int32) ([]uuid.UUID, error) {
+	rows, err := r.q.GetPurgeableGroupIDs(ctx, database.GetPurgeableGroupIDsParams{
+		Cutoff:     pgtype.Timestamptz{Time: cutoff, Valid: true},
+		LimitCount: limit,
+	})
+	return fromPgUUIDs(rows), err
+}
+
+// GetPurgeableSongIDs locks and returns songs deleted before the cutoff
+func (r *TrashRepository) GetPurgeableSongIDs(ctx context.Context, cutoff time.Time, limit int32) ([]uuid.UUID, error) {
+	rows, err := r.q.GetPurgeableSongIDs(ctx, database.GetPurgeableSongIDsParams{
+		Cutoff:     pgtype.Timestamptz{Time: cutoff, Valid: true},
+		LimitCount: limit,
+	})
+	return fromPgUUIDs(rows), err
+}
+
+// GetGroupsSongIDsForUpdate locks and returns every song of the groups, deleted or not
+func (r *TrashRepository) GetGroupsSongIDsForUpdate(ctx context.Context, groupIDs []uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := r.q.GetGroupsSongIDsForUpdate(ctx, toPgUUIDs(groupIDs))
+	return fromPgUUIDs(rows), err
+}
+
+// PurgeSongs permanently deletes soft-deleted songs, their playlist entries, tags, plays and ratings go with them
+func (r *TrashRepository) PurgeSongs(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return r.q.PurgeSongs(ctx, toPgUUIDs(ids))
+}
+
+// PurgeGroups permanently deletes soft-deleted groups and their aliases
+func (r *TrashRepository) PurgeGroups(ctx context.Context, ids []uuid.UUID) (int64, error) {
+	return r.q.PurgeGroups(ctx, toPgUUIDs(ids))
+}
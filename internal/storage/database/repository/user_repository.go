@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgtype"
+	"music-service/internal/storage/database"
+	"time"
+)
+
+type UserRepositoryInterface interface {
+	CreateUser(ctx context.Context, username, passwordHash, role string) (database.User, error)
+	GetUser(ctx context.Context, id uuid.UUID) (database.User, error)
+	GetUserByUsername(ctx context.Context, username string) (database.User, error)
+	GetUsersWithPagination(ctx context.Context, params UserFilterParams) ([]database.User, error)
+	GetUsersCount(ctx context.Context, role string) (int64, error)
+	UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (database.User, error)
+	LockAdmins(ctx context.Context) ([]uuid.UUID, error)
+	CreateApiKey(ctx context.Context, params ApiKeyCreateParams) (database.ApiKey, error)
+	GetApiKeysByUser(ctx context.Context, userID uuid.UUID) ([]database.ApiKey, error)
+	GetActiveApiKeyByHash(ctx context.Context, keyHash string) (database.ApiKey, error)
+	TouchApiKey(ctx context.Context, id uuid.UUID) error
+	RevokeApiKey(ctx context.Context, id, userID uuid.UUID) (bool, error)
+}
+
+type UserFilterParams struct {
+	Role   string // empty for every role
+	Limit  int32
+	Offset int32
+}
+
+type ApiKeyCreateParams struct {
+	UserID    uuid.UUID
+	Name      string
+	Prefix    string
+	KeyHash   string
+	ExpiresAt *time.Time // nil for keys that never expire
+}
+
+type UserRepository struct {
+	q *database.Queries
+}
+
+func NewUserRepository(db database.DBTX) UserRepositoryInterface {
+	return &UserRepository{
+		q: database.New(db),
+	}
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, username, passwordHash, role string) (database.User, error) {
+	return r.q.CreateUser(ctx, database.CreateUserParams{
+		Username:     username,
+		PasswordHash: passwordHash,
+		Role:         role,
+	})
+}
+
+func (r *UserRepository) GetUser(ctx context.Context, id uuid.UUID) (database.User, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	return r.q.GetUser(ctx, pgID)
+}
+
+func (r *UserRepository) GetUserByUsername(ctx context.Context, username string) (database.User, error) {
+	return r.q.GetUserByUsername(ctx, username)
+}
+
+func (r *UserRepository) GetUsersWithPagination(ctx context.Context, params UserFilterParams) ([]database.User, error) {
+	return r.q.GetUsersWithPagination(ctx, database.GetUsersWithPaginationParams{
+		Role:        params.Role,
+		LimitCount:  params.Limit,
+		OffsetCount: params.Offset,
+	})
+}
+
+func (r *UserRepository) GetUsersCount(ctx context.Context, role string) (int64, error) {
+	return r.q.GetUsersCount(ctx, role)
+}
+
+func (r *UserRepository) UpdateUserRole(ctx context.Context, id uuid.UUID, role string) (database.User, error) {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	return r.q.UpdateUserRole(ctx, database.UpdateUserRoleParams{
+		ID:   pgID,
+		Role: role,
+	})
+}
+
+// LockAdmins locks the rows of all admins until the end of the transaction and returns their IDs
+func (r *UserRepository) LockAdmins(ctx context.Context) ([]uuid.UUID, error) {
+	ids, err := r.q.LockAdmins(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return fromPgUUIDs(ids), nil
+}
+
+func (r *UserRepository) CreateApiKey(ctx context.Context, params ApiKeyCreateParams) (database.ApiKey, error) {
+	var expiresAt pgtype.Timestamptz
+	if params.ExpiresAt != nil {
+		expiresAt = pgtype.Timestamptz{Time: *params.ExpiresAt, Valid: true}
+	}
+
+	return r.q.CreateApiKey(ctx, database.CreateApiKeyParams{
+		UserID:    pgtype.UUID{Bytes: params.UserID, Valid: true},
+		Name:      params.Name,
+		Prefix:    params.Prefix,
+		KeyHash:   params.KeyHash,
+		ExpiresAt: expiresAt,
+	})
+}
+
+func (r *UserRepository) GetApiKeysByUser(ctx context.Context, userID uuid.UUID) ([]database.ApiKey, error) {
+	pgID := pgtype.UUID{Bytes: userID, Valid: true}
+	return r.q.GetApiKeysByUser(ctx, pgID)
+}
+
+func (r *UserRepository) GetActiveApiKeyByHash(ctx context.Context, keyHash string) (database.ApiKey, error) {
+	return r.q.GetActiveApiKeyByHash(ctx, keyHash)
+}
+
+func (r *UserRepository) TouchApiKey(ctx context.Context, id uuid.UUID) error {
+	pgID := pgtype.UUID{Bytes: id, Valid: true}
+	return r.q.TouchApiKey(ctx, pgID)
+}
+
+func (r *UserRepository) RevokeApiKey(ctx context.Context, id, userID uuid.UUID) (bool, error) {
+	result, err := r.q.RevokeApiKey(ctx, database.RevokeApiKeyParams{
+		ID:     pgtype.UUID{Bytes: id, Valid: true},
+		UserID: pgtype.UUID{Bytes: userID, Valid: true},
+	})
+	if err != nil {
+		return false, err
+	}
+	return result.RowsAffected() > 0, nil
+}
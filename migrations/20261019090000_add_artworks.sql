@@ -0,0 +1,14 @@
+-- Create "artworks" table
+CREATE TABLE "artworks" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "entity_type" character varying(16) NOT NULL,
+  "entity_id" uuid NOT NULL,
+  "width" integer NOT NULL,
+  "height" integer NOT NULL,
+  "format" character varying(16) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uq_artworks_entity" UNIQUE ("entity_type", "entity_id"),
+  CONSTRAINT "check_artworks_entity_type" CHECK ((entity_type)::text = ANY ((ARRAY['song'::character varying, 'group'::character varying])::text[]))
+);
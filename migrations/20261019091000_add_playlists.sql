@@ -0,0 +1,32 @@
+-- Create "playlists" table
+CREATE TABLE "playlists" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "name" character varying(255) NOT NULL,
+  "description" text NOT NULL DEFAULT '',
+  "owner" character varying(255) NOT NULL,
+  "visibility" character varying(16) NOT NULL DEFAULT 'private',
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "check_playlists_visibility" CHECK ((visibility)::text = ANY ((ARRAY['public'::character varying, 'unlisted'::character varying, 'private'::character varying])::text[]))
+);
+-- Create index "idx_playlists_deleted_at" to table: "playlists"
+CREATE INDEX "idx_playlists_deleted_at" ON "playlists" ("deleted_at") WHERE (deleted_at IS NOT NULL);
+-- Create index "idx_playlists_owner" to table: "playlists"
+CREATE INDEX "idx_playlists_owner" ON "playlists" ("owner");
+-- Create "playlist_entries" table
+CREATE TABLE "playlist_entries" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "playlist_id" uuid NOT NULL,
+  "song_id" uuid NOT NULL,
+  "position" integer NOT NULL,
+  "added_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "uq_playlist_entries_position" UNIQUE ("playlist_id", "position") DEFERRABLE INITIALLY DEFERRED,
+  CONSTRAINT "fk_playlist_entries_playlist" FOREIGN KEY ("playlist_id") REFERENCES "playlists" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_playlist_entries_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "check_playlist_entries_position_positive" CHECK ("position" > 0)
+);
+-- Create index "idx_playlist_entries_song_id" to table: "playlist_entries"
+CREATE INDEX "idx_playlist_entries_song_id" ON "playlist_entries" ("song_id");
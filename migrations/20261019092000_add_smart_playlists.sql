@@ -0,0 +1,27 @@
+-- Create "song_tags" table
+CREATE TABLE "song_tags" (
+  "song_id" uuid NOT NULL,
+  "tag" character varying(64) NOT NULL,
+  PRIMARY KEY ("song_id", "tag"),
+  CONSTRAINT "fk_song_tags_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_song_tags_tag" to table: "song_tags"
+CREATE INDEX "idx_song_tags_tag" ON "song_tags" ("tag");
+-- Create "smart_playlists" table
+CREATE TABLE "smart_playlists" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "name" character varying(255) NOT NULL,
+  "description" text NOT NULL DEFAULT '',
+  "owner" character varying(255) NOT NULL,
+  "visibility" character varying(16) NOT NULL DEFAULT 'private',
+  "rules" jsonb NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  "deleted_at" timestamptz NULL,
+  PRIMARY KEY ("id"),
+  CONSTRAINT "check_smart_playlists_visibility" CHECK ((visibility)::text = ANY ((ARRAY['public'::character varying, 'unlisted'::character varying, 'private'::character varying])::text[]))
+);
+-- Create index "idx_smart_playlists_deleted_at" to table: "smart_playlists"
+CREATE INDEX "idx_smart_playlists_deleted_at" ON "smart_playlists" ("deleted_at") WHERE (deleted_at IS NOT NULL);
+-- Create index "idx_smart_playlists_owner" to table: "smart_playlists"
+CREATE INDEX "idx_smart_playlists_owner" ON "smart_playlists" ("owner");
@@ -0,0 +1,4 @@
+-- Modify "users" table
+ALTER TABLE "users" ADD COLUMN "role" character varying(16) NOT NULL DEFAULT 'viewer', ADD CONSTRAINT "check_users_role" CHECK ((role)::text = ANY ((ARRAY['viewer'::character varying, 'editor'::character varying, 'admin'::character varying])::text[]));
+-- Promote the earliest user so existing installations keep an admin
+UPDATE "users" SET "role" = 'admin' WHERE "id" = (SELECT "id" FROM "users" ORDER BY "created_at" LIMIT 1);
@@ -0,0 +1,20 @@
+-- Playlists were owned by a free-text name, link them to the user of that name or else to the earliest admin.
+-- Without any user there is nobody who could own them and they are removed.
+ALTER TABLE "playlists" ADD COLUMN "owner_id" uuid NULL;
+UPDATE "playlists" p SET "owner_id" = COALESCE(
+    (SELECT u."id" FROM "users" u WHERE u."username" = lower(btrim(p."owner"))),
+    (SELECT u."id" FROM "users" u WHERE u."role" = 'admin' ORDER BY u."created_at", u."id" LIMIT 1));
+DELETE FROM "playlists" WHERE "owner_id" IS NULL;
+ALTER TABLE "smart_playlists" ADD COLUMN "owner_id" uuid NULL;
+UPDATE "smart_playlists" p SET "owner_id" = COALESCE(
+    (SELECT u."id" FROM "users" u WHERE u."username" = lower(btrim(p."owner"))),
+    (SELECT u."id" FROM "users" u WHERE u."role" = 'admin' ORDER BY u."created_at", u."id" LIMIT 1));
+DELETE FROM "smart_playlists" WHERE "owner_id" IS NULL;
+-- Modify "playlists" table
+ALTER TABLE "playlists" DROP COLUMN "owner", ALTER COLUMN "owner_id" SET NOT NULL, ADD CONSTRAINT "fk_playlists_owner" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+-- Create index "idx_playlists_owner_id" to table: "playlists"
+CREATE INDEX "idx_playlists_owner_id" ON "playlists" ("owner_id");
+-- Modify "smart_playlists" table
+ALTER TABLE "smart_playlists" DROP COLUMN "owner", ALTER COLUMN "owner_id" SET NOT NULL, ADD CONSTRAINT "fk_smart_playlists_owner" FOREIGN KEY ("owner_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE;
+-- Create index "idx_smart_playlists_owner_id" to table: "smart_playlists"
+CREATE INDEX "idx_smart_playlists_owner_id" ON "smart_playlists" ("owner_id");
@@ -0,0 +1,30 @@
+-- Create "favorites" table
+CREATE TABLE "favorites" (
+  "user_id" uuid NOT NULL,
+  "entity_type" character varying(16) NOT NULL,
+  "entity_id" uuid NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("user_id", "entity_type", "entity_id"),
+  CONSTRAINT "fk_favorites_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "check_favorites_entity_type" CHECK ((entity_type)::text = ANY ((ARRAY['song'::character varying, 'group'::character varying])::text[]))
+);
+-- Create index "idx_favorites_user_created_at" to table: "favorites"
+CREATE INDEX "idx_favorites_user_created_at" ON "favorites" ("user_id", "created_at" DESC);
+-- Create "play_events" table
+CREATE TABLE "play_events" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "user_id" uuid NOT NULL,
+  "song_id" uuid NOT NULL,
+  "played_at" timestamptz NOT NULL,
+  "duration_played" integer NOT NULL,
+  "client" character varying(64) NOT NULL DEFAULT '',
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_play_events_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_play_events_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "check_play_events_duration_played" CHECK (duration_played >= 0)
+);
+-- Create index "idx_play_events_song_id" to table: "play_events"
+CREATE INDEX "idx_play_events_song_id" ON "play_events" ("song_id");
+-- Create index "idx_play_events_user_played_at" to table: "play_events"
+CREATE INDEX "idx_play_events_user_played_at" ON "play_events" ("user_id", "played_at" DESC);
@@ -0,0 +1,26 @@
+-- Create "song_ratings" table
+CREATE TABLE "song_ratings" (
+  "user_id" uuid NOT NULL,
+  "song_id" uuid NOT NULL,
+  "rating" integer NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("user_id", "song_id"),
+  CONSTRAINT "fk_song_ratings_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_song_ratings_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "check_song_ratings_rating" CHECK ((rating >= 1) AND (rating <= 5))
+);
+-- Create index "idx_song_ratings_song_id" to table: "song_ratings"
+CREATE INDEX "idx_song_ratings_song_id" ON "song_ratings" ("song_id");
+-- Create "song_rating_stats" table
+CREATE TABLE "song_rating_stats" (
+  "song_id" uuid NOT NULL,
+  "rating_count" integer NOT NULL DEFAULT 0,
+  "rating_sum" integer NOT NULL DEFAULT 0,
+  "rating_average" double precision NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("song_id"),
+  CONSTRAINT "fk_song_rating_stats_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_song_rating_stats_rating_average" to table: "song_rating_stats"
+CREATE INDEX "idx_song_rating_stats_rating_average" ON "song_rating_stats" ("rating_average" DESC);
@@ -0,0 +1,13 @@
+-- Create "group_aliases" table
+CREATE TABLE "group_aliases" (
+  "id" uuid NOT NULL DEFAULT gen_random_uuid(),
+  "group_id" uuid NOT NULL,
+  "name" character varying(255) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("id"),
+  CONSTRAINT "fk_group_aliases_group" FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "uq_group_aliases_group_name" to table: "group_aliases"
+CREATE UNIQUE INDEX "uq_group_aliases_group_name" ON "group_aliases" ("group_id", (lower((name)::text)));
+-- Create index "idx_group_aliases_name" to table: "group_aliases"
+CREATE INDEX "idx_group_aliases_name" ON "group_aliases" ((lower((name)::text)));
@@ -0,0 +1,4 @@
+-- Modify "group_aliases" table
+ALTER TABLE "group_aliases" ADD COLUMN "locale" character varying(35) NOT NULL DEFAULT '', ADD COLUMN "type" character varying(16) NOT NULL DEFAULT 'search', ADD CONSTRAINT "check_group_aliases_type" CHECK ((type)::text = ANY ((ARRAY['legal'::character varying, 'stage'::character varying, 'former'::character varying, 'search'::character varying])::text[]));
+-- Aliases so far were only recorded by group merges, they are former names
+UPDATE "group_aliases" SET "type" = 'former';
@@ -0,0 +1,31 @@
+-- Merge live groups whose names only differ in case or surrounding whitespace, otherwise the unique index below
+-- cannot be created. The oldest group with artwork is kept, or else the oldest one, since artwork files are stored
+-- under the ID of their group and cannot be moved here. Artwork of the other duplicates stays with them in the trash.
+CREATE TEMPORARY TABLE "duplicate_groups" AS
+SELECT "id", "name", "keep_id"
+FROM (SELECT g."id", g."name", first_value(g."id") OVER (PARTITION BY lower(btrim(g."name")) ORDER BY a."id" IS NULL, g."created_at", g."id") AS "keep_id"
+      FROM "groups" g
+      LEFT JOIN "artworks" a ON a."entity_type" = 'group' AND a."entity_id" = g."id"
+      WHERE g."deleted_at" IS NULL) AS "ranked"
+WHERE "id" <> "keep_id";
+UPDATE "songs" SET "group_id" = d."keep_id" FROM "duplicate_groups" d WHERE "songs"."group_id" = d."id" AND "songs"."deleted_at" IS NULL;
+INSERT INTO "favorites" ("user_id", "entity_type", "entity_id", "created_at")
+SELECT f."user_id", f."entity_type", d."keep_id", f."created_at"
+FROM "favorites" f JOIN "duplicate_groups" d ON f."entity_type" = 'group' AND f."entity_id" = d."id"
+ON CONFLICT DO NOTHING;
+DELETE FROM "favorites" f USING "duplicate_groups" d WHERE f."entity_type" = 'group' AND f."entity_id" = d."id";
+INSERT INTO "group_aliases" ("group_id", "name", "locale", "type", "created_at")
+SELECT d."keep_id", a."name", a."locale", a."type", a."created_at"
+FROM "group_aliases" a JOIN "duplicate_groups" d ON a."group_id" = d."id"
+ON CONFLICT DO NOTHING;
+INSERT INTO "group_aliases" ("group_id", "name", "type")
+SELECT d."keep_id", btrim(d."name"), 'former'
+FROM "duplicate_groups" d JOIN "groups" k ON k."id" = d."keep_id"
+WHERE btrim(d."name") <> btrim(k."name")
+ON CONFLICT DO NOTHING;
+UPDATE "groups" SET "deleted_at" = now() WHERE "id" IN (SELECT "id" FROM "duplicate_groups");
+DROP TABLE "duplicate_groups";
+-- Drop index "idx_groups_name" from table: "groups"
+DROP INDEX "idx_groups_name";
+-- Create index "uq_groups_name_normalized" to table: "groups"
+CREATE UNIQUE INDEX "uq_groups_name_normalized" ON "groups" ((lower(btrim((name)::text)))) WHERE (deleted_at IS NULL);
@@ -0,0 +1,20 @@
+-- Create "group_deleted_songs" table
+CREATE TABLE "group_deleted_songs" (
+  "song_id" uuid NOT NULL,
+  "group_id" uuid NOT NULL,
+  "deleted_at" timestamptz NOT NULL DEFAULT now(),
+  CONSTRAINT "group_deleted_songs_pkey" PRIMARY KEY ("song_id"),
+  CONSTRAINT "fk_group_deleted_songs_group" FOREIGN KEY ("group_id") REFERENCES "groups" ("id") ON UPDATE NO ACTION ON DELETE CASCADE,
+  CONSTRAINT "fk_group_deleted_songs_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_group_deleted_songs_group_id" to table: "group_deleted_songs"
+CREATE INDEX "idx_group_deleted_songs_group_id" ON "group_deleted_songs" ("group_id");
+-- Cascade the deletion of groups deleted before songs followed them
+WITH "deleted" AS (
+  UPDATE "songs" s SET "deleted_at" = g."deleted_at"
+  FROM "groups" g
+  WHERE s."group_id" = g."id" AND g."deleted_at" IS NOT NULL AND s."deleted_at" IS NULL
+  RETURNING s."id", s."group_id", s."deleted_at"
+)
+INSERT INTO "group_deleted_songs" ("song_id", "group_id", "deleted_at")
+SELECT "id", "group_id", "deleted_at" FROM "deleted";
@@ -0,0 +1,15 @@
+-- Create "idempotency_keys" table
+CREATE TABLE "idempotency_keys" (
+  "user_id" uuid NOT NULL,
+  "key" character varying(255) NOT NULL,
+  "request_hash" bytea NOT NULL,
+  "status_code" integer NULL,
+  "content_type" character varying(255) NOT NULL DEFAULT '',
+  "response_body" bytea NULL,
+  "created_at" timestamptz NOT NULL DEFAULT now(),
+  "expires_at" timestamptz NOT NULL,
+  CONSTRAINT "idempotency_keys_pkey" PRIMARY KEY ("user_id", "key"),
+  CONSTRAINT "fk_idempotency_keys_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Create index "idx_idempotency_keys_expires_at" to table: "idempotency_keys"
+CREATE INDEX "idx_idempotency_keys_expires_at" ON "idempotency_keys" ("expires_at");
@@ -0,0 +1,4 @@
+-- Create index "idx_groups_created_at_id" to table: "groups"
+CREATE INDEX "idx_groups_created_at_id" ON "groups" ("created_at" DESC, "id" DESC) WHERE ("deleted_at" IS NULL);
+-- Create index "idx_songs_created_at_id" to table: "songs"
+CREATE INDEX "idx_songs_created_at_id" ON "songs" ("created_at" DESC, "id" DESC) WHERE ("deleted_at" IS NULL);
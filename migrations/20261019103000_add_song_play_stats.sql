@@ -0,0 +1,69 @@
+-- Create "song_play_stats" table
+CREATE TABLE "song_play_stats" (
+  "song_id" uuid NOT NULL,
+  "play_count" bigint NOT NULL DEFAULT 0,
+  "updated_at" timestamptz NOT NULL DEFAULT now(),
+  PRIMARY KEY ("song_id"),
+  CONSTRAINT "fk_song_play_stats_song" FOREIGN KEY ("song_id") REFERENCES "songs" ("id") ON UPDATE NO ACTION ON DELETE CASCADE
+);
+-- Backfill "song_play_stats" from "play_events"
+INSERT INTO "song_play_stats" ("song_id", "play_count")
+SELECT "song_id", count(*) FROM "play_events" GROUP BY "song_id";
+-- Create "song_listings" view
+CREATE VIEW "song_listings" (
+  "id",
+  "group_id",
+  "title",
+  "runtime",
+  "lyrics",
+  "release_date",
+  "link",
+  "created_at",
+  "updated_at",
+  "deleted_at",
+  "group_name",
+  "group_created_at",
+  "group_updated_at",
+  "group_deleted_at",
+  "play_count",
+  "rating_average",
+  "rating_count",
+  "artwork_width",
+  "artwork_height",
+  "artwork_format",
+  "artwork_updated_at",
+  "group_artwork_width",
+  "group_artwork_height",
+  "group_artwork_format",
+  "group_artwork_updated_at"
+) AS SELECT s.id,
+    s.group_id,
+    s.title,
+    s.runtime,
+    s.lyrics,
+    s.release_date,
+    s.link,
+    s.created_at,
+    s.updated_at,
+    s.deleted_at,
+    g.name,
+    g.created_at,
+    g.updated_at,
+    g.deleted_at,
+    (COALESCE(p.play_count, (0)::bigint))::bigint,
+    (COALESCE(r.rating_average, (0)::double precision))::double precision,
+    COALESCE(r.rating_count, 0),
+    sa.width,
+    sa.height,
+    sa.format,
+    sa.updated_at,
+    ga.width,
+    ga.height,
+    ga.format,
+    ga.updated_at
+   FROM ((((songs s
+     JOIN groups g ON ((g.id = s.group_id)))
+     LEFT JOIN song_play_stats p ON ((p.song_id = s.id)))
+     LEFT JOIN song_rating_stats r ON ((r.song_id = s.id)))
+     LEFT JOIN artworks sa ON ((((sa.entity_type)::text = 'song'::text) AND (sa.entity_id = s.id))))
+     LEFT JOIN artworks ga ON ((((ga.entity_type)::text = 'group'::text) AND (ga.entity_id = g.id))));